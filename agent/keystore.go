@@ -0,0 +1,177 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agent
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/cryptogo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"io"
+	"log"
+	"net"
+)
+
+// Connects to an agent listening on the Unix socket.
+func Dial(socket string) (*grpc.ClientConn, error) {
+	return grpc.Dial(socket, grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, "unix", address)
+	}))
+}
+
+// KeyStore holds keys in an agent; keys can be added but never read back, instead the agent signs and decrypts on the caller's behalf.
+type KeyStore struct {
+	Client AgentClient
+}
+
+func NewKeyStore(cc grpc.ClientConnInterface) *KeyStore {
+	return &KeyStore{
+		Client: NewAgentClient(cc),
+	}
+}
+
+func (s *KeyStore) AddKey(alias string, password []byte, key crypto.Signer) error {
+	return s.addKey(alias, password, key, false)
+}
+
+// Returns a signer which holds the password and sends each operation to the agent, as private keys cannot leave the agent.
+func (s *KeyStore) GetKey(alias string, password []byte) (crypto.Signer, error) {
+	response, err := s.Client.GetPublicKey(context.Background(), &UnlockRequest{
+		Alias:    alias,
+		Password: password,
+	})
+	if err != nil {
+		return nil, toError(err)
+	}
+	key, err := conveygo.ParsePublicKey(response.PublicKey, response.PublicFormat)
+	if err != nil {
+		return nil, err
+	}
+	return &signer{
+		client:   s.Client,
+		alias:    alias,
+		password: password,
+		key:      key,
+	}, nil
+}
+
+func (s *KeyStore) HasKey(alias string) bool {
+	response, err := s.Client.HasKey(context.Background(), &AliasRequest{
+		Alias: alias,
+	})
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+	return response.Exists
+}
+
+func (s *KeyStore) SetKey(alias string, password []byte, key crypto.Signer) error {
+	return s.addKey(alias, password, key, true)
+}
+
+func (s *KeyStore) ChangePassword(alias string, oldPassword, newPassword []byte) error {
+	_, err := s.Client.ChangePassword(context.Background(), &ChangePasswordRequest{
+		Alias:       alias,
+		Password:    oldPassword,
+		NewPassword: newPassword,
+	})
+	return toError(err)
+}
+
+func (s *KeyStore) addKey(alias string, password []byte, key crypto.Signer, replace bool) error {
+	privateKey, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+	_, err = s.Client.AddKey(context.Background(), &KeyRequest{
+		Alias:         alias,
+		Password:      password,
+		PrivateKey:    privateKey,
+		PrivateFormat: cryptogo.PrivateKeyFormat_PKCS8,
+		Replace:       replace,
+	})
+	return toError(err)
+}
+
+// signer implements crypto.Signer and crypto.Decrypter with a key held by an agent.
+type signer struct {
+	client   AgentClient
+	alias    string
+	password []byte
+	key      crypto.PublicKey
+}
+
+func (s *signer) Public() crypto.PublicKey {
+	return s.key
+}
+
+func (s *signer) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	request := &SignRequest{
+		Alias:    s.alias,
+		Password: s.password,
+		Digest:   digest,
+		Hash:     uint32(opts.HashFunc()),
+	}
+	if o, ok := opts.(*rsa.PSSOptions); ok {
+		request.Pss = true
+		request.SaltLength = int32(o.SaltLength)
+	}
+	response, err := s.client.Sign(context.Background(), request)
+	if err != nil {
+		return nil, toError(err)
+	}
+	return response.Signature, nil
+}
+
+func (s *signer) Decrypt(rand io.Reader, ciphertext []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	request := &DecryptRequest{
+		Alias:      s.alias,
+		Password:   s.password,
+		Ciphertext: ciphertext,
+	}
+	switch o := opts.(type) {
+	case *rsa.OAEPOptions:
+		request.Oaep = true
+		request.Hash = uint32(o.Hash)
+		request.Label = o.Label
+	case *rsa.PKCS1v15DecryptOptions:
+		request.SessionKeyLen = uint32(o.SessionKeyLen)
+	}
+	response, err := s.client.Decrypt(context.Background(), request)
+	if err != nil {
+		return nil, toError(err)
+	}
+	return response.Plaintext, nil
+}
+
+// Returns the message of a status error, so errors from the agent match those of the other key stores.
+func toError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if s, ok := status.FromError(err); ok {
+		return errors.New(s.Message())
+	}
+	return err
+}
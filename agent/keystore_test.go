@@ -0,0 +1,203 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agent_test
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/agent"
+	"github.com/AletheiaWareLLC/cryptogo"
+	"github.com/AletheiaWareLLC/testinggo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"testing"
+)
+
+// Starts an agent over the key store on a Unix socket and returns a key store connected to it.
+func makeKeyStore(t *testing.T, keys conveygo.KeyStore) (*agent.KeyStore, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "agent")
+	testinggo.AssertNoError(t, err)
+	socket := path.Join(dir, "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	testinggo.AssertNoError(t, err)
+	s := grpc.NewServer()
+	agent.RegisterAgentServer(s, agent.NewServer(keys))
+	go s.Serve(listener)
+	conn, err := agent.Dial(socket)
+	testinggo.AssertNoError(t, err)
+	return agent.NewKeyStore(conn), func() {
+		conn.Close()
+		s.Stop()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestKeyStore(t *testing.T) {
+	alias := "Alice"
+	password := []byte("password1234")
+	newPassword := []byte("newpassword5678")
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	testinggo.AssertNoError(t, err)
+	_, newKey, err := ed25519.GenerateKey(rand.Reader)
+	testinggo.AssertNoError(t, err)
+
+	keys := conveygo.NewMemoryKeyStore()
+	s, closer := makeKeyStore(t, keys)
+	defer closer()
+
+	t.Run("AddKey", func(t *testing.T) {
+		if s.HasKey(alias) {
+			t.Errorf("Expected no key for '%s'", alias)
+		}
+		testinggo.AssertNoError(t, s.AddKey(alias, password, key))
+		if !s.HasKey(alias) {
+			t.Errorf("Expected key for '%s'", alias)
+		}
+		testinggo.AssertError(t, fmt.Sprintf(conveygo.ERROR_KEY_ALREADY_EXISTS, alias), s.AddKey(alias, password, newKey))
+	})
+	t.Run("GetKey", func(t *testing.T) {
+		_, err := s.GetKey(alias, newPassword)
+		testinggo.AssertError(t, conveygo.ERROR_ACCESS_DENIED, err)
+		signer, err := s.GetKey(alias, password)
+		testinggo.AssertNoError(t, err)
+		if signer.Public().(*rsa.PublicKey).N.Cmp(key.PublicKey.N) != 0 {
+			t.Error("Wrong public key")
+		}
+		t.Run("Export", func(t *testing.T) {
+			_, err := conveygo.NewKeyBundle(alias, signer, nil, password)
+			testinggo.AssertError(t, fmt.Sprintf(conveygo.ERROR_KEY_NOT_EXPORTABLE, alias), err)
+		})
+		t.Run("Sign", func(t *testing.T) {
+			hash := cryptogo.Hash([]byte("Hello World"))
+			signature, err := signer.Sign(rand.Reader, hash, &rsa.PSSOptions{
+				SaltLength: rsa.PSSSaltLengthAuto,
+				Hash:       crypto.SHA512,
+			})
+			testinggo.AssertNoError(t, err)
+			testinggo.AssertNoError(t, cryptogo.VerifySignature(&key.PublicKey, hash, signature, cryptogo.SignatureAlgorithm_SHA512WITHRSA_PSS))
+			signature, err = signer.Sign(rand.Reader, hash, crypto.SHA512)
+			testinggo.AssertNoError(t, err)
+			testinggo.AssertNoError(t, cryptogo.VerifySignature(&key.PublicKey, hash, signature, cryptogo.SignatureAlgorithm_SHA512WITHRSA))
+		})
+		t.Run("Decrypt", func(t *testing.T) {
+			secret := []byte("secret")
+			ciphertext, err := rsa.EncryptOAEP(sha512.New(), rand.Reader, &key.PublicKey, secret, nil)
+			testinggo.AssertNoError(t, err)
+			plaintext, err := signer.(crypto.Decrypter).Decrypt(rand.Reader, ciphertext, &rsa.OAEPOptions{
+				Hash: crypto.SHA512,
+			})
+			testinggo.AssertNoError(t, err)
+			if !bytes.Equal(secret, plaintext) {
+				t.Errorf("Wrong plaintext; expected '%s', got '%s'", secret, plaintext)
+			}
+		})
+	})
+	t.Run("ChangePassword", func(t *testing.T) {
+		testinggo.AssertError(t, conveygo.ERROR_ACCESS_DENIED, s.ChangePassword(alias, newPassword, newPassword))
+		testinggo.AssertNoError(t, s.ChangePassword(alias, password, newPassword))
+		_, err := s.GetKey(alias, password)
+		testinggo.AssertError(t, conveygo.ERROR_ACCESS_DENIED, err)
+		_, err = s.GetKey(alias, newPassword)
+		testinggo.AssertNoError(t, err)
+	})
+	t.Run("SetKey", func(t *testing.T) {
+		// The password was changed so the old one cannot replace the key
+		testinggo.AssertError(t, conveygo.ERROR_ACCESS_DENIED, s.SetKey(alias, password, newKey))
+		testinggo.AssertNoError(t, s.SetKey(alias, newPassword, newKey))
+		actual, err := keys.GetKey(alias, newPassword)
+		testinggo.AssertNoError(t, err)
+		if !bytes.Equal(newKey, actual.(ed25519.PrivateKey)) {
+			t.Error("Wrong private key")
+		}
+		t.Run("Record", func(t *testing.T) {
+			signer, err := s.GetKey(alias, newPassword)
+			testinggo.AssertNoError(t, err)
+			_, err = conveygo.CreateRecord(bcgo.Timestamp(), alias, signer, nil, []byte("FooBar"))
+			testinggo.AssertError(t, fmt.Sprintf(conveygo.ERROR_KEY_CANNOT_SIGN_RECORDS, signer.Public()), err)
+			_, err = signer.(crypto.Decrypter).Decrypt(rand.Reader, []byte("ciphertext"), nil)
+			testinggo.AssertError(t, fmt.Sprintf(conveygo.ERROR_KEY_CANNOT_DECRYPT, actual), err)
+		})
+	})
+}
+
+// failingKeyStore is a key store which cannot write keys.
+type failingKeyStore struct {
+	*conveygo.MemoryKeyStore
+}
+
+func (s *failingKeyStore) SetKey(alias string, password []byte, key crypto.Signer) error {
+	return errors.New("Write failed")
+}
+
+func TestServer_AddKey(t *testing.T) {
+	alias := "Alice"
+	password := []byte("password1234")
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	testinggo.AssertNoError(t, err)
+	privateKey, err := x509.MarshalPKCS8PrivateKey(key)
+	testinggo.AssertNoError(t, err)
+
+	for name, tt := range map[string]struct {
+		keys     conveygo.KeyStore
+		existing bool
+		alias    string
+		password []byte
+		key      []byte
+		replace  bool
+		expected codes.Code
+	}{
+		"Add":           {conveygo.NewMemoryKeyStore(), false, alias, password, privateKey, false, codes.OK},
+		"Replace":       {conveygo.NewMemoryKeyStore(), true, alias, password, privateKey, true, codes.OK},
+		"InvalidAlias":  {conveygo.NewMemoryKeyStore(), false, "../Alice", password, privateKey, false, codes.InvalidArgument},
+		"InvalidKey":    {conveygo.NewMemoryKeyStore(), false, alias, password, []byte("FooBar"), false, codes.InvalidArgument},
+		"AlreadyExists": {conveygo.NewMemoryKeyStore(), true, alias, password, privateKey, false, codes.AlreadyExists},
+		"WrongPassword": {conveygo.NewMemoryKeyStore(), true, alias, []byte("wrong"), privateKey, true, codes.PermissionDenied},
+		"WriteFailed":   {&failingKeyStore{conveygo.NewMemoryKeyStore()}, false, alias, password, privateKey, false, codes.Internal},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if tt.existing {
+				testinggo.AssertNoError(t, tt.keys.AddKey(alias, password, key))
+			}
+			_, err := agent.NewServer(tt.keys).AddKey(context.Background(), &agent.KeyRequest{
+				Alias:         tt.alias,
+				Password:      tt.password,
+				PrivateKey:    tt.key,
+				PrivateFormat: cryptogo.PrivateKeyFormat_PKCS8,
+				Replace:       tt.replace,
+			})
+			if code := status.Code(err); code != tt.expected {
+				t.Errorf("Wrong code; expected '%s', got '%s' (%v)", tt.expected, code, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/agent"
+	"google.golang.org/grpc"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"path"
+	"syscall"
+)
+
+var (
+	socket   = flag.String("socket", "", "Unix socket to listen on, empty for agent.sock in the root directory")
+	keystore = flag.String("keystore", "", "directory of private keys, empty for the default keystore")
+)
+
+func main() {
+	flag.Parse()
+
+	rootDir, err := bcgo.GetRootDirectory()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	directory := *keystore
+	if directory == "" {
+		directory, err = bcgo.GetKeyDirectory(rootDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	address := *socket
+	if address == "" {
+		address = path.Join(rootDir, "agent.sock")
+	}
+	// Remove socket left by a previous run
+	if err := os.Remove(address); err != nil && !os.IsNotExist(err) {
+		log.Fatal(err)
+	}
+	// Only the owner may connect; the socket is created with these permissions so there is no window in which others can
+	mask := syscall.Umask(0177)
+	listener, err := net.Listen("unix", address)
+	syscall.Umask(mask)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server := grpc.NewServer()
+	agent.RegisterAgentServer(server, agent.NewServer(conveygo.NewFileKeyStore(directory)))
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		server.Stop()
+	}()
+
+	log.Println("Listening on", address)
+	if err := server.Serve(listener); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agent
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"github.com/AletheiaWareLLC/aliasgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/cryptogo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sync"
+)
+
+// Server implements the Agent service over a KeyStore, signing and decrypting on behalf of clients so private keys never leave the agent process.
+type Server struct {
+	Keys conveygo.KeyStore
+	lock sync.Mutex
+}
+
+func NewServer(keys conveygo.KeyStore) *Server {
+	return &Server{
+		Keys: keys,
+	}
+}
+
+// Adds the key to the store, or if replacing, swaps the existing key for it once the password has unlocked the existing key.
+func (s *Server) AddKey(ctx context.Context, request *KeyRequest) (*KeyResponse, error) {
+	if err := aliasgo.ValidateAlias(request.Alias); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if request.PrivateFormat != cryptogo.PrivateKeyFormat_PKCS8 {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf(cryptogo.ERROR_UNSUPPORTED_PRIVATE_KEY_FORMAT, request.PrivateFormat))
+	}
+	key, err := conveygo.ParsePrivateKey(request.PrivateKey)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	// Hold the lock so the existing key cannot change between the checks and the write
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.Keys.HasKey(request.Alias) {
+		if !request.Replace {
+			return nil, status.Error(codes.AlreadyExists, fmt.Sprintf(conveygo.ERROR_KEY_ALREADY_EXISTS, request.Alias))
+		}
+		if _, err := s.getKey(request.Alias, request.Password); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.Keys.SetKey(request.Alias, request.Password, key); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return publicKeyResponse(key.Public())
+}
+
+func (s *Server) HasKey(ctx context.Context, request *AliasRequest) (*HasKeyResponse, error) {
+	return &HasKeyResponse{
+		Exists: s.Keys.HasKey(request.Alias),
+	}, nil
+}
+
+func (s *Server) GetPublicKey(ctx context.Context, request *UnlockRequest) (*KeyResponse, error) {
+	key, err := s.getKey(request.Alias, request.Password)
+	if err != nil {
+		return nil, err
+	}
+	return publicKeyResponse(key.Public())
+}
+
+func (s *Server) ChangePassword(ctx context.Context, request *ChangePasswordRequest) (*KeyResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	key, err := s.getKey(request.Alias, request.Password)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Keys.ChangePassword(request.Alias, request.Password, request.NewPassword); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return publicKeyResponse(key.Public())
+}
+
+func (s *Server) Sign(ctx context.Context, request *SignRequest) (*SignResponse, error) {
+	key, err := s.getKey(request.Alias, request.Password)
+	if err != nil {
+		return nil, err
+	}
+	var opts crypto.SignerOpts = crypto.Hash(request.Hash)
+	if request.Pss {
+		opts = &rsa.PSSOptions{
+			SaltLength: int(request.SaltLength),
+			Hash:       crypto.Hash(request.Hash),
+		}
+	}
+	signature, err := key.Sign(rand.Reader, request.Digest, opts)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &SignResponse{
+		Signature: signature,
+	}, nil
+}
+
+func (s *Server) Decrypt(ctx context.Context, request *DecryptRequest) (*DecryptResponse, error) {
+	key, err := s.getKey(request.Alias, request.Password)
+	if err != nil {
+		return nil, err
+	}
+	var opts crypto.DecrypterOpts = &rsa.PKCS1v15DecryptOptions{
+		SessionKeyLen: int(request.SessionKeyLen),
+	}
+	if request.Oaep {
+		opts = &rsa.OAEPOptions{
+			Hash:  crypto.Hash(request.Hash),
+			Label: request.Label,
+		}
+	}
+	decrypter, ok := key.(crypto.Decrypter)
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf(conveygo.ERROR_KEY_CANNOT_DECRYPT, key))
+	}
+	plaintext, err := decrypter.Decrypt(rand.Reader, request.Ciphertext, opts)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &DecryptResponse{
+		Plaintext: plaintext,
+	}, nil
+}
+
+func (s *Server) getKey(alias string, password []byte) (crypto.Signer, error) {
+	key, err := s.Keys.GetKey(alias, password)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	return key, nil
+}
+
+func publicKeyResponse(key crypto.PublicKey) (*KeyResponse, error) {
+	bytes, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &KeyResponse{
+		PublicKey:    bytes,
+		PublicFormat: cryptogo.PublicKeyFormat_PKIX,
+	}, nil
+}
@@ -0,0 +1,907 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: agent/service.proto
+
+package agent
+
+import (
+	context "context"
+	fmt "fmt"
+	cryptogo "github.com/AletheiaWareLLC/cryptogo"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type KeyRequest struct {
+	Alias         string                    `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	Password      []byte                    `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	PrivateKey    []byte                    `protobuf:"bytes,3,opt,name=private_key,json=privateKey,proto3" json:"private_key,omitempty"`
+	PrivateFormat cryptogo.PrivateKeyFormat `protobuf:"varint,4,opt,name=private_format,json=privateFormat,proto3,enum=crypto.PrivateKeyFormat" json:"private_format,omitempty"`
+	// Replace any existing key of the alias, which the password must unlock.
+	Replace              bool     `protobuf:"varint,5,opt,name=replace,proto3" json:"replace,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *KeyRequest) Reset()         { *m = KeyRequest{} }
+func (m *KeyRequest) String() string { return proto.CompactTextString(m) }
+func (*KeyRequest) ProtoMessage()    {}
+func (*KeyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5c38f9e112d9fe8, []int{0}
+}
+
+func (m *KeyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyRequest.Unmarshal(m, b)
+}
+func (m *KeyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyRequest.Marshal(b, m, deterministic)
+}
+func (m *KeyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyRequest.Merge(m, src)
+}
+func (m *KeyRequest) XXX_Size() int {
+	return xxx_messageInfo_KeyRequest.Size(m)
+}
+func (m *KeyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyRequest proto.InternalMessageInfo
+
+func (m *KeyRequest) GetAlias() string {
+	if m != nil {
+		return m.Alias
+	}
+	return ""
+}
+
+func (m *KeyRequest) GetPassword() []byte {
+	if m != nil {
+		return m.Password
+	}
+	return nil
+}
+
+func (m *KeyRequest) GetPrivateKey() []byte {
+	if m != nil {
+		return m.PrivateKey
+	}
+	return nil
+}
+
+func (m *KeyRequest) GetPrivateFormat() cryptogo.PrivateKeyFormat {
+	if m != nil {
+		return m.PrivateFormat
+	}
+	return cryptogo.PrivateKeyFormat_UNKNOWN_PRIVATE_KEY_FORMAT
+}
+
+func (m *KeyRequest) GetReplace() bool {
+	if m != nil {
+		return m.Replace
+	}
+	return false
+}
+
+type KeyResponse struct {
+	PublicKey            []byte                   `protobuf:"bytes,1,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	PublicFormat         cryptogo.PublicKeyFormat `protobuf:"varint,2,opt,name=public_format,json=publicFormat,proto3,enum=crypto.PublicKeyFormat" json:"public_format,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
+	XXX_unrecognized     []byte                   `json:"-"`
+	XXX_sizecache        int32                    `json:"-"`
+}
+
+func (m *KeyResponse) Reset()         { *m = KeyResponse{} }
+func (m *KeyResponse) String() string { return proto.CompactTextString(m) }
+func (*KeyResponse) ProtoMessage()    {}
+func (*KeyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5c38f9e112d9fe8, []int{1}
+}
+
+func (m *KeyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyResponse.Unmarshal(m, b)
+}
+func (m *KeyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyResponse.Marshal(b, m, deterministic)
+}
+func (m *KeyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyResponse.Merge(m, src)
+}
+func (m *KeyResponse) XXX_Size() int {
+	return xxx_messageInfo_KeyResponse.Size(m)
+}
+func (m *KeyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyResponse proto.InternalMessageInfo
+
+func (m *KeyResponse) GetPublicKey() []byte {
+	if m != nil {
+		return m.PublicKey
+	}
+	return nil
+}
+
+func (m *KeyResponse) GetPublicFormat() cryptogo.PublicKeyFormat {
+	if m != nil {
+		return m.PublicFormat
+	}
+	return cryptogo.PublicKeyFormat_UNKNOWN_PUBLIC_KEY_FORMAT
+}
+
+type AliasRequest struct {
+	Alias                string   `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AliasRequest) Reset()         { *m = AliasRequest{} }
+func (m *AliasRequest) String() string { return proto.CompactTextString(m) }
+func (*AliasRequest) ProtoMessage()    {}
+func (*AliasRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5c38f9e112d9fe8, []int{2}
+}
+
+func (m *AliasRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AliasRequest.Unmarshal(m, b)
+}
+func (m *AliasRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AliasRequest.Marshal(b, m, deterministic)
+}
+func (m *AliasRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AliasRequest.Merge(m, src)
+}
+func (m *AliasRequest) XXX_Size() int {
+	return xxx_messageInfo_AliasRequest.Size(m)
+}
+func (m *AliasRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AliasRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AliasRequest proto.InternalMessageInfo
+
+func (m *AliasRequest) GetAlias() string {
+	if m != nil {
+		return m.Alias
+	}
+	return ""
+}
+
+type HasKeyResponse struct {
+	Exists               bool     `protobuf:"varint,1,opt,name=exists,proto3" json:"exists,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HasKeyResponse) Reset()         { *m = HasKeyResponse{} }
+func (m *HasKeyResponse) String() string { return proto.CompactTextString(m) }
+func (*HasKeyResponse) ProtoMessage()    {}
+func (*HasKeyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5c38f9e112d9fe8, []int{3}
+}
+
+func (m *HasKeyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HasKeyResponse.Unmarshal(m, b)
+}
+func (m *HasKeyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HasKeyResponse.Marshal(b, m, deterministic)
+}
+func (m *HasKeyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HasKeyResponse.Merge(m, src)
+}
+func (m *HasKeyResponse) XXX_Size() int {
+	return xxx_messageInfo_HasKeyResponse.Size(m)
+}
+func (m *HasKeyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_HasKeyResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HasKeyResponse proto.InternalMessageInfo
+
+func (m *HasKeyResponse) GetExists() bool {
+	if m != nil {
+		return m.Exists
+	}
+	return false
+}
+
+type UnlockRequest struct {
+	Alias                string   `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	Password             []byte   `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UnlockRequest) Reset()         { *m = UnlockRequest{} }
+func (m *UnlockRequest) String() string { return proto.CompactTextString(m) }
+func (*UnlockRequest) ProtoMessage()    {}
+func (*UnlockRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5c38f9e112d9fe8, []int{4}
+}
+
+func (m *UnlockRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UnlockRequest.Unmarshal(m, b)
+}
+func (m *UnlockRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UnlockRequest.Marshal(b, m, deterministic)
+}
+func (m *UnlockRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UnlockRequest.Merge(m, src)
+}
+func (m *UnlockRequest) XXX_Size() int {
+	return xxx_messageInfo_UnlockRequest.Size(m)
+}
+func (m *UnlockRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UnlockRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UnlockRequest proto.InternalMessageInfo
+
+func (m *UnlockRequest) GetAlias() string {
+	if m != nil {
+		return m.Alias
+	}
+	return ""
+}
+
+func (m *UnlockRequest) GetPassword() []byte {
+	if m != nil {
+		return m.Password
+	}
+	return nil
+}
+
+type ChangePasswordRequest struct {
+	Alias                string   `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	Password             []byte   `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	NewPassword          []byte   `protobuf:"bytes,3,opt,name=new_password,json=newPassword,proto3" json:"new_password,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ChangePasswordRequest) Reset()         { *m = ChangePasswordRequest{} }
+func (m *ChangePasswordRequest) String() string { return proto.CompactTextString(m) }
+func (*ChangePasswordRequest) ProtoMessage()    {}
+func (*ChangePasswordRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5c38f9e112d9fe8, []int{5}
+}
+
+func (m *ChangePasswordRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChangePasswordRequest.Unmarshal(m, b)
+}
+func (m *ChangePasswordRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChangePasswordRequest.Marshal(b, m, deterministic)
+}
+func (m *ChangePasswordRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChangePasswordRequest.Merge(m, src)
+}
+func (m *ChangePasswordRequest) XXX_Size() int {
+	return xxx_messageInfo_ChangePasswordRequest.Size(m)
+}
+func (m *ChangePasswordRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChangePasswordRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChangePasswordRequest proto.InternalMessageInfo
+
+func (m *ChangePasswordRequest) GetAlias() string {
+	if m != nil {
+		return m.Alias
+	}
+	return ""
+}
+
+func (m *ChangePasswordRequest) GetPassword() []byte {
+	if m != nil {
+		return m.Password
+	}
+	return nil
+}
+
+func (m *ChangePasswordRequest) GetNewPassword() []byte {
+	if m != nil {
+		return m.NewPassword
+	}
+	return nil
+}
+
+type SignRequest struct {
+	Alias    string `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	Password []byte `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	Digest   []byte `protobuf:"bytes,3,opt,name=digest,proto3" json:"digest,omitempty"`
+	// crypto.Hash used to create the digest.
+	Hash uint32 `protobuf:"varint,4,opt,name=hash,proto3" json:"hash,omitempty"`
+	// Sign with RSASSA-PSS instead of PKCS #1 v1.5.
+	Pss                  bool     `protobuf:"varint,5,opt,name=pss,proto3" json:"pss,omitempty"`
+	SaltLength           int32    `protobuf:"zigzag32,6,opt,name=salt_length,json=saltLength,proto3" json:"salt_length,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SignRequest) Reset()         { *m = SignRequest{} }
+func (m *SignRequest) String() string { return proto.CompactTextString(m) }
+func (*SignRequest) ProtoMessage()    {}
+func (*SignRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5c38f9e112d9fe8, []int{6}
+}
+
+func (m *SignRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SignRequest.Unmarshal(m, b)
+}
+func (m *SignRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SignRequest.Marshal(b, m, deterministic)
+}
+func (m *SignRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SignRequest.Merge(m, src)
+}
+func (m *SignRequest) XXX_Size() int {
+	return xxx_messageInfo_SignRequest.Size(m)
+}
+func (m *SignRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SignRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SignRequest proto.InternalMessageInfo
+
+func (m *SignRequest) GetAlias() string {
+	if m != nil {
+		return m.Alias
+	}
+	return ""
+}
+
+func (m *SignRequest) GetPassword() []byte {
+	if m != nil {
+		return m.Password
+	}
+	return nil
+}
+
+func (m *SignRequest) GetDigest() []byte {
+	if m != nil {
+		return m.Digest
+	}
+	return nil
+}
+
+func (m *SignRequest) GetHash() uint32 {
+	if m != nil {
+		return m.Hash
+	}
+	return 0
+}
+
+func (m *SignRequest) GetPss() bool {
+	if m != nil {
+		return m.Pss
+	}
+	return false
+}
+
+func (m *SignRequest) GetSaltLength() int32 {
+	if m != nil {
+		return m.SaltLength
+	}
+	return 0
+}
+
+type SignResponse struct {
+	Signature            []byte   `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SignResponse) Reset()         { *m = SignResponse{} }
+func (m *SignResponse) String() string { return proto.CompactTextString(m) }
+func (*SignResponse) ProtoMessage()    {}
+func (*SignResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5c38f9e112d9fe8, []int{7}
+}
+
+func (m *SignResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SignResponse.Unmarshal(m, b)
+}
+func (m *SignResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SignResponse.Marshal(b, m, deterministic)
+}
+func (m *SignResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SignResponse.Merge(m, src)
+}
+func (m *SignResponse) XXX_Size() int {
+	return xxx_messageInfo_SignResponse.Size(m)
+}
+func (m *SignResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SignResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SignResponse proto.InternalMessageInfo
+
+func (m *SignResponse) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+type DecryptRequest struct {
+	Alias      string `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	Password   []byte `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	Ciphertext []byte `protobuf:"bytes,3,opt,name=ciphertext,proto3" json:"ciphertext,omitempty"`
+	// Decrypt with RSA-OAEP instead of PKCS #1 v1.5.
+	Oaep bool `protobuf:"varint,4,opt,name=oaep,proto3" json:"oaep,omitempty"`
+	// crypto.Hash used by RSA-OAEP.
+	Hash  uint32 `protobuf:"varint,5,opt,name=hash,proto3" json:"hash,omitempty"`
+	Label []byte `protobuf:"bytes,6,opt,name=label,proto3" json:"label,omitempty"`
+	// Length of the session key expected by PKCS #1 v1.5, zero for any length.
+	SessionKeyLen        uint32   `protobuf:"varint,7,opt,name=session_key_len,json=sessionKeyLen,proto3" json:"session_key_len,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DecryptRequest) Reset()         { *m = DecryptRequest{} }
+func (m *DecryptRequest) String() string { return proto.CompactTextString(m) }
+func (*DecryptRequest) ProtoMessage()    {}
+func (*DecryptRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5c38f9e112d9fe8, []int{8}
+}
+
+func (m *DecryptRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DecryptRequest.Unmarshal(m, b)
+}
+func (m *DecryptRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DecryptRequest.Marshal(b, m, deterministic)
+}
+func (m *DecryptRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DecryptRequest.Merge(m, src)
+}
+func (m *DecryptRequest) XXX_Size() int {
+	return xxx_messageInfo_DecryptRequest.Size(m)
+}
+func (m *DecryptRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DecryptRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DecryptRequest proto.InternalMessageInfo
+
+func (m *DecryptRequest) GetAlias() string {
+	if m != nil {
+		return m.Alias
+	}
+	return ""
+}
+
+func (m *DecryptRequest) GetPassword() []byte {
+	if m != nil {
+		return m.Password
+	}
+	return nil
+}
+
+func (m *DecryptRequest) GetCiphertext() []byte {
+	if m != nil {
+		return m.Ciphertext
+	}
+	return nil
+}
+
+func (m *DecryptRequest) GetOaep() bool {
+	if m != nil {
+		return m.Oaep
+	}
+	return false
+}
+
+func (m *DecryptRequest) GetHash() uint32 {
+	if m != nil {
+		return m.Hash
+	}
+	return 0
+}
+
+func (m *DecryptRequest) GetLabel() []byte {
+	if m != nil {
+		return m.Label
+	}
+	return nil
+}
+
+func (m *DecryptRequest) GetSessionKeyLen() uint32 {
+	if m != nil {
+		return m.SessionKeyLen
+	}
+	return 0
+}
+
+type DecryptResponse struct {
+	Plaintext            []byte   `protobuf:"bytes,1,opt,name=plaintext,proto3" json:"plaintext,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DecryptResponse) Reset()         { *m = DecryptResponse{} }
+func (m *DecryptResponse) String() string { return proto.CompactTextString(m) }
+func (*DecryptResponse) ProtoMessage()    {}
+func (*DecryptResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5c38f9e112d9fe8, []int{9}
+}
+
+func (m *DecryptResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DecryptResponse.Unmarshal(m, b)
+}
+func (m *DecryptResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DecryptResponse.Marshal(b, m, deterministic)
+}
+func (m *DecryptResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DecryptResponse.Merge(m, src)
+}
+func (m *DecryptResponse) XXX_Size() int {
+	return xxx_messageInfo_DecryptResponse.Size(m)
+}
+func (m *DecryptResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DecryptResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DecryptResponse proto.InternalMessageInfo
+
+func (m *DecryptResponse) GetPlaintext() []byte {
+	if m != nil {
+		return m.Plaintext
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*KeyRequest)(nil), "convey.agent.KeyRequest")
+	proto.RegisterType((*KeyResponse)(nil), "convey.agent.KeyResponse")
+	proto.RegisterType((*AliasRequest)(nil), "convey.agent.AliasRequest")
+	proto.RegisterType((*HasKeyResponse)(nil), "convey.agent.HasKeyResponse")
+	proto.RegisterType((*UnlockRequest)(nil), "convey.agent.UnlockRequest")
+	proto.RegisterType((*ChangePasswordRequest)(nil), "convey.agent.ChangePasswordRequest")
+	proto.RegisterType((*SignRequest)(nil), "convey.agent.SignRequest")
+	proto.RegisterType((*SignResponse)(nil), "convey.agent.SignResponse")
+	proto.RegisterType((*DecryptRequest)(nil), "convey.agent.DecryptRequest")
+	proto.RegisterType((*DecryptResponse)(nil), "convey.agent.DecryptResponse")
+}
+
+func init() {
+	proto.RegisterFile("agent/service.proto", fileDescriptor_a5c38f9e112d9fe8)
+}
+
+var fileDescriptor_a5c38f9e112d9fe8 = []byte{
+	// 639 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x54, 0xdd, 0x6e, 0xd3, 0x30,
+	0x14, 0x5e, 0xb6, 0xb5, 0xeb, 0x4e, 0xd3, 0x0e, 0xcc, 0x18, 0x21, 0x6c, 0x50, 0x02, 0x42, 0x45,
+	0xa0, 0x56, 0x1a, 0xb7, 0x48, 0x53, 0xd9, 0x04, 0x93, 0xd6, 0x8b, 0x29, 0x08, 0x21, 0x71, 0x33,
+	0xb9, 0xe9, 0x21, 0x31, 0xcb, 0x9c, 0x10, 0xbb, 0xdb, 0xfa, 0x32, 0xbc, 0x06, 0x12, 0x4f, 0xc0,
+	0x63, 0xa1, 0xd8, 0x4e, 0x96, 0x4c, 0xa3, 0x17, 0xbd, 0xcb, 0x39, 0xdf, 0x67, 0xe7, 0xfb, 0xce,
+	0x8f, 0xe1, 0x01, 0x0d, 0x91, 0xcb, 0xa1, 0xc0, 0xec, 0x92, 0x05, 0x38, 0x48, 0xb3, 0x44, 0x26,
+	0xc4, 0x0e, 0x12, 0x7e, 0x89, 0xf3, 0x81, 0xc2, 0x5c, 0x3b, 0xc8, 0xe6, 0xa9, 0x4c, 0x34, 0xe6,
+	0xfd, 0xb1, 0x00, 0x4e, 0x70, 0xee, 0xe3, 0xcf, 0x19, 0x0a, 0x49, 0xb6, 0xa1, 0x41, 0x63, 0x46,
+	0x85, 0x63, 0xf5, 0xac, 0xfe, 0xa6, 0xaf, 0x03, 0xe2, 0x42, 0x2b, 0xa5, 0x42, 0x5c, 0x25, 0xd9,
+	0xd4, 0x59, 0xed, 0x59, 0x7d, 0xdb, 0x2f, 0x63, 0xf2, 0x0c, 0xda, 0x69, 0xc6, 0x2e, 0xa9, 0xc4,
+	0xb3, 0x73, 0x9c, 0x3b, 0x6b, 0x0a, 0x06, 0x93, 0x3a, 0xc1, 0x39, 0x39, 0x80, 0x6e, 0x41, 0xf8,
+	0x9e, 0x64, 0x17, 0x54, 0x3a, 0xeb, 0x3d, 0xab, 0xdf, 0xdd, 0x77, 0x06, 0x46, 0xc8, 0x69, 0xc9,
+	0xfd, 0xa8, 0x70, 0xbf, 0x63, 0xf8, 0x3a, 0x24, 0x0e, 0x6c, 0x64, 0x98, 0xc6, 0x34, 0x40, 0xa7,
+	0xd1, 0xb3, 0xfa, 0x2d, 0xbf, 0x08, 0xbd, 0x1f, 0xd0, 0x56, 0xda, 0x45, 0x9a, 0x70, 0x81, 0x64,
+	0x0f, 0x20, 0x9d, 0x4d, 0x62, 0x16, 0x28, 0x25, 0x96, 0x52, 0xb2, 0xa9, 0x33, 0xb9, 0x90, 0xf7,
+	0xd0, 0x31, 0xb0, 0xd1, 0xb1, 0xaa, 0x74, 0x3c, 0x2a, 0x75, 0x14, 0x4c, 0x23, 0xc3, 0xd6, 0x6c,
+	0x1d, 0x79, 0x2f, 0xc1, 0x1e, 0xe5, 0xc5, 0x58, 0x58, 0x29, 0xaf, 0x0f, 0xdd, 0x63, 0x2a, 0xaa,
+	0xa2, 0x76, 0xa0, 0x89, 0xd7, 0x4c, 0x48, 0x4d, 0x6c, 0xf9, 0x26, 0xf2, 0x46, 0xd0, 0xf9, 0xc2,
+	0xe3, 0x24, 0x38, 0x5f, 0xba, 0xf4, 0x5e, 0x0c, 0x0f, 0x0f, 0x23, 0xca, 0x43, 0x3c, 0x35, 0x99,
+	0xe5, 0xbb, 0xf8, 0x1c, 0x6c, 0x8e, 0x57, 0x67, 0x25, 0xae, 0xdb, 0xd8, 0xe6, 0x78, 0x55, 0xdc,
+	0xed, 0xfd, 0xb2, 0xa0, 0xfd, 0x99, 0x85, 0x7c, 0xf9, 0x9f, 0xec, 0x40, 0x73, 0xca, 0x42, 0x14,
+	0xd2, 0x5c, 0x6f, 0x22, 0x42, 0x60, 0x3d, 0xa2, 0x22, 0x52, 0x73, 0xd1, 0xf1, 0xd5, 0x37, 0xb9,
+	0x07, 0x6b, 0xa9, 0x10, 0xa6, 0xe1, 0xf9, 0x67, 0x3e, 0x68, 0x82, 0xc6, 0xf2, 0x2c, 0x46, 0x1e,
+	0xca, 0xc8, 0x69, 0xf6, 0xac, 0xfe, 0x7d, 0x1f, 0xf2, 0xd4, 0x58, 0x65, 0xbc, 0xb7, 0x60, 0x6b,
+	0x7d, 0xa6, 0xf2, 0xbb, 0xb0, 0x29, 0x58, 0xc8, 0xa9, 0x9c, 0x65, 0x58, 0x4c, 0x43, 0x99, 0xf0,
+	0xfe, 0x5a, 0xd0, 0x3d, 0x42, 0xd5, 0xfa, 0xe5, 0x1d, 0x3d, 0x05, 0x08, 0x58, 0x1a, 0x61, 0x26,
+	0xf1, 0xba, 0x70, 0x55, 0xc9, 0xe4, 0xce, 0x12, 0x8a, 0xa9, 0x72, 0xd6, 0xf2, 0xd5, 0x77, 0xe9,
+	0xb6, 0x51, 0x71, 0xbb, 0x0d, 0x8d, 0x98, 0x4e, 0x30, 0x56, 0xae, 0x6c, 0x5f, 0x07, 0xe4, 0x15,
+	0x6c, 0x09, 0x14, 0x82, 0x25, 0x3c, 0x1f, 0xe8, 0xdc, 0xb8, 0xb3, 0xa1, 0x0e, 0x75, 0x4c, 0xfa,
+	0x04, 0xe7, 0x63, 0xe4, 0xde, 0x10, 0xb6, 0x4a, 0x27, 0x37, 0xde, 0xd3, 0x98, 0x32, 0xae, 0x74,
+	0x15, 0x9b, 0x50, 0x24, 0xf6, 0x7f, 0xaf, 0x41, 0x63, 0x94, 0x3f, 0x06, 0xe4, 0x00, 0x9a, 0xa3,
+	0xe9, 0x34, 0xdf, 0x0e, 0x67, 0x50, 0x7d, 0x25, 0x06, 0x37, 0x6f, 0x82, 0xfb, 0xf8, 0x0e, 0x44,
+	0xff, 0xc6, 0x5b, 0x21, 0x47, 0xd0, 0xd4, 0x03, 0x4f, 0xdc, 0x3a, 0xad, 0xba, 0x2c, 0xee, 0x6e,
+	0x1d, 0xab, 0xaf, 0x88, 0xb7, 0x42, 0x8e, 0xc1, 0xfe, 0x84, 0xb2, 0x5c, 0x40, 0xf2, 0xa4, 0xce,
+	0xaf, 0x2d, 0xca, 0x62, 0x3d, 0x3e, 0x74, 0xeb, 0x3b, 0x41, 0x5e, 0xd4, 0xe9, 0x77, 0x6e, 0xcc,
+	0xe2, 0x3b, 0x0f, 0x60, 0x3d, 0x1f, 0x2c, 0x72, 0x8b, 0x54, 0x59, 0x06, 0xd7, 0xbd, 0x0b, 0xaa,
+	0xd8, 0xdb, 0x30, 0x0d, 0x22, 0xb7, 0x2a, 0x51, 0x9f, 0x40, 0x77, 0xef, 0x3f, 0x68, 0x71, 0xd3,
+	0x87, 0x37, 0xdf, 0x5e, 0x87, 0x4c, 0x46, 0xb3, 0xc9, 0x20, 0x48, 0x2e, 0x86, 0xa3, 0x18, 0x65,
+	0x84, 0x8c, 0x7e, 0xa5, 0x19, 0x8e, 0xc7, 0x87, 0x43, 0x7d, 0x38, 0x4c, 0x86, 0xea, 0xf8, 0xa4,
+	0xa9, 0x9e, 0xf8, 0x77, 0xff, 0x06, 0x00, 0x2d, 0x29, 0x69, 0x4a, 0x15, 0x06, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// AgentClient is the client API for Agent service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AgentClient interface {
+	// Adds a private key, returning its public key.
+	AddKey(ctx context.Context, in *KeyRequest, opts ...grpc.CallOption) (*KeyResponse, error)
+	// Returns whether a key is held for an alias.
+	HasKey(ctx context.Context, in *AliasRequest, opts ...grpc.CallOption) (*HasKeyResponse, error)
+	// Unlocks a key with its password, returning its public key.
+	GetPublicKey(ctx context.Context, in *UnlockRequest, opts ...grpc.CallOption) (*KeyResponse, error)
+	// Changes the password protecting a key.
+	ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*KeyResponse, error)
+	// Signs a digest with a key.
+	Sign(ctx context.Context, in *SignRequest, opts ...grpc.CallOption) (*SignResponse, error)
+	// Decrypts a ciphertext with a key.
+	Decrypt(ctx context.Context, in *DecryptRequest, opts ...grpc.CallOption) (*DecryptResponse, error)
+}
+
+type agentClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAgentClient(cc grpc.ClientConnInterface) AgentClient {
+	return &agentClient{cc}
+}
+
+func (c *agentClient) AddKey(ctx context.Context, in *KeyRequest, opts ...grpc.CallOption) (*KeyResponse, error) {
+	out := new(KeyResponse)
+	err := c.cc.Invoke(ctx, "/convey.agent.Agent/AddKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) HasKey(ctx context.Context, in *AliasRequest, opts ...grpc.CallOption) (*HasKeyResponse, error) {
+	out := new(HasKeyResponse)
+	err := c.cc.Invoke(ctx, "/convey.agent.Agent/HasKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) GetPublicKey(ctx context.Context, in *UnlockRequest, opts ...grpc.CallOption) (*KeyResponse, error) {
+	out := new(KeyResponse)
+	err := c.cc.Invoke(ctx, "/convey.agent.Agent/GetPublicKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*KeyResponse, error) {
+	out := new(KeyResponse)
+	err := c.cc.Invoke(ctx, "/convey.agent.Agent/ChangePassword", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) Sign(ctx context.Context, in *SignRequest, opts ...grpc.CallOption) (*SignResponse, error) {
+	out := new(SignResponse)
+	err := c.cc.Invoke(ctx, "/convey.agent.Agent/Sign", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) Decrypt(ctx context.Context, in *DecryptRequest, opts ...grpc.CallOption) (*DecryptResponse, error) {
+	out := new(DecryptResponse)
+	err := c.cc.Invoke(ctx, "/convey.agent.Agent/Decrypt", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AgentServer is the server API for Agent service.
+type AgentServer interface {
+	// Adds a private key, returning its public key.
+	AddKey(context.Context, *KeyRequest) (*KeyResponse, error)
+	// Returns whether a key is held for an alias.
+	HasKey(context.Context, *AliasRequest) (*HasKeyResponse, error)
+	// Unlocks a key with its password, returning its public key.
+	GetPublicKey(context.Context, *UnlockRequest) (*KeyResponse, error)
+	// Changes the password protecting a key.
+	ChangePassword(context.Context, *ChangePasswordRequest) (*KeyResponse, error)
+	// Signs a digest with a key.
+	Sign(context.Context, *SignRequest) (*SignResponse, error)
+	// Decrypts a ciphertext with a key.
+	Decrypt(context.Context, *DecryptRequest) (*DecryptResponse, error)
+}
+
+// UnimplementedAgentServer can be embedded to have forward compatible implementations.
+type UnimplementedAgentServer struct {
+}
+
+func (*UnimplementedAgentServer) AddKey(ctx context.Context, req *KeyRequest) (*KeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddKey not implemented")
+}
+func (*UnimplementedAgentServer) HasKey(ctx context.Context, req *AliasRequest) (*HasKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HasKey not implemented")
+}
+func (*UnimplementedAgentServer) GetPublicKey(ctx context.Context, req *UnlockRequest) (*KeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPublicKey not implemented")
+}
+func (*UnimplementedAgentServer) ChangePassword(ctx context.Context, req *ChangePasswordRequest) (*KeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangePassword not implemented")
+}
+func (*UnimplementedAgentServer) Sign(ctx context.Context, req *SignRequest) (*SignResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Sign not implemented")
+}
+func (*UnimplementedAgentServer) Decrypt(ctx context.Context, req *DecryptRequest) (*DecryptResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Decrypt not implemented")
+}
+
+func RegisterAgentServer(s *grpc.Server, srv AgentServer) {
+	s.RegisterService(&_Agent_serviceDesc, srv)
+}
+
+func _Agent_AddKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).AddKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/convey.agent.Agent/AddKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).AddKey(ctx, req.(*KeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_HasKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AliasRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).HasKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/convey.agent.Agent/HasKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).HasKey(ctx, req.(*AliasRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_GetPublicKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).GetPublicKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/convey.agent.Agent/GetPublicKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).GetPublicKey(ctx, req.(*UnlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_ChangePassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangePasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).ChangePassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/convey.agent.Agent/ChangePassword",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).ChangePassword(ctx, req.(*ChangePasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_Sign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).Sign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/convey.agent.Agent/Sign",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).Sign(ctx, req.(*SignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_Decrypt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DecryptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).Decrypt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/convey.agent.Agent/Decrypt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).Decrypt(ctx, req.(*DecryptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Agent_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "convey.agent.Agent",
+	HandlerType: (*AgentServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddKey",
+			Handler:    _Agent_AddKey_Handler,
+		},
+		{
+			MethodName: "HasKey",
+			Handler:    _Agent_HasKey_Handler,
+		},
+		{
+			MethodName: "GetPublicKey",
+			Handler:    _Agent_GetPublicKey_Handler,
+		},
+		{
+			MethodName: "ChangePassword",
+			Handler:    _Agent_ChangePassword_Handler,
+		},
+		{
+			MethodName: "Sign",
+			Handler:    _Agent_Sign_Handler,
+		},
+		{
+			MethodName: "Decrypt",
+			Handler:    _Agent_Decrypt_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "agent/service.proto",
+}
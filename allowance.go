@@ -0,0 +1,117 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo
+
+import (
+	"github.com/AletheiaWareLLC/bcgo"
+	"time"
+)
+
+const BYTES_PER_TOKEN = 100
+
+// Returns the Tokens it costs to post the given number of bytes.
+func BytesToTokens(size uint64) uint64 {
+	return (size + BYTES_PER_TOKEN - 1) / BYTES_PER_TOKEN
+}
+
+// PostingPlan is the monthly posting allowance given to subscribers of a plan, in Tokens, Bytes, or both.
+type PostingPlan struct {
+	Tokens uint64
+	Bytes  uint64
+}
+
+// Returns the monthly posting allowance of the plan in Tokens.
+func (p *PostingPlan) GetAllowance() uint64 {
+	return p.Tokens + BytesToTokens(p.Bytes)
+}
+
+// Allowance is the Tokens an Alias may post each month without spending their balance, from the month they became eligible.
+type Allowance struct {
+	Tokens uint64
+	Since  uint64
+}
+
+// Sets the alias's posting allowance to that of the plan returned by GetSubscription.
+// Eligibility starts from the month it is first set, so posts made before subscribing are not covered.
+func (l *Ledger) UpdateAllowance(users UserStore, alias string) error {
+	subscription, err := users.GetSubscription(alias)
+	if err != nil {
+		return err
+	}
+	if subscription == nil {
+		return nil
+	}
+	plan, ok := l.Plans[subscription.PlanId]
+	if !ok {
+		return nil
+	}
+	allowance, ok := l.Allowances[alias]
+	if !ok {
+		start, _ := InvoicePeriod(time.Unix(0, int64(bcgo.Timestamp())))
+		allowance = &Allowance{
+			Since: start,
+		}
+		l.Allowances[alias] = allowance
+	}
+	allowance.Tokens = plan.GetAllowance()
+	return nil
+}
+
+// Returns the Tokens posted by the alias which were covered by their allowance, rather than their balance.
+func (l *Ledger) GetAllowanceUsed(alias string) uint64 {
+	allowance, ok := l.Allowances[alias]
+	if !ok {
+		return 0
+	}
+	// Month Start -> Tokens Posted
+	posted := make(map[uint64]uint64)
+	for _, p := range l.Posts[alias] {
+		if p.Timestamp < allowance.Since {
+			continue
+		}
+		start, _ := InvoicePeriod(time.Unix(0, int64(p.Timestamp)))
+		posted[start] += p.Cost
+	}
+	var used uint64
+	for _, cost := range posted {
+		if cost > allowance.Tokens {
+			cost = allowance.Tokens
+		}
+		used += cost
+	}
+	return used
+}
+
+// Returns the allowance the alias has left to post this month.
+func (l *Ledger) GetAllowanceRemaining(alias string) uint64 {
+	allowance, ok := l.Allowances[alias]
+	if !ok {
+		return 0
+	}
+	start, end := InvoicePeriod(time.Unix(0, int64(bcgo.Timestamp())))
+	if start < allowance.Since {
+		start = allowance.Since
+	}
+	var posted uint64
+	for _, p := range l.GetPosts(alias, start, end) {
+		posted += p.Cost
+	}
+	if posted >= allowance.Tokens {
+		return 0
+	}
+	return allowance.Tokens - posted
+}
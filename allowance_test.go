@@ -0,0 +1,102 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/testinggo"
+	"testing"
+	"time"
+)
+
+func TestPostingPlan(t *testing.T) {
+	plan := &conveygo.PostingPlan{
+		Tokens: 10,
+		Bytes:  250,
+	}
+	if a := plan.GetAllowance(); a != 13 {
+		t.Errorf("Wrong allowance; expected '13', got '%d'", a)
+	}
+}
+
+func TestLedgerAllowance(t *testing.T) {
+	alias := "Alice"
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	testinggo.AssertNoError(t, err)
+
+	setup := func(t *testing.T, plan string) (*conveygo.MemoryStore, *conveygo.Ledger) {
+		t.Helper()
+		s := conveygo.NewMemoryStore()
+		_, err := s.RegisterCustomer(alias, key, "alice@example.com", "payment1234")
+		testinggo.AssertNoError(t, err)
+		_, err = s.SubscribeCustomer(alias, key, "product1234", plan)
+		testinggo.AssertNoError(t, err)
+		ledger := conveygo.NewLedger(nil)
+		ledger.Plans["plan1234"] = &conveygo.PostingPlan{
+			Tokens: 10,
+		}
+		ledger.RecordMinted(alias, 100)
+		return s, ledger
+	}
+	post := func(ledger *conveygo.Ledger, timestamp, cost uint64) {
+		ledger.RecordBurned(alias, cost)
+		ledger.RecordPosted(alias, &conveygo.Post{
+			Channel:   conveygo.CONVEY_CONVERSATION,
+			Timestamp: timestamp,
+			Cost:      cost,
+		})
+	}
+	assertBalance := func(t *testing.T, ledger *conveygo.Ledger, expectedBalance int64, expectedAllowance uint64) {
+		t.Helper()
+		balance, allowance := ledger.GetBalance(alias)
+		if balance != expectedBalance || allowance != expectedAllowance {
+			t.Errorf("Wrong balance; expected '%d' tokens and '%d' allowance, got '%d' and '%d'", expectedBalance, expectedAllowance, balance, allowance)
+		}
+	}
+
+	t.Run("Subscribed", func(t *testing.T) {
+		s, ledger := setup(t, "plan1234")
+		testinggo.AssertNoError(t, ledger.UpdateAllowance(s, alias))
+		assertBalance(t, ledger, 100, 10)
+		// Allowance is consumed before tokens
+		post(ledger, bcgo.Timestamp(), 4)
+		assertBalance(t, ledger, 100, 6)
+		post(ledger, bcgo.Timestamp(), 10)
+		assertBalance(t, ledger, 96, 0)
+	})
+	t.Run("NotSubscribed", func(t *testing.T) {
+		_, ledger := setup(t, "plan1234")
+		testinggo.AssertNoError(t, ledger.UpdateAllowance(conveygo.NewMemoryStore(), alias))
+		post(ledger, bcgo.Timestamp(), 4)
+		assertBalance(t, ledger, 96, 0)
+	})
+	t.Run("UnknownPlan", func(t *testing.T) {
+		s, ledger := setup(t, "plan5678")
+		testinggo.AssertNoError(t, ledger.UpdateAllowance(s, alias))
+		post(ledger, bcgo.Timestamp(), 4)
+		assertBalance(t, ledger, 96, 0)
+	})
+	t.Run("PostedBeforeSubscribing", func(t *testing.T) {
+		s, ledger := setup(t, "plan1234")
+		post(ledger, uint64(time.Now().AddDate(0, -1, 0).UnixNano()), 4)
+		testinggo.AssertNoError(t, ledger.UpdateAllowance(s, alias))
+		assertBalance(t, ledger, 96, 10)
+	})
+}
@@ -0,0 +1,625 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/notifications"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_LIMIT         = 10
+	DEFAULT_DIGEST_PERIOD = 7 * 24 * time.Hour
+
+	VIEW_TREE = "tree"
+
+	ERROR_MISSING_FIELD      = "Missing field: %s"
+	ERROR_METHOD_NOT_ALLOWED = "Method not allowed: %s"
+	ERROR_NO_SUCH_MESSAGE    = "No such message: %s"
+	ERROR_NOT_FOUND          = "Not found: %s"
+	ERROR_UNAUTHORIZED       = "Unauthorized"
+)
+
+type ConversationResult struct {
+	Hash      string
+	Timestamp uint64
+	Author    string
+	Topic     string
+	Cost      uint64
+}
+
+type MessageResult struct {
+	Hash      string
+	Previous  string `json:",omitempty"`
+	Timestamp uint64
+	Author    string
+	Cost      uint64
+	Type      string
+	Content   string
+	Replies   []*MessageResult `json:",omitempty"`
+}
+
+type YieldResult struct {
+	Cost   uint64
+	Reward uint64
+	Yield  int64
+}
+
+type BalanceResult struct {
+	Alias   string
+	Balance int64
+}
+
+type StatementResult struct {
+	Alias     string
+	Balance   int64
+	Allowance uint64
+	Minted    uint64
+	Burned    uint64
+	Bought    uint64
+	Sold      uint64
+	Earned    uint64
+	Spent     uint64
+	Locked    int64
+}
+
+type DigestEntryResult struct {
+	Hash      string
+	Topic     string
+	Timestamp string
+	Author    string
+	Cost      uint64
+	Reward    uint64
+	Yield     int64
+	Type      string
+	Content   string
+}
+
+type DigestResult struct {
+	From    uint64
+	To      uint64
+	Entries []*DigestEntryResult
+}
+
+type RecordResult struct {
+	Hash string
+}
+
+// Body of a request to start a conversation.
+type NewConversationRequest struct {
+	Topic   string
+	Content string
+}
+
+// Body of a request to reply to a message.
+type ReplyRequest struct {
+	Previous string
+	Content  string
+}
+
+// Body of a request to mark notifications as read; no IDs marks them all.
+type ReadRequest struct {
+	IDs []string
+}
+
+type NotificationsResult struct {
+	Unread        int
+	Notifications []*notifications.Notification
+}
+
+type ErrorResult struct {
+	Error string
+}
+
+// Server exposes the stores and ledger as a JSON API.
+// Requests that write records authenticate with HTTP Basic credentials, which unlock the alias's key in the UserStore.
+// Notifications are served from the Inbox if it is set.
+// Access to the stores and ledger is serialized by the lock, as they are not safe for concurrent use.
+type Server struct {
+	Messages conveygo.MessageStore
+	Users    conveygo.UserStore
+	Ledger   *conveygo.Ledger
+	Inbox    *notifications.Inbox
+	lock     sync.Mutex
+}
+
+func NewServer(messages conveygo.MessageStore, users conveygo.UserStore, ledger *conveygo.Ledger) *Server {
+	return &Server{
+		Messages: messages,
+		Users:    users,
+		Ledger:   ledger,
+	}
+}
+
+// Returns a handler serving;
+//
+//	GET  /conversations?limit=N or ?from=T&to=T
+//	POST /conversations
+//	GET  /conversations/<hash>
+//	GET  /conversations/<hash>/messages[?view=tree]
+//	POST /conversations/<hash>/messages
+//	GET  /conversations/<hash>/yield
+//	GET  /aliases/<alias>/balance
+//	GET  /aliases/<alias>/statement
+//	GET  /aliases/<alias>/notifications[?unread=true]
+//	POST /aliases/<alias>/notifications/read
+//	GET  /digest?from=T&to=T
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/conversations", s.HandleConversations)
+	mux.HandleFunc("/conversations/", s.HandleConversation)
+	mux.HandleFunc("/aliases/", s.HandleAlias)
+	mux.HandleFunc("/digest", s.HandleDigest)
+	return mux
+}
+
+func (s *Server) HandleConversations(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	switch r.Method {
+	case http.MethodGet:
+		var listings []*conveygo.Listing
+		var err error
+		query := r.URL.Query()
+		if query.Get("from") != "" || query.Get("to") != "" {
+			var from, to uint64
+			from, to, err = parsePeriod(r, 0)
+			if err != nil {
+				WriteError(w, http.StatusBadRequest, err)
+				return
+			}
+			listings, err = s.Messages.GetAllConversations(from, to)
+		} else {
+			limit := uint64(DEFAULT_LIMIT)
+			if l := query.Get("limit"); l != "" {
+				limit, err = strconv.ParseUint(l, 10, 32)
+				if err != nil {
+					WriteError(w, http.StatusBadRequest, err)
+					return
+				}
+			}
+			listings, err = s.Messages.GetRecentConversations(uint(limit))
+		}
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		results := make([]*ConversationResult, len(listings))
+		for i, l := range listings {
+			results[i] = ListingToResult(l)
+		}
+		WriteJSON(w, http.StatusOK, results)
+	case http.MethodPost:
+		alias, key, ok := s.authenticate(w, r)
+		if !ok {
+			return
+		}
+		request := &NewConversationRequest{}
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		if request.Topic == "" {
+			WriteError(w, http.StatusBadRequest, errors.New(fmt.Sprintf(ERROR_MISSING_FIELD, "Topic")))
+			return
+		}
+		if request.Content == "" {
+			WriteError(w, http.StatusBadRequest, errors.New(fmt.Sprintf(ERROR_MISSING_FIELD, "Content")))
+			return
+		}
+		timestamp := bcgo.Timestamp()
+		conversationHash, conversationRecord, err := conveygo.ProtoToRecord(alias, key, timestamp, &conveygo.Conversation{
+			Topic: request.Topic,
+		})
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		messageHash, messageRecord, err := conveygo.ProtoToRecord(alias, key, timestamp, &conveygo.Message{
+			Content: []byte(request.Content),
+			Type:    conveygo.MediaType_TEXT_PLAIN,
+		})
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if err := s.Messages.NewConversation(conversationHash, conversationRecord, messageHash, messageRecord); err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		WriteJSON(w, http.StatusCreated, &RecordResult{
+			Hash: base64.RawURLEncoding.EncodeToString(conversationHash),
+		})
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, errors.New(fmt.Sprintf(ERROR_METHOD_NOT_ALLOWED, r.Method)))
+	}
+}
+
+func (s *Server) HandleConversation(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/conversations/"), "/")
+	conversationHash, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(conversationHash) == 0 {
+		WriteError(w, http.StatusNotFound, errors.New(fmt.Sprintf(ERROR_NOT_FOUND, r.URL.Path)))
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		listing, err := s.Messages.GetConversation(conversationHash)
+		if err != nil {
+			WriteError(w, http.StatusNotFound, err)
+			return
+		}
+		WriteJSON(w, http.StatusOK, ListingToResult(listing))
+	case len(parts) == 2 && parts[1] == "messages" && r.Method == http.MethodGet:
+		messages, err := s.getMessages(conversationHash)
+		if err != nil {
+			WriteError(w, http.StatusNotFound, err)
+			return
+		}
+		if r.URL.Query().Get("view") == VIEW_TREE {
+			WriteJSON(w, http.StatusOK, MessageTree(messages))
+		} else {
+			WriteJSON(w, http.StatusOK, messages)
+		}
+	case len(parts) == 2 && parts[1] == "messages" && r.Method == http.MethodPost:
+		alias, key, ok := s.authenticate(w, r)
+		if !ok {
+			return
+		}
+		request := &ReplyRequest{}
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		if request.Previous == "" {
+			WriteError(w, http.StatusBadRequest, errors.New(fmt.Sprintf(ERROR_MISSING_FIELD, "Previous")))
+			return
+		}
+		if request.Content == "" {
+			WriteError(w, http.StatusBadRequest, errors.New(fmt.Sprintf(ERROR_MISSING_FIELD, "Content")))
+			return
+		}
+		previous, err := base64.RawURLEncoding.DecodeString(request.Previous)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		// Check the message being replied to is in the conversation
+		found := false
+		if err := s.Messages.GetMessage(conversationHash, previous, func([]byte, uint64, string, uint64, *conveygo.Message) error {
+			found = true
+			return nil
+		}); err != nil {
+			WriteError(w, http.StatusNotFound, err)
+			return
+		}
+		if !found {
+			WriteError(w, http.StatusBadRequest, errors.New(fmt.Sprintf(ERROR_NO_SUCH_MESSAGE, request.Previous)))
+			return
+		}
+		messageHash, messageRecord, err := conveygo.ProtoToRecord(alias, key, bcgo.Timestamp(), &conveygo.Message{
+			Previous: previous,
+			Content:  []byte(request.Content),
+			Type:     conveygo.MediaType_TEXT_PLAIN,
+		})
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if err := s.Messages.AddMessage(conversationHash, messageHash, messageRecord); err != nil {
+			WriteError(w, http.StatusNotFound, err)
+			return
+		}
+		WriteJSON(w, http.StatusCreated, &RecordResult{
+			Hash: base64.RawURLEncoding.EncodeToString(messageHash),
+		})
+	case len(parts) == 2 && parts[1] == "yield" && r.Method == http.MethodGet:
+		listing, err := s.Messages.GetConversation(conversationHash)
+		if err != nil {
+			WriteError(w, http.StatusNotFound, err)
+			return
+		}
+		cost, reward, err := s.Messages.GetYield(conversationHash)
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		cost += listing.Cost
+		WriteJSON(w, http.StatusOK, &YieldResult{
+			Cost:   cost,
+			Reward: reward,
+			Yield:  int64(reward) - int64(cost),
+		})
+	case len(parts) <= 2 && r.Method != http.MethodGet && r.Method != http.MethodPost:
+		WriteError(w, http.StatusMethodNotAllowed, errors.New(fmt.Sprintf(ERROR_METHOD_NOT_ALLOWED, r.Method)))
+	default:
+		WriteError(w, http.StatusNotFound, errors.New(fmt.Sprintf(ERROR_NOT_FOUND, r.URL.Path)))
+	}
+}
+
+func (s *Server) HandleAlias(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/aliases/"), "/")
+	if len(parts) < 2 || parts[0] == "" {
+		WriteError(w, http.StatusNotFound, errors.New(fmt.Sprintf(ERROR_NOT_FOUND, r.URL.Path)))
+		return
+	}
+	if parts[1] == "notifications" && s.Inbox != nil {
+		s.HandleNotifications(w, r, parts[0], parts[2:])
+		return
+	}
+	if len(parts) != 2 {
+		WriteError(w, http.StatusNotFound, errors.New(fmt.Sprintf(ERROR_NOT_FOUND, r.URL.Path)))
+		return
+	}
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, errors.New(fmt.Sprintf(ERROR_METHOD_NOT_ALLOWED, r.Method)))
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	statement, err := s.getStatement(parts[0])
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	switch parts[1] {
+	case "balance":
+		WriteJSON(w, http.StatusOK, &BalanceResult{
+			Alias:   statement.Alias,
+			Balance: statement.Balance,
+		})
+	case "statement":
+		WriteJSON(w, http.StatusOK, statement)
+	default:
+		WriteError(w, http.StatusNotFound, errors.New(fmt.Sprintf(ERROR_NOT_FOUND, r.URL.Path)))
+	}
+}
+
+// Serves the inbox of the alias, which must match the request's credentials.
+func (s *Server) HandleNotifications(w http.ResponseWriter, r *http.Request, alias string, parts []string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	authenticated, _, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+	if authenticated != alias {
+		WriteError(w, http.StatusForbidden, errors.New(conveygo.ERROR_ACCESS_DENIED))
+		return
+	}
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		WriteJSON(w, http.StatusOK, &NotificationsResult{
+			Unread:        s.Inbox.Unread(alias),
+			Notifications: s.Inbox.Get(alias, r.URL.Query().Get("unread") == "true"),
+		})
+	case len(parts) == 1 && parts[0] == "read" && r.Method == http.MethodPost:
+		request := &ReadRequest{}
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		if len(request.IDs) == 0 {
+			s.Inbox.MarkAllRead(alias)
+		}
+		for _, id := range request.IDs {
+			if err := s.Inbox.MarkRead(alias, id); err != nil {
+				WriteError(w, http.StatusNotFound, err)
+				return
+			}
+		}
+		WriteJSON(w, http.StatusOK, &NotificationsResult{
+			Unread: s.Inbox.Unread(alias),
+		})
+	case len(parts) <= 1:
+		WriteError(w, http.StatusMethodNotAllowed, errors.New(fmt.Sprintf(ERROR_METHOD_NOT_ALLOWED, r.Method)))
+	default:
+		WriteError(w, http.StatusNotFound, errors.New(fmt.Sprintf(ERROR_NOT_FOUND, r.URL.Path)))
+	}
+}
+
+func (s *Server) HandleDigest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, errors.New(fmt.Sprintf(ERROR_METHOD_NOT_ALLOWED, r.Method)))
+		return
+	}
+	from, to, err := parsePeriod(r, DEFAULT_DIGEST_PERIOD)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	entries, err := conveygo.GetDigestEntries(s.Messages, from, to)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	result := &DigestResult{
+		From: from,
+		To:   to,
+	}
+	for _, e := range entries {
+		entry := &DigestEntryResult{
+			Hash:      e.Hash,
+			Topic:     e.Topic,
+			Timestamp: e.Timestamp,
+			Author:    e.Author,
+			Cost:      e.Cost,
+			Reward:    e.Reward,
+			Yield:     e.Yield,
+		}
+		if e.Message != nil {
+			entry.Type = e.Message.Type.String()
+			entry.Content = string(e.Message.Content)
+		}
+		result.Entries = append(result.Entries, entry)
+	}
+	WriteJSON(w, http.StatusOK, result)
+}
+
+// Returns the messages in the given conversation in chronological order.
+func (s *Server) GetMessages(conversationHash []byte) ([]*MessageResult, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.getMessages(conversationHash)
+}
+
+func (s *Server) getMessages(conversationHash []byte) ([]*MessageResult, error) {
+	var messages []*MessageResult
+	if err := s.Messages.GetMessage(conversationHash, nil, func(hash []byte, timestamp uint64, author string, cost uint64, message *conveygo.Message) error {
+		m := &MessageResult{
+			Hash:      base64.RawURLEncoding.EncodeToString(hash),
+			Timestamp: timestamp,
+			Author:    author,
+			Cost:      cost,
+			Type:      message.Type.String(),
+			Content:   string(message.Content),
+		}
+		if len(message.Previous) > 0 {
+			m.Previous = base64.RawURLEncoding.EncodeToString(message.Previous)
+		}
+		messages = append(messages, m)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Timestamp < messages[j].Timestamp
+	})
+	return messages, nil
+}
+
+// Returns the balance and its breakdown for the given alias, after bringing the ledger up to date.
+func (s *Server) GetStatement(alias string) (*StatementResult, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.getStatement(alias)
+}
+
+func (s *Server) getStatement(alias string) (*StatementResult, error) {
+	if err := s.Ledger.UpdateAll(); err != nil {
+		return nil, err
+	}
+	if err := s.Ledger.UpdateAllowance(s.Users, alias); err != nil {
+		return nil, err
+	}
+	balance, allowance := s.Ledger.GetBalance(alias)
+	return &StatementResult{
+		Alias:     alias,
+		Balance:   balance,
+		Allowance: allowance,
+		Minted:    s.Ledger.Minted[alias],
+		Burned:    s.Ledger.Burned[alias],
+		Bought:    s.Ledger.Bought[alias],
+		Sold:      s.Ledger.Sold[alias],
+		Earned:    s.Ledger.Earned[alias],
+		Spent:     s.Ledger.Spent[alias],
+		Locked:    s.Ledger.GetLocked(alias),
+	}, nil
+}
+
+// Nests each message under the message it replies to, returning the roots.
+func MessageTree(messages []*MessageResult) []*MessageResult {
+	var roots []*MessageResult
+	index := make(map[string]*MessageResult)
+	for _, m := range messages {
+		index[m.Hash] = m
+	}
+	for _, m := range messages {
+		if parent, ok := index[m.Previous]; ok {
+			parent.Replies = append(parent.Replies, m)
+		} else {
+			roots = append(roots, m)
+		}
+	}
+	return roots
+}
+
+func ListingToResult(listing *conveygo.Listing) *ConversationResult {
+	return &ConversationResult{
+		Hash:      base64.RawURLEncoding.EncodeToString(listing.Hash),
+		Timestamp: listing.Timestamp,
+		Author:    listing.Author,
+		Topic:     listing.Topic,
+		Cost:      listing.Cost,
+	}
+}
+
+func WriteJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func WriteError(w http.ResponseWriter, status int, err error) {
+	WriteJSON(w, status, &ErrorResult{
+		Error: err.Error(),
+	})
+}
+
+// Unlocks the key of the alias in the request's Basic credentials, writing an error response if it fails.
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) (string, crypto.Signer, bool) {
+	alias, password, ok := r.BasicAuth()
+	if ok {
+		key, err := s.Users.GetKey(alias, []byte(password))
+		if err == nil {
+			return alias, key, true
+		}
+	}
+	w.Header().Set("WWW-Authenticate", `Basic realm="Convey"`)
+	WriteError(w, http.StatusUnauthorized, errors.New(ERROR_UNAUTHORIZED))
+	return "", nil, false
+}
+
+// Parses the from and to query parameters, defaulting to the given period ending now; a zero period starts from the beginning of time.
+func parsePeriod(r *http.Request, period time.Duration) (uint64, uint64, error) {
+	query := r.URL.Query()
+	to := bcgo.Timestamp()
+	if t := query.Get("to"); t != "" {
+		v, err := strconv.ParseUint(t, 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		to = v
+	}
+	var from uint64
+	if f := query.Get("from"); f != "" {
+		v, err := strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		from = v
+	} else if p := uint64(period.Nanoseconds()); p < to {
+		from = to - p
+	}
+	return from, to, nil
+}
@@ -0,0 +1,249 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/api"
+	"github.com/AletheiaWareLLC/conveygo/notifications"
+	"github.com/AletheiaWareLLC/testinggo"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func makeServer(t *testing.T, alias string, password []byte) (*api.Server, *httptest.Server) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	testinggo.AssertNoError(t, err)
+	store := conveygo.NewMemoryStore()
+	testinggo.AssertNoError(t, store.AddKey(alias, password, key))
+	node := &bcgo.Node{
+		Alias:    alias,
+		Cache:    bcgo.NewMemoryCache(10),
+		Channels: make(map[string]*bcgo.Channel),
+	}
+	server := api.NewServer(store, store, conveygo.NewLedger(node))
+	return server, httptest.NewServer(server.Handler())
+}
+
+func doRequest(t *testing.T, method, url, alias, password, body string, status int, result interface{}) {
+	t.Helper()
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	testinggo.AssertNoError(t, err)
+	if alias != "" {
+		request.SetBasicAuth(alias, password)
+	}
+	response, err := http.DefaultClient.Do(request)
+	testinggo.AssertNoError(t, err)
+	defer response.Body.Close()
+	if response.StatusCode != status {
+		t.Fatalf("Wrong status; expected '%d', got '%d'", status, response.StatusCode)
+	}
+	if result != nil {
+		testinggo.AssertNoError(t, json.NewDecoder(response.Body).Decode(result))
+	}
+}
+
+func TestServer(t *testing.T) {
+	alias := "Alice"
+	password := "password1234"
+	server, ts := makeServer(t, alias, []byte(password))
+	defer ts.Close()
+
+	conversation := &api.RecordResult{}
+	doRequest(t, http.MethodPost, ts.URL+"/conversations", alias, password, `{"Topic":"Test","Content":"Hello"}`, http.StatusCreated, conversation)
+	var messages []*api.MessageResult
+	doRequest(t, http.MethodGet, ts.URL+"/conversations/"+conversation.Hash+"/messages", "", "", "", http.StatusOK, &messages)
+	first := messages[0]
+	reply := &api.RecordResult{}
+	doRequest(t, http.MethodPost, ts.URL+"/conversations/"+conversation.Hash+"/messages", alias, password, `{"Previous":"`+first.Hash+`","Content":"Reply"}`, http.StatusCreated, reply)
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		doRequest(t, http.MethodPost, ts.URL+"/conversations", "", "", `{"Topic":"Test","Content":"Hello"}`, http.StatusUnauthorized, nil)
+		doRequest(t, http.MethodPost, ts.URL+"/conversations", alias, "wrong", `{"Topic":"Test","Content":"Hello"}`, http.StatusUnauthorized, nil)
+	})
+	t.Run("MissingField", func(t *testing.T) {
+		e := &api.ErrorResult{}
+		doRequest(t, http.MethodPost, ts.URL+"/conversations", alias, password, `{"Content":"Hello"}`, http.StatusBadRequest, e)
+		if e.Error != "Missing field: Topic" {
+			t.Errorf("Wrong error; expected 'Missing field: Topic', got '%s'", e.Error)
+		}
+	})
+	t.Run("ListConversations", func(t *testing.T) {
+		var results []*api.ConversationResult
+		doRequest(t, http.MethodGet, ts.URL+"/conversations?limit=5", "", "", "", http.StatusOK, &results)
+		if len(results) != 1 {
+			t.Fatalf("Wrong number of conversations; expected '1', got '%d'", len(results))
+		}
+		if results[0].Topic != "Test" {
+			t.Errorf("Wrong topic; expected 'Test', got '%s'", results[0].Topic)
+		}
+	})
+	t.Run("GetConversation", func(t *testing.T) {
+		result := &api.ConversationResult{}
+		doRequest(t, http.MethodGet, ts.URL+"/conversations/"+conversation.Hash, "", "", "", http.StatusOK, result)
+		if result.Hash != conversation.Hash {
+			t.Errorf("Wrong hash; expected '%s', got '%s'", conversation.Hash, result.Hash)
+		}
+		doRequest(t, http.MethodGet, ts.URL+"/conversations/AAAA", "", "", "", http.StatusNotFound, nil)
+	})
+	t.Run("ListMessages", func(t *testing.T) {
+		var results []*api.MessageResult
+		doRequest(t, http.MethodGet, ts.URL+"/conversations/"+conversation.Hash+"/messages", "", "", "", http.StatusOK, &results)
+		if len(results) != 2 {
+			t.Fatalf("Wrong number of messages; expected '2', got '%d'", len(results))
+		}
+		if results[1].Previous != first.Hash {
+			t.Errorf("Wrong previous; expected '%s', got '%s'", first.Hash, results[1].Previous)
+		}
+	})
+	t.Run("MessageTree", func(t *testing.T) {
+		var results []*api.MessageResult
+		doRequest(t, http.MethodGet, ts.URL+"/conversations/"+conversation.Hash+"/messages?view=tree", "", "", "", http.StatusOK, &results)
+		if len(results) != 1 {
+			t.Fatalf("Wrong number of roots; expected '1', got '%d'", len(results))
+		}
+		if len(results[0].Replies) != 1 || results[0].Replies[0].Hash != reply.Hash {
+			t.Errorf("Wrong replies; expected '%s', got '%v'", reply.Hash, results[0].Replies)
+		}
+	})
+	t.Run("Yield", func(t *testing.T) {
+		result := &api.YieldResult{}
+		doRequest(t, http.MethodGet, ts.URL+"/conversations/"+conversation.Hash+"/yield", "", "", "", http.StatusOK, result)
+		if result.Yield != int64(result.Reward)-int64(result.Cost) {
+			t.Errorf("Wrong yield; expected '%d', got '%d'", int64(result.Reward)-int64(result.Cost), result.Yield)
+		}
+	})
+	t.Run("Balance", func(t *testing.T) {
+		server.Ledger.RecordMinted(alias, 3600)
+		server.Ledger.RecordBurned(alias, 10)
+		balance := &api.BalanceResult{}
+		doRequest(t, http.MethodGet, ts.URL+"/aliases/"+alias+"/balance", "", "", "", http.StatusOK, balance)
+		if balance.Balance != 3590 {
+			t.Errorf("Wrong balance; expected '3590', got '%d'", balance.Balance)
+		}
+		statement := &api.StatementResult{}
+		doRequest(t, http.MethodGet, ts.URL+"/aliases/"+alias+"/statement", "", "", "", http.StatusOK, statement)
+		if statement.Minted != 3600 || statement.Burned != 10 {
+			t.Errorf("Wrong statement; expected '3600/10', got '%d/%d'", statement.Minted, statement.Burned)
+		}
+	})
+	t.Run("Digest", func(t *testing.T) {
+		result := &api.DigestResult{}
+		doRequest(t, http.MethodGet, ts.URL+"/digest", "", "", "", http.StatusOK, result)
+		if len(result.Entries) != 1 {
+			t.Fatalf("Wrong number of entries; expected '1', got '%d'", len(result.Entries))
+		}
+		if result.Entries[0].Content != "Hello" {
+			t.Errorf("Wrong content; expected 'Hello', got '%s'", result.Entries[0].Content)
+		}
+	})
+	t.Run("Notifications", func(t *testing.T) {
+		server.Inbox = notifications.NewInbox()
+		server.Inbox.Add(&notifications.Notification{
+			ID:      reply.Hash,
+			Alias:   alias,
+			Replier: "Bob",
+		})
+		doRequest(t, http.MethodGet, ts.URL+"/aliases/"+alias+"/notifications", "", "", "", http.StatusUnauthorized, nil)
+		result := &api.NotificationsResult{}
+		doRequest(t, http.MethodGet, ts.URL+"/aliases/"+alias+"/notifications?unread=true", alias, password, "", http.StatusOK, result)
+		if result.Unread != 1 || len(result.Notifications) != 1 || result.Notifications[0].Replier != "Bob" {
+			t.Fatalf("Wrong notifications; expected 1 unread from 'Bob', got '%d'", result.Unread)
+		}
+		doRequest(t, http.MethodPost, ts.URL+"/aliases/"+alias+"/notifications/read", alias, password, `{"IDs":["`+reply.Hash+`"]}`, http.StatusOK, result)
+		if result.Unread != 0 {
+			t.Errorf("Wrong unread; expected '0', got '%d'", result.Unread)
+		}
+		doRequest(t, http.MethodPost, ts.URL+"/aliases/"+alias+"/notifications/read", alias, password, `{"IDs":["unknown"]}`, http.StatusNotFound, nil)
+	})
+	t.Run("NoSuchPrevious", func(t *testing.T) {
+		other := &api.RecordResult{}
+		doRequest(t, http.MethodPost, ts.URL+"/conversations", alias, password, `{"Topic":"Other","Content":"Hello"}`, http.StatusCreated, other)
+		var messages []*api.MessageResult
+		doRequest(t, http.MethodGet, ts.URL+"/conversations/"+other.Hash+"/messages", "", "", "", http.StatusOK, &messages)
+		e := &api.ErrorResult{}
+		doRequest(t, http.MethodPost, ts.URL+"/conversations/"+conversation.Hash+"/messages", alias, password, `{"Previous":"`+messages[0].Hash+`","Content":"Reply"}`, http.StatusBadRequest, e)
+		if expected := "No such message: " + messages[0].Hash; e.Error != expected {
+			t.Errorf("Wrong error; expected '%s', got '%s'", expected, e.Error)
+		}
+	})
+}
+
+func TestServerConcurrentRequests(t *testing.T) {
+	alias := "Alice"
+	password := "password1234"
+	_, ts := makeServer(t, alias, []byte(password))
+	defer ts.Close()
+
+	conversation := &api.RecordResult{}
+	doRequest(t, http.MethodPost, ts.URL+"/conversations", alias, password, `{"Topic":"Test","Content":"Hello"}`, http.StatusCreated, conversation)
+	var messages []*api.MessageResult
+	doRequest(t, http.MethodGet, ts.URL+"/conversations/"+conversation.Hash+"/messages", "", "", "", http.StatusOK, &messages)
+
+	// Requests are sent from other goroutines, so failures are reported with Errorf
+	post := func(url, body string) {
+		request, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		request.SetBasicAuth(alias, password)
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		response.Body.Close()
+		if response.StatusCode != http.StatusCreated {
+			t.Errorf("Wrong status; expected '%d', got '%d'", http.StatusCreated, response.StatusCode)
+		}
+	}
+
+	count := 10
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			post(ts.URL+"/conversations", fmt.Sprintf(`{"Topic":"Topic%d","Content":"Hello"}`, i))
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			post(ts.URL+"/conversations/"+conversation.Hash+"/messages", fmt.Sprintf(`{"Previous":"%s","Content":"Reply%d"}`, messages[0].Hash, i))
+		}(i)
+	}
+	wg.Wait()
+
+	var conversations []*api.ConversationResult
+	doRequest(t, http.MethodGet, ts.URL+"/conversations?limit=100", "", "", "", http.StatusOK, &conversations)
+	if len(conversations) != count+1 {
+		t.Errorf("Wrong number of conversations; expected '%d', got '%d'", count+1, len(conversations))
+	}
+	doRequest(t, http.MethodGet, ts.URL+"/conversations/"+conversation.Hash+"/messages", "", "", "", http.StatusOK, &messages)
+	if len(messages) != count+1 {
+		t.Errorf("Wrong number of messages; expected '%d', got '%d'", count+1, len(messages))
+	}
+}
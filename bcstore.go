@@ -18,48 +18,45 @@ package conveygo
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/rsa"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"github.com/AletheiaWareLLC/aliasgo"
 	"github.com/AletheiaWareLLC/bcgo"
-	"github.com/AletheiaWareLLC/cryptogo"
 	"github.com/AletheiaWareLLC/financego"
 	"github.com/golang/protobuf/proto"
 	"log"
+	"math"
 )
 
 type BCStore struct {
-	Node     *bcgo.Node
-	Listener bcgo.MiningListener
-	KeyStore string
+	Node      *bcgo.Node
+	Listener  bcgo.MiningListener
+	KeyStore  KeyStore
+	Processor PaymentProcessor
 }
 
-func (s *BCStore) AddKey(alias string, password []byte, key *rsa.PrivateKey) error {
-	if s.HasKey(alias) {
-		return errors.New(fmt.Sprintf(ERROR_KEY_ALREADY_EXISTS, alias))
-	}
-	// Write private key to keystore on filesystem
-	return cryptogo.WriteRSAPrivateKey(key, s.KeyStore, alias, password)
+func (s *BCStore) AddKey(alias string, password []byte, key crypto.Signer) error {
+	return s.KeyStore.AddKey(alias, password, key)
 }
 
-func (s *BCStore) GetKey(alias string, password []byte) (*rsa.PrivateKey, error) {
-	key, err := cryptogo.GetRSAPrivateKey(s.KeyStore, alias, password)
-	if err != nil {
-		log.Println(err)
-		return nil, errors.New(ERROR_ACCESS_DENIED)
-	}
-	return key, nil
+func (s *BCStore) GetKey(alias string, password []byte) (crypto.Signer, error) {
+	return s.KeyStore.GetKey(alias, password)
 }
 
 func (s *BCStore) HasKey(alias string) bool {
-	return cryptogo.HasRSAPrivateKey(s.KeyStore, alias)
+	return s.KeyStore.HasKey(alias)
 }
 
-func (s *BCStore) RegisterAlias(alias string, password []byte, key *rsa.PrivateKey) error {
+func (s *BCStore) ChangePassword(alias string, oldPassword, newPassword []byte) error {
+	return s.KeyStore.ChangePassword(alias, oldPassword, newPassword)
+}
+
+func (s *BCStore) RegisterAlias(alias string, password []byte, key crypto.Signer) error {
 	// Create alias record
-	record, err := aliasgo.CreateSignedAliasRecord(alias, key)
+	record, err := CreateSignedAliasRecord(alias, key)
 	if err != nil {
 		return err
 	}
@@ -87,63 +84,39 @@ func (s *BCStore) RegisterAlias(alias string, password []byte, key *rsa.PrivateK
 	return nil
 }
 
-func (s *BCStore) RegisterCustomer(alias string, key *rsa.PrivateKey, customerId string) error {
-	// Create Registration proto
-	registration := &financego.Registration{
-		MerchantAlias: s.Node.Alias,
-		CustomerAlias: alias,
-		Processor:     financego.PaymentProcessor_STRIPE,
-		CustomerId:    customerId,
-	}
-	log.Println("Registration", registration)
-
-	// Create Access Control List
-	acl := map[string]*rsa.PublicKey{
-		alias:        &key.PublicKey,
-		s.Node.Alias: &s.Node.Key.PublicKey,
+func (s *BCStore) RegisterCustomer(alias string, key crypto.Signer, email, payment string) (*financego.Registration, error) {
+	if s.Processor == nil {
+		return nil, errors.New(ERROR_NO_PAYMENT_PROCESSOR)
 	}
-	log.Println("Access", acl)
-
-	// Marshal Registration proto
-	registrationData, err := proto.Marshal(registration)
+	registration, err := s.GetRegistration(alias)
 	if err != nil {
-		return err
-	}
-
-	// Get Registration Channel
-	registrations, err := s.Node.GetChannel(CONVEY_REGISTRATION)
-	if err != nil {
-		return err
+		return nil, err
 	}
-
-	if err := registrations.Refresh(s.Node.Cache, s.Node.Network); err != nil {
-		log.Println(err)
+	if registration != nil {
+		return nil, errors.New(fmt.Sprintf(ERROR_ALREADY_REGISTERED, alias))
 	}
-
-	// Write Registration data to cache
-	if _, err := s.Node.Write(bcgo.Timestamp(), registrations, acl, nil, registrationData); err != nil {
-		return err
+	publicKey, err := EncryptionKey(key)
+	if err != nil {
+		return nil, err
 	}
 
-	// Mine Registration Chain
-	if _, _, err := s.Node.Mine(registrations, bcgo.THRESHOLD_G, s.Listener); err != nil {
-		return err
+	// Create Customer with Payment Processor
+	registration, err = s.Processor.NewCustomer(s.Node.Alias, alias, email, payment, s.Node.Alias+" "+alias)
+	if err != nil {
+		return nil, err
 	}
+	log.Println("Registration", registration)
 
-	// Push Update to Network
-	if err := registrations.Push(s.Node.Cache, s.Node.Network); err != nil {
-		log.Println(err)
+	// Write Registration to Registration Channel
+	if _, err := s.writeCustomerRecord(s.Node.GetOrOpenChannel(CONVEY_REGISTRATION, OpenRegistrationChannel), alias, publicKey, registration); err != nil {
+		return nil, err
 	}
-
-	return nil
+	return registration, nil
 }
 
 func (s *BCStore) GetRegistration(alias string) (*financego.Registration, error) {
 	// Get Registration Channel
-	registrations, err := s.Node.GetChannel(CONVEY_REGISTRATION)
-	if err != nil {
-		return nil, err
-	}
+	registrations := s.Node.GetOrOpenChannel(CONVEY_REGISTRATION, OpenRegistrationChannel)
 	var registration *financego.Registration
 	// Read Registration Channel
 	if err := bcgo.Read(registrations.Name, registrations.Head, nil, s.Node.Cache, s.Node.Network, s.Node.Alias, s.Node.Key, nil, func(entry *bcgo.BlockEntry, key, data []byte) error {
@@ -170,6 +143,143 @@ func (s *BCStore) GetRegistration(alias string) (*financego.Registration, error)
 	return registration, nil
 }
 
+func (s *BCStore) ChargeCustomer(alias string, key crypto.Signer, product, plan, country, currency string, amount int64, description string) (*financego.Charge, error) {
+	charge, _, err := s.chargeCustomer(alias, key, product, plan, country, currency, amount, description)
+	return charge, err
+}
+
+// Charges the customer with the payment processor, and returns the charge and the hash of its record in the Convey-Charge Chain.
+func (s *BCStore) chargeCustomer(alias string, key crypto.Signer, product, plan, country, currency string, amount int64, description string) (*financego.Charge, []byte, error) {
+	if s.Processor == nil {
+		return nil, nil, errors.New(ERROR_NO_PAYMENT_PROCESSOR)
+	}
+	registration, err := s.GetRegistration(alias)
+	if err != nil {
+		return nil, nil, err
+	}
+	if registration == nil {
+		return nil, nil, errors.New(fmt.Sprintf(ERROR_NO_SUCH_REGISTRATION, alias))
+	}
+	publicKey, err := EncryptionKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Charge Customer with Payment Processor
+	charge, err := s.Processor.NewCharge(registration, product, plan, country, currency, amount, description)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Println("Charge", charge)
+
+	// Write Charge to Charge Channel
+	hash, err := s.writeCustomerRecord(s.Node.GetOrOpenChannel(CONVEY_CHARGE, OpenChargeChannel), alias, publicKey, charge)
+	if err != nil {
+		return nil, nil, err
+	}
+	return charge, hash, nil
+}
+
+func (s *BCStore) SubscribeCustomer(alias string, key crypto.Signer, product, plan string) (*financego.Subscription, error) {
+	if s.Processor == nil {
+		return nil, errors.New(ERROR_NO_PAYMENT_PROCESSOR)
+	}
+	subscription, err := s.GetSubscription(alias)
+	if err != nil {
+		return nil, err
+	}
+	if subscription != nil {
+		return nil, errors.New(fmt.Sprintf(ERROR_ALREADY_SUBSCRIBED, alias))
+	}
+	registration, err := s.GetRegistration(alias)
+	if err != nil {
+		return nil, err
+	}
+	if registration == nil {
+		return nil, errors.New(fmt.Sprintf(ERROR_NO_SUCH_REGISTRATION, alias))
+	}
+	publicKey, err := EncryptionKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Subscribe Customer with Payment Processor
+	subscription, err = s.Processor.NewSubscription(registration, product, plan)
+	if err != nil {
+		return nil, err
+	}
+	log.Println("Subscription", subscription)
+
+	// Write Subscription to Subscription Channel
+	if _, err := s.writeCustomerRecord(s.Node.GetOrOpenChannel(CONVEY_SUBSCRIPTION, OpenSubscriptionChannel), alias, publicKey, subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *BCStore) GetSubscription(alias string) (*financego.Subscription, error) {
+	// Get Subscription Channel
+	subscriptions := s.Node.GetOrOpenChannel(CONVEY_SUBSCRIPTION, OpenSubscriptionChannel)
+	var subscription *financego.Subscription
+	// Read Subscription Channel
+	if err := bcgo.Read(subscriptions.Name, subscriptions.Head, nil, s.Node.Cache, s.Node.Network, s.Node.Alias, s.Node.Key, nil, func(entry *bcgo.BlockEntry, key, data []byte) error {
+		// Unmarshal as Subscription
+		sub := &financego.Subscription{}
+		err := proto.Unmarshal(data, sub)
+		if err != nil {
+			return err
+		}
+		if sub.CustomerAlias == alias {
+			subscription = sub
+			return bcgo.StopIterationError{}
+		}
+		return nil
+	}); err != nil {
+		switch err.(type) {
+		case bcgo.StopIterationError:
+			// Do nothing
+			break
+		default:
+			return nil, err
+		}
+	}
+	return subscription, nil
+}
+
+// Writes the record to the channel, readable by the customer and the merchant, then mines and pushes the channel, and returns the hash of the record.
+func (s *BCStore) writeCustomerRecord(channel *bcgo.Channel, alias string, key *rsa.PublicKey, message proto.Message) ([]byte, error) {
+	// Create Access Control List
+	acl := map[string]*rsa.PublicKey{
+		alias:        key,
+		s.Node.Alias: &s.Node.Key.PublicKey,
+	}
+	log.Println("Access", acl)
+
+	// Marshal proto
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write data to cache
+	reference, err := s.Node.Write(bcgo.Timestamp(), channel, acl, nil, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mine Channel
+	if _, _, err := s.Node.Mine(channel, bcgo.THRESHOLD_G, s.Listener); err != nil {
+		return nil, err
+	}
+
+	// Push Update to Network
+	if err := channel.Push(s.Node.Cache, s.Node.Network); err != nil {
+		log.Println(err)
+	}
+
+	return reference.RecordHash, nil
+}
+
 func (s *BCStore) NewConversation(conversationHash []byte, conversationRecord *bcgo.Record, messageHash []byte, messageRecord *bcgo.Record) error {
 	conversations, err := s.Node.GetChannel(CONVEY_CONVERSATION)
 	if err != nil {
@@ -183,9 +293,11 @@ func (s *BCStore) NewConversation(conversationHash []byte, conversationRecord *b
 		return err
 	}
 
-	channel := CONVEY_PREFIX_MESSAGE + base64.RawURLEncoding.EncodeToString(conversationHash)
-	messages := bcgo.OpenPoWChannel(channel, bcgo.THRESHOLD_G)
-	s.Node.AddChannel(messages)
+	// Reuse the message channel if it was opened when the conversation was mined, so its triggers are kept
+	conversationHashString := base64.RawURLEncoding.EncodeToString(conversationHash)
+	messages := s.Node.GetOrOpenChannel(CONVEY_PREFIX_MESSAGE+conversationHashString, func() *bcgo.Channel {
+		return OpenMessageChannel(conversationHashString)
+	})
 
 	if err := s.MineBlockEntry(messages, &bcgo.BlockEntry{
 		RecordHash: messageHash,
@@ -364,11 +476,19 @@ type ReplyNode struct {
 }
 
 func (s *BCStore) GetYield(conversationHash []byte) (uint64, uint64, error) {
+	return s.GetYieldUntil(conversationHash, math.MaxUint64)
+}
+
+// Returns the cost and reward of the conversation, counting only messages created at or before the given timestamp.
+func (s *BCStore) GetYieldUntil(conversationHash []byte, until uint64) (uint64, uint64, error) {
 	var messageHash string
 	var messageCost uint64
 	var messageReward uint64
 	replies := make(map[string]*ReplyNode)
 	if err := s.GetMessage(conversationHash, nil, func(hash []byte, timestamp uint64, author string, cost uint64, message *Message) error {
+		if timestamp > until {
+			return nil
+		}
 		key := base64.RawURLEncoding.EncodeToString(hash)
 		if message.Previous == nil || len(message.Previous) == 0 {
 			messageHash = key
@@ -400,3 +520,34 @@ func (s *BCStore) GetYield(conversationHash []byte) (uint64, uint64, error) {
 	}
 	return messageCost, messageReward, nil
 }
+
+func (s *BCStore) getTagChannel(messageHash []byte) *bcgo.Channel {
+	messageHashString := base64.RawURLEncoding.EncodeToString(messageHash)
+	return s.Node.GetOrOpenChannel(CONVEY_PREFIX_TAG+messageHashString, func() *bcgo.Channel {
+		return OpenTagChannel(messageHashString)
+	})
+}
+
+func (s *BCStore) AddTag(messageHash, tagHash []byte, tagRecord *bcgo.Record) error {
+	return s.MineBlockEntry(s.getTagChannel(messageHash), &bcgo.BlockEntry{
+		RecordHash: tagHash,
+		Record:     tagRecord,
+	})
+}
+
+func (s *BCStore) GetTags(messageHash []byte, callback func([]byte, uint64, string, *Tag) error) error {
+	tags := s.getTagChannel(messageHash)
+	return bcgo.Iterate(tags.Name, tags.Head, nil, s.Node.Cache, s.Node.Network, func(h []byte, b *bcgo.Block) error {
+		for _, entry := range b.Entry {
+			// Unmarshal as Tag
+			t := &Tag{}
+			if err := proto.Unmarshal(entry.Record.Payload, t); err != nil {
+				return err
+			}
+			if err := callback(entry.RecordHash, entry.Record.GetTimestamp(), entry.Record.GetCreator(), t); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
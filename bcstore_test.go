@@ -17,6 +17,8 @@
 package conveygo_test
 
 import (
+	"crypto"
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/rsa"
 	"github.com/AletheiaWareLLC/aliasgo"
@@ -44,20 +46,33 @@ func makeBCStore(t *testing.T, alias string, key *rsa.PrivateKey, keystore strin
 			Network:  bcgo.NewTCPNetwork(),
 			Channels: make(map[string]*bcgo.Channel),
 		},
-		Listener: nil,
-		KeyStore: keystore,
+		Listener:  nil,
+		KeyStore:  conveygo.NewFileKeyStore(keystore),
+		Processor: conveygo.NewFakeProcessor(),
 	}
 	store.Node.AddChannel(aliasgo.OpenAliasChannel())
 	store.Node.AddChannel(conveygo.OpenConversationChannel())
 	return store
 }
 
+// Registers the alias in the node's Alias Chain so the signatures of its records can be validated.
+func registerAlias(t *testing.T, node *bcgo.Node, alias string, key crypto.Signer) {
+	t.Helper()
+	record, err := conveygo.CreateSignedAliasRecord(alias, key)
+	testinggo.AssertNoError(t, err)
+	_, err = bcgo.WriteRecord(aliasgo.ALIAS, node.Cache, record)
+	testinggo.AssertNoError(t, err)
+	aliases := node.GetOrOpenChannel(aliasgo.ALIAS, aliasgo.OpenAliasChannel)
+	_, _, err = node.Mine(aliases, aliasgo.ALIAS_THRESHOLD, nil)
+	testinggo.AssertNoError(t, err)
+}
+
 func TestBCStore(t *testing.T) {
 	dir, err := ioutil.TempDir("", "keystore")
 	testinggo.AssertNoError(t, err)
 	defer os.RemoveAll(dir)
 	aliasA := "Alice"
-	keyA, err := rsa.GenerateKey(rand.Reader, 4096)
+	keyA, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
 		t.Error("Could not generate key:", err)
 	}
@@ -65,116 +80,172 @@ func TestBCStore(t *testing.T) {
 	emailB := "bob@example.com"
 	paymentB := "payment1234"
 	passwordB := []byte("password1234")
-	keyB, err := rsa.GenerateKey(rand.Reader, 4096)
+	// Customer records are encrypted so customers need RSA keys
+	keyB, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
 		t.Error("Could not generate key:", err)
 	}
+	_, newKeyB, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Error("Could not generate key:", err)
+	}
+	makeStore := func(t *testing.T) *conveygo.BCStore {
+		store := makeBCStore(t, aliasA, keyA, dir)
+		registerAlias(t, store.Node, aliasA, keyA)
+		return store
+	}
+	// Conversations, messages, and tags are written by Bob so he must be registered too
+	makeAuthorStore := func(t *testing.T) *conveygo.BCStore {
+		store := makeStore(t)
+		registerAlias(t, store.Node, aliasB, keyB)
+		return store
+	}
 	t.Run("AddKey", func(t *testing.T) {
 		t.Run("Exists", func(t *testing.T) {
-			testUserStore_AddKey_Exists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, passwordB, keyB)
+			testUserStore_AddKey_Exists(t, makeStore(t), aliasB, passwordB, keyB)
 		})
 		t.Run("NotExists", func(t *testing.T) {
-			testUserStore_AddKey_NotExists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, passwordB, keyB)
+			testUserStore_AddKey_NotExists(t, makeStore(t), aliasB, passwordB, keyB)
 		})
 	})
 	t.Run("GetKey", func(t *testing.T) {
 		t.Run("Exists", func(t *testing.T) {
-			testUserStore_GetKey_Exists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, passwordB, keyB)
+			testUserStore_GetKey_Exists(t, makeStore(t), aliasB, passwordB, keyB)
 		})
 		t.Run("NotExists", func(t *testing.T) {
-			testUserStore_GetKey_NotExists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, passwordB, keyB)
+			testUserStore_GetKey_NotExists(t, makeStore(t), aliasB, passwordB, keyB)
 		})
 	})
 	t.Run("HasKey", func(t *testing.T) {
 		t.Run("Exists", func(t *testing.T) {
-			testUserStore_HasKey_Exists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, passwordB, keyB)
+			testUserStore_HasKey_Exists(t, makeStore(t), aliasB, passwordB, keyB)
+		})
+		t.Run("NotExists", func(t *testing.T) {
+			testUserStore_HasKey_NotExists(t, makeStore(t), aliasB, passwordB, keyB)
+		})
+	})
+	t.Run("ChangePassword", func(t *testing.T) {
+		t.Run("Exists", func(t *testing.T) {
+			testUserStore_ChangePassword_Exists(t, makeStore(t), aliasB, passwordB, keyB)
+		})
+		t.Run("NotExists", func(t *testing.T) {
+			testUserStore_ChangePassword_NotExists(t, makeStore(t), aliasB, passwordB, keyB)
+		})
+	})
+	t.Run("RotateKey", func(t *testing.T) {
+		t.Run("Exists", func(t *testing.T) {
+			testUserStore_RotateKey_Exists(t, makeStore(t), aliasB, passwordB, keyB, newKeyB)
+		})
+		t.Run("NotExists", func(t *testing.T) {
+			testUserStore_RotateKey_NotExists(t, makeStore(t), aliasB, passwordB, keyB, newKeyB)
+		})
+	})
+	t.Run("ExportKey", func(t *testing.T) {
+		t.Run("Exists", func(t *testing.T) {
+			testUserStore_ExportKey_Exists(t, makeStore(t), aliasB, passwordB, keyB)
 		})
 		t.Run("NotExists", func(t *testing.T) {
-			testUserStore_HasKey_NotExists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, passwordB, keyB)
+			testUserStore_ExportKey_NotExists(t, makeStore(t), aliasB, passwordB, keyB)
+		})
+	})
+	t.Run("ImportKey", func(t *testing.T) {
+		t.Run("Exists", func(t *testing.T) {
+			testUserStore_ImportKey_Exists(t, makeStore(t), aliasB, passwordB, keyB, newKeyB)
+		})
+		t.Run("NotExists", func(t *testing.T) {
+			testUserStore_ImportKey_NotExists(t, makeStore(t), aliasB, passwordB, keyB, newKeyB)
 		})
 	})
 	t.Run("RegisterAlias", func(t *testing.T) {
 		t.Run("Exists", func(t *testing.T) {
-			testUserStore_RegisterAlias_Exists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, emailB, paymentB, keyB)
+			testUserStore_RegisterAlias_Exists(t, makeStore(t), aliasB, emailB, paymentB, keyB)
 		})
 		t.Run("NotExists", func(t *testing.T) {
-			testUserStore_RegisterAlias_NotExists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, emailB, paymentB, keyB)
+			testUserStore_RegisterAlias_NotExists(t, makeStore(t), aliasB, emailB, paymentB, keyB)
 		})
 	})
 	t.Run("RegisterCustomer", func(t *testing.T) {
 		t.Run("Exists", func(t *testing.T) {
-			testUserStore_RegisterCustomer_Exists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, emailB, paymentB, keyB)
+			testUserStore_RegisterCustomer_Exists(t, makeStore(t), aliasB, emailB, paymentB, keyB)
 		})
 		t.Run("NotExists", func(t *testing.T) {
-			testUserStore_RegisterCustomer_NotExists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, emailB, paymentB, keyB)
+			testUserStore_RegisterCustomer_NotExists(t, makeStore(t), aliasB, emailB, paymentB, keyB)
 		})
 	})
 	t.Run("GetRegistration", func(t *testing.T) {
 		t.Run("Exists", func(t *testing.T) {
-			testUserStore_GetRegistration_Exists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, emailB, paymentB, keyB)
+			testUserStore_GetRegistration_Exists(t, makeStore(t), aliasB, emailB, paymentB, keyB)
 		})
 		t.Run("NotExists", func(t *testing.T) {
-			testUserStore_GetRegistration_NotExists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, emailB, paymentB, keyB)
+			testUserStore_GetRegistration_NotExists(t, makeStore(t), aliasB, emailB, paymentB, keyB)
+		})
+	})
+	t.Run("ChargeCustomer", func(t *testing.T) {
+		t.Run("Exists", func(t *testing.T) {
+			testUserStore_ChargeCustomer_Exists(t, makeStore(t), aliasB, emailB, paymentB, keyB)
+		})
+		t.Run("NotExists", func(t *testing.T) {
+			testUserStore_ChargeCustomer_NotExists(t, makeStore(t), aliasB, emailB, paymentB, keyB)
 		})
 	})
 	t.Run("SubscribeCustomer", func(t *testing.T) {
 		t.Run("Exists", func(t *testing.T) {
-			testUserStore_SubscribeCustomer_Exists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, emailB, paymentB, keyB)
+			testUserStore_SubscribeCustomer_Exists(t, makeStore(t), aliasB, emailB, paymentB, keyB)
 		})
 		t.Run("NotExists", func(t *testing.T) {
-			testUserStore_SubscribeCustomer_NotExists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, emailB, paymentB, keyB)
+			testUserStore_SubscribeCustomer_NotExists(t, makeStore(t), aliasB, emailB, paymentB, keyB)
 		})
 	})
 	t.Run("GetSubscription", func(t *testing.T) {
 		t.Run("Exists", func(t *testing.T) {
-			testUserStore_GetSubscription_Exists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, emailB, paymentB, keyB)
+			testUserStore_GetSubscription_Exists(t, makeStore(t), aliasB, emailB, paymentB, keyB)
 		})
 		t.Run("NotExists", func(t *testing.T) {
-			testUserStore_GetSubscription_NotExists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, emailB, paymentB, keyB)
+			testUserStore_GetSubscription_NotExists(t, makeStore(t), aliasB, emailB, paymentB, keyB)
 		})
 	})
 	t.Run("NewConversation", func(t *testing.T) {
-		testConversationStore_NewConversation(t, makeBCStore(t, aliasA, keyA, dir), aliasB, keyB)
+		testConversationStore_NewConversation(t, makeAuthorStore(t), aliasB, keyB)
 	})
 	t.Run("GetConversation", func(t *testing.T) {
 		t.Run("Exists", func(t *testing.T) {
-			testConversationStore_GetConversation_Exists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, keyB)
+			testConversationStore_GetConversation_Exists(t, makeAuthorStore(t), aliasB, keyB)
 		})
 		t.Run("NotExists", func(t *testing.T) {
-			testConversationStore_GetConversation_NotExists(t, makeBCStore(t, aliasA, keyA, dir))
+			testConversationStore_GetConversation_NotExists(t, makeAuthorStore(t))
 		})
 	})
 	t.Run("GetAllConversations", func(t *testing.T) {
 		t.Run("Empty", func(t *testing.T) {
-			testConversationStore_GetAllConversations_Empty(t, makeBCStore(t, aliasA, keyA, dir))
+			testConversationStore_GetAllConversations_Empty(t, makeAuthorStore(t))
 		})
 		t.Run("NotEmpty", func(t *testing.T) {
-			testConversationStore_GetAllConversations_NotEmpty(t, makeBCStore(t, aliasA, keyA, dir), aliasB, keyB)
+			testConversationStore_GetAllConversations_NotEmpty(t, makeAuthorStore(t), aliasB, keyB)
 		})
 		t.Run("From", func(t *testing.T) {
-			testConversationStore_GetAllConversations_From(t, makeBCStore(t, aliasA, keyA, dir), aliasB, keyB)
+			testConversationStore_GetAllConversations_From(t, makeAuthorStore(t), aliasB, keyB)
 		})
 		t.Run("To", func(t *testing.T) {
-			testConversationStore_GetAllConversations_To(t, makeBCStore(t, aliasA, keyA, dir), aliasB, keyB)
+			testConversationStore_GetAllConversations_To(t, makeAuthorStore(t), aliasB, keyB)
 		})
 	})
 	t.Run("GetRecentConversations", func(t *testing.T) {
 		t.Run("Empty", func(t *testing.T) {
-			testConversationStore_GetRecentConversations_Empty(t, makeBCStore(t, aliasA, keyA, dir))
+			testConversationStore_GetRecentConversations_Empty(t, makeAuthorStore(t))
 		})
 		t.Run("NotEmpty", func(t *testing.T) {
-			testConversationStore_GetRecentConversations_NotEmpty(t, makeBCStore(t, aliasA, keyA, dir), aliasB, keyB)
+			testConversationStore_GetRecentConversations_NotEmpty(t, makeAuthorStore(t), aliasB, keyB)
 		})
 		t.Run("Limit", func(t *testing.T) {
-			testConversationStore_GetRecentConversations_Limit(t, makeBCStore(t, aliasA, keyA, dir), aliasB, keyB)
+			testConversationStore_GetRecentConversations_Limit(t, makeAuthorStore(t), aliasB, keyB)
 		})
 	})
 	t.Run("AddMessage", func(t *testing.T) {
 		t.Run("Exists", func(t *testing.T) {
-			testMessageStore_AddMessage_Exists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, keyB)
+			testMessageStore_AddMessage_Exists(t, makeAuthorStore(t), aliasB, keyB)
 		})
 		t.Run("NotExists", func(t *testing.T) {
-			testMessageStore_AddMessage_NotExists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, keyB)
+			testMessageStore_AddMessage_NotExists(t, makeAuthorStore(t), aliasB, keyB)
 		})
 	})
 	t.Run("MineBlockEntry", func(t *testing.T) {
@@ -182,27 +253,66 @@ func TestBCStore(t *testing.T) {
 	})
 	t.Run("GetMessage", func(t *testing.T) {
 		t.Run("Exists", func(t *testing.T) {
-			testMessageStore_GetMessage_Exists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, keyB)
+			testMessageStore_GetMessage_Exists(t, makeAuthorStore(t), aliasB, keyB)
 		})
 		t.Run("Exists_Hash", func(t *testing.T) {
-			testMessageStore_GetMessage_Exists_Hash(t, makeBCStore(t, aliasA, keyA, dir), aliasB, keyB)
+			testMessageStore_GetMessage_Exists_Hash(t, makeAuthorStore(t), aliasB, keyB)
 		})
 		t.Run("Exists_Reply", func(t *testing.T) {
-			testMessageStore_GetMessage_Exists_Reply(t, makeBCStore(t, aliasA, keyA, dir), aliasB, keyB)
+			testMessageStore_GetMessage_Exists_Reply(t, makeAuthorStore(t), aliasB, keyB)
 		})
 		t.Run("NotExists", func(t *testing.T) {
-			testMessageStore_GetMessage_NotExists(t, makeBCStore(t, aliasA, keyA, dir))
+			testMessageStore_GetMessage_NotExists(t, makeAuthorStore(t))
 		})
 	})
 	t.Run("GetYield", func(t *testing.T) {
 		t.Run("Exists", func(t *testing.T) {
-			testMessageStore_GetYield_Exists(t, makeBCStore(t, aliasA, keyA, dir), aliasB, keyB)
+			testMessageStore_GetYield_Exists(t, makeAuthorStore(t), aliasB, keyB)
 		})
 		t.Run("Exists_Reply", func(t *testing.T) {
-			testMessageStore_GetYield_Exists_Reply(t, makeBCStore(t, aliasA, keyA, dir), aliasB, keyB)
+			testMessageStore_GetYield_Exists_Reply(t, makeAuthorStore(t), aliasB, keyB)
+		})
+		t.Run("NotExists", func(t *testing.T) {
+			testMessageStore_GetYield_NotExists(t, makeAuthorStore(t))
+		})
+	})
+	t.Run("Transfer", func(t *testing.T) {
+		t.Run("Exists", func(t *testing.T) {
+			s := makeAuthorStore(t)
+			testTransactionStore_Transfer(t, s, conveygo.NewLedger(s.Node), aliasA, keyA, aliasB)
+		})
+		t.Run("InsufficientBalance", func(t *testing.T) {
+			s := makeAuthorStore(t)
+			testTransactionStore_Transfer_InsufficientBalance(t, s, conveygo.NewLedger(s.Node), aliasA, keyA, aliasB)
+		})
+		t.Run("IdempotencyKey", func(t *testing.T) {
+			s := makeAuthorStore(t)
+			testTransactionStore_Transfer_IdempotencyKey(t, s, conveygo.NewLedger(s.Node), aliasA, keyA, aliasB)
+		})
+	})
+	t.Run("Escrow", func(t *testing.T) {
+		t.Run("Release", func(t *testing.T) {
+			s := makeAuthorStore(t)
+			testEscrowStore_Release(t, s, conveygo.NewLedger(s.Node), aliasA, keyA, aliasB)
+		})
+		t.Run("Refund", func(t *testing.T) {
+			s := makeAuthorStore(t)
+			testEscrowStore_Refund(t, s, conveygo.NewLedger(s.Node), aliasA, keyA, aliasB)
+		})
+		t.Run("InsufficientBalance", func(t *testing.T) {
+			s := makeAuthorStore(t)
+			testEscrowStore_LockEscrow_InsufficientBalance(t, s, conveygo.NewLedger(s.Node), aliasA, keyA)
+		})
+	})
+	t.Run("AddTag", func(t *testing.T) {
+		testTagStore_AddTag(t, makeAuthorStore(t), aliasB, keyB)
+	})
+	t.Run("GetTags", func(t *testing.T) {
+		t.Run("Exists", func(t *testing.T) {
+			testTagStore_GetTags_Exists(t, makeAuthorStore(t), aliasB, keyB)
 		})
 		t.Run("NotExists", func(t *testing.T) {
-			testMessageStore_GetYield_NotExists(t, makeBCStore(t, aliasA, keyA, dir))
+			testTagStore_GetTags_NotExists(t, makeAuthorStore(t))
 		})
 	})
 }
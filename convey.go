@@ -17,25 +17,29 @@
 package conveygo
 
 import (
-	"crypto/rsa"
+	"crypto"
 	"github.com/AletheiaWareLLC/bcgo"
 	"github.com/AletheiaWareLLC/cryptogo"
 	"github.com/golang/protobuf/proto"
 )
 
 const (
-	CONVEY_HOUR           = "Convey-Hour"         // Hourly Validation Chain
-	CONVEY_DAY            = "Convey-Day"          // Daily Validation Chain
-	CONVEY_WEEK           = "Convey-Week"         // Weekly Validation Chain
-	CONVEY_YEAR           = "Convey-Year"         // Yearly Validation Chain
-	CONVEY_DECADE         = "Convey-Decade"       // Decennially Validation Chain
-	CONVEY_CENTURY        = "Convey-Century"      // Centennially Validation Chain
-	CONVEY_CHARGE         = "Convey-Charge"       // financego.Charge Chain
-	CONVEY_INVOICE        = "Convey-Invoice"      // financego.Invoice Chain
-	CONVEY_REGISTRATION   = "Convey-Registration" // financego.Registration Chain
-	CONVEY_SUBSCRIPTION   = "Convey-Subscription" // financego.Subscription Chain
-	CONVEY_CONVERSATION   = "Convey-Conversation" // conveygo.Conversation Chain
-	CONVEY_TRANSACTION    = "Convey-Transaction"  // conveygo.Transaction Chain
+	CONVEY_HOUR           = "Convey-Hour"           // Hourly Validation Chain
+	CONVEY_DAY            = "Convey-Day"            // Daily Validation Chain
+	CONVEY_WEEK           = "Convey-Week"           // Weekly Validation Chain
+	CONVEY_YEAR           = "Convey-Year"           // Yearly Validation Chain
+	CONVEY_DECADE         = "Convey-Decade"         // Decennially Validation Chain
+	CONVEY_CENTURY        = "Convey-Century"        // Centennially Validation Chain
+	CONVEY_CHARGE         = "Convey-Charge"         // financego.Charge Chain
+	CONVEY_INVOICE        = "Convey-Invoice"        // financego.Invoice Chain
+	CONVEY_REGISTRATION   = "Convey-Registration"   // financego.Registration Chain
+	CONVEY_SUBSCRIPTION   = "Convey-Subscription"   // financego.Subscription Chain
+	CONVEY_CONVERSATION   = "Convey-Conversation"   // conveygo.Conversation Chain
+	CONVEY_TRANSACTION    = "Convey-Transaction"    // conveygo.Transaction Chain
+	CONVEY_ESCROW         = "Convey-Escrow"         // conveygo.Escrow Chain
+	CONVEY_ESCROW_RELEASE = "Convey-Escrow-Release" // conveygo.EscrowRelease Chain
+	CONVEY_KEY_ROTATION   = "Convey-Key-Rotation"   // conveygo.KeyRotation Chain
+	CONVEY_REFUND         = "Convey-Refund"         // conveygo.Refund Chain
 	CONVEY_PREFIX         = "Convey-"
 	CONVEY_PREFIX_MESSAGE = "Convey-Message-" // conveygo.Message Chain
 	CONVEY_PREFIX_TAG     = "Convey-Tag-"     // conveygo.Tag Chain
@@ -81,44 +85,66 @@ func OpenCenturyChannel() *bcgo.Channel {
 
 func OpenChargeChannel() *bcgo.Channel {
 	// TODO(v2) add validator to ensure Message Payload can be unmarshalled as protobuf
-	return bcgo.OpenPoWChannel(CONVEY_CHARGE, bcgo.THRESHOLD_G)
+	charges := bcgo.OpenPoWChannel(CONVEY_CHARGE, bcgo.THRESHOLD_G)
+	charges.AddValidator(&SignatureValidator{})
+	return charges
 }
 
 func OpenInvoiceChannel() *bcgo.Channel {
 	// TODO(v2) add validator to ensure Message Payload can be unmarshalled as protobuf
-	return bcgo.OpenPoWChannel(CONVEY_INVOICE, bcgo.THRESHOLD_G)
+	invoices := bcgo.OpenPoWChannel(CONVEY_INVOICE, bcgo.THRESHOLD_G)
+	invoices.AddValidator(&SignatureValidator{})
+	return invoices
 }
 
 func OpenRegistrationChannel() *bcgo.Channel {
 	// TODO(v2) add validator to ensure Message Payload can be unmarshalled as protobuf
-	return bcgo.OpenPoWChannel(CONVEY_REGISTRATION, bcgo.THRESHOLD_G)
+	registrations := bcgo.OpenPoWChannel(CONVEY_REGISTRATION, bcgo.THRESHOLD_G)
+	registrations.AddValidator(&SignatureValidator{})
+	return registrations
 }
 
 func OpenSubscriptionChannel() *bcgo.Channel {
 	// TODO(v2) add validator to ensure Message Payload can be unmarshalled as protobuf
-	return bcgo.OpenPoWChannel(CONVEY_SUBSCRIPTION, bcgo.THRESHOLD_G)
+	subscriptions := bcgo.OpenPoWChannel(CONVEY_SUBSCRIPTION, bcgo.THRESHOLD_G)
+	subscriptions.AddValidator(&SignatureValidator{})
+	return subscriptions
+}
+
+func OpenRefundChannel() *bcgo.Channel {
+	// TODO(v2) add validator to ensure Message Payload can be unmarshalled as protobuf
+	refunds := bcgo.OpenPoWChannel(CONVEY_REFUND, bcgo.THRESHOLD_G)
+	refunds.AddValidator(&SignatureValidator{})
+	return refunds
 }
 
 func OpenConversationChannel() *bcgo.Channel {
 	// TODO(v2) add validator to ensure Message Payload can be unmarshalled as protobuf
-	return bcgo.OpenPoWChannel(CONVEY_CONVERSATION, bcgo.THRESHOLD_G)
+	conversations := bcgo.OpenPoWChannel(CONVEY_CONVERSATION, bcgo.THRESHOLD_G)
+	conversations.AddValidator(&SignatureValidator{})
+	return conversations
 }
 
 func OpenTransactionChannel() *bcgo.Channel {
 	// TODO(v2) add validator to ensure Message Payload can be unmarshalled as protobuf
 	transactions := bcgo.OpenPoWChannel(CONVEY_TRANSACTION, bcgo.THRESHOLD_G)
 	transactions.AddValidator(&TransactionValidator{})
+	transactions.AddValidator(&SignatureValidator{})
 	return transactions
 }
 
 func OpenMessageChannel(conversationId string) *bcgo.Channel {
 	// TODO(v2) add validator to ensure Message Payload can be unmarshalled as protobuf
-	return bcgo.OpenPoWChannel(CONVEY_PREFIX_MESSAGE+conversationId, bcgo.THRESHOLD_G)
+	messages := bcgo.OpenPoWChannel(CONVEY_PREFIX_MESSAGE+conversationId, bcgo.THRESHOLD_G)
+	messages.AddValidator(&SignatureValidator{})
+	return messages
 }
 
 func OpenTagChannel(messageId string) *bcgo.Channel {
 	// TODO(v2) add validator to ensure Message Payload can be unmarshalled as protobuf
-	return bcgo.OpenPoWChannel(CONVEY_PREFIX_TAG+messageId, bcgo.THRESHOLD_G)
+	tags := bcgo.OpenPoWChannel(CONVEY_PREFIX_TAG+messageId, bcgo.THRESHOLD_G)
+	tags.AddValidator(&SignatureValidator{})
+	return tags
 }
 
 func ConversationEntryToListing(entry *bcgo.BlockEntry) (*Listing, error) {
@@ -138,7 +164,11 @@ func ConversationEntryToListing(entry *bcgo.BlockEntry) (*Listing, error) {
 	}, nil
 }
 
-func ProtoToRecord(alias string, key *rsa.PrivateKey, timestamp uint64, protobuf proto.Message) ([]byte, *bcgo.Record, error) {
+func ProtoToRecord(alias string, key crypto.Signer, timestamp uint64, protobuf proto.Message) ([]byte, *bcgo.Record, error) {
+	return ProtoToRecordWithReferences(alias, key, timestamp, nil, protobuf)
+}
+
+func ProtoToRecordWithReferences(alias string, key crypto.Signer, timestamp uint64, references []*bcgo.Reference, protobuf proto.Message) ([]byte, *bcgo.Record, error) {
 	// Marshal Protobuf
 	data, err := proto.Marshal(protobuf)
 	if err != nil {
@@ -146,7 +176,7 @@ func ProtoToRecord(alias string, key *rsa.PrivateKey, timestamp uint64, protobuf
 	}
 
 	// Create Record
-	_, record, err := bcgo.CreateRecord(timestamp, alias, key, nil, nil, data)
+	record, err := CreateRecord(timestamp, alias, key, references, data)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -5,6 +5,8 @@ package conveygo
 
 import (
 	fmt "fmt"
+	cryptogo "github.com/AletheiaWareLLC/cryptogo"
+	financego "github.com/AletheiaWareLLC/financego"
 	proto "github.com/golang/protobuf/proto"
 	math "math"
 )
@@ -228,7 +230,11 @@ type Transaction struct {
 	// Token Receiver.
 	Receiver string `protobuf:"bytes,2,opt,name=receiver,proto3" json:"receiver,omitempty"`
 	// Token Amount.
-	Amount               uint64   `protobuf:"varint,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Amount uint64 `protobuf:"varint,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	// Optional note from the Sender to the Receiver.
+	Memo string `protobuf:"bytes,4,opt,name=memo,proto3" json:"memo,omitempty"`
+	// Optional key chosen by the Sender so a retried transfer is only recorded once.
+	IdempotencyKey       string   `protobuf:"bytes,5,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -280,38 +286,693 @@ func (m *Transaction) GetAmount() uint64 {
 	return 0
 }
 
+func (m *Transaction) GetMemo() string {
+	if m != nil {
+		return m.Memo
+	}
+	return ""
+}
+
+func (m *Transaction) GetIdempotencyKey() string {
+	if m != nil {
+		return m.IdempotencyKey
+	}
+	return ""
+}
+
+// Tag labels a Message, and is written to the Convey-Tag-<MessageHash> Chain.
+type Tag struct {
+	Value                string   `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Tag) Reset()         { *m = Tag{} }
+func (m *Tag) String() string { return proto.CompactTextString(m) }
+func (*Tag) ProtoMessage()    {}
+func (*Tag) Descriptor() ([]byte, []int) {
+	return fileDescriptor_44db357c6aa8dfc7, []int{4}
+}
+
+func (m *Tag) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Tag.Unmarshal(m, b)
+}
+func (m *Tag) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Tag.Marshal(b, m, deterministic)
+}
+func (m *Tag) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Tag.Merge(m, src)
+}
+func (m *Tag) XXX_Size() int {
+	return xxx_messageInfo_Tag.Size(m)
+}
+func (m *Tag) XXX_DiscardUnknown() {
+	xxx_messageInfo_Tag.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Tag proto.InternalMessageInfo
+
+func (m *Tag) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+// Escrow locks the Sender's tokens until they are released to a Receiver, or refunded after the Deadline, and is written to the Convey-Escrow Chain.
+type Escrow struct {
+	Sender string `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	Amount uint64 `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	// Timestamp after which the Sender can refund the tokens.
+	Deadline uint64 `protobuf:"fixed64,3,opt,name=deadline,proto3" json:"deadline,omitempty"`
+	Memo     string `protobuf:"bytes,4,opt,name=memo,proto3" json:"memo,omitempty"`
+	// Hash of the Conversation the escrow is a bounty for, if any.
+	Conversation         []byte   `protobuf:"bytes,5,opt,name=conversation,proto3" json:"conversation,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Escrow) Reset()         { *m = Escrow{} }
+func (m *Escrow) String() string { return proto.CompactTextString(m) }
+func (*Escrow) ProtoMessage()    {}
+func (*Escrow) Descriptor() ([]byte, []int) {
+	return fileDescriptor_44db357c6aa8dfc7, []int{5}
+}
+
+func (m *Escrow) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Escrow.Unmarshal(m, b)
+}
+func (m *Escrow) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Escrow.Marshal(b, m, deterministic)
+}
+func (m *Escrow) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Escrow.Merge(m, src)
+}
+func (m *Escrow) XXX_Size() int {
+	return xxx_messageInfo_Escrow.Size(m)
+}
+func (m *Escrow) XXX_DiscardUnknown() {
+	xxx_messageInfo_Escrow.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Escrow proto.InternalMessageInfo
+
+func (m *Escrow) GetSender() string {
+	if m != nil {
+		return m.Sender
+	}
+	return ""
+}
+
+func (m *Escrow) GetAmount() uint64 {
+	if m != nil {
+		return m.Amount
+	}
+	return 0
+}
+
+func (m *Escrow) GetDeadline() uint64 {
+	if m != nil {
+		return m.Deadline
+	}
+	return 0
+}
+
+func (m *Escrow) GetMemo() string {
+	if m != nil {
+		return m.Memo
+	}
+	return ""
+}
+
+func (m *Escrow) GetConversation() []byte {
+	if m != nil {
+		return m.Conversation
+	}
+	return nil
+}
+
+// EscrowRelease releases the tokens locked by an Escrow to the Receiver, or refunds them if the Receiver is the Sender, and is written to the Convey-Escrow-Release Chain.
+type EscrowRelease struct {
+	// Hash of the Escrow record.
+	Escrow               []byte   `protobuf:"bytes,1,opt,name=escrow,proto3" json:"escrow,omitempty"`
+	Sender               string   `protobuf:"bytes,2,opt,name=sender,proto3" json:"sender,omitempty"`
+	Receiver             string   `protobuf:"bytes,3,opt,name=receiver,proto3" json:"receiver,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EscrowRelease) Reset()         { *m = EscrowRelease{} }
+func (m *EscrowRelease) String() string { return proto.CompactTextString(m) }
+func (*EscrowRelease) ProtoMessage()    {}
+func (*EscrowRelease) Descriptor() ([]byte, []int) {
+	return fileDescriptor_44db357c6aa8dfc7, []int{6}
+}
+
+func (m *EscrowRelease) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EscrowRelease.Unmarshal(m, b)
+}
+func (m *EscrowRelease) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EscrowRelease.Marshal(b, m, deterministic)
+}
+func (m *EscrowRelease) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EscrowRelease.Merge(m, src)
+}
+func (m *EscrowRelease) XXX_Size() int {
+	return xxx_messageInfo_EscrowRelease.Size(m)
+}
+func (m *EscrowRelease) XXX_DiscardUnknown() {
+	xxx_messageInfo_EscrowRelease.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EscrowRelease proto.InternalMessageInfo
+
+func (m *EscrowRelease) GetEscrow() []byte {
+	if m != nil {
+		return m.Escrow
+	}
+	return nil
+}
+
+func (m *EscrowRelease) GetSender() string {
+	if m != nil {
+		return m.Sender
+	}
+	return ""
+}
+
+func (m *EscrowRelease) GetReceiver() string {
+	if m != nil {
+		return m.Receiver
+	}
+	return ""
+}
+
+// InvoiceItem is a line of an invoice, billing the Tokens cost by a quantity of Records.
+type InvoiceItem struct {
+	Description          string   `protobuf:"bytes,1,opt,name=description,proto3" json:"description,omitempty"`
+	Quantity             uint64   `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Tokens               uint64   `protobuf:"varint,3,opt,name=tokens,proto3" json:"tokens,omitempty"`
+	Amount               int64    `protobuf:"varint,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *InvoiceItem) Reset()         { *m = InvoiceItem{} }
+func (m *InvoiceItem) String() string { return proto.CompactTextString(m) }
+func (*InvoiceItem) ProtoMessage()    {}
+func (*InvoiceItem) Descriptor() ([]byte, []int) {
+	return fileDescriptor_44db357c6aa8dfc7, []int{7}
+}
+
+func (m *InvoiceItem) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InvoiceItem.Unmarshal(m, b)
+}
+func (m *InvoiceItem) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InvoiceItem.Marshal(b, m, deterministic)
+}
+func (m *InvoiceItem) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InvoiceItem.Merge(m, src)
+}
+func (m *InvoiceItem) XXX_Size() int {
+	return xxx_messageInfo_InvoiceItem.Size(m)
+}
+func (m *InvoiceItem) XXX_DiscardUnknown() {
+	xxx_messageInfo_InvoiceItem.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InvoiceItem proto.InternalMessageInfo
+
+func (m *InvoiceItem) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *InvoiceItem) GetQuantity() uint64 {
+	if m != nil {
+		return m.Quantity
+	}
+	return 0
+}
+
+func (m *InvoiceItem) GetTokens() uint64 {
+	if m != nil {
+		return m.Tokens
+	}
+	return 0
+}
+
+func (m *InvoiceItem) GetAmount() int64 {
+	if m != nil {
+		return m.Amount
+	}
+	return 0
+}
+
+// InvoiceUsage itemises the posting activity billed by a finance.Invoice.
+type InvoiceUsage struct {
+	Start                uint64         `protobuf:"fixed64,1,opt,name=start,proto3" json:"start,omitempty"`
+	End                  uint64         `protobuf:"fixed64,2,opt,name=end,proto3" json:"end,omitempty"`
+	Item                 []*InvoiceItem `protobuf:"bytes,3,rep,name=item,proto3" json:"item,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *InvoiceUsage) Reset()         { *m = InvoiceUsage{} }
+func (m *InvoiceUsage) String() string { return proto.CompactTextString(m) }
+func (*InvoiceUsage) ProtoMessage()    {}
+func (*InvoiceUsage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_44db357c6aa8dfc7, []int{8}
+}
+
+func (m *InvoiceUsage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InvoiceUsage.Unmarshal(m, b)
+}
+func (m *InvoiceUsage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InvoiceUsage.Marshal(b, m, deterministic)
+}
+func (m *InvoiceUsage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InvoiceUsage.Merge(m, src)
+}
+func (m *InvoiceUsage) XXX_Size() int {
+	return xxx_messageInfo_InvoiceUsage.Size(m)
+}
+func (m *InvoiceUsage) XXX_DiscardUnknown() {
+	xxx_messageInfo_InvoiceUsage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InvoiceUsage proto.InternalMessageInfo
+
+func (m *InvoiceUsage) GetStart() uint64 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *InvoiceUsage) GetEnd() uint64 {
+	if m != nil {
+		return m.End
+	}
+	return 0
+}
+
+func (m *InvoiceUsage) GetItem() []*InvoiceItem {
+	if m != nil {
+		return m.Item
+	}
+	return nil
+}
+
+// Invoice holds a finance.Invoice and the usage it bills, and is written to the Convey-Invoice Chain.
+type Invoice struct {
+	Invoice              *financego.Invoice `protobuf:"bytes,1,opt,name=invoice,proto3" json:"invoice,omitempty"`
+	Usage                *InvoiceUsage      `protobuf:"bytes,2,opt,name=usage,proto3" json:"usage,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *Invoice) Reset()         { *m = Invoice{} }
+func (m *Invoice) String() string { return proto.CompactTextString(m) }
+func (*Invoice) ProtoMessage()    {}
+func (*Invoice) Descriptor() ([]byte, []int) {
+	return fileDescriptor_44db357c6aa8dfc7, []int{9}
+}
+
+func (m *Invoice) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Invoice.Unmarshal(m, b)
+}
+func (m *Invoice) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Invoice.Marshal(b, m, deterministic)
+}
+func (m *Invoice) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Invoice.Merge(m, src)
+}
+func (m *Invoice) XXX_Size() int {
+	return xxx_messageInfo_Invoice.Size(m)
+}
+func (m *Invoice) XXX_DiscardUnknown() {
+	xxx_messageInfo_Invoice.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Invoice proto.InternalMessageInfo
+
+func (m *Invoice) GetInvoice() *financego.Invoice {
+	if m != nil {
+		return m.Invoice
+	}
+	return nil
+}
+
+func (m *Invoice) GetUsage() *InvoiceUsage {
+	if m != nil {
+		return m.Usage
+	}
+	return nil
+}
+
+// KeyRotation replaces the public key of an Alias, and is written to the Convey-Key-Rotation Chain in a Record signed by the key it replaces.
+type KeyRotation struct {
+	Alias                string                   `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	PublicKey            []byte                   `protobuf:"bytes,2,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	PublicFormat         cryptogo.PublicKeyFormat `protobuf:"varint,3,opt,name=public_format,json=publicFormat,proto3,enum=crypto.PublicKeyFormat" json:"public_format,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
+	XXX_unrecognized     []byte                   `json:"-"`
+	XXX_sizecache        int32                    `json:"-"`
+}
+
+func (m *KeyRotation) Reset()         { *m = KeyRotation{} }
+func (m *KeyRotation) String() string { return proto.CompactTextString(m) }
+func (*KeyRotation) ProtoMessage()    {}
+func (*KeyRotation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_44db357c6aa8dfc7, []int{10}
+}
+
+func (m *KeyRotation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyRotation.Unmarshal(m, b)
+}
+func (m *KeyRotation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyRotation.Marshal(b, m, deterministic)
+}
+func (m *KeyRotation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyRotation.Merge(m, src)
+}
+func (m *KeyRotation) XXX_Size() int {
+	return xxx_messageInfo_KeyRotation.Size(m)
+}
+func (m *KeyRotation) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyRotation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyRotation proto.InternalMessageInfo
+
+func (m *KeyRotation) GetAlias() string {
+	if m != nil {
+		return m.Alias
+	}
+	return ""
+}
+
+func (m *KeyRotation) GetPublicKey() []byte {
+	if m != nil {
+		return m.PublicKey
+	}
+	return nil
+}
+
+func (m *KeyRotation) GetPublicFormat() cryptogo.PublicKeyFormat {
+	if m != nil {
+		return m.PublicFormat
+	}
+	return cryptogo.PublicKeyFormat_UNKNOWN_PUBLIC_KEY_FORMAT
+}
+
+// KeyBundle is a password protected export of an alias's keystore entry.
+// The Payload is a KeyBundleContent encrypted with AES-GCM under an Argon2id key derived from the password with the given parameters.
+type KeyBundle struct {
+	Version              uint32   `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Salt                 []byte   `protobuf:"bytes,2,opt,name=salt,proto3" json:"salt,omitempty"`
+	Time                 uint32   `protobuf:"varint,3,opt,name=time,proto3" json:"time,omitempty"`
+	Memory               uint32   `protobuf:"varint,4,opt,name=memory,proto3" json:"memory,omitempty"`
+	Threads              uint32   `protobuf:"varint,5,opt,name=threads,proto3" json:"threads,omitempty"`
+	Payload              []byte   `protobuf:"bytes,6,opt,name=payload,proto3" json:"payload,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *KeyBundle) Reset()         { *m = KeyBundle{} }
+func (m *KeyBundle) String() string { return proto.CompactTextString(m) }
+func (*KeyBundle) ProtoMessage()    {}
+func (*KeyBundle) Descriptor() ([]byte, []int) {
+	return fileDescriptor_44db357c6aa8dfc7, []int{11}
+}
+
+func (m *KeyBundle) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyBundle.Unmarshal(m, b)
+}
+func (m *KeyBundle) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyBundle.Marshal(b, m, deterministic)
+}
+func (m *KeyBundle) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyBundle.Merge(m, src)
+}
+func (m *KeyBundle) XXX_Size() int {
+	return xxx_messageInfo_KeyBundle.Size(m)
+}
+func (m *KeyBundle) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyBundle.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyBundle proto.InternalMessageInfo
+
+func (m *KeyBundle) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *KeyBundle) GetSalt() []byte {
+	if m != nil {
+		return m.Salt
+	}
+	return nil
+}
+
+func (m *KeyBundle) GetTime() uint32 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *KeyBundle) GetMemory() uint32 {
+	if m != nil {
+		return m.Memory
+	}
+	return 0
+}
+
+func (m *KeyBundle) GetThreads() uint32 {
+	if m != nil {
+		return m.Threads
+	}
+	return 0
+}
+
+func (m *KeyBundle) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+// KeyBundleContent holds the private key of an alias, and their customer registration if any.
+type KeyBundleContent struct {
+	Alias                string                    `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	PrivateKey           []byte                    `protobuf:"bytes,2,opt,name=private_key,json=privateKey,proto3" json:"private_key,omitempty"`
+	PrivateFormat        cryptogo.PrivateKeyFormat `protobuf:"varint,3,opt,name=private_format,json=privateFormat,proto3,enum=crypto.PrivateKeyFormat" json:"private_format,omitempty"`
+	Registration         *financego.Registration   `protobuf:"bytes,4,opt,name=registration,proto3" json:"registration,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                  `json:"-"`
+	XXX_unrecognized     []byte                    `json:"-"`
+	XXX_sizecache        int32                     `json:"-"`
+}
+
+func (m *KeyBundleContent) Reset()         { *m = KeyBundleContent{} }
+func (m *KeyBundleContent) String() string { return proto.CompactTextString(m) }
+func (*KeyBundleContent) ProtoMessage()    {}
+func (*KeyBundleContent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_44db357c6aa8dfc7, []int{12}
+}
+
+func (m *KeyBundleContent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyBundleContent.Unmarshal(m, b)
+}
+func (m *KeyBundleContent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyBundleContent.Marshal(b, m, deterministic)
+}
+func (m *KeyBundleContent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyBundleContent.Merge(m, src)
+}
+func (m *KeyBundleContent) XXX_Size() int {
+	return xxx_messageInfo_KeyBundleContent.Size(m)
+}
+func (m *KeyBundleContent) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyBundleContent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyBundleContent proto.InternalMessageInfo
+
+func (m *KeyBundleContent) GetAlias() string {
+	if m != nil {
+		return m.Alias
+	}
+	return ""
+}
+
+func (m *KeyBundleContent) GetPrivateKey() []byte {
+	if m != nil {
+		return m.PrivateKey
+	}
+	return nil
+}
+
+func (m *KeyBundleContent) GetPrivateFormat() cryptogo.PrivateKeyFormat {
+	if m != nil {
+		return m.PrivateFormat
+	}
+	return cryptogo.PrivateKeyFormat_UNKNOWN_PRIVATE_KEY_FORMAT
+}
+
+func (m *KeyBundleContent) GetRegistration() *financego.Registration {
+	if m != nil {
+		return m.Registration
+	}
+	return nil
+}
+
+// Refund returns the amount of a Charge to the customer, and is written to the Convey-Refund Chain.
+type Refund struct {
+	// Hash of the Charge record.
+	Charge               []byte   `protobuf:"bytes,1,opt,name=charge,proto3" json:"charge,omitempty"`
+	RefundId             string   `protobuf:"bytes,2,opt,name=refund_id,json=refundId,proto3" json:"refund_id,omitempty"`
+	Amount               int64    `protobuf:"varint,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Refund) Reset()         { *m = Refund{} }
+func (m *Refund) String() string { return proto.CompactTextString(m) }
+func (*Refund) ProtoMessage()    {}
+func (*Refund) Descriptor() ([]byte, []int) {
+	return fileDescriptor_44db357c6aa8dfc7, []int{13}
+}
+
+func (m *Refund) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Refund.Unmarshal(m, b)
+}
+func (m *Refund) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Refund.Marshal(b, m, deterministic)
+}
+func (m *Refund) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Refund.Merge(m, src)
+}
+func (m *Refund) XXX_Size() int {
+	return xxx_messageInfo_Refund.Size(m)
+}
+func (m *Refund) XXX_DiscardUnknown() {
+	xxx_messageInfo_Refund.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Refund proto.InternalMessageInfo
+
+func (m *Refund) GetCharge() []byte {
+	if m != nil {
+		return m.Charge
+	}
+	return nil
+}
+
+func (m *Refund) GetRefundId() string {
+	if m != nil {
+		return m.RefundId
+	}
+	return ""
+}
+
+func (m *Refund) GetAmount() int64 {
+	if m != nil {
+		return m.Amount
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterEnum("convey.MediaType", MediaType_name, MediaType_value)
 	proto.RegisterType((*Message)(nil), "convey.Message")
 	proto.RegisterType((*Conversation)(nil), "convey.Conversation")
 	proto.RegisterType((*Listing)(nil), "convey.Listing")
 	proto.RegisterType((*Transaction)(nil), "convey.Transaction")
+	proto.RegisterType((*Tag)(nil), "convey.Tag")
+	proto.RegisterType((*Escrow)(nil), "convey.Escrow")
+	proto.RegisterType((*EscrowRelease)(nil), "convey.EscrowRelease")
+	proto.RegisterType((*InvoiceItem)(nil), "convey.InvoiceItem")
+	proto.RegisterType((*InvoiceUsage)(nil), "convey.InvoiceUsage")
+	proto.RegisterType((*Invoice)(nil), "convey.Invoice")
+	proto.RegisterType((*KeyRotation)(nil), "convey.KeyRotation")
+	proto.RegisterType((*KeyBundle)(nil), "convey.KeyBundle")
+	proto.RegisterType((*KeyBundleContent)(nil), "convey.KeyBundleContent")
+	proto.RegisterType((*Refund)(nil), "convey.Refund")
 }
 
-func init() { proto.RegisterFile("convey.proto", fileDescriptor_44db357c6aa8dfc7) }
+func init() {
+	proto.RegisterFile("convey.proto", fileDescriptor_44db357c6aa8dfc7)
+}
 
 var fileDescriptor_44db357c6aa8dfc7 = []byte{
-	// 346 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x4c, 0x91, 0x51, 0xcb, 0xd3, 0x30,
-	0x14, 0x86, 0xad, 0x76, 0xad, 0x3d, 0x1b, 0x63, 0x06, 0x99, 0x45, 0xbc, 0x18, 0x53, 0x61, 0x78,
-	0xd1, 0x81, 0xfe, 0x82, 0x6d, 0x78, 0x21, 0xeb, 0xea, 0x28, 0x95, 0xa9, 0x37, 0x92, 0x65, 0xc7,
-	0x36, 0x60, 0x93, 0x92, 0xa4, 0x93, 0x5e, 0xf8, 0xdf, 0xa5, 0x69, 0xbe, 0xed, 0xbb, 0xcb, 0x93,
-	0x73, 0xe0, 0x7d, 0x78, 0x0f, 0x4c, 0x98, 0x14, 0x57, 0xec, 0x92, 0x46, 0x49, 0x23, 0x49, 0x30,
-	0xd0, 0xf2, 0x37, 0x84, 0x07, 0xd4, 0x9a, 0x96, 0x48, 0x5e, 0xc3, 0xf3, 0x46, 0xe1, 0x95, 0xcb,
-	0x56, 0xc7, 0xde, 0xc2, 0x5b, 0x4d, 0xf2, 0x1b, 0x93, 0x18, 0x42, 0x26, 0x85, 0x41, 0x61, 0xe2,
-	0xa7, 0x76, 0xf4, 0x80, 0xe4, 0x3d, 0xf8, 0xa6, 0x6b, 0x30, 0x7e, 0xb6, 0xf0, 0x56, 0xd3, 0x8f,
-	0x2f, 0x12, 0x97, 0x72, 0xc0, 0x0b, 0xa7, 0x45, 0xd7, 0x60, 0x6e, 0xc7, 0xcb, 0x77, 0x30, 0xd9,
-	0xf5, 0x13, 0xa5, 0xa9, 0xe1, 0x52, 0x90, 0x97, 0x30, 0x32, 0xb2, 0xe1, 0xcc, 0x26, 0x45, 0xf9,
-	0x00, 0xcb, 0x7f, 0x10, 0xa6, 0x5c, 0x1b, 0x2e, 0x4a, 0x42, 0xc0, 0xaf, 0xa8, 0xae, 0x9c, 0x89,
-	0x7d, 0xf7, 0x7f, 0x4c, 0xea, 0x41, 0xc1, 0xcf, 0xed, 0x9b, 0xbc, 0x81, 0xc8, 0xf0, 0x1a, 0xb5,
-	0xa1, 0x75, 0x63, 0x25, 0xfc, 0xfc, 0xfe, 0x41, 0xe6, 0x10, 0xd0, 0xd6, 0x54, 0x52, 0xc5, 0xbe,
-	0xcd, 0x71, 0x74, 0x8f, 0x1f, 0x3d, 0x8e, 0xff, 0x01, 0xe3, 0x42, 0x51, 0xa1, 0x29, 0xb3, 0x8e,
-	0x73, 0x08, 0x34, 0x8a, 0x0b, 0x2a, 0x27, 0xe9, 0xa8, 0x2f, 0x4a, 0x21, 0x43, 0x7e, 0x45, 0x65,
-	0x55, 0xa2, 0xfc, 0xc6, 0x36, 0xb0, 0x96, 0xad, 0x30, 0xce, 0xc5, 0xd1, 0x87, 0x15, 0x44, 0xb7,
-	0x4a, 0xc8, 0x18, 0xc2, 0x6f, 0xd9, 0x3e, 0xfb, 0x7a, 0xca, 0x66, 0x4f, 0xc8, 0x14, 0xa0, 0xf8,
-	0xfc, 0xbd, 0xf8, 0x75, 0x4c, 0x37, 0x5f, 0xb2, 0x99, 0xb7, 0xdd, 0xc3, 0x2b, 0x26, 0xeb, 0x84,
-	0xfe, 0x41, 0x53, 0x21, 0xa7, 0x7f, 0xa9, 0x42, 0x57, 0xea, 0x76, 0x6c, 0x2b, 0xec, 0x8e, 0xfd,
-	0x05, 0x7f, 0xbe, 0x2d, 0xb9, 0xa9, 0xda, 0x73, 0xc2, 0x64, 0xbd, 0xde, 0xb8, 0xe5, 0x13, 0x55,
-	0x98, 0xa6, 0xbb, 0xf5, 0xb0, 0x5f, 0xca, 0x73, 0x60, 0xaf, 0xfd, 0xe9, 0x7f, 0x00, 0x00, 0x00,
-	0xff, 0xff, 0x59, 0xd6, 0xa2, 0xa3, 0xfd, 0x01, 0x00, 0x00,
+	// 861 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x55, 0x5f, 0x6b, 0x1b, 0x47,
+	0x10, 0xef, 0x59, 0xf2, 0x29, 0x9a, 0x93, 0x5c, 0x75, 0xeb, 0x26, 0xc2, 0x69, 0xa9, 0xb9, 0xb6,
+	0xc4, 0xf8, 0x41, 0x01, 0xf7, 0xa9, 0x50, 0x28, 0xb1, 0x49, 0xc1, 0xc8, 0x71, 0xcd, 0x22, 0x93,
+	0xd2, 0x3e, 0x98, 0xf5, 0xdd, 0x58, 0x5a, 0x72, 0xb7, 0x7b, 0xdd, 0xdd, 0x53, 0x38, 0x4a, 0xa1,
+	0x1f, 0xa0, 0xcf, 0x85, 0x7e, 0xa0, 0x7e, 0xaf, 0xb0, 0x7f, 0xee, 0x24, 0x85, 0xe4, 0x6d, 0x7e,
+	0x33, 0xc3, 0xcd, 0x6f, 0x7f, 0xf3, 0xe7, 0x60, 0x94, 0x49, 0xb1, 0xc6, 0x66, 0x56, 0x29, 0x69,
+	0x24, 0x89, 0x3d, 0x3a, 0x1a, 0x65, 0xaa, 0xa9, 0x8c, 0xf4, 0xde, 0xa3, 0xf1, 0x03, 0x17, 0x4c,
+	0x64, 0xe8, 0x61, 0xfa, 0x00, 0x83, 0x57, 0xa8, 0x35, 0x5b, 0x22, 0x39, 0x82, 0x47, 0x95, 0xc2,
+	0x35, 0x97, 0xb5, 0x9e, 0x46, 0xc7, 0xd1, 0xc9, 0x88, 0x76, 0x98, 0x4c, 0x61, 0x90, 0x49, 0x61,
+	0x50, 0x98, 0xe9, 0x9e, 0x0b, 0xb5, 0x90, 0x7c, 0x07, 0x7d, 0xd3, 0x54, 0x38, 0xed, 0x1d, 0x47,
+	0x27, 0x07, 0x67, 0x9f, 0xcd, 0x02, 0x85, 0x57, 0x98, 0x73, 0xb6, 0x68, 0x2a, 0xa4, 0x2e, 0x9c,
+	0x7e, 0x0b, 0xa3, 0x0b, 0x1b, 0x51, 0x9a, 0x19, 0x2e, 0x05, 0x39, 0x84, 0x7d, 0x23, 0x2b, 0x9e,
+	0xb9, 0x4a, 0x43, 0xea, 0x41, 0xfa, 0x17, 0x0c, 0xae, 0xb8, 0x36, 0x5c, 0x2c, 0x09, 0x81, 0xfe,
+	0x8a, 0xe9, 0x55, 0x60, 0xe2, 0x6c, 0xeb, 0xcb, 0xa4, 0xf6, 0x14, 0xfa, 0xd4, 0xd9, 0xe4, 0x4b,
+	0x18, 0x1a, 0x5e, 0xa2, 0x36, 0xac, 0xac, 0x1c, 0x89, 0x3e, 0xdd, 0x38, 0xc8, 0x63, 0x88, 0x59,
+	0x6d, 0x56, 0x52, 0x4d, 0xfb, 0xae, 0x4e, 0x40, 0x9b, 0xf2, 0xfb, 0xdb, 0xe5, 0xff, 0x8d, 0x20,
+	0x59, 0x28, 0x26, 0x34, 0xcb, 0x1c, 0xc9, 0xc7, 0x10, 0x6b, 0x14, 0x39, 0xaa, 0xc0, 0x32, 0x20,
+	0xab, 0x94, 0xc2, 0x0c, 0xf9, 0x1a, 0x95, 0xe3, 0x32, 0xa4, 0x1d, 0x76, 0x15, 0x4b, 0x59, 0x0b,
+	0x13, 0xc8, 0x04, 0x64, 0xb9, 0x97, 0x58, 0xca, 0xc0, 0xc3, 0xd9, 0xe4, 0x19, 0x7c, 0xca, 0x73,
+	0x2c, 0x2b, 0x69, 0x50, 0x64, 0xcd, 0xdd, 0x1b, 0x6c, 0x02, 0x9f, 0x83, 0x2d, 0xf7, 0x1c, 0x9b,
+	0xf4, 0x29, 0xf4, 0x16, 0x6c, 0x69, 0x59, 0xaf, 0x59, 0x51, 0x63, 0x2b, 0x9a, 0x03, 0xe9, 0x3f,
+	0x11, 0xc4, 0x2f, 0x75, 0xa6, 0xe4, 0xdb, 0x8f, 0x12, 0xde, 0x90, 0xda, 0xdb, 0x21, 0x75, 0x04,
+	0x8f, 0x72, 0x64, 0x79, 0xc1, 0x85, 0x6f, 0x60, 0x4c, 0x3b, 0xfc, 0x41, 0xc2, 0x69, 0x18, 0xb1,
+	0xd0, 0x45, 0xc7, 0x76, 0x44, 0x77, 0x7c, 0xe9, 0xef, 0x30, 0xf6, 0x6c, 0x28, 0x16, 0xc8, 0x34,
+	0xda, 0xe2, 0xe8, 0x1c, 0xa1, 0x97, 0x01, 0x6d, 0x91, 0xdd, 0xfb, 0xa8, 0xba, 0xbd, 0x5d, 0x75,
+	0xd3, 0x3f, 0x21, 0xb9, 0x14, 0x6b, 0xc9, 0x33, 0xbc, 0x34, 0x58, 0x92, 0x63, 0x48, 0x72, 0xfb,
+	0x35, 0x5e, 0x39, 0x3a, 0xfe, 0xd1, 0xdb, 0x2e, 0xfb, 0xb1, 0x3f, 0x6a, 0x26, 0x0c, 0x37, 0x4d,
+	0x78, 0x7b, 0x87, 0x2d, 0x01, 0x23, 0xdf, 0xa0, 0xd0, 0x6d, 0xab, 0x3c, 0xda, 0x52, 0xcb, 0xbe,
+	0xbd, 0xd7, 0xaa, 0x95, 0xde, 0xc1, 0x28, 0x14, 0xbf, 0x75, 0x0b, 0x73, 0x08, 0xfb, 0xda, 0x30,
+	0x65, 0x5c, 0xdd, 0x98, 0x7a, 0x40, 0x26, 0xd0, 0x43, 0x91, 0xbb, 0x62, 0x31, 0xb5, 0x26, 0x79,
+	0x06, 0x7d, 0x6e, 0xb0, 0x9c, 0xf6, 0x8e, 0x7b, 0x27, 0xc9, 0xd9, 0xe7, 0xed, 0x8a, 0x6c, 0x3d,
+	0x84, 0xba, 0x84, 0x94, 0xc1, 0x20, 0x38, 0xc9, 0x29, 0x0c, 0xb8, 0x37, 0xdd, 0xd7, 0x93, 0xb3,
+	0xc9, 0xac, 0x5d, 0xdc, 0x90, 0x42, 0xdb, 0x04, 0x72, 0x0a, 0xfb, 0xb5, 0x25, 0xe4, 0x6a, 0x26,
+	0x67, 0x87, 0xef, 0x15, 0x70, 0x64, 0xa9, 0x4f, 0x49, 0xff, 0x8e, 0x20, 0x99, 0x63, 0x43, 0xa5,
+	0xe9, 0xf6, 0x90, 0x15, 0x9c, 0xe9, 0x76, 0xa4, 0x1c, 0x20, 0x5f, 0x01, 0x54, 0xf5, 0x7d, 0xc1,
+	0x33, 0x37, 0x93, 0x7e, 0xe3, 0x87, 0xde, 0x33, 0xc7, 0x86, 0xfc, 0x08, 0xe3, 0x10, 0x7e, 0x90,
+	0xaa, 0x64, 0x26, 0x2c, 0xff, 0x93, 0x59, 0xb8, 0x34, 0x37, 0x6d, 0xe6, 0xcf, 0x2e, 0x4c, 0x47,
+	0x3e, 0xdb, 0xa3, 0xf4, 0xbf, 0x08, 0x86, 0x73, 0x6c, 0xce, 0x6b, 0x91, 0x17, 0x68, 0x2f, 0x8b,
+	0x9d, 0x9d, 0xb6, 0x7d, 0x63, 0xda, 0x42, 0x3b, 0x80, 0x9a, 0x15, 0xed, 0xc1, 0x71, 0xb6, 0xf5,
+	0xd9, 0xe5, 0x76, 0x05, 0xc7, 0xd4, 0xd9, 0xb6, 0x5d, 0x76, 0x38, 0x55, 0xe3, 0xda, 0x35, 0xa6,
+	0x01, 0xd9, 0x2f, 0x9b, 0x95, 0x42, 0x96, 0x6b, 0x37, 0xa7, 0x63, 0xda, 0x42, 0x1b, 0xa9, 0x58,
+	0x53, 0x48, 0x96, 0x4f, 0x63, 0x7f, 0xcd, 0x02, 0x4c, 0xff, 0x8f, 0x60, 0xd2, 0x71, 0xbb, 0x08,
+	0x27, 0xee, 0xc3, 0x1a, 0x7d, 0x0d, 0x49, 0xa5, 0xf8, 0x9a, 0x19, 0xdc, 0x12, 0x09, 0x82, 0xcb,
+	0xaa, 0xf4, 0x13, 0x1c, 0xb4, 0x09, 0x3b, 0x32, 0x4d, 0x3b, 0x99, 0xba, 0xdc, 0xa0, 0xd3, 0x38,
+	0xe4, 0x7b, 0x48, 0x7e, 0x80, 0x91, 0xc2, 0x25, 0xd7, 0x46, 0xf9, 0x6d, 0xeb, 0xbb, 0xf6, 0x7e,
+	0xd1, 0x0d, 0x02, 0xdd, 0x0a, 0xd2, 0x9d, 0xd4, 0xf4, 0x16, 0x62, 0x8a, 0x0f, 0xb5, 0xc8, 0xad,
+	0x3a, 0xd9, 0x8a, 0xa9, 0x25, 0xb6, 0xdb, 0xe7, 0x11, 0x79, 0x0a, 0x43, 0xe5, 0x32, 0xee, 0x78,
+	0xbe, 0x39, 0x62, 0xd6, 0x71, 0x99, 0xbf, 0x77, 0xc4, 0xba, 0x0d, 0x38, 0x3d, 0x81, 0x61, 0x77,
+	0xd8, 0x49, 0x02, 0x83, 0xdb, 0xeb, 0xf9, 0xf5, 0x2f, 0xaf, 0xaf, 0x27, 0x9f, 0x90, 0x03, 0x80,
+	0xc5, 0xcb, 0x5f, 0x17, 0x77, 0x37, 0x57, 0x2f, 0x2e, 0xaf, 0x27, 0xd1, 0xf9, 0x1c, 0x9e, 0x64,
+	0xb2, 0x9c, 0xb1, 0x02, 0xcd, 0x0a, 0x39, 0x7b, 0xcb, 0x14, 0x86, 0xb1, 0x3c, 0x4f, 0xdc, 0x8f,
+	0xa0, 0xb9, 0xb1, 0xff, 0x9f, 0xdf, 0xbe, 0x59, 0x72, 0xb3, 0xaa, 0xef, 0x67, 0x99, 0x2c, 0x9f,
+	0xbf, 0x08, 0xc9, 0xaf, 0x99, 0xc2, 0xab, 0xab, 0x8b, 0xe7, 0x3e, 0x7f, 0x29, 0xef, 0x63, 0xf7,
+	0xaf, 0xfa, 0xfe, 0xdd, 0x00, 0xc2, 0x78, 0x18, 0xd8, 0xe0, 0x06, 0x00, 0x00,
 }
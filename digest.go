@@ -17,8 +17,12 @@
 package conveygo
 
 import (
+	"crypto"
 	"encoding/base64"
+	"encoding/json"
 	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/cryptogo"
+	"github.com/golang/protobuf/proto"
 	"sort"
 )
 
@@ -86,3 +90,100 @@ func GetDigestEntries(messages MessageStore, from, to uint64) ([]*DigestEntry, e
 
 	return entries, nil
 }
+
+// Canonical form of a DigestEntry, with the Message serialized as protobuf.
+type signedDigestEntry struct {
+	Hash      string
+	Topic     string
+	Timestamp string
+	Author    string
+	Cost      uint64
+	Reward    uint64
+	Yield     int64
+	Message   []byte
+}
+
+// Returns the canonical serialization of the given entries, which is signed by DigestToRecord.
+func MarshalDigestEntries(entries []*DigestEntry) ([]byte, error) {
+	signed := make([]*signedDigestEntry, len(entries))
+	for i, e := range entries {
+		message, err := proto.Marshal(e.Message)
+		if err != nil {
+			return nil, err
+		}
+		signed[i] = &signedDigestEntry{
+			Hash:      e.Hash,
+			Topic:     e.Topic,
+			Timestamp: e.Timestamp,
+			Author:    e.Author,
+			Cost:      e.Cost,
+			Reward:    e.Reward,
+			Yield:     e.Yield,
+			Message:   message,
+		}
+	}
+	return json.Marshal(signed)
+}
+
+func UnmarshalDigestEntries(data []byte) ([]*DigestEntry, error) {
+	var signed []*signedDigestEntry
+	if err := json.Unmarshal(data, &signed); err != nil {
+		return nil, err
+	}
+	entries := make([]*DigestEntry, len(signed))
+	for i, s := range signed {
+		message := &Message{}
+		if err := proto.Unmarshal(s.Message, message); err != nil {
+			return nil, err
+		}
+		entries[i] = &DigestEntry{
+			Hash:      s.Hash,
+			Topic:     s.Topic,
+			Timestamp: s.Timestamp,
+			Author:    s.Author,
+			Cost:      s.Cost,
+			Reward:    s.Reward,
+			Yield:     s.Yield,
+			Message:   message,
+		}
+	}
+	return entries, nil
+}
+
+// Creates a record of the given entries signed by the given key.
+func DigestToRecord(alias string, key crypto.Signer, timestamp uint64, entries []*DigestEntry) ([]byte, *bcgo.Record, error) {
+	data, err := MarshalDigestEntries(entries)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create Record
+	record, err := CreateRecord(timestamp, alias, key, nil, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hash, err := cryptogo.HashProtobuf(record)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return hash, record, nil
+}
+
+// Verifies the record was signed by the given key, and returns the entries it holds.
+func RecordToDigest(key crypto.PublicKey, record *bcgo.Record) ([]*DigestEntry, error) {
+	if err := VerifyRecord(key, record); err != nil {
+		return nil, err
+	}
+	return UnmarshalDigestEntries(record.Payload)
+}
+
+// Verifies the record was signed by the key that was active for its creator's alias at its timestamp, and returns the entries it holds.
+func VerifyDigestRecord(aliases, rotations *bcgo.Channel, cache bcgo.Cache, network bcgo.Network, record *bcgo.Record) ([]*DigestEntry, error) {
+	key, err := GetPublicKeyAt(aliases, rotations, cache, network, record.Creator, record.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	return RecordToDigest(key, record)
+}
@@ -15,3 +15,89 @@
  */
 
 package conveygo_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"github.com/AletheiaWareLLC/aliasgo"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/testinggo"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func makeDigestEntries() []*conveygo.DigestEntry {
+	return []*conveygo.DigestEntry{
+		&conveygo.DigestEntry{
+			Hash:      "Hash123",
+			Topic:     "Test123",
+			Timestamp: bcgo.TimestampToString(1234),
+			Author:    "Alice",
+			Cost:      5,
+			Reward:    8,
+			Yield:     3,
+			Message: &conveygo.Message{
+				Content: []byte("FooBar"),
+				Type:    conveygo.MediaType_TEXT_PLAIN,
+			},
+		},
+	}
+}
+
+func TestDigestRecord(t *testing.T) {
+	alias := "Alice"
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Error("Could not generate key:", err)
+	}
+	t.Run("Valid", func(t *testing.T) {
+		_, record, err := conveygo.DigestToRecord(alias, key, bcgo.Timestamp(), makeDigestEntries())
+		testinggo.AssertNoError(t, err)
+		entries, err := conveygo.RecordToDigest(key.Public(), record)
+		testinggo.AssertNoError(t, err)
+		if len(entries) != 1 {
+			t.Fatalf("Wrong number of entries; expected '1', got '%d'", len(entries))
+		}
+		if entries[0].Topic != "Test123" || entries[0].Yield != 3 || string(entries[0].Message.Content) != "FooBar" {
+			t.Errorf("Wrong entry; got '%v'", entries[0])
+		}
+	})
+	t.Run("WrongKey", func(t *testing.T) {
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Error("Could not generate key:", err)
+		}
+		_, record, err := conveygo.DigestToRecord(alias, key, bcgo.Timestamp(), makeDigestEntries())
+		testinggo.AssertNoError(t, err)
+		_, err = conveygo.RecordToDigest(&other.PublicKey, record)
+		testinggo.AssertError(t, rsa.ErrVerification.Error(), err)
+	})
+	t.Run("Tampered", func(t *testing.T) {
+		_, record, err := conveygo.DigestToRecord(alias, key, bcgo.Timestamp(), makeDigestEntries())
+		testinggo.AssertNoError(t, err)
+		entries := makeDigestEntries()
+		entries[0].Yield = 1000
+		record.Payload, err = conveygo.MarshalDigestEntries(entries)
+		testinggo.AssertNoError(t, err)
+		_, err = conveygo.RecordToDigest(key.Public(), record)
+		testinggo.AssertError(t, rsa.ErrVerification.Error(), err)
+	})
+	t.Run("Alias", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "keystore")
+		testinggo.AssertNoError(t, err)
+		defer os.RemoveAll(dir)
+		store := makeBCStore(t, alias, nil, dir)
+		testinggo.AssertNoError(t, store.RegisterAlias(alias, []byte("password1234"), key))
+		aliases, err := store.Node.GetChannel(aliasgo.ALIAS)
+		testinggo.AssertNoError(t, err)
+		_, record, err := conveygo.DigestToRecord(alias, key, bcgo.Timestamp(), makeDigestEntries())
+		testinggo.AssertNoError(t, err)
+		entries, err := conveygo.VerifyDigestRecord(aliases, nil, store.Node.Cache, nil, record)
+		testinggo.AssertNoError(t, err)
+		if len(entries) != 1 {
+			t.Errorf("Wrong number of entries; expected '1', got '%d'", len(entries))
+		}
+	})
+}
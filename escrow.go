@@ -0,0 +1,263 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	ERROR_CREATOR_ESCROW_SENDER_DONT_MATCH = "Record Creator and Escrow Sender don't match: %s vs %s"
+	ERROR_ESCROW_ALREADY_RELEASED          = "Escrow already released: %s"
+	ERROR_ESCROW_DEADLINE_PASSED           = "Escrow deadline passed: %s"
+	ERROR_ESCROW_DEADLINE_NOT_PASSED       = "Escrow deadline not passed: %s"
+	ERROR_NO_SUCH_ESCROW                   = "No such escrow: %s"
+)
+
+// Returns true if the release refunds the tokens to the sender.
+func (m *EscrowRelease) IsRefund() bool {
+	return m.Receiver == m.Sender
+}
+
+func OpenEscrowChannel() *bcgo.Channel {
+	escrows := bcgo.OpenPoWChannel(CONVEY_ESCROW, bcgo.THRESHOLD_G)
+	escrows.AddValidator(&EscrowValidator{})
+	escrows.AddValidator(&SignatureValidator{})
+	return escrows
+}
+
+func OpenEscrowReleaseChannel() *bcgo.Channel {
+	releases := bcgo.OpenPoWChannel(CONVEY_ESCROW_RELEASE, bcgo.THRESHOLD_G)
+	releases.AddValidator(&EscrowReleaseValidator{})
+	releases.AddValidator(&SignatureValidator{})
+	return releases
+}
+
+// Returns the escrow in the Convey-Escrow Chain with the given record hash, and the timestamp of its record.
+func GetEscrow(cache bcgo.Cache, network bcgo.Network, escrowHash []byte) (*Escrow, uint64, error) {
+	block, err := bcgo.GetBlockContainingRecord(CONVEY_ESCROW, cache, network, escrowHash)
+	if err != nil {
+		return nil, 0, errors.New(fmt.Sprintf(ERROR_NO_SUCH_ESCROW, base64.RawURLEncoding.EncodeToString(escrowHash)))
+	}
+	for _, entry := range block.Entry {
+		if bytes.Equal(entry.RecordHash, escrowHash) {
+			e := &Escrow{}
+			if err := proto.Unmarshal(entry.Record.Payload, e); err != nil {
+				return nil, 0, err
+			}
+			return e, entry.Record.Timestamp, nil
+		}
+	}
+	return nil, 0, errors.New(fmt.Sprintf(ERROR_NO_SUCH_ESCROW, base64.RawURLEncoding.EncodeToString(escrowHash)))
+}
+
+// Checks the release was made by the sender of the escrow, and that refunds are only made after the deadline.
+func CheckEscrowRelease(escrow *Escrow, release *EscrowRelease, timestamp uint64) error {
+	if escrow.Sender != release.Sender {
+		return errors.New(fmt.Sprintf(ERROR_CREATOR_ESCROW_SENDER_DONT_MATCH, release.Sender, escrow.Sender))
+	}
+	if release.IsRefund() && timestamp <= escrow.Deadline {
+		return errors.New(fmt.Sprintf(ERROR_ESCROW_DEADLINE_NOT_PASSED, base64.RawURLEncoding.EncodeToString(release.Escrow)))
+	}
+	return nil
+}
+
+type EscrowValidator struct {
+}
+
+func (v *EscrowValidator) Validate(channel *bcgo.Channel, cache bcgo.Cache, network bcgo.Network, hash []byte, block *bcgo.Block) error {
+	return bcgo.Iterate(channel.Name, hash, block, cache, network, func(h []byte, b *bcgo.Block) error {
+		for _, entry := range b.Entry {
+			// Unmarshal as Escrow
+			e := &Escrow{}
+			if err := proto.Unmarshal(entry.Record.Payload, e); err != nil {
+				return err
+			}
+			// Check Record Creator matches Sender of Tokens
+			if entry.Record.Creator != e.Sender {
+				return errors.New(fmt.Sprintf(ERROR_CREATOR_ESCROW_SENDER_DONT_MATCH, entry.Record.Creator, e.Sender))
+			}
+			if e.Amount == 0 {
+				return errors.New(fmt.Sprintf(ERROR_INVALID_AMOUNT, e.Amount))
+			}
+			// Check the deadline is in the future, otherwise the tokens could be refunded immediately
+			if e.Deadline <= entry.Record.Timestamp {
+				return errors.New(fmt.Sprintf(ERROR_ESCROW_DEADLINE_PASSED, base64.RawURLEncoding.EncodeToString(entry.RecordHash)))
+			}
+		}
+		return nil
+	})
+}
+
+// EscrowReleaseValidator ensures each escrow is released at most once, by its sender, and only refunded after its deadline.
+type EscrowReleaseValidator struct {
+}
+
+func (v *EscrowReleaseValidator) Validate(channel *bcgo.Channel, cache bcgo.Cache, network bcgo.Network, hash []byte, block *bcgo.Block) error {
+	released := make(map[string]bool)
+	return bcgo.Iterate(channel.Name, hash, block, cache, network, func(h []byte, b *bcgo.Block) error {
+		for _, entry := range b.Entry {
+			// Unmarshal as EscrowRelease
+			r := &EscrowRelease{}
+			if err := proto.Unmarshal(entry.Record.Payload, r); err != nil {
+				return err
+			}
+			key := base64.RawURLEncoding.EncodeToString(r.Escrow)
+			if released[key] {
+				return errors.New(fmt.Sprintf(ERROR_ESCROW_ALREADY_RELEASED, key))
+			}
+			released[key] = true
+			// Check Record Creator matches Sender of Tokens
+			if entry.Record.Creator != r.Sender {
+				return errors.New(fmt.Sprintf(ERROR_CREATOR_ESCROW_SENDER_DONT_MATCH, entry.Record.Creator, r.Sender))
+			}
+			e, _, err := GetEscrow(cache, network, r.Escrow)
+			if err != nil {
+				return err
+			}
+			if err := CheckEscrowRelease(e, r, entry.Record.Timestamp); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Locks tokens from the alias in escrow until the deadline, after checking the alias's balance in the ledger, and returns the hash of the escrow record.
+func (s *BCStore) LockEscrow(ledger *Ledger, alias string, key crypto.Signer, amount, deadline uint64, memo string, conversation []byte) ([]byte, error) {
+	if amount == 0 {
+		return nil, errors.New(fmt.Sprintf(ERROR_INVALID_AMOUNT, amount))
+	}
+	escrows := s.Node.GetOrOpenChannel(CONVEY_ESCROW, OpenEscrowChannel)
+	s.Node.GetOrOpenChannel(CONVEY_ESCROW_RELEASE, OpenEscrowReleaseChannel)
+
+	if err := ledger.UpdateAll(); err != nil {
+		return nil, err
+	}
+	if balance, _ := ledger.GetBalance(alias); balance < int64(amount) {
+		return nil, errors.New(fmt.Sprintf(ERROR_INSUFFICIENT_BALANCE, balance, amount))
+	}
+
+	hash, record, err := ProtoToRecord(alias, key, bcgo.Timestamp(), &Escrow{
+		Sender:       alias,
+		Amount:       amount,
+		Deadline:     deadline,
+		Memo:         memo,
+		Conversation: conversation,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.MineBlockEntry(escrows, &bcgo.BlockEntry{
+		RecordHash: hash,
+		Record:     record,
+	}); err != nil {
+		return nil, err
+	}
+	if err := ledger.Update(escrows.Name, escrows.Head); err != nil {
+		return nil, err
+	}
+	return hash, nil
+}
+
+// Releases the tokens locked by the escrow to the receiver, and returns the hash of the release record.
+func (s *BCStore) ReleaseEscrow(ledger *Ledger, alias string, key crypto.Signer, escrowHash []byte, receiver string) ([]byte, error) {
+	if receiver == "" {
+		return nil, errors.New(ERROR_MISSING_RECEIVER)
+	}
+	return s.releaseEscrow(ledger, alias, key, escrowHash, receiver)
+}
+
+// Refunds the tokens locked by the escrow to the alias after the deadline, and returns the hash of the refund record.
+func (s *BCStore) RefundEscrow(ledger *Ledger, alias string, key crypto.Signer, escrowHash []byte) ([]byte, error) {
+	return s.releaseEscrow(ledger, alias, key, escrowHash, alias)
+}
+
+// Returns the release of the escrow, or nil if it has not been released.
+func (s *BCStore) GetEscrowRelease(escrowHash []byte) (*EscrowRelease, error) {
+	releases := s.Node.GetOrOpenChannel(CONVEY_ESCROW_RELEASE, OpenEscrowReleaseChannel)
+	var release *EscrowRelease
+	if err := bcgo.Iterate(releases.Name, releases.Head, nil, s.Node.Cache, s.Node.Network, func(h []byte, b *bcgo.Block) error {
+		for _, entry := range b.Entry {
+			r := &EscrowRelease{}
+			if err := proto.Unmarshal(entry.Record.Payload, r); err != nil {
+				return err
+			}
+			if bytes.Equal(r.Escrow, escrowHash) {
+				release = r
+				return bcgo.StopIterationError{}
+			}
+		}
+		return nil
+	}); err != nil {
+		switch err.(type) {
+		case bcgo.StopIterationError:
+			// Do nothing
+			break
+		default:
+			return nil, err
+		}
+	}
+	return release, nil
+}
+
+func (s *BCStore) releaseEscrow(ledger *Ledger, alias string, key crypto.Signer, escrowHash []byte, receiver string) ([]byte, error) {
+	s.Node.GetOrOpenChannel(CONVEY_ESCROW, OpenEscrowChannel)
+	releases := s.Node.GetOrOpenChannel(CONVEY_ESCROW_RELEASE, OpenEscrowReleaseChannel)
+
+	escrow, _, err := GetEscrow(s.Node.Cache, s.Node.Network, escrowHash)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.GetEscrowRelease(escrowHash)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		return nil, errors.New(fmt.Sprintf(ERROR_ESCROW_ALREADY_RELEASED, base64.RawURLEncoding.EncodeToString(escrowHash)))
+	}
+
+	release := &EscrowRelease{
+		Escrow:   escrowHash,
+		Sender:   alias,
+		Receiver: receiver,
+	}
+	timestamp := bcgo.Timestamp()
+	if err := CheckEscrowRelease(escrow, release, timestamp); err != nil {
+		return nil, err
+	}
+	hash, record, err := ProtoToRecord(alias, key, timestamp, release)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.MineBlockEntry(releases, &bcgo.BlockEntry{
+		RecordHash: hash,
+		Record:     record,
+	}); err != nil {
+		return nil, err
+	}
+	if err := ledger.UpdateAll(); err != nil {
+		return nil, err
+	}
+	return hash, nil
+}
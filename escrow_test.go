@@ -0,0 +1,125 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/testinggo"
+	"github.com/golang/protobuf/proto"
+	"testing"
+	"time"
+)
+
+func mineEscrowRecord(t *testing.T, node *bcgo.Node, channel *bcgo.Channel, alias string, key *rsa.PrivateKey, timestamp uint64, payload proto.Message) ([]byte, error) {
+	t.Helper()
+	hash, record, err := conveygo.ProtoToRecord(alias, key, timestamp, payload)
+	testinggo.AssertNoError(t, err)
+	testinggo.AssertNoError(t, node.Cache.PutBlockEntry(channel.Name, &bcgo.BlockEntry{
+		RecordHash: hash,
+		Record:     record,
+	}))
+	_, _, err = node.Mine(channel, bcgo.THRESHOLD_Z, nil)
+	return hash, err
+}
+
+func makeEscrowNode(t *testing.T, alias string, key *rsa.PrivateKey, timestamp, deadline uint64) (*bcgo.Node, *bcgo.Channel, *bcgo.Channel, []byte) {
+	t.Helper()
+	node := makeNode(t, alias, key)
+	escrows := bcgo.OpenPoWChannel(conveygo.CONVEY_ESCROW, bcgo.THRESHOLD_Z)
+	escrows.AddValidator(&conveygo.EscrowValidator{})
+	node.AddChannel(escrows)
+	releases := bcgo.OpenPoWChannel(conveygo.CONVEY_ESCROW_RELEASE, bcgo.THRESHOLD_Z)
+	releases.AddValidator(&conveygo.EscrowReleaseValidator{})
+	node.AddChannel(releases)
+	escrowHash, err := mineEscrowRecord(t, node, escrows, alias, key, timestamp, &conveygo.Escrow{
+		Sender:   alias,
+		Amount:   50,
+		Deadline: deadline,
+	})
+	testinggo.AssertNoError(t, err)
+	return node, escrows, releases, escrowHash
+}
+
+func TestEscrowValidators(t *testing.T) {
+	aliasA := "Alice"
+	keyA, err := rsa.GenerateKey(rand.Reader, 2048)
+	testinggo.AssertNoError(t, err)
+	aliasB := "Bob"
+	keyB, err := rsa.GenerateKey(rand.Reader, 2048)
+	testinggo.AssertNoError(t, err)
+	now := bcgo.Timestamp()
+	deadline := now + uint64(time.Hour)
+
+	t.Run("DeadlinePassed", func(t *testing.T) {
+		node, escrows, _, _ := makeEscrowNode(t, aliasA, keyA, now, deadline)
+		hash, err := mineEscrowRecord(t, node, escrows, aliasA, keyA, bcgo.Timestamp(), &conveygo.Escrow{
+			Sender:   aliasA,
+			Amount:   50,
+			Deadline: now,
+		})
+		testinggo.AssertError(t, fmt.Sprintf("Chain invalid: Escrow deadline passed: %s", base64.RawURLEncoding.EncodeToString(hash)), err)
+	})
+	t.Run("NotSender", func(t *testing.T) {
+		node, _, releases, escrowHash := makeEscrowNode(t, aliasA, keyA, now, deadline)
+		_, err := mineEscrowRecord(t, node, releases, aliasB, keyB, bcgo.Timestamp(), &conveygo.EscrowRelease{
+			Escrow:   escrowHash,
+			Sender:   aliasB,
+			Receiver: aliasB,
+		})
+		testinggo.AssertError(t, fmt.Sprintf("Chain invalid: Record Creator and Escrow Sender don't match: %s vs %s", aliasB, aliasA), err)
+	})
+	t.Run("RefundBeforeDeadline", func(t *testing.T) {
+		node, _, releases, escrowHash := makeEscrowNode(t, aliasA, keyA, now, deadline)
+		_, err := mineEscrowRecord(t, node, releases, aliasA, keyA, bcgo.Timestamp(), &conveygo.EscrowRelease{
+			Escrow:   escrowHash,
+			Sender:   aliasA,
+			Receiver: aliasA,
+		})
+		testinggo.AssertError(t, fmt.Sprintf("Chain invalid: Escrow deadline not passed: %s", base64.RawURLEncoding.EncodeToString(escrowHash)), err)
+	})
+	t.Run("ReleaseOnce", func(t *testing.T) {
+		node, _, releases, escrowHash := makeEscrowNode(t, aliasA, keyA, now, deadline)
+		_, err := mineEscrowRecord(t, node, releases, aliasA, keyA, bcgo.Timestamp(), &conveygo.EscrowRelease{
+			Escrow:   escrowHash,
+			Sender:   aliasA,
+			Receiver: aliasB,
+		})
+		testinggo.AssertNoError(t, err)
+		_, err = mineEscrowRecord(t, node, releases, aliasA, keyA, deadline+1, &conveygo.EscrowRelease{
+			Escrow:   escrowHash,
+			Sender:   aliasA,
+			Receiver: aliasA,
+		})
+		testinggo.AssertError(t, fmt.Sprintf("Chain invalid: Escrow already released: %s", base64.RawURLEncoding.EncodeToString(escrowHash)), err)
+
+		ledger := conveygo.NewLedger(node)
+		ledger.RecordMinted(aliasA, 50)
+		testinggo.AssertNoError(t, ledger.UpdateAll())
+		checkLedger(t, ledger)
+		if b, _ := ledger.GetBalance(aliasB); b != 50 {
+			t.Errorf("Wrong balance; expected '50', got '%d'", b)
+		}
+		if l := ledger.GetLocked(aliasA); l != 0 {
+			t.Errorf("Wrong locked; expected '0', got '%d'", l)
+		}
+	})
+}
@@ -0,0 +1,374 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package events
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/golang/protobuf/proto"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	EVENT_NEW_CONVERSATION = "NewConversation"
+	EVENT_NEW_REPLY        = "NewReply"
+	EVENT_NEW_TAG          = "NewTag"
+	EVENT_NEW_TRANSACTION  = "NewTransaction"
+	EVENT_BALANCE_CHANGE   = "BalanceChange"
+	EVENT_NOTIFICATION     = "Notification"
+
+	DEFAULT_BUFFER = 64
+)
+
+type Event struct {
+	Type string
+	// Hash of the conversation the event belongs to.
+	Conversation string `json:",omitempty"`
+	// Hash of the reply, or of the tagged message.
+	Message string `json:",omitempty"`
+	// Hash of the message being replied to.
+	Previous  string `json:",omitempty"`
+	Author    string `json:",omitempty"`
+	Timestamp uint64 `json:",omitempty"`
+	Topic     string `json:",omitempty"`
+	Tag       string `json:",omitempty"`
+	// Alias whose balance changed, with the new balance and the change, or who was notified of a reply.
+	Alias   string `json:",omitempty"`
+	Balance int64  `json:",omitempty"`
+	Change  int64  `json:",omitempty"`
+	// Hash of the transaction, with its receiver and amount; the sender is the Author.
+	Transaction string `json:",omitempty"`
+	Receiver    string `json:",omitempty"`
+	Amount      uint64 `json:",omitempty"`
+}
+
+// Filter selects events; empty fields match everything.
+type Filter struct {
+	Types        []string
+	Conversation string
+	// Matches the author of a conversation, reply or tag, the sender or receiver of a transaction, or the alias of a balance change.
+	Author string
+}
+
+func (f *Filter) Matches(e *Event) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.Types) > 0 {
+		var found bool
+		for _, t := range f.Types {
+			if t == e.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Conversation != "" && f.Conversation != e.Conversation {
+		return false
+	}
+	if f.Author != "" && f.Author != e.Author && f.Author != e.Alias && f.Author != e.Receiver {
+		return false
+	}
+	return true
+}
+
+type Subscription struct {
+	Filter *Filter
+	Events chan *Event
+}
+
+// Dispatcher watches channel head updates and publishes the resulting events to subscribers.
+// Watching the Convey-Transaction channel publishes transactions, and watching the Convey-Conversation channel also watches the Message channel of each new conversation, and each new message's Tag channel.
+// If a Ledger is set it is updated after each head update, and balance changes are published.
+type Dispatcher struct {
+	Node          *bcgo.Node
+	Ledger        *conveygo.Ledger
+	Heads         map[string][]byte // Channel Name -> Last Processed Head
+	Balances      map[string]int64
+	subscriptions map[*Subscription]bool
+	lock          sync.Mutex
+}
+
+func NewDispatcher(node *bcgo.Node, ledger *conveygo.Ledger) *Dispatcher {
+	return &Dispatcher{
+		Node:          node,
+		Ledger:        ledger,
+		Heads:         make(map[string][]byte),
+		Balances:      make(map[string]int64),
+		subscriptions: make(map[*Subscription]bool),
+	}
+}
+
+// Subscribes to events matching the filter, buffering up to the given number of events.
+// Events are dropped for subscribers whose buffer is full.
+func (d *Dispatcher) Subscribe(filter *Filter, buffer int) *Subscription {
+	s := &Subscription{
+		Filter: filter,
+		Events: make(chan *Event, buffer),
+	}
+	d.lock.Lock()
+	d.subscriptions[s] = true
+	d.lock.Unlock()
+	return s
+}
+
+func (d *Dispatcher) Unsubscribe(s *Subscription) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.subscriptions[s] {
+		delete(d.subscriptions, s)
+		close(s.Events)
+	}
+}
+
+func (d *Dispatcher) Publish(e *Event) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for s := range d.subscriptions {
+		if s.Filter.Matches(e) {
+			select {
+			case s.Events <- e:
+			default:
+				log.Println("Dropped", e.Type, "event for slow subscriber")
+			}
+		}
+	}
+}
+
+// Watches the channel for head updates, starting from its current head.
+func (d *Dispatcher) Watch(channel *bcgo.Channel) {
+	d.lock.Lock()
+	if _, ok := d.Heads[channel.Name]; ok {
+		d.lock.Unlock()
+		return
+	}
+	d.Heads[channel.Name] = channel.Head
+	d.lock.Unlock()
+	channel.AddTrigger(func() {
+		if err := d.Update(channel); err != nil {
+			log.Println(err)
+		}
+	})
+}
+
+// Watches the Message channel of the given conversation, opening it if necessary.
+func (d *Dispatcher) WatchConversation(conversationHash string) {
+	d.Watch(d.open(conveygo.CONVEY_PREFIX_MESSAGE+conversationHash, func() *bcgo.Channel {
+		return conveygo.OpenMessageChannel(conversationHash)
+	}))
+}
+
+// Watches the Tag channel of the given message, opening it if necessary.
+func (d *Dispatcher) WatchMessage(messageHash string) {
+	d.Watch(d.open(conveygo.CONVEY_PREFIX_TAG+messageHash, func() *bcgo.Channel {
+		return conveygo.OpenTagChannel(messageHash)
+	}))
+}
+
+// Publishes events for the blocks added to the channel since it was last processed, oldest first.
+func (d *Dispatcher) Update(channel *bcgo.Channel) error {
+	d.lock.Lock()
+	last := d.Heads[channel.Name]
+	d.Heads[channel.Name] = channel.Head
+	d.lock.Unlock()
+
+	var blocks []*bcgo.Block
+	if err := bcgo.Iterate(channel.Name, channel.Head, nil, d.Node.Cache, d.Node.Network, func(h []byte, b *bcgo.Block) error {
+		if bytes.Equal(h, last) {
+			return bcgo.StopIterationError{}
+		}
+		blocks = append(blocks, b)
+		return nil
+	}); err != nil {
+		switch err.(type) {
+		case bcgo.StopIterationError:
+			// Do nothing
+			break
+		default:
+			return err
+		}
+	}
+
+	for i := len(blocks) - 1; i >= 0; i-- {
+		for _, entry := range blocks[i].Entry {
+			if err := d.process(channel.Name, entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	if d.Ledger != nil {
+		return d.updateBalances(channel)
+	}
+	return nil
+}
+
+func (d *Dispatcher) process(name string, entry *bcgo.BlockEntry) error {
+	hash := base64.RawURLEncoding.EncodeToString(entry.RecordHash)
+	record := entry.Record
+	switch {
+	case name == conveygo.CONVEY_CONVERSATION:
+		c := &conveygo.Conversation{}
+		if err := proto.Unmarshal(record.Payload, c); err != nil {
+			return err
+		}
+		d.WatchConversation(hash)
+		d.Publish(&Event{
+			Type:         EVENT_NEW_CONVERSATION,
+			Conversation: hash,
+			Author:       record.Creator,
+			Timestamp:    record.Timestamp,
+			Topic:        c.Topic,
+		})
+	case name == conveygo.CONVEY_TRANSACTION:
+		t := &conveygo.Transaction{}
+		if err := proto.Unmarshal(record.Payload, t); err != nil {
+			return err
+		}
+		d.Publish(&Event{
+			Type:        EVENT_NEW_TRANSACTION,
+			Author:      t.Sender,
+			Timestamp:   record.Timestamp,
+			Transaction: hash,
+			Receiver:    t.Receiver,
+			Amount:      t.Amount,
+		})
+	case strings.HasPrefix(name, conveygo.CONVEY_PREFIX_MESSAGE):
+		m := &conveygo.Message{}
+		if err := proto.Unmarshal(record.Payload, m); err != nil {
+			return err
+		}
+		d.WatchMessage(hash)
+		if len(m.Previous) > 0 {
+			d.Publish(&Event{
+				Type:         EVENT_NEW_REPLY,
+				Conversation: strings.TrimPrefix(name, conveygo.CONVEY_PREFIX_MESSAGE),
+				Message:      hash,
+				Previous:     base64.RawURLEncoding.EncodeToString(m.Previous),
+				Author:       record.Creator,
+				Timestamp:    record.Timestamp,
+			})
+		}
+	case strings.HasPrefix(name, conveygo.CONVEY_PREFIX_TAG):
+		t := &conveygo.Tag{}
+		if err := proto.Unmarshal(record.Payload, t); err != nil {
+			return err
+		}
+		d.Publish(&Event{
+			Type:      EVENT_NEW_TAG,
+			Message:   strings.TrimPrefix(name, conveygo.CONVEY_PREFIX_TAG),
+			Author:    record.Creator,
+			Timestamp: record.Timestamp,
+			Tag:       t.Value,
+		})
+	}
+	return nil
+}
+
+// Updates the ledger with the channel's new head, and publishes the balances that changed.
+func (d *Dispatcher) updateBalances(channel *bcgo.Channel) error {
+	if err := d.Ledger.Update(channel.Name, channel.Head); err != nil {
+		return err
+	}
+	var changes []*Event
+	d.lock.Lock()
+	for alias := range d.Ledger.Aliases {
+		balance, _ := d.Ledger.GetBalance(alias)
+		if previous := d.Balances[alias]; balance != previous {
+			d.Balances[alias] = balance
+			changes = append(changes, &Event{
+				Type:    EVENT_BALANCE_CHANGE,
+				Alias:   alias,
+				Balance: balance,
+				Change:  balance - previous,
+			})
+		}
+	}
+	d.lock.Unlock()
+	for _, e := range changes {
+		d.Publish(e)
+	}
+	return nil
+}
+
+// Opens the named channel from the cache if the node does not already have it.
+func (d *Dispatcher) open(name string, opener func() *bcgo.Channel) *bcgo.Channel {
+	channel, err := d.Node.GetChannel(name)
+	if err != nil {
+		channel = opener()
+		if err := channel.LoadCachedHead(d.Node.Cache); err != nil {
+			log.Println(err)
+		}
+		d.Node.AddChannel(channel)
+	}
+	return channel
+}
+
+// Streams events to the client as Server-Sent Events.
+// The type, conversation and author query parameters set the filter, with types separated by commas.
+func (d *Dispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	query := r.URL.Query()
+	filter := &Filter{
+		Conversation: query.Get("conversation"),
+		Author:       query.Get("author"),
+	}
+	if t := query.Get("type"); t != "" {
+		filter.Types = strings.Split(t, ",")
+	}
+	s := d.Subscribe(filter, DEFAULT_BUFFER)
+	defer d.Unsubscribe(s)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-s.Events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
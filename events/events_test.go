@@ -0,0 +1,247 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package events_test
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"github.com/AletheiaWareLLC/aliasgo"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/events"
+	"github.com/AletheiaWareLLC/testinggo"
+	"github.com/golang/protobuf/proto"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func nextEvent(t *testing.T, s *events.Subscription) *events.Event {
+	t.Helper()
+	select {
+	case e := <-s.Events:
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for event")
+	}
+	return nil
+}
+
+func assertNoEvent(t *testing.T, s *events.Subscription) {
+	t.Helper()
+	select {
+	case e := <-s.Events:
+		t.Errorf("Unexpected event: %v", e)
+	default:
+	}
+}
+
+func TestFilter(t *testing.T) {
+	e := &events.Event{
+		Type:         events.EVENT_NEW_REPLY,
+		Conversation: "Conversation",
+		Author:       "Alice",
+	}
+	for name, test := range map[string]struct {
+		filter   *events.Filter
+		expected bool
+	}{
+		"Nil":                   {nil, true},
+		"Empty":                 {&events.Filter{}, true},
+		"Type":                  {&events.Filter{Types: []string{events.EVENT_NEW_TAG, events.EVENT_NEW_REPLY}}, true},
+		"WrongType":             {&events.Filter{Types: []string{events.EVENT_NEW_TAG}}, false},
+		"Conversation":          {&events.Filter{Conversation: "Conversation"}, true},
+		"WrongConversation":     {&events.Filter{Conversation: "Other"}, false},
+		"Author":                {&events.Filter{Author: "Alice"}, true},
+		"WrongAuthor":           {&events.Filter{Author: "Bob"}, false},
+		"AuthorAndConversation": {&events.Filter{Author: "Alice", Conversation: "Other"}, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if actual := test.filter.Matches(e); actual != test.expected {
+				t.Errorf("Wrong match; expected '%t', got '%t'", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	alias := "Alice"
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	testinggo.AssertNoError(t, err)
+	node := &bcgo.Node{
+		Alias:    alias,
+		Cache:    bcgo.NewMemoryCache(10),
+		Channels: make(map[string]*bcgo.Channel),
+	}
+	// Register the alias so the signatures of its records can be validated
+	aliasRecord, err := conveygo.CreateSignedAliasRecord(alias, key)
+	testinggo.AssertNoError(t, err)
+	_, err = bcgo.WriteRecord(aliasgo.ALIAS, node.Cache, aliasRecord)
+	testinggo.AssertNoError(t, err)
+	aliases := node.GetOrOpenChannel(aliasgo.ALIAS, aliasgo.OpenAliasChannel)
+	_, _, err = node.Mine(aliases, aliasgo.ALIAS_THRESHOLD, nil)
+	testinggo.AssertNoError(t, err)
+	conversations := conveygo.OpenConversationChannel()
+	node.AddChannel(conversations)
+	store := &conveygo.BCStore{
+		Node: node,
+	}
+
+	dispatcher := events.NewDispatcher(node, conveygo.NewLedger(node))
+	dispatcher.Watch(conversations)
+	all := dispatcher.Subscribe(nil, events.DEFAULT_BUFFER)
+	tags := dispatcher.Subscribe(&events.Filter{
+		Types: []string{events.EVENT_NEW_TAG},
+	}, events.DEFAULT_BUFFER)
+	bob := dispatcher.Subscribe(&events.Filter{
+		Author: "Bob",
+	}, events.DEFAULT_BUFFER)
+
+	timestamp := bcgo.Timestamp()
+	conversationHash, conversationRecord, err := conveygo.ProtoToRecord(alias, key, timestamp, &conveygo.Conversation{
+		Topic: "Test",
+	})
+	testinggo.AssertNoError(t, err)
+	messageHash, messageRecord, err := conveygo.ProtoToRecord(alias, key, timestamp, &conveygo.Message{
+		Content: []byte("Hello"),
+		Type:    conveygo.MediaType_TEXT_PLAIN,
+	})
+	testinggo.AssertNoError(t, err)
+	testinggo.AssertNoError(t, store.NewConversation(conversationHash, conversationRecord, messageHash, messageRecord))
+	conversation := base64.RawURLEncoding.EncodeToString(conversationHash)
+
+	t.Run("NewConversation", func(t *testing.T) {
+		e := nextEvent(t, all)
+		if e.Type != events.EVENT_NEW_CONVERSATION || e.Conversation != conversation || e.Topic != "Test" {
+			t.Errorf("Wrong event; expected new conversation '%s', got '%v'", conversation, e)
+		}
+		// Creating the conversation burned tokens
+		e = nextEvent(t, all)
+		if e.Type != events.EVENT_BALANCE_CHANGE || e.Alias != alias || e.Change >= 0 {
+			t.Errorf("Wrong event; expected balance decrease for '%s', got '%v'", alias, e)
+		}
+		// Burning the first message
+		e = nextEvent(t, all)
+		if e.Type != events.EVENT_BALANCE_CHANGE || e.Alias != alias {
+			t.Errorf("Wrong event; expected balance change for '%s', got '%v'", alias, e)
+		}
+	})
+	t.Run("NewReply", func(t *testing.T) {
+		replyHash, replyRecord, err := conveygo.ProtoToRecord(alias, key, bcgo.Timestamp(), &conveygo.Message{
+			Previous: messageHash,
+			Content:  []byte("Reply"),
+			Type:     conveygo.MediaType_TEXT_PLAIN,
+		})
+		testinggo.AssertNoError(t, err)
+		testinggo.AssertNoError(t, store.AddMessage(conversationHash, replyHash, replyRecord))
+		e := nextEvent(t, all)
+		if e.Type != events.EVENT_NEW_REPLY || e.Conversation != conversation || e.Previous != base64.RawURLEncoding.EncodeToString(messageHash) {
+			t.Errorf("Wrong event; expected reply in '%s', got '%v'", conversation, e)
+		}
+		nextEvent(t, all) // Balance change
+	})
+	t.Run("NewTag", func(t *testing.T) {
+		tagHash, tagRecord, err := conveygo.ProtoToRecord(alias, key, bcgo.Timestamp(), &conveygo.Tag{
+			Value: "news",
+		})
+		testinggo.AssertNoError(t, err)
+		testinggo.AssertNoError(t, store.AddTag(messageHash, tagHash, tagRecord))
+		e := nextEvent(t, tags)
+		if e.Type != events.EVENT_NEW_TAG || e.Tag != "news" || e.Message != base64.RawURLEncoding.EncodeToString(messageHash) {
+			t.Errorf("Wrong event; expected tag 'news', got '%v'", e)
+		}
+		assertNoEvent(t, tags)
+	})
+	t.Run("NewTransaction", func(t *testing.T) {
+		transactions := conveygo.OpenTransactionChannel()
+		node.AddChannel(transactions)
+		dispatcher.Watch(transactions)
+		data, err := proto.Marshal(&conveygo.Transaction{
+			Sender:   alias,
+			Receiver: "Bob",
+			Amount:   10,
+		})
+		testinggo.AssertNoError(t, err)
+		record, err := conveygo.CreateRecord(bcgo.Timestamp(), alias, key, nil, data)
+		testinggo.AssertNoError(t, err)
+		_, err = bcgo.WriteRecord(transactions.Name, node.Cache, record)
+		testinggo.AssertNoError(t, err)
+		_, _, err = node.Mine(transactions, bcgo.THRESHOLD_G, nil)
+		testinggo.AssertNoError(t, err)
+		e := nextEvent(t, bob)
+		if e.Type != events.EVENT_NEW_TRANSACTION || e.Author != alias || e.Receiver != "Bob" || e.Amount != 10 {
+			t.Errorf("Wrong event; expected transaction to 'Bob', got '%v'", e)
+		}
+		e = nextEvent(t, bob)
+		if e.Type != events.EVENT_BALANCE_CHANGE || e.Alias != "Bob" || e.Balance != 10 {
+			t.Errorf("Wrong event; expected balance of '10' for 'Bob', got '%v'", e)
+		}
+	})
+	t.Run("AuthorFilter", func(t *testing.T) {
+		assertNoEvent(t, bob)
+	})
+	t.Run("Unsubscribe", func(t *testing.T) {
+		dispatcher.Unsubscribe(bob)
+		if _, ok := <-bob.Events; ok {
+			t.Error("Expected events channel to be closed")
+		}
+	})
+}
+
+func TestServeHTTP(t *testing.T) {
+	node := &bcgo.Node{
+		Cache:    bcgo.NewMemoryCache(10),
+		Channels: make(map[string]*bcgo.Channel),
+	}
+	dispatcher := events.NewDispatcher(node, nil)
+	ts := httptest.NewServer(dispatcher)
+	defer ts.Close()
+
+	response, err := http.Get(ts.URL + "?type=" + events.EVENT_NEW_CONVERSATION + "&author=Alice")
+	testinggo.AssertNoError(t, err)
+	defer response.Body.Close()
+	if ct := response.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Wrong content type; expected 'text/event-stream', got '%s'", ct)
+	}
+
+	// Not matching the filter
+	dispatcher.Publish(&events.Event{
+		Type:   events.EVENT_NEW_CONVERSATION,
+		Author: "Bob",
+	})
+	dispatcher.Publish(&events.Event{
+		Type:   events.EVENT_NEW_CONVERSATION,
+		Author: "Alice",
+		Topic:  "Test",
+	})
+
+	reader := bufio.NewReader(response.Body)
+	line, err := reader.ReadString('\n')
+	testinggo.AssertNoError(t, err)
+	if line != "event: NewConversation\n" {
+		t.Errorf("Wrong event line; expected 'event: NewConversation', got '%s'", line)
+	}
+	line, err = reader.ReadString('\n')
+	testinggo.AssertNoError(t, err)
+	if !strings.Contains(line, `"Author":"Alice"`) || !strings.Contains(line, `"Topic":"Test"`) {
+		t.Errorf("Wrong data line; got '%s'", line)
+	}
+}
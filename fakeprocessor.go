@@ -0,0 +1,142 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/financego"
+	"sync"
+)
+
+// FakeProcessor processes payments in memory without contacting a payment service, for tests and development.
+type FakeProcessor struct {
+	Customers     map[string]*financego.Registration // Customer ID -> Registration
+	Charges       map[string]*financego.Charge       // Charge ID -> Charge
+	Subscriptions map[string]*financego.Subscription // Subscription ID -> Subscription
+	Refunded      map[string]int64                   // Charge ID -> Amount Refunded
+	Declined      map[string]bool                    // Payment ID -> Declined Flag
+	counter       int
+	lock          sync.Mutex
+}
+
+func NewFakeProcessor() *FakeProcessor {
+	return &FakeProcessor{
+		Customers:     make(map[string]*financego.Registration),
+		Charges:       make(map[string]*financego.Charge),
+		Subscriptions: make(map[string]*financego.Subscription),
+		Refunded:      make(map[string]int64),
+		Declined:      make(map[string]bool),
+	}
+}
+
+// Causes charges to the payment method to be declined.
+func (p *FakeProcessor) Decline(payment string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.Declined[payment] = true
+}
+
+func (p *FakeProcessor) NewCustomer(merchant, alias, email, payment, description string) (*financego.Registration, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	registration := &financego.Registration{
+		MerchantAlias: merchant,
+		CustomerAlias: alias,
+		Processor:     financego.PaymentProcessor_UNKNOWN_PROCESSOR,
+		CustomerId:    p.newId("cus"),
+		PaymentId:     payment,
+	}
+	p.Customers[registration.CustomerId] = registration
+	return registration, nil
+}
+
+func (p *FakeProcessor) NewCharge(registration *financego.Registration, product, plan, country, currency string, amount int64, description string) (*financego.Charge, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	customer, ok := p.Customers[registration.CustomerId]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf(ERROR_NO_SUCH_CUSTOMER, registration.CustomerId))
+	}
+	if p.Declined[customer.PaymentId] {
+		return nil, errors.New(fmt.Sprintf(ERROR_PAYMENT_DECLINED, customer.PaymentId))
+	}
+	charge := &financego.Charge{
+		MerchantAlias: customer.MerchantAlias,
+		CustomerAlias: customer.CustomerAlias,
+		Processor:     financego.PaymentProcessor_UNKNOWN_PROCESSOR,
+		CustomerId:    customer.CustomerId,
+		PaymentId:     customer.PaymentId,
+		ChargeId:      p.newId("ch"),
+		Amount:        amount,
+		ProductId:     product,
+		PlanId:        plan,
+		Country:       country,
+		Currency:      currency,
+		Description:   description,
+	}
+	p.Charges[charge.ChargeId] = charge
+	return charge, nil
+}
+
+func (p *FakeProcessor) NewSubscription(registration *financego.Registration, product, plan string) (*financego.Subscription, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	customer, ok := p.Customers[registration.CustomerId]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf(ERROR_NO_SUCH_CUSTOMER, registration.CustomerId))
+	}
+	if p.Declined[customer.PaymentId] {
+		return nil, errors.New(fmt.Sprintf(ERROR_PAYMENT_DECLINED, customer.PaymentId))
+	}
+	subscription := &financego.Subscription{
+		MerchantAlias:      customer.MerchantAlias,
+		CustomerAlias:      customer.CustomerAlias,
+		Processor:          financego.PaymentProcessor_UNKNOWN_PROCESSOR,
+		CustomerId:         customer.CustomerId,
+		PaymentId:          customer.PaymentId,
+		ProductId:          product,
+		PlanId:             plan,
+		SubscriptionId:     p.newId("sub"),
+		SubscriptionItemId: p.newId("si"),
+	}
+	p.Subscriptions[subscription.SubscriptionId] = subscription
+	return subscription, nil
+}
+
+func (p *FakeProcessor) Refund(charge *financego.Charge, amount int64) (string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	c, ok := p.Charges[charge.ChargeId]
+	if !ok {
+		return "", errors.New(fmt.Sprintf(ERROR_NO_SUCH_CHARGE, charge.ChargeId))
+	}
+	remaining := c.Amount - p.Refunded[c.ChargeId]
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount > remaining {
+		return "", errors.New(fmt.Sprintf(ERROR_REFUND_EXCEEDS_CHARGE, amount, remaining))
+	}
+	p.Refunded[c.ChargeId] += amount
+	return p.newId("re"), nil
+}
+
+func (p *FakeProcessor) newId(prefix string) string {
+	p.counter++
+	return fmt.Sprintf("%s_fake_%d", prefix, p.counter)
+}
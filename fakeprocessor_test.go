@@ -0,0 +1,70 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo_test
+
+import (
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/financego"
+	"github.com/AletheiaWareLLC/testinggo"
+	"testing"
+)
+
+func TestFakeProcessor(t *testing.T) {
+	t.Run("Charge", func(t *testing.T) {
+		p := conveygo.NewFakeProcessor()
+		registration, err := p.NewCustomer("Merchant", "Alice", "alice@example.com", "payment1234", "")
+		testinggo.AssertNoError(t, err)
+		charge, err := p.NewCharge(registration, "product1234", "plan1234", "US", "usd", 500, "Test")
+		testinggo.AssertNoError(t, err)
+		if charge.MerchantAlias != "Merchant" || charge.CustomerAlias != "Alice" || charge.Amount != 500 {
+			t.Errorf("Wrong charge; got '%v'", charge)
+		}
+	})
+	t.Run("Declined", func(t *testing.T) {
+		p := conveygo.NewFakeProcessor()
+		p.Decline("payment1234")
+		registration, err := p.NewCustomer("Merchant", "Alice", "alice@example.com", "payment1234", "")
+		testinggo.AssertNoError(t, err)
+		_, err = p.NewCharge(registration, "product1234", "plan1234", "US", "usd", 500, "Test")
+		testinggo.AssertError(t, "Payment declined: payment1234", err)
+		_, err = p.NewSubscription(registration, "product1234", "plan1234")
+		testinggo.AssertError(t, "Payment declined: payment1234", err)
+	})
+	t.Run("NoSuchCustomer", func(t *testing.T) {
+		p := conveygo.NewFakeProcessor()
+		_, err := p.NewCharge(&financego.Registration{CustomerId: "cus1234"}, "product1234", "plan1234", "US", "usd", 500, "Test")
+		testinggo.AssertError(t, "No such customer: cus1234", err)
+	})
+	t.Run("Refund", func(t *testing.T) {
+		p := conveygo.NewFakeProcessor()
+		registration, err := p.NewCustomer("Merchant", "Alice", "alice@example.com", "payment1234", "")
+		testinggo.AssertNoError(t, err)
+		charge, err := p.NewCharge(registration, "product1234", "plan1234", "US", "usd", 500, "Test")
+		testinggo.AssertNoError(t, err)
+		_, err = p.Refund(charge, 200)
+		testinggo.AssertNoError(t, err)
+		_, err = p.Refund(charge, 400)
+		testinggo.AssertError(t, "Refund exceeds charge: 400 > 300", err)
+		_, err = p.Refund(charge, 0)
+		testinggo.AssertNoError(t, err)
+		if p.Refunded[charge.ChargeId] != 500 {
+			t.Errorf("Wrong refunded; expected '500', got '%d'", p.Refunded[charge.ChargeId])
+		}
+		_, err = p.Refund(&financego.Charge{ChargeId: "ch1234"}, 0)
+		testinggo.AssertError(t, "No such charge: ch1234", err)
+	})
+}
@@ -11,6 +11,8 @@ require (
 	github.com/AletheiaWareLLC/testinggo v0.0.0-20200510171654-41852dce2bed
 	github.com/golang/protobuf v1.4.2
 	github.com/stretchr/testify v1.6.1 // indirect
-	golang.org/x/crypto v0.0.0-20200820211705-5c72a883971a // indirect
+	github.com/stripe/stripe-go v70.15.0+incompatible
+	golang.org/x/crypto v0.0.0-20200820211705-5c72a883971a
 	golang.org/x/sys v0.0.0-20200826173525-f9321e4c35a6 // indirect
+	google.golang.org/grpc v1.31.0
 )
@@ -17,16 +17,26 @@
 package html
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"github.com/AletheiaWareLLC/bcgo"
 	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/golang/protobuf/proto"
 	"html/template"
 	"regexp"
 )
 
+const (
+	DIGEST_SIGNATURE_META = "convey-digest-signature"
+
+	ERROR_NO_DIGEST_SIGNATURE = "No digest signature in HTML"
+)
+
 var (
-	newlines = regexp.MustCompile(`\r?\n\r?\n`)
-	anchors  = regexp.MustCompile(`\b(file|ftp|https?):\/\/\S+[\/\w]`)
+	newlines  = regexp.MustCompile(`\r?\n\r?\n`)
+	anchors   = regexp.MustCompile(`\b(file|ftp|https?):\/\/\S+[\/\w]`)
+	signature = regexp.MustCompile(`<meta name="` + DIGEST_SIGNATURE_META + `" content="([A-Za-z0-9_-]*)">`)
 )
 
 func ContentToHTML(message *conveygo.Message) (template.HTML, error) {
@@ -40,3 +50,29 @@ func ContentToHTML(message *conveygo.Message) (template.HTML, error) {
 		return "", errors.New(fmt.Sprintf(conveygo.ERROR_UNRECOGNIZED_MEDIA_TYPE, message.GetType()))
 	}
 }
+
+// Returns a meta tag holding the signed digest record, for the head of a digest page.
+func DigestSignatureToHTML(record *bcgo.Record) (template.HTML, error) {
+	data, err := proto.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(`<meta name="` + DIGEST_SIGNATURE_META + `" content="` + base64.RawURLEncoding.EncodeToString(data) + `">`), nil
+}
+
+// Extracts the signed digest record from the meta tag of a digest page.
+func ReadDigestSignature(data []byte) (*bcgo.Record, error) {
+	match := signature.FindSubmatch(data)
+	if match == nil {
+		return nil, errors.New(ERROR_NO_DIGEST_SIGNATURE)
+	}
+	bytes, err := base64.RawURLEncoding.DecodeString(string(match[1]))
+	if err != nil {
+		return nil, err
+	}
+	record := &bcgo.Record{}
+	if err := proto.Unmarshal(bytes, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
@@ -17,6 +17,9 @@
 package html_test
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"github.com/AletheiaWareLLC/bcgo"
 	"github.com/AletheiaWareLLC/conveygo"
 	"github.com/AletheiaWareLLC/conveygo/html"
 	"github.com/AletheiaWareLLC/testinggo"
@@ -69,3 +72,35 @@ func TestContentToHTML(t *testing.T) {
 		testTextPlain(t, expected, "Visit https://example.com for more.")
 	})
 }
+
+func TestDigestSignature(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Error("Could not generate key:", err)
+	}
+	t.Run("Exists", func(t *testing.T) {
+		_, record, err := conveygo.DigestToRecord("Alice", key, bcgo.Timestamp(), []*conveygo.DigestEntry{
+			&conveygo.DigestEntry{
+				Topic: "Test123",
+				Message: &conveygo.Message{
+					Content: []byte("FooBar"),
+					Type:    conveygo.MediaType_TEXT_PLAIN,
+				},
+			},
+		})
+		testinggo.AssertNoError(t, err)
+		meta, err := html.DigestSignatureToHTML(record)
+		testinggo.AssertNoError(t, err)
+		actual, err := html.ReadDigestSignature([]byte("<html><head>" + string(meta) + "</head></html>"))
+		testinggo.AssertNoError(t, err)
+		entries, err := conveygo.RecordToDigest(key.Public(), actual)
+		testinggo.AssertNoError(t, err)
+		if len(entries) != 1 || entries[0].Topic != "Test123" {
+			t.Errorf("Wrong entries; got '%v'", entries)
+		}
+	})
+	t.Run("NotExists", func(t *testing.T) {
+		_, err := html.ReadDigestSignature([]byte("<html></html>"))
+		testinggo.AssertError(t, html.ERROR_NO_DIGEST_SIGNATURE, err)
+	})
+}
@@ -0,0 +1,219 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"github.com/AletheiaWareLLC/aliasgo"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/financego"
+	"github.com/golang/protobuf/proto"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	INVOICE_ITEM_CONVERSATIONS = "Conversations"
+	INVOICE_ITEM_MESSAGES      = "Messages"
+)
+
+// ItemisedInvoice is an invoice read from the Convey-Invoice Chain, with the usage it bills.
+type ItemisedInvoice struct {
+	Hash    []byte
+	Invoice *financego.Invoice
+	Usage   *InvoiceUsage
+}
+
+// Returns the start (inclusive) and end (exclusive) timestamps of the calendar month, in UTC, containing the given time.
+func InvoicePeriod(month time.Time) (uint64, uint64) {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return uint64(start.UnixNano()), uint64(start.AddDate(0, 1, 0).UnixNano())
+}
+
+// Returns the number of the customer's invoice for the period starting at the given timestamp.
+func InvoiceNumber(alias string, start uint64) string {
+	return fmt.Sprintf("%s-%s", alias, time.Unix(0, int64(start)).UTC().Format("200601"))
+}
+
+// Itemises the records the alias posted in the ledger during the period, billing each Token at the rate.
+func NewInvoiceUsage(ledger *Ledger, alias string, start, end uint64, rate int64) *InvoiceUsage {
+	conversations := &InvoiceItem{
+		Description: INVOICE_ITEM_CONVERSATIONS,
+	}
+	messages := &InvoiceItem{
+		Description: INVOICE_ITEM_MESSAGES,
+	}
+	for _, p := range ledger.GetPosts(alias, start, end) {
+		item := messages
+		if p.Channel == CONVEY_CONVERSATION {
+			item = conversations
+		}
+		item.Quantity++
+		item.Tokens += p.Cost
+		item.Amount += int64(p.Cost) * rate
+	}
+	usage := &InvoiceUsage{
+		Start: start,
+		End:   end,
+	}
+	for _, item := range []*InvoiceItem{conversations, messages} {
+		if item.Quantity > 0 {
+			usage.Item = append(usage.Item, item)
+		}
+	}
+	return usage
+}
+
+// Returns the active subscriptions to the merchant in the Convey-Subscription Chain, keyed by customer alias.
+func (s *BCStore) GetSubscriptions() (map[string]*financego.Subscription, error) {
+	subscriptions := s.Node.GetOrOpenChannel(CONVEY_SUBSCRIPTION, OpenSubscriptionChannel)
+	results := make(map[string]*financego.Subscription)
+	if err := bcgo.Read(subscriptions.Name, subscriptions.Head, nil, s.Node.Cache, s.Node.Network, s.Node.Alias, s.Node.Key, nil, func(entry *bcgo.BlockEntry, key, data []byte) error {
+		// Unmarshal as Subscription
+		subscription := &financego.Subscription{}
+		if err := proto.Unmarshal(data, subscription); err != nil {
+			return err
+		}
+		// Iteration starts at the head so the latest subscription of each customer is kept
+		if _, ok := results[subscription.CustomerAlias]; !ok && subscription.MerchantAlias == s.Node.Alias {
+			results[subscription.CustomerAlias] = subscription
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Returns the invoices in the Convey-Invoice Chain which the alias can read, and in which they are either the customer or the merchant.
+func (s *BCStore) GetInvoices(alias string, key *rsa.PrivateKey) ([]*ItemisedInvoice, error) {
+	invoices := s.Node.GetOrOpenChannel(CONVEY_INVOICE, OpenInvoiceChannel)
+	var results []*ItemisedInvoice
+	if err := bcgo.Read(invoices.Name, invoices.Head, nil, s.Node.Cache, s.Node.Network, alias, key, nil, func(entry *bcgo.BlockEntry, key, data []byte) error {
+		// Unmarshal as Invoice
+		i := &Invoice{}
+		if err := proto.Unmarshal(data, i); err != nil {
+			return err
+		}
+		invoice := i.Invoice
+		if invoice == nil || (invoice.CustomerAlias != alias && invoice.MerchantAlias != alias) {
+			return nil
+		}
+		results = append(results, &ItemisedInvoice{
+			Hash:    entry.RecordHash,
+			Invoice: invoice,
+			Usage:   i.Usage,
+		})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Invoices each subscriber for the Tokens cost by the records they posted during the month containing the given time, at the rate per Token in the currency.
+// Invoices are written to the Convey-Invoice Chain, readable by the customer and the merchant, and customers already invoiced for the month are not invoiced again.
+// Returns all invoices for the month.
+func (s *BCStore) GenerateInvoices(ledger *Ledger, month time.Time, currency string, rate int64) ([]*ItemisedInvoice, error) {
+	if err := ledger.UpdateAll(); err != nil {
+		return nil, err
+	}
+	start, end := InvoicePeriod(month)
+
+	existing := make(map[string]*ItemisedInvoice)
+	invoices, err := s.GetInvoices(s.Node.Alias, s.Node.Key)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range invoices {
+		existing[i.Invoice.Number] = i
+	}
+
+	subscriptions, err := s.GetSubscriptions()
+	if err != nil {
+		return nil, err
+	}
+
+	aliases, err := s.Node.GetChannel(aliasgo.ALIAS)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*ItemisedInvoice
+	for alias, subscription := range subscriptions {
+		number := InvoiceNumber(alias, start)
+		if i, ok := existing[number]; ok {
+			results = append(results, i)
+			continue
+		}
+
+		publicKey, err := aliasgo.GetPublicKey(aliases, s.Node.Cache, s.Node.Network, alias)
+		if err != nil {
+			return nil, err
+		}
+
+		registration, err := s.GetRegistration(alias)
+		if err != nil {
+			return nil, err
+		}
+		processor := subscription.Processor
+		if registration != nil {
+			processor = registration.Processor
+		}
+
+		usage := NewInvoiceUsage(ledger, alias, start, end, rate)
+		var amount int64
+		for _, item := range usage.Item {
+			amount += item.Amount
+		}
+		invoice := &financego.Invoice{
+			MerchantAlias:   s.Node.Alias,
+			CustomerAlias:   alias,
+			Processor:       processor,
+			CustomerId:      subscription.CustomerId,
+			PaymentId:       subscription.PaymentId,
+			ProductId:       subscription.ProductId,
+			PlanId:          subscription.PlanId,
+			Currency:        strings.ToLower(currency),
+			Number:          number,
+			AmountDue:       amount,
+			AmountRemaining: amount,
+		}
+		log.Println("Invoice", invoice, usage)
+
+		// Write Invoice to Invoice Channel
+		hash, err := s.writeCustomerRecord(s.Node.GetOrOpenChannel(CONVEY_INVOICE, OpenInvoiceChannel), alias, publicKey, &Invoice{
+			Invoice: invoice,
+			Usage:   usage,
+		})
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, &ItemisedInvoice{
+			Hash:    hash,
+			Invoice: invoice,
+			Usage:   usage,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Invoice.Number < results[j].Invoice.Number
+	})
+	return results, nil
+}
@@ -0,0 +1,206 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/AletheiaWareLLC/aliasgo"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/pdf"
+	"github.com/AletheiaWareLLC/pdfgo"
+	"github.com/AletheiaWareLLC/pdfgo/font"
+	"log"
+	"os"
+	"path"
+	"time"
+)
+
+const (
+	ERROR_UNKNOWN_FONT_FAMILY = "Unknown font family: %s"
+)
+
+var (
+	host       = flag.String("host", "", "Convey host, empty for the default hosts")
+	month      = flag.String("month", "", "month to invoice as YYYY-MM, empty for the previous month")
+	currency   = flag.String("currency", "usd", "currency to invoice in")
+	rate       = flag.Int64("rate", 1, "amount charged per token, in the smallest unit of the currency")
+	directory  = flag.String("directory", ".", "directory to write invoice PDFs to")
+	fontfamily = flag.String("fontfamily", "Helvetica", "core font family; Courier, Helvetica, or Times")
+	themefile  = flag.String("theme", "", "JSON theme file, empty for the default A4 theme")
+)
+
+func main() {
+	flag.Parse()
+
+	period := time.Now().UTC().AddDate(0, -1, 0)
+	if *month != "" {
+		t, err := time.Parse("2006-01", *month)
+		if err != nil {
+			log.Fatal(err)
+		}
+		period = t
+	}
+
+	theme := pdf.DefaultTheme()
+	if *themefile != "" {
+		t, err := pdf.LoadTheme(*themefile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		theme = t
+	}
+
+	node, err := OpenNode()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	store := &conveygo.BCStore{
+		Node:     node,
+		Listener: &bcgo.PrintingMiningListener{Output: os.Stdout},
+	}
+
+	invoices, err := store.GenerateInvoices(conveygo.NewLedger(node), period, *currency, *rate)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, invoice := range invoices {
+		if err := WriteInvoice(theme, invoice); err != nil {
+			log.Fatal(err)
+		}
+	}
+	log.Println("Invoiced", len(invoices), "subscribers for", period.Format("2006-01"))
+}
+
+// Renders the invoice as a PDF named after the invoice number in the output directory.
+func WriteInvoice(theme *pdf.Theme, invoice *conveygo.ItemisedInvoice) error {
+	p := pdfgo.NewPDF()
+
+	names, ok := map[string]map[string]string{
+		"Courier": {
+			"F1": "Courier-Bold",
+			"F2": "Courier-Oblique",
+			"F3": "Courier",
+		},
+		"Helvetica": {
+			"F1": "Helvetica-Bold",
+			"F2": "Helvetica-Oblique",
+			"F3": "Helvetica",
+		},
+		"Times": {
+			"F1": "Times-Bold",
+			"F2": "Times-Italic",
+			"F3": "Times-Roman",
+		},
+	}[*fontfamily]
+	if !ok {
+		return errors.New(fmt.Sprintf(ERROR_UNKNOWN_FONT_FAMILY, *fontfamily))
+	}
+	fonts := make(map[string]font.Font)
+	for id, name := range names {
+		f, err := font.NewCoreFont(p, name)
+		if err != nil {
+			return err
+		}
+		fonts[id] = f
+	}
+
+	if err := pdf.AddInvoice(p, theme, invoice, fonts); err != nil {
+		return err
+	}
+
+	name := path.Join(*directory, invoice.Invoice.Number+".pdf")
+	log.Println("Writing:", name)
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return p.Write(file)
+}
+
+// Opens the node with the Convey channels holding subscriptions, invoices, and the conversations and messages posted.
+func OpenNode() (*bcgo.Node, error) {
+	rootDir, err := bcgo.GetRootDirectory()
+	if err != nil {
+		return nil, err
+	}
+	log.Println("Root Directory:", rootDir)
+
+	cacheDir, err := bcgo.GetCacheDirectory(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	cache, err := bcgo.NewFileCache(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	peers, err := bcgo.GetPeers(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	if *host == "" {
+		peers = append(peers, conveygo.GetConveyHosts()...)
+	} else {
+		peers = append(peers, *host)
+	}
+	log.Println("Peers:", peers)
+
+	network := bcgo.NewTCPNetwork(peers...)
+
+	node, err := bcgo.GetNode(rootDir, cache, network)
+	if err != nil {
+		return nil, err
+	}
+
+	conversations := conveygo.OpenConversationChannel()
+	channels := []*bcgo.Channel{
+		aliasgo.OpenAliasChannel(),
+		conversations,
+		conveygo.OpenRegistrationChannel(),
+		conveygo.OpenSubscriptionChannel(),
+		conveygo.OpenInvoiceChannel(),
+	}
+	for _, channel := range channels {
+		if err := channel.Refresh(cache, network); err != nil {
+			log.Println(err)
+		}
+		node.AddChannel(channel)
+	}
+
+	// Open the message channel of each conversation so the ledger counts the messages posted
+	if err := bcgo.Iterate(conversations.Name, conversations.Head, nil, cache, network, func(h []byte, b *bcgo.Block) error {
+		for _, entry := range b.Entry {
+			channel := bcgo.OpenPoWChannel(conveygo.CONVEY_PREFIX_MESSAGE+base64.RawURLEncoding.EncodeToString(entry.RecordHash), bcgo.THRESHOLD_G)
+			if err := channel.Refresh(cache, network); err != nil {
+				log.Println(err)
+			}
+			node.AddChannel(channel)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
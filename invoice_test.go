@@ -0,0 +1,107 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/testinggo"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestGenerateInvoices(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	testinggo.AssertNoError(t, err)
+	defer os.RemoveAll(dir)
+	merchant := "Merchant"
+	merchantKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	testinggo.AssertNoError(t, err)
+	customer := "Alice"
+	customerKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	testinggo.AssertNoError(t, err)
+	month := time.Date(2020, time.August, 15, 12, 0, 0, 0, time.UTC)
+	start, end := conveygo.InvoicePeriod(month)
+
+	setup := func(t *testing.T) (*conveygo.BCStore, *conveygo.Ledger) {
+		t.Helper()
+		s := makeBCStore(t, merchant, merchantKey, dir)
+		registerAlias(t, s.Node, merchant, merchantKey)
+		testinggo.AssertNoError(t, s.RegisterAlias(customer, []byte("password1234"), customerKey))
+		_, err := s.RegisterCustomer(customer, customerKey, "alice@example.com", "payment1234")
+		testinggo.AssertNoError(t, err)
+		_, err = s.SubscribeCustomer(customer, customerKey, "product1234", "plan1234")
+		testinggo.AssertNoError(t, err)
+		ledger := conveygo.NewLedger(s.Node)
+		ledger.RecordPosted(customer, &conveygo.Post{Channel: conveygo.CONVEY_CONVERSATION, Timestamp: start, Cost: 3})
+		ledger.RecordPosted(customer, &conveygo.Post{Channel: conveygo.CONVEY_PREFIX_MESSAGE + "abc", Timestamp: start + 1, Cost: 2})
+		ledger.RecordPosted(customer, &conveygo.Post{Channel: conveygo.CONVEY_PREFIX_MESSAGE + "abc", Timestamp: end - 1, Cost: 4})
+		// Outside of the period
+		ledger.RecordPosted(customer, &conveygo.Post{Channel: conveygo.CONVEY_CONVERSATION, Timestamp: end, Cost: 5})
+		return s, ledger
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		s, ledger := setup(t)
+		invoices, err := s.GenerateInvoices(ledger, month, "USD", 10)
+		testinggo.AssertNoError(t, err)
+		if len(invoices) != 1 {
+			t.Fatalf("Wrong number of invoices; expected '1', got '%d'", len(invoices))
+		}
+		invoice := invoices[0].Invoice
+		if invoice.Number != "Alice-202008" || invoice.CustomerAlias != customer || invoice.MerchantAlias != merchant || invoice.PlanId != "plan1234" || invoice.Currency != "usd" || invoice.AmountDue != 90 {
+			t.Errorf("Wrong invoice; got '%v'", invoice)
+		}
+		items := invoices[0].Usage.Item
+		if len(items) != 2 || items[0].Description != conveygo.INVOICE_ITEM_CONVERSATIONS || items[0].Quantity != 1 || items[0].Tokens != 3 || items[1].Description != conveygo.INVOICE_ITEM_MESSAGES || items[1].Quantity != 2 || items[1].Tokens != 6 || items[1].Amount != 60 {
+			t.Errorf("Wrong items; got '%v'", items)
+		}
+
+		// Customer can read the invoice and its usage
+		read, err := s.GetInvoices(customer, customerKey)
+		testinggo.AssertNoError(t, err)
+		if len(read) != 1 || read[0].Invoice.Number != invoice.Number || read[0].Usage.Start != start || read[0].Usage.End != end || len(read[0].Usage.Item) != 2 {
+			t.Errorf("Wrong invoices read; got '%v'", read)
+		}
+	})
+	t.Run("AlreadyInvoiced", func(t *testing.T) {
+		s, ledger := setup(t)
+		_, err := s.GenerateInvoices(ledger, month, "usd", 10)
+		testinggo.AssertNoError(t, err)
+		invoices, err := s.GenerateInvoices(ledger, month, "usd", 10)
+		testinggo.AssertNoError(t, err)
+		if len(invoices) != 1 {
+			t.Errorf("Wrong number of invoices; expected '1', got '%d'", len(invoices))
+		}
+		read, err := s.GetInvoices(merchant, merchantKey)
+		testinggo.AssertNoError(t, err)
+		if len(read) != 1 {
+			t.Errorf("Expected customer to be invoiced once; got '%d'", len(read))
+		}
+	})
+	t.Run("NoUsage", func(t *testing.T) {
+		s, ledger := setup(t)
+		invoices, err := s.GenerateInvoices(ledger, month.AddDate(0, 2, 0), "usd", 10)
+		testinggo.AssertNoError(t, err)
+		if len(invoices) != 1 || invoices[0].Invoice.AmountDue != 0 || len(invoices[0].Usage.Item) != 0 {
+			t.Errorf("Wrong invoices; got '%v'", invoices)
+		}
+	})
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo
+
+import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/cryptogo"
+	"github.com/AletheiaWareLLC/financego"
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/crypto/argon2"
+	"log"
+)
+
+const (
+	KEY_BUNDLE_VERSION = 1
+
+	// Bundles are read before the password is checked, so their Argon2 parameters are bounded
+	KEY_BUNDLE_MAX_TIME    = 16
+	KEY_BUNDLE_MAX_MEMORY  = 1024 * 1024 // KiB
+	KEY_BUNDLE_MAX_THREADS = 255
+
+	ERROR_KEY_BUNDLE_VERSION    = "Unsupported key bundle version: %d"
+	ERROR_KEY_BUNDLE_PARAMETERS = "Invalid key bundle parameters: time %d, memory %d KiB, threads %d"
+)
+
+// Returns a bundle of the alias's private key and registration, encrypted with the password.
+// Keys held by an agent cannot be exported.
+func NewKeyBundle(alias string, key crypto.Signer, registration *financego.Registration, password []byte) ([]byte, error) {
+	privateKey, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New(fmt.Sprintf(ERROR_KEY_NOT_EXPORTABLE, alias))
+	}
+	content, err := proto.Marshal(&KeyBundleContent{
+		Alias:         alias,
+		PrivateKey:    privateKey,
+		PrivateFormat: cryptogo.PrivateKeyFormat_PKCS8,
+		Registration:  registration,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The password hash, derived with a new random salt, is the encryption key
+	hash, err := NewPasswordHash(password)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := cryptogo.EncryptAESGCM(hash.Hash, content)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(&KeyBundle{
+		Version: KEY_BUNDLE_VERSION,
+		Salt:    hash.Salt,
+		Time:    hash.Time,
+		Memory:  hash.Memory,
+		Threads: uint32(hash.Threads),
+		Payload: payload,
+	})
+}
+
+// Decrypts the bundle with the password, and returns its content and the private key it holds.
+func ReadKeyBundle(data, password []byte) (*KeyBundleContent, crypto.Signer, error) {
+	bundle := &KeyBundle{}
+	if err := proto.Unmarshal(data, bundle); err != nil {
+		return nil, nil, err
+	}
+	if bundle.Version != KEY_BUNDLE_VERSION {
+		return nil, nil, errors.New(fmt.Sprintf(ERROR_KEY_BUNDLE_VERSION, bundle.Version))
+	}
+	if bundle.Time < 1 || bundle.Time > KEY_BUNDLE_MAX_TIME || bundle.Memory > KEY_BUNDLE_MAX_MEMORY || bundle.Threads < 1 || bundle.Threads > KEY_BUNDLE_MAX_THREADS {
+		return nil, nil, errors.New(fmt.Sprintf(ERROR_KEY_BUNDLE_PARAMETERS, bundle.Time, bundle.Memory, bundle.Threads))
+	}
+	secret := argon2.IDKey(password, bundle.Salt, bundle.Time, bundle.Memory, uint8(bundle.Threads), PASSWORD_HASH_SIZE)
+	data, err := cryptogo.DecryptAESGCM(secret, bundle.Payload)
+	if err != nil {
+		log.Println(err)
+		return nil, nil, errors.New(ERROR_ACCESS_DENIED)
+	}
+	content := &KeyBundleContent{}
+	if err := proto.Unmarshal(data, content); err != nil {
+		return nil, nil, err
+	}
+	if content.PrivateFormat != cryptogo.PrivateKeyFormat_PKCS8 {
+		return nil, nil, errors.New(fmt.Sprintf(cryptogo.ERROR_UNSUPPORTED_PRIVATE_KEY_FORMAT, content.PrivateFormat))
+	}
+	key, err := ParsePrivateKey(content.PrivateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, key, nil
+}
+
+// Returns a bundle of the alias's private key and registration, encrypted with their keystore password.
+func (s *BCStore) ExportKey(alias string, password []byte) ([]byte, error) {
+	key, err := s.GetKey(alias, password)
+	if err != nil {
+		return nil, err
+	}
+	registration, err := s.GetRegistration(alias)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyBundle(alias, key, registration, password)
+}
+
+// Writes the private key in the bundle to the keystore, encrypted with the bundle's password, and returns the alias it belongs to.
+// The registration is already held in the Convey-Registration Chain so is not written again.
+func (s *BCStore) ImportKey(data, password []byte, force bool) (string, error) {
+	content, key, err := ReadKeyBundle(data, password)
+	if err != nil {
+		return "", err
+	}
+	alias := content.Alias
+	if s.HasKey(alias) && !force {
+		return "", errors.New(fmt.Sprintf(ERROR_KEY_ALREADY_EXISTS, alias))
+	}
+	if err := s.KeyStore.SetKey(alias, password, key); err != nil {
+		return "", err
+	}
+	return alias, nil
+}
+
+// Returns a bundle of the alias's private key and registration, encrypted with their password.
+func (s *MemoryStore) ExportKey(alias string, password []byte) ([]byte, error) {
+	key, err := s.GetKey(alias, password)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyBundle(alias, key, s.Registrations[alias], password)
+}
+
+// Adds the private key and registration in the bundle, protected by the bundle's password, and returns the alias they belong to.
+func (s *MemoryStore) ImportKey(data, password []byte, force bool) (string, error) {
+	content, key, err := ReadKeyBundle(data, password)
+	if err != nil {
+		return "", err
+	}
+	alias := content.Alias
+	if s.HasKey(alias) && !force {
+		return "", errors.New(fmt.Sprintf(ERROR_KEY_ALREADY_EXISTS, alias))
+	}
+	if err := s.KeyStore.SetKey(alias, password, key); err != nil {
+		return "", err
+	}
+	if content.Registration != nil {
+		s.Registrations[alias] = content.Registration
+	}
+	return alias, nil
+}
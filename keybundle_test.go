@@ -0,0 +1,106 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/testinggo"
+	"github.com/golang/protobuf/proto"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestKeyBundle(t *testing.T) {
+	alias := "Alice"
+	password := []byte("password1234")
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Error("Could not generate key:", err)
+	}
+	t.Run("Version", func(t *testing.T) {
+		data, err := conveygo.NewKeyBundle(alias, key, nil, password)
+		testinggo.AssertNoError(t, err)
+		bundle := &conveygo.KeyBundle{}
+		testinggo.AssertNoError(t, proto.Unmarshal(data, bundle))
+		bundle.Version = conveygo.KEY_BUNDLE_VERSION + 1
+		data, err = proto.Marshal(bundle)
+		testinggo.AssertNoError(t, err)
+		_, _, err = conveygo.ReadKeyBundle(data, password)
+		testinggo.AssertError(t, fmt.Sprintf(conveygo.ERROR_KEY_BUNDLE_VERSION, conveygo.KEY_BUNDLE_VERSION+1), err)
+	})
+	t.Run("Parameters", func(t *testing.T) {
+		for name, tamper := range map[string]func(*conveygo.KeyBundle){
+			"ZeroTime":     func(b *conveygo.KeyBundle) { b.Time = 0 },
+			"LargeTime":    func(b *conveygo.KeyBundle) { b.Time = conveygo.KEY_BUNDLE_MAX_TIME + 1 },
+			"LargeMemory":  func(b *conveygo.KeyBundle) { b.Memory = conveygo.KEY_BUNDLE_MAX_MEMORY + 1 },
+			"ZeroThreads":  func(b *conveygo.KeyBundle) { b.Threads = 0 },
+			"LargeThreads": func(b *conveygo.KeyBundle) { b.Threads = 256 },
+		} {
+			t.Run(name, func(t *testing.T) {
+				data, err := conveygo.NewKeyBundle(alias, key, nil, password)
+				testinggo.AssertNoError(t, err)
+				bundle := &conveygo.KeyBundle{}
+				testinggo.AssertNoError(t, proto.Unmarshal(data, bundle))
+				tamper(bundle)
+				data, err = proto.Marshal(bundle)
+				testinggo.AssertNoError(t, err)
+				_, _, err = conveygo.ReadKeyBundle(data, password)
+				testinggo.AssertError(t, fmt.Sprintf(conveygo.ERROR_KEY_BUNDLE_PARAMETERS, bundle.Time, bundle.Memory, bundle.Threads), err)
+			})
+		}
+	})
+	t.Run("MemoryToMemory", func(t *testing.T) {
+		from := conveygo.NewMemoryStore()
+		testinggo.AssertNoError(t, from.AddKey(alias, password, key))
+		registration, err := from.RegisterCustomer(alias, key, "alice@example.com", "payment1234")
+		testinggo.AssertNoError(t, err)
+		data, err := from.ExportKey(alias, password)
+		testinggo.AssertNoError(t, err)
+
+		to := conveygo.NewMemoryStore()
+		_, err = to.ImportKey(data, password, false)
+		testinggo.AssertNoError(t, err)
+		actual, err := to.GetKey(alias, password)
+		testinggo.AssertNoError(t, err)
+		assertKeyEqual(t, key, actual)
+		r, err := to.GetRegistration(alias)
+		testinggo.AssertNoError(t, err)
+		if !proto.Equal(registration, r) {
+			t.Errorf("Wrong registration; expected '%v', got '%v'", registration, r)
+		}
+	})
+	t.Run("MemoryToBC", func(t *testing.T) {
+		from := conveygo.NewMemoryStore()
+		testinggo.AssertNoError(t, from.AddKey(alias, password, key))
+		data, err := from.ExportKey(alias, password)
+		testinggo.AssertNoError(t, err)
+
+		dir, err := ioutil.TempDir("", "keystore")
+		testinggo.AssertNoError(t, err)
+		defer os.RemoveAll(dir)
+		to := makeBCStore(t, alias, nil, dir)
+		_, err = to.ImportKey(data, password, false)
+		testinggo.AssertNoError(t, err)
+		actual, err := to.GetKey(alias, password)
+		testinggo.AssertNoError(t, err)
+		assertKeyEqual(t, key, actual)
+	})
+}
@@ -0,0 +1,220 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo
+
+import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/aliasgo"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/cryptogo"
+	"github.com/golang/protobuf/proto"
+	"log"
+	"sort"
+)
+
+const (
+	ERROR_CREATOR_ROTATION_ALIAS_DONT_MATCH = "Record Creator and Key Rotation Alias don't match: %s vs %s"
+	ERROR_KEY_ROTATION_SIGNATURE            = "Key Rotation not signed by active key: %s"
+)
+
+func OpenKeyRotationChannel() *bcgo.Channel {
+	rotations := bcgo.OpenPoWChannel(CONVEY_KEY_ROTATION, bcgo.THRESHOLD_G)
+	rotations.AddValidator(&KeyRotationValidator{})
+	return rotations
+}
+
+// Creates a record naming the new public key of the alias, signed by their old key.
+func NewKeyRotationRecord(alias string, oldKey crypto.Signer, newKey crypto.PublicKey) (*bcgo.Record, error) {
+	publicKey, err := x509.MarshalPKIXPublicKey(newKey)
+	if err != nil {
+		return nil, err
+	}
+	data, err := proto.Marshal(&KeyRotation{
+		Alias:        alias,
+		PublicKey:    publicKey,
+		PublicFormat: cryptogo.PublicKeyFormat_PKIX,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return CreateRecord(bcgo.Timestamp(), alias, oldKey, nil, data)
+}
+
+// keyRotationEntry is a rotation with the timestamp and signature of its record.
+type keyRotationEntry struct {
+	Record   *bcgo.Record
+	Rotation *KeyRotation
+}
+
+// Returns the rotations in the chain ending at the given block, oldest first.
+func getKeyRotations(name string, hash []byte, block *bcgo.Block, cache bcgo.Cache, network bcgo.Network) ([]*keyRotationEntry, error) {
+	var rotations []*keyRotationEntry
+	if hash == nil {
+		return rotations, nil
+	}
+	if err := bcgo.Iterate(name, hash, block, cache, network, func(h []byte, b *bcgo.Block) error {
+		for _, entry := range b.Entry {
+			// Unmarshal as KeyRotation
+			r := &KeyRotation{}
+			if err := proto.Unmarshal(entry.Record.Payload, r); err != nil {
+				return err
+			}
+			rotations = append(rotations, &keyRotationEntry{
+				Record:   entry.Record,
+				Rotation: r,
+			})
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(rotations, func(i, j int) bool {
+		return rotations[i].Record.Timestamp < rotations[j].Record.Timestamp
+	})
+	return rotations, nil
+}
+
+// Verifies the record's signature with the given public key.
+func VerifyRecord(key crypto.PublicKey, record *bcgo.Record) error {
+	return VerifySignature(key, cryptogo.Hash(record.Payload), record.Signature, record.SignatureAlgorithm)
+}
+
+// KeyRotationValidator ensures each rotation is signed by the key the alias had before it, either registered in the Alias Chain or named by the previous rotation.
+type KeyRotationValidator struct {
+}
+
+func (v *KeyRotationValidator) Validate(channel *bcgo.Channel, cache bcgo.Cache, network bcgo.Network, hash []byte, block *bcgo.Block) error {
+	rotations, err := getKeyRotations(channel.Name, hash, block, cache, network)
+	if err != nil {
+		return err
+	}
+	aliases := aliasgo.OpenAliasChannel()
+	if err := aliases.LoadHead(cache, network); err != nil {
+		return err
+	}
+	// Alias -> Active Key
+	keys := make(map[string]crypto.PublicKey)
+	for _, r := range rotations {
+		alias := r.Rotation.Alias
+		// Check Record Creator matches Alias being rotated
+		if r.Record.Creator != alias {
+			return errors.New(fmt.Sprintf(ERROR_CREATOR_ROTATION_ALIAS_DONT_MATCH, r.Record.Creator, alias))
+		}
+		key, ok := keys[alias]
+		if !ok {
+			key, err = GetPublicKey(aliases, cache, network, alias)
+			if err != nil {
+				return err
+			}
+		}
+		if err := VerifyRecord(key, r.Record); err != nil {
+			return errors.New(fmt.Sprintf(ERROR_KEY_ROTATION_SIGNATURE, alias))
+		}
+		keys[alias], err = ParsePublicKey(r.Rotation.PublicKey, r.Rotation.PublicFormat)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the public key that was active for the alias at the given timestamp; the key named by the latest rotation made at or before the timestamp, or else the key registered in the Alias Chain.
+func GetPublicKeyAt(aliases, rotations *bcgo.Channel, cache bcgo.Cache, network bcgo.Network, alias string, timestamp uint64) (crypto.PublicKey, error) {
+	var active *KeyRotation
+	if rotations != nil {
+		entries, err := getKeyRotations(rotations.Name, rotations.Head, nil, cache, network)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range entries {
+			if r.Rotation.Alias == alias && r.Record.Timestamp <= timestamp {
+				active = r.Rotation
+			}
+		}
+	}
+	if active != nil {
+		return ParsePublicKey(active.PublicKey, active.PublicFormat)
+	}
+	return GetPublicKey(aliases, cache, network, alias)
+}
+
+// Verifies the record was signed by the key that was active for its creator at its timestamp.
+func VerifyRecordAt(aliases, rotations *bcgo.Channel, cache bcgo.Cache, network bcgo.Network, record *bcgo.Record) error {
+	key, err := GetPublicKeyAt(aliases, rotations, cache, network, record.Creator, record.Timestamp)
+	if err != nil {
+		return err
+	}
+	return VerifyRecord(key, record)
+}
+
+// SignatureValidator ensures each record in a channel was signed by the key that was active for its creator at its timestamp.
+type SignatureValidator struct {
+}
+
+func (v *SignatureValidator) Validate(channel *bcgo.Channel, cache bcgo.Cache, network bcgo.Network, hash []byte, block *bcgo.Block) error {
+	aliases := aliasgo.OpenAliasChannel()
+	if err := aliases.LoadHead(cache, network); err != nil {
+		return err
+	}
+	rotations := OpenKeyRotationChannel()
+	if err := rotations.LoadHead(cache, network); err != nil {
+		// Without rotations every record is checked against the key registered in the Alias Chain
+		rotations = nil
+	}
+	return bcgo.Iterate(channel.Name, hash, block, cache, network, func(h []byte, b *bcgo.Block) error {
+		for _, entry := range b.Entry {
+			if err := VerifyRecordAt(aliases, rotations, cache, network, entry.Record); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Writes a rotation to the Convey-Key-Rotation Chain naming the new key of the alias, signed by their old key, then replaces the old key in the keystore.
+func (s *BCStore) RotateKey(alias string, password []byte, key crypto.Signer) error {
+	oldKey, err := s.GetKey(alias, password)
+	if err != nil {
+		return err
+	}
+
+	record, err := NewKeyRotationRecord(alias, oldKey, key.Public())
+	if err != nil {
+		return err
+	}
+
+	// Write rotation record to cache
+	if _, err := bcgo.WriteRecord(CONVEY_KEY_ROTATION, s.Node.Cache, record); err != nil {
+		return err
+	}
+
+	// Mine rotation record
+	rotations := s.Node.GetOrOpenChannel(CONVEY_KEY_ROTATION, OpenKeyRotationChannel)
+	if _, _, err := s.Node.Mine(rotations, bcgo.THRESHOLD_G, s.Listener); err != nil {
+		return err
+	}
+
+	// Push Update to Network
+	if err := rotations.Push(s.Node.Cache, s.Node.Network); err != nil {
+		log.Println(err)
+	}
+
+	return s.KeyStore.SetKey(alias, password, key)
+}
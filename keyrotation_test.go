@@ -0,0 +1,123 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"github.com/AletheiaWareLLC/aliasgo"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/testinggo"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestKeyRotation(t *testing.T) {
+	alias := "Alice"
+	password := []byte("password1234")
+	// An existing RSA alias rotates to a new RSA key
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Error("Could not generate key:", err)
+	}
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Error("Could not generate key:", err)
+	}
+	t.Run("Rotated", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "keystore")
+		testinggo.AssertNoError(t, err)
+		defer os.RemoveAll(dir)
+		store := makeBCStore(t, alias, oldKey, dir)
+		testinggo.AssertNoError(t, store.AddKey(alias, password, oldKey))
+		testinggo.AssertNoError(t, store.RegisterAlias(alias, password, oldKey))
+
+		// Signed before rotation
+		_, before, err := conveygo.DigestToRecord(alias, oldKey, bcgo.Timestamp(), makeDigestEntries())
+		testinggo.AssertNoError(t, err)
+
+		testinggo.AssertNoError(t, store.RotateKey(alias, password, newKey))
+
+		// Signed after rotation
+		_, after, err := conveygo.DigestToRecord(alias, newKey, bcgo.Timestamp(), makeDigestEntries())
+		testinggo.AssertNoError(t, err)
+		_, stale, err := conveygo.DigestToRecord(alias, oldKey, bcgo.Timestamp(), makeDigestEntries())
+		testinggo.AssertNoError(t, err)
+
+		aliases, err := store.Node.GetChannel(aliasgo.ALIAS)
+		testinggo.AssertNoError(t, err)
+		rotations, err := store.Node.GetChannel(conveygo.CONVEY_KEY_ROTATION)
+		testinggo.AssertNoError(t, err)
+
+		key, err := conveygo.GetPublicKeyAt(aliases, rotations, store.Node.Cache, nil, alias, before.Timestamp)
+		testinggo.AssertNoError(t, err)
+		assertPublicKeyEqual(t, oldKey.Public(), key)
+		key, err = conveygo.GetPublicKeyAt(aliases, rotations, store.Node.Cache, nil, alias, after.Timestamp)
+		testinggo.AssertNoError(t, err)
+		assertPublicKeyEqual(t, newKey.Public(), key)
+
+		_, err = conveygo.VerifyDigestRecord(aliases, rotations, store.Node.Cache, nil, before)
+		testinggo.AssertNoError(t, err)
+		_, err = conveygo.VerifyDigestRecord(aliases, rotations, store.Node.Cache, nil, after)
+		testinggo.AssertNoError(t, err)
+		_, err = conveygo.VerifyDigestRecord(aliases, rotations, store.Node.Cache, nil, stale)
+		testinggo.AssertError(t, rsa.ErrVerification.Error(), err)
+	})
+	t.Run("WrongKey", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "keystore")
+		testinggo.AssertNoError(t, err)
+		defer os.RemoveAll(dir)
+		store := makeBCStore(t, alias, oldKey, dir)
+		testinggo.AssertNoError(t, store.RegisterAlias(alias, password, oldKey))
+
+		// Rotation signed by a key that was never active
+		record, err := conveygo.NewKeyRotationRecord(alias, newKey, newKey.Public())
+		testinggo.AssertNoError(t, err)
+		_, err = bcgo.WriteRecord(conveygo.CONVEY_KEY_ROTATION, store.Node.Cache, record)
+		testinggo.AssertNoError(t, err)
+		rotations := store.Node.GetOrOpenChannel(conveygo.CONVEY_KEY_ROTATION, conveygo.OpenKeyRotationChannel)
+		_, _, err = store.Node.Mine(rotations, bcgo.THRESHOLD_G, nil)
+		testinggo.AssertError(t, "Chain invalid: Key Rotation not signed by active key: Alice", err)
+	})
+	t.Run("SignatureValidator", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "keystore")
+		testinggo.AssertNoError(t, err)
+		defer os.RemoveAll(dir)
+		store := makeBCStore(t, alias, oldKey, dir)
+		testinggo.AssertNoError(t, store.AddKey(alias, password, oldKey))
+		testinggo.AssertNoError(t, store.RegisterAlias(alias, password, oldKey))
+		testinggo.AssertNoError(t, store.RotateKey(alias, password, newKey))
+
+		// Signed with the rotated in key
+		testConversationStore_NewConversation(t, store, alias, newKey)
+
+		// Signed with the retired key after rotation
+		timestamp := bcgo.Timestamp()
+		conversationHash, conversationRecord, err := conveygo.ProtoToRecord(alias, oldKey, timestamp, &conveygo.Conversation{
+			Topic: "Stale",
+		})
+		testinggo.AssertNoError(t, err)
+		messageHash, messageRecord, err := conveygo.ProtoToRecord(alias, oldKey, timestamp, &conveygo.Message{
+			Content: []byte("Stale"),
+			Type:    conveygo.MediaType_TEXT_PLAIN,
+		})
+		testinggo.AssertNoError(t, err)
+		testinggo.AssertError(t, "Chain invalid: "+rsa.ErrVerification.Error(), store.NewConversation(conversationHash, conversationRecord, messageHash, messageRecord))
+	})
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/cryptogo"
+	"log"
+	"os"
+	"path"
+	"sync"
+)
+
+const (
+	ERROR_KEY_NOT_EXPORTABLE = "Key not exportable: %s"
+)
+
+// KeyStore holds the private keys of aliases, each protected by a password.
+type KeyStore interface {
+	AddKey(alias string, password []byte, key crypto.Signer) error
+	// Returns the private key, or a signer if the key cannot leave the store.
+	GetKey(alias string, password []byte) (crypto.Signer, error)
+	HasKey(alias string) bool
+	// Replaces any existing key of the alias.
+	SetKey(alias string, password []byte, key crypto.Signer) error
+	ChangePassword(alias string, oldPassword, newPassword []byte) error
+}
+
+// FileKeyStore holds private keys in password encrypted files in a directory.
+type FileKeyStore struct {
+	Directory string
+}
+
+func NewFileKeyStore(directory string) *FileKeyStore {
+	return &FileKeyStore{
+		Directory: directory,
+	}
+}
+
+func (s *FileKeyStore) AddKey(alias string, password []byte, key crypto.Signer) error {
+	if s.HasKey(alias) {
+		return errors.New(fmt.Sprintf(ERROR_KEY_ALREADY_EXISTS, alias))
+	}
+	// Write private key to keystore on filesystem
+	return WritePrivateKey(key, s.Directory, alias, password)
+}
+
+func (s *FileKeyStore) GetKey(alias string, password []byte) (crypto.Signer, error) {
+	key, err := ReadPrivateKey(s.Directory, alias, password)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New(ERROR_ACCESS_DENIED)
+	}
+	return key, nil
+}
+
+func (s *FileKeyStore) HasKey(alias string) bool {
+	return cryptogo.HasRSAPrivateKey(s.Directory, alias)
+}
+
+func (s *FileKeyStore) SetKey(alias string, password []byte, key crypto.Signer) error {
+	// Write encrypted private key alongside the original, then replace it so the keystore is never left without a key
+	temp := alias + ".tmp"
+	if err := WritePrivateKey(key, s.Directory, temp, password); err != nil {
+		return err
+	}
+	return os.Rename(path.Join(s.Directory, temp+".go.private"), path.Join(s.Directory, alias+".go.private"))
+}
+
+func (s *FileKeyStore) ChangePassword(alias string, oldPassword, newPassword []byte) error {
+	key, err := s.GetKey(alias, oldPassword)
+	if err != nil {
+		return err
+	}
+	return s.SetKey(alias, newPassword, key)
+}
+
+// MemoryKeyStore holds private keys in memory, each with a hash of its password.
+type MemoryKeyStore struct {
+	Passwords map[string]*PasswordHash
+	Keys      map[string]crypto.Signer
+	lock      sync.Mutex
+}
+
+func NewMemoryKeyStore() *MemoryKeyStore {
+	return &MemoryKeyStore{
+		Passwords: make(map[string]*PasswordHash),
+		Keys:      make(map[string]crypto.Signer),
+	}
+}
+
+func (s *MemoryKeyStore) AddKey(alias string, password []byte, key crypto.Signer) error {
+	if s.HasKey(alias) {
+		return errors.New(fmt.Sprintf(ERROR_KEY_ALREADY_EXISTS, alias))
+	}
+	return s.SetKey(alias, password, key)
+}
+
+func (s *MemoryKeyStore) GetKey(alias string, password []byte) (crypto.Signer, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	hash, ok := s.Passwords[alias]
+	if ok && hash.Matches(password) {
+		return s.Keys[alias], nil
+	}
+	return nil, errors.New(ERROR_ACCESS_DENIED)
+}
+
+func (s *MemoryKeyStore) HasKey(alias string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, ok := s.Passwords[alias]
+	return ok
+}
+
+func (s *MemoryKeyStore) SetKey(alias string, password []byte, key crypto.Signer) error {
+	hash, err := NewPasswordHash(password)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.Passwords[alias] = hash
+	s.Keys[alias] = key
+	return nil
+}
+
+func (s *MemoryKeyStore) ChangePassword(alias string, oldPassword, newPassword []byte) error {
+	key, err := s.GetKey(alias, oldPassword)
+	if err != nil {
+		return err
+	}
+	return s.SetKey(alias, newPassword, key)
+}
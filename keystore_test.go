@@ -0,0 +1,90 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/cryptogo"
+	"github.com/AletheiaWareLLC/testinggo"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// Checks the behaviour common to all key stores, starting from an empty store.
+func testKeyStore(t *testing.T, s conveygo.KeyStore, alias string, password []byte, key, newKey crypto.Signer) {
+	t.Helper()
+	newPassword := []byte("newpassword5678")
+	if s.HasKey(alias) {
+		t.Errorf("Expected no key for '%s'", alias)
+	}
+	testinggo.AssertNoError(t, s.AddKey(alias, password, key))
+	if !s.HasKey(alias) {
+		t.Errorf("Expected key for '%s'", alias)
+	}
+	testinggo.AssertError(t, fmt.Sprintf(conveygo.ERROR_KEY_ALREADY_EXISTS, alias), s.AddKey(alias, password, newKey))
+
+	_, err := s.GetKey(alias, newPassword)
+	testinggo.AssertError(t, conveygo.ERROR_ACCESS_DENIED, err)
+	signer, err := s.GetKey(alias, password)
+	testinggo.AssertNoError(t, err)
+	hash := cryptogo.Hash([]byte("Hello World"))
+	signature, algorithm, err := conveygo.CreateSignature(signer, hash)
+	testinggo.AssertNoError(t, err)
+	testinggo.AssertNoError(t, conveygo.VerifySignature(key.Public(), hash, signature, algorithm))
+
+	testinggo.AssertError(t, conveygo.ERROR_ACCESS_DENIED, s.ChangePassword(alias, newPassword, newPassword))
+	testinggo.AssertNoError(t, s.ChangePassword(alias, password, newPassword))
+	_, err = s.GetKey(alias, password)
+	testinggo.AssertError(t, conveygo.ERROR_ACCESS_DENIED, err)
+	actual, err := s.GetKey(alias, newPassword)
+	testinggo.AssertNoError(t, err)
+	assertKeyEqual(t, key, actual)
+
+	testinggo.AssertNoError(t, s.SetKey(alias, password, newKey))
+	actual, err = s.GetKey(alias, password)
+	testinggo.AssertNoError(t, err)
+	assertKeyEqual(t, newKey, actual)
+}
+
+func TestKeyStore(t *testing.T) {
+	alias := "Alice"
+	password := []byte("password1234")
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Error("Could not generate key:", err)
+	}
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Error("Could not generate key:", err)
+	}
+	t.Run("File", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "keystore")
+		testinggo.AssertNoError(t, err)
+		defer os.RemoveAll(dir)
+		testKeyStore(t, conveygo.NewFileKeyStore(dir), alias, password, key, newKey)
+	})
+	t.Run("Memory", func(t *testing.T) {
+		testKeyStore(t, conveygo.NewMemoryKeyStore(), alias, password, key, newKey)
+	})
+}
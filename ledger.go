@@ -18,10 +18,11 @@ package conveygo
 
 import (
 	"encoding/base64"
+	"errors"
+	"fmt"
 	"github.com/AletheiaWareLLC/bcgo"
 	"github.com/golang/protobuf/proto"
 	"log"
-	"math"
 	"strings"
 )
 
@@ -43,6 +44,11 @@ Burned - an Alias burns Tokens by starting Conversations;
 Bought - an Alias gains Tokens by buying them from another.
 
 Sold - an Alias loses Tokens by selling them to another.
+    - Convey-Transaction: A Transaction referencing a Record in the Convey-Charge Chain sells Tokens purchased with fiat currency
+
+Locked - an Alias locks Tokens in Escrow, for example as a bounty;
+    - Convey-Escrow: The Escrow Chain locks the Sender's Tokens until they are released
+    - Convey-Escrow-Release: The Escrow Release Chain unlocks the Tokens, which are Sold by the Sender and Bought by the Receiver, or refunded to the Sender after the Deadline
 
 Earned - an Alias earns 1/2 Token per 100 Bytes for each Record in any Message Chain that replies to a Message they Authored.
 	- It gets slightly more complicated with replies to a reply;
@@ -55,13 +61,20 @@ Earned - an Alias earns 1/2 Token per 100 Bytes for each Record in any Message C
 
 Spent - an Alias spends 1 Token per 100 Bytes for each Record they Author in any Conversation or Message Chain.
 
+Posted - each Record an Alias Authors in any Conversation or Message Chain is kept with its Timestamp and Cost, so a period's posting activity can be invoiced.
+
+Allowance - a Subscriber to a Plan with a Posting Allowance posts without spending their balance each month until the Tokens they have Posted that month exceed the Allowance.
+    - The Tokens are still Burned, Spent, and Earned as usual, and the Tokens covered by the Allowance are credited back to the Subscriber.
+
 Balance - an Alias's balance is;
     - credited for each Token
         - minted by Mining PVC
         - bought through a Transaction
         - earned from Replies
+        - posted within an Allowance
     - debited for each Token
         - sold through a Transaction
+        - locked in an Escrow
         - burned or spent in Conversations and Messages
 */
 
@@ -77,29 +90,45 @@ const (
 )
 
 type Ledger struct {
-	Node      *bcgo.Node
-	Processed map[string]map[string]bool // Channel Name -> Block Hash -> Processed Flag
-	Aliases   map[string]bool            // Alias -> Seen Flag
-	Minted    map[string]uint64
-	Burned    map[string]uint64
-	Bought    map[string]uint64
-	Sold      map[string]uint64
-	Earned    map[string]uint64
-	Spent     map[string]uint64
-	Trigger   chan bool
+	Node       *bcgo.Node
+	Processed  map[string]map[string]bool // Channel Name -> Block Hash -> Processed Flag
+	Aliases    map[string]bool            // Alias -> Seen Flag
+	Minted     map[string]uint64
+	Burned     map[string]uint64
+	Bought     map[string]uint64
+	Sold       map[string]uint64
+	Earned     map[string]uint64
+	Spent      map[string]uint64
+	Locked     map[string]uint64
+	Unlocked   map[string]uint64
+	Escrows    map[string]*Escrow        // Escrow Record Hash -> Escrow
+	Released   map[string]bool           // Escrow Record Hash -> Released Flag
+	Purchases  map[string][]*Transaction // Charge Record Hash -> Transactions selling the purchased Tokens
+	Posts      map[string][]*Post        // Alias -> Records Authored in Conversation and Message Chains
+	Plans      map[string]*PostingPlan   // Plan ID -> Monthly Posting Allowance
+	Allowances map[string]*Allowance     // Alias -> Monthly Posting Allowance
+	Trigger    chan bool
 }
 
 func NewLedger(node *bcgo.Node) *Ledger {
 	ledger := &Ledger{
-		Node:      node,
-		Processed: make(map[string]map[string]bool),
-		Aliases:   make(map[string]bool),
-		Minted:    make(map[string]uint64),
-		Burned:    make(map[string]uint64),
-		Bought:    make(map[string]uint64),
-		Sold:      make(map[string]uint64),
-		Earned:    make(map[string]uint64),
-		Spent:     make(map[string]uint64),
+		Node:       node,
+		Processed:  make(map[string]map[string]bool),
+		Aliases:    make(map[string]bool),
+		Minted:     make(map[string]uint64),
+		Burned:     make(map[string]uint64),
+		Bought:     make(map[string]uint64),
+		Sold:       make(map[string]uint64),
+		Earned:     make(map[string]uint64),
+		Spent:      make(map[string]uint64),
+		Locked:     make(map[string]uint64),
+		Unlocked:   make(map[string]uint64),
+		Escrows:    make(map[string]*Escrow),
+		Released:   make(map[string]bool),
+		Purchases:  make(map[string][]*Transaction),
+		Posts:      make(map[string][]*Post),
+		Plans:      make(map[string]*PostingPlan),
+		Allowances: make(map[string]*Allowance),
 	}
 	return ledger
 }
@@ -149,7 +178,31 @@ func (l *Ledger) RecordSpent(alias string, amount uint64) {
 	Record(l.Spent, alias, amount)
 }
 
-func (l *Ledger) GetBalance(alias string) int64 {
+func (l *Ledger) RecordPosted(alias string, post *Post) {
+	// log.Println(alias, "posted", post)
+	l.Aliases[alias] = true
+	l.Posts[alias] = append(l.Posts[alias], post)
+}
+
+func (l *Ledger) RecordLocked(alias string, amount uint64) {
+	// log.Println(alias, "locked", amount)
+	l.Aliases[alias] = true
+	Record(l.Locked, alias, amount)
+}
+
+func (l *Ledger) RecordUnlocked(alias string, amount uint64) {
+	// log.Println(alias, "unlocked", amount)
+	l.Aliases[alias] = true
+	Record(l.Unlocked, alias, amount)
+}
+
+// Returns the tokens the alias has locked in escrows which have not yet been released.
+func (l *Ledger) GetLocked(alias string) int64 {
+	return int64(l.Locked[alias]) - int64(l.Unlocked[alias])
+}
+
+// Returns the alias's balance of Tokens, and the allowance they have left to post this month.
+func (l *Ledger) GetBalance(alias string) (int64, uint64) {
 	var balance int64
 	balance += int64(l.Minted[alias])
 	balance -= int64(l.Burned[alias])
@@ -157,11 +210,24 @@ func (l *Ledger) GetBalance(alias string) int64 {
 	balance -= int64(l.Sold[alias])
 	balance += int64(l.Earned[alias])
 	balance -= int64(l.Spent[alias])
-	return balance
+	balance -= l.GetLocked(alias)
+	balance += int64(l.GetAllowanceUsed(alias))
+	return balance, l.GetAllowanceRemaining(alias)
+}
+
+// Returns the records the alias authored from (inclusive) until to (exclusive).
+func (l *Ledger) GetPosts(alias string, from, to uint64) []*Post {
+	var posts []*Post
+	for _, p := range l.Posts[alias] {
+		if p.Timestamp >= from && p.Timestamp < to {
+			posts = append(posts, p)
+		}
+	}
+	return posts
 }
 
 func Cost(record *bcgo.Record) uint64 {
-	return uint64(math.Ceil(float64(proto.Size(record)) / 100))
+	return BytesToTokens(uint64(proto.Size(record)))
 }
 
 // Iterates through unprocessed blocks in the given channel
@@ -190,10 +256,18 @@ func (l *Ledger) iterate(channel string, hash []byte, callback func([]byte, *bcg
 	return nil
 }
 
+// Post is a Record an Alias authored in a Conversation or Message Chain, and the Tokens it cost.
+type Post struct {
+	Channel   string
+	Timestamp uint64
+	Cost      uint64
+}
+
 type MessageNode struct {
-	Author   string
-	Cost     uint64
-	Previous string
+	Author    string
+	Timestamp uint64
+	Cost      uint64
+	Previous  string
 }
 
 func (l *Ledger) Update(name string, hash []byte) error {
@@ -264,6 +338,64 @@ func (l *Ledger) Update(name string, hash []byte) error {
 				amount := t.Amount
 				l.RecordSold(t.Sender, amount)
 				l.RecordBought(t.Receiver, amount)
+				for _, reference := range record.Reference {
+					if reference.ChannelName == CONVEY_CHARGE {
+						charge := base64.RawURLEncoding.EncodeToString(reference.RecordHash)
+						l.Purchases[charge] = append(l.Purchases[charge], t)
+					}
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	case CONVEY_ESCROW:
+		// Holds escrows where Sender locks Tokens
+		if err := l.iterate(name, hash, func(h []byte, b *bcgo.Block) error {
+			for _, entry := range b.Entry {
+				// Unmarshal as Escrow
+				e := &Escrow{}
+				err := proto.Unmarshal(entry.Record.Payload, e)
+				if err != nil {
+					return err
+				}
+				l.Escrows[base64.RawURLEncoding.EncodeToString(entry.RecordHash)] = e
+				l.RecordLocked(e.Sender, e.Amount)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	case CONVEY_ESCROW_RELEASE:
+		// Ensure the escrows being released have been processed
+		if escrows, err := l.Node.GetChannel(CONVEY_ESCROW); err == nil {
+			if err := l.Update(escrows.Name, escrows.Head); err != nil {
+				return err
+			}
+		}
+		// Holds releases where Sender unlocks Tokens, and either sells them to Receiver, or refunds them to themselves
+		if err := l.iterate(name, hash, func(h []byte, b *bcgo.Block) error {
+			for _, entry := range b.Entry {
+				// Unmarshal as EscrowRelease
+				r := &EscrowRelease{}
+				err := proto.Unmarshal(entry.Record.Payload, r)
+				if err != nil {
+					return err
+				}
+				key := base64.RawURLEncoding.EncodeToString(r.Escrow)
+				e, ok := l.Escrows[key]
+				if !ok {
+					return errors.New(fmt.Sprintf(ERROR_NO_SUCH_ESCROW, key))
+				}
+				if l.Released[key] {
+					continue
+				}
+				l.Released[key] = true
+				l.RecordUnlocked(e.Sender, e.Amount)
+				if !r.IsRefund() {
+					l.RecordSold(e.Sender, e.Amount)
+					l.RecordBought(r.Receiver, e.Amount)
+				}
 			}
 			return nil
 		}); err != nil {
@@ -273,7 +405,14 @@ func (l *Ledger) Update(name string, hash []byte) error {
 		// Record Author burns 1 Token per 100 Bytes
 		if err := l.iterate(name, hash, func(h []byte, b *bcgo.Block) error {
 			for _, entry := range b.Entry {
-				l.RecordBurned(entry.Record.Creator, Cost(entry.Record))
+				record := entry.Record
+				cost := Cost(record)
+				l.RecordBurned(record.Creator, cost)
+				l.RecordPosted(record.Creator, &Post{
+					Channel:   name,
+					Timestamp: record.Timestamp,
+					Cost:      cost,
+				})
 			}
 			return nil
 		}); err != nil {
@@ -292,8 +431,9 @@ func (l *Ledger) Update(name string, hash []byte) error {
 					blocks[recordKey] = blockKey
 					record := entry.Record
 					node := &MessageNode{
-						Author: record.Creator,
-						Cost:   Cost(record),
+						Author:    record.Creator,
+						Timestamp: record.Timestamp,
+						Cost:      Cost(record),
 					}
 					// Unmarshal as Message
 					m := &Message{}
@@ -324,6 +464,11 @@ func (l *Ledger) Update(name string, hash []byte) error {
 				author := node.Author
 				cost := node.Cost
 				prev := node.Previous
+				l.RecordPosted(author, &Post{
+					Channel:   name,
+					Timestamp: node.Timestamp,
+					Cost:      cost,
+				})
 				if prev == "" {
 					l.RecordBurned(author, cost)
 				} else {
@@ -17,6 +17,7 @@
 package conveygo_test
 
 import (
+	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
 	"github.com/AletheiaWareLLC/bcgo"
@@ -83,7 +84,7 @@ func checkLedger(t *testing.T, ledger *conveygo.Ledger) {
 		}
 	}
 	for alias := range ledger.Aliases {
-		balance := ledger.GetBalance(alias)
+		balance, _ := ledger.GetBalance(alias)
 		if balance < 0 {
 			t.Errorf("Token balance for %s cannot be negative, instead got %d", alias, balance)
 		}
@@ -100,7 +101,7 @@ func makePeriodicValidationBlock(t *testing.T, node *bcgo.Node, listener bcgo.Mi
 	return h, b
 }
 
-func makeTransactionBlock(t *testing.T, node *bcgo.Node, listener bcgo.MiningListener, channel *bcgo.Channel, alias string, key *rsa.PrivateKey, amount uint64) {
+func makeTransactionBlock(t *testing.T, node *bcgo.Node, listener bcgo.MiningListener, channel *bcgo.Channel, alias string, key crypto.Signer, amount uint64) {
 	transaction := &conveygo.Transaction{
 		Sender:   node.Alias,
 		Receiver: alias,
@@ -121,27 +122,28 @@ func TestLedger(t *testing.T) {
 		Output: os.Stdout,
 	}
 	aliasNode := "Node"
-	keyNode, err := rsa.GenerateKey(rand.Reader, 4096)
+	keyNode, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
 		t.Error("Could not generate key:", err)
 	}
 	aliasAlice := "Alice"
-	keyAlice, err := rsa.GenerateKey(rand.Reader, 4096)
+	keyAlice, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
 		t.Error("Could not generate key:", err)
 	}
 	aliasBob := "Bob"
-	keyBob, err := rsa.GenerateKey(rand.Reader, 4096)
+	keyBob, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
 		t.Error("Could not generate key:", err)
 	}
 	aliasCharlie := "Charlie"
-	keyCharlie, err := rsa.GenerateKey(rand.Reader, 4096)
+	keyCharlie, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
 		t.Error("Could not generate key:", err)
 	}
 	t.Run("Conversation", func(t *testing.T) {
 		node := makeNode(t, aliasNode, keyNode)
+		registerAlias(t, node, aliasAlice, keyAlice)
 		years, err := node.GetChannel(conveygo.CONVEY_YEAR)
 		testinggo.AssertNoError(t, err)
 		makePeriodicValidationBlock(t, node, listener, years, nil, nil)
@@ -155,7 +157,7 @@ func TestLedger(t *testing.T) {
 		store := &conveygo.BCStore{
 			Node:     node,
 			Listener: nil,
-			KeyStore: keystore,
+			KeyStore: conveygo.NewFileKeyStore(keystore),
 		}
 
 		timestamp := bcgo.Timestamp()
@@ -192,6 +194,8 @@ func TestLedger(t *testing.T) {
 	})
 	t.Run("Conversation_Reply", func(t *testing.T) {
 		node := makeNode(t, aliasNode, keyNode)
+		registerAlias(t, node, aliasAlice, keyAlice)
+		registerAlias(t, node, aliasBob, keyBob)
 		years, err := node.GetChannel(conveygo.CONVEY_YEAR)
 		testinggo.AssertNoError(t, err)
 		pvh, pvb := makePeriodicValidationBlock(t, node, listener, years, nil, nil)
@@ -207,7 +211,7 @@ func TestLedger(t *testing.T) {
 		store := &conveygo.BCStore{
 			Node:     node,
 			Listener: nil,
-			KeyStore: keystore,
+			KeyStore: conveygo.NewFileKeyStore(keystore),
 		}
 
 		timestamp := bcgo.Timestamp()
@@ -253,6 +257,9 @@ func TestLedger(t *testing.T) {
 	})
 	t.Run("Conversation_Replies", func(t *testing.T) {
 		node := makeNode(t, aliasNode, keyNode)
+		registerAlias(t, node, aliasAlice, keyAlice)
+		registerAlias(t, node, aliasBob, keyBob)
+		registerAlias(t, node, aliasCharlie, keyCharlie)
 		years, err := node.GetChannel(conveygo.CONVEY_YEAR)
 		testinggo.AssertNoError(t, err)
 		pvh1, pvb1 := makePeriodicValidationBlock(t, node, listener, years, nil, nil)
@@ -270,7 +277,7 @@ func TestLedger(t *testing.T) {
 		store := &conveygo.BCStore{
 			Node:     node,
 			Listener: nil,
-			KeyStore: keystore,
+			KeyStore: conveygo.NewFileKeyStore(keystore),
 		}
 
 		timestamp := bcgo.Timestamp()
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo
+
+import (
+	"github.com/AletheiaWareLLC/bcgo"
+	"sync"
+)
+
+// LockingMessageStore serializes access to a MessageStore shared by several servers.
+// Callbacks passed to GetMessage run while the lock is held so must not call back into the store.
+type LockingMessageStore struct {
+	Store MessageStore
+	lock  sync.Mutex
+}
+
+func NewLockingMessageStore(store MessageStore) *LockingMessageStore {
+	return &LockingMessageStore{
+		Store: store,
+	}
+}
+
+func (s *LockingMessageStore) NewConversation(conversationHash []byte, conversationRecord *bcgo.Record, messageHash []byte, messageRecord *bcgo.Record) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Store.NewConversation(conversationHash, conversationRecord, messageHash, messageRecord)
+}
+
+func (s *LockingMessageStore) GetConversation(conversationHash []byte) (*Listing, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Store.GetConversation(conversationHash)
+}
+
+func (s *LockingMessageStore) GetAllConversations(from, to uint64) ([]*Listing, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Store.GetAllConversations(from, to)
+}
+
+func (s *LockingMessageStore) GetRecentConversations(limit uint) ([]*Listing, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Store.GetRecentConversations(limit)
+}
+
+func (s *LockingMessageStore) AddMessage(conversationHash, messageHash []byte, messageRecord *bcgo.Record) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Store.AddMessage(conversationHash, messageHash, messageRecord)
+}
+
+func (s *LockingMessageStore) GetMessage(conversationHash, messageHash []byte, callback func([]byte, uint64, string, uint64, *Message) error) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Store.GetMessage(conversationHash, messageHash, callback)
+}
+
+func (s *LockingMessageStore) GetYield(conversationHash []byte) (uint64, uint64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Store.GetYield(conversationHash)
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/testinggo"
+	"sync"
+	"testing"
+)
+
+func TestLockingMessageStore(t *testing.T) {
+	alias := "Alice"
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	testinggo.AssertNoError(t, err)
+	store := conveygo.NewLockingMessageStore(conveygo.NewMemoryStore())
+	timestamp := bcgo.Timestamp()
+	conversationHash, conversationRecord, err := conveygo.ProtoToRecord(alias, key, timestamp, &conveygo.Conversation{
+		Topic: "Test",
+	})
+	testinggo.AssertNoError(t, err)
+	messageHash, messageRecord, err := conveygo.ProtoToRecord(alias, key, timestamp, &conveygo.Message{
+		Content: []byte("Hello"),
+		Type:    conveygo.MediaType_TEXT_PLAIN,
+	})
+	testinggo.AssertNoError(t, err)
+	testinggo.AssertNoError(t, store.NewConversation(conversationHash, conversationRecord, messageHash, messageRecord))
+
+	// Concurrent replies and reads are serialized, as checked by the race detector
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		replyHash, replyRecord, err := conveygo.ProtoToRecord(alias, key, bcgo.Timestamp(), &conveygo.Message{
+			Previous: messageHash,
+			Content:  []byte{byte(i)},
+			Type:     conveygo.MediaType_TEXT_PLAIN,
+		})
+		testinggo.AssertNoError(t, err)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := store.AddMessage(conversationHash, replyHash, replyRecord); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := store.GetRecentConversations(10); err != nil {
+				t.Error(err)
+			}
+			if err := store.GetMessage(conversationHash, nil, func([]byte, uint64, string, uint64, *conveygo.Message) error {
+				return nil
+			}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var count int
+	testinggo.AssertNoError(t, store.GetMessage(conversationHash, nil, func([]byte, uint64, string, uint64, *conveygo.Message) error {
+		count++
+		return nil
+	}))
+	if count != 11 {
+		t.Errorf("Wrong number of messages; expected '11', got '%d'", count)
+	}
+}
@@ -0,0 +1,699 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/AletheiaWareLLC/aliasgo"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DEFAULT_LIST_LIMIT = 10
+
+	ERROR_MISSING_ARGUMENTS = "Missing arguments: %s"
+	ERROR_UNKNOWN_COMMAND   = "Unknown command: %s"
+)
+
+var (
+	jsonOutput = flag.Bool("json", false, "write results as JSON")
+	host       = flag.String("host", "", "Convey host, empty for the default hosts")
+)
+
+type Client struct {
+	Node  *bcgo.Node
+	Store *conveygo.BCStore
+	Out   io.Writer
+}
+
+type ConversationResult struct {
+	Hash      string
+	Timestamp uint64
+	Author    string
+	Topic     string
+	Cost      uint64
+}
+
+type MessageResult struct {
+	Hash      string
+	Previous  string `json:",omitempty"`
+	Timestamp uint64
+	Author    string
+	Cost      uint64
+	Type      string
+	Content   string
+	Tags      []string         `json:",omitempty"`
+	Replies   []*MessageResult `json:",omitempty"`
+}
+
+type ShowResult struct {
+	Conversation *ConversationResult
+	Messages     []*MessageResult
+}
+
+type BalanceResult struct {
+	Alias     string
+	Balance   int64
+	Allowance uint64
+	Minted    uint64
+	Burned    uint64
+	Bought    uint64
+	Sold      uint64
+	Earned    uint64
+	Spent     uint64
+	Locked    int64
+}
+
+type RecordResult struct {
+	Hash string
+}
+
+func main() {
+	flag.Usage = PrintUsage
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		PrintUsage()
+		os.Exit(1)
+	}
+
+	client, err := NewClient(os.Stdout)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := client.Handle(args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func PrintUsage() {
+	output := flag.CommandLine.Output()
+	fmt.Fprintln(output, "Convey Usage:")
+	fmt.Fprintln(output, "\tconvey [flags] new [topic] [file] - start a conversation, reading the message from file or stdin")
+	fmt.Fprintln(output, "\tconvey [flags] reply [conversation] [message] [file] - reply to a message, reading the reply from file or stdin")
+	fmt.Fprintln(output, "\tconvey [flags] list [limit] - list recent conversations")
+	fmt.Fprintln(output, "\tconvey [flags] show [conversation] - show the messages in a conversation")
+	fmt.Fprintln(output, "\tconvey [flags] tree [conversation] - show the replies in a conversation as a tree")
+	fmt.Fprintln(output, "\tconvey [flags] balance [alias] - show the token balance of the alias, or this node")
+	fmt.Fprintln(output, "\tconvey [flags] transfer [receiver] [amount] [memo] [idempotency-key] - transfer tokens to the receiver, with an optional memo, and an optional key to prevent retries transferring twice")
+	fmt.Fprintln(output, "\tconvey [flags] escrow [amount] [duration] [conversation] [memo] - lock tokens in escrow, such as a bounty for a conversation, refundable after the duration (eg. 168h)")
+	fmt.Fprintln(output, "\tconvey [flags] release [escrow] [receiver] - release tokens locked in escrow to the receiver")
+	fmt.Fprintln(output, "\tconvey [flags] refund [escrow] - refund tokens locked in escrow after the deadline")
+	fmt.Fprintln(output, "\tconvey [flags] tag [message] [value] - tag a message")
+	fmt.Fprintln(output, "\tconvey [flags] search [query] - search conversation topics and tags")
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "Flags:")
+	flag.PrintDefaults()
+}
+
+func NewClient(out io.Writer) (*Client, error) {
+	rootDir, err := bcgo.GetRootDirectory()
+	if err != nil {
+		return nil, err
+	}
+
+	cacheDir, err := bcgo.GetCacheDirectory(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	cache, err := bcgo.NewFileCache(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	peers, err := bcgo.GetPeers(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	if *host == "" {
+		peers = append(peers, conveygo.GetConveyHosts()...)
+	} else {
+		peers = append(peers, *host)
+	}
+
+	network := bcgo.NewTCPNetwork(peers...)
+
+	node, err := bcgo.GetNode(rootDir, cache, network)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, opener := range []func() *bcgo.Channel{
+		aliasgo.OpenAliasChannel,
+		conveygo.OpenConversationChannel,
+		conveygo.OpenTransactionChannel,
+		conveygo.OpenEscrowChannel,
+		conveygo.OpenEscrowReleaseChannel,
+	} {
+		channel := opener()
+		if err := channel.Refresh(cache, network); err != nil {
+			log.Println(err)
+		}
+		node.AddChannel(channel)
+	}
+
+	return &Client{
+		Node: node,
+		Store: &conveygo.BCStore{
+			Node:     node,
+			Listener: &bcgo.PrintingMiningListener{Output: os.Stderr},
+		},
+		Out: out,
+	}, nil
+}
+
+func (c *Client) Handle(args []string) error {
+	switch args[0] {
+	case "new":
+		if len(args) < 2 {
+			return errors.New(fmt.Sprintf(ERROR_MISSING_ARGUMENTS, "topic"))
+		}
+		content, err := ReadContent(args[2:])
+		if err != nil {
+			return err
+		}
+		return c.NewConversation(args[1], content)
+	case "reply":
+		if len(args) < 3 {
+			return errors.New(fmt.Sprintf(ERROR_MISSING_ARGUMENTS, "conversation, message"))
+		}
+		content, err := ReadContent(args[3:])
+		if err != nil {
+			return err
+		}
+		return c.Reply(args[1], args[2], content)
+	case "list":
+		limit := uint64(DEFAULT_LIST_LIMIT)
+		if len(args) > 1 {
+			l, err := strconv.ParseUint(args[1], 10, 32)
+			if err != nil {
+				return err
+			}
+			limit = l
+		}
+		return c.List(uint(limit))
+	case "show":
+		if len(args) < 2 {
+			return errors.New(fmt.Sprintf(ERROR_MISSING_ARGUMENTS, "conversation"))
+		}
+		return c.Show(args[1])
+	case "tree":
+		if len(args) < 2 {
+			return errors.New(fmt.Sprintf(ERROR_MISSING_ARGUMENTS, "conversation"))
+		}
+		return c.Tree(args[1])
+	case "balance":
+		alias := c.Node.Alias
+		if len(args) > 1 {
+			alias = args[1]
+		}
+		return c.Balance(alias)
+	case "transfer":
+		if len(args) < 3 {
+			return errors.New(fmt.Sprintf(ERROR_MISSING_ARGUMENTS, "receiver, amount"))
+		}
+		amount, err := strconv.ParseUint(args[2], 10, 64)
+		if err != nil {
+			return err
+		}
+		var memo, idempotencyKey string
+		if len(args) > 3 {
+			memo = args[3]
+		}
+		if len(args) > 4 {
+			idempotencyKey = args[4]
+		}
+		return c.Transfer(args[1], amount, memo, idempotencyKey)
+	case "escrow":
+		if len(args) < 3 {
+			return errors.New(fmt.Sprintf(ERROR_MISSING_ARGUMENTS, "amount, duration"))
+		}
+		amount, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return err
+		}
+		duration, err := time.ParseDuration(args[2])
+		if err != nil {
+			return err
+		}
+		var conversation, memo string
+		if len(args) > 3 {
+			conversation = args[3]
+		}
+		if len(args) > 4 {
+			memo = args[4]
+		}
+		return c.Escrow(amount, duration, conversation, memo)
+	case "release":
+		if len(args) < 3 {
+			return errors.New(fmt.Sprintf(ERROR_MISSING_ARGUMENTS, "escrow, receiver"))
+		}
+		return c.Release(args[1], args[2])
+	case "refund":
+		if len(args) < 2 {
+			return errors.New(fmt.Sprintf(ERROR_MISSING_ARGUMENTS, "escrow"))
+		}
+		return c.Release(args[1], c.Node.Alias)
+	case "tag":
+		if len(args) < 3 {
+			return errors.New(fmt.Sprintf(ERROR_MISSING_ARGUMENTS, "message, value"))
+		}
+		return c.Tag(args[1], args[2])
+	case "search":
+		if len(args) < 2 {
+			return errors.New(fmt.Sprintf(ERROR_MISSING_ARGUMENTS, "query"))
+		}
+		return c.Search(strings.Join(args[1:], " "))
+	default:
+		return errors.New(fmt.Sprintf(ERROR_UNKNOWN_COMMAND, args[0]))
+	}
+}
+
+// Reads message content from the file named in args, or stdin if there is none.
+func ReadContent(args []string) ([]byte, error) {
+	if len(args) > 0 {
+		return ioutil.ReadFile(args[0])
+	}
+	return ioutil.ReadAll(os.Stdin)
+}
+
+func (c *Client) NewConversation(topic string, content []byte) error {
+	timestamp := bcgo.Timestamp()
+	conversationHash, conversationRecord, err := conveygo.ProtoToRecord(c.Node.Alias, c.Node.Key, timestamp, &conveygo.Conversation{
+		Topic: topic,
+	})
+	if err != nil {
+		return err
+	}
+	messageHash, messageRecord, err := conveygo.ProtoToRecord(c.Node.Alias, c.Node.Key, timestamp, &conveygo.Message{
+		Content: content,
+		Type:    conveygo.MediaType_TEXT_PLAIN,
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.Store.NewConversation(conversationHash, conversationRecord, messageHash, messageRecord); err != nil {
+		return err
+	}
+	return c.writeRecord(conversationHash)
+}
+
+func (c *Client) Reply(conversation, message string, content []byte) error {
+	conversationHash, err := c.openMessageChannel(conversation)
+	if err != nil {
+		return err
+	}
+	previous, err := base64.RawURLEncoding.DecodeString(message)
+	if err != nil {
+		return err
+	}
+	messageHash, messageRecord, err := conveygo.ProtoToRecord(c.Node.Alias, c.Node.Key, bcgo.Timestamp(), &conveygo.Message{
+		Previous: previous,
+		Content:  content,
+		Type:     conveygo.MediaType_TEXT_PLAIN,
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.Store.AddMessage(conversationHash, messageHash, messageRecord); err != nil {
+		return err
+	}
+	return c.writeRecord(messageHash)
+}
+
+func (c *Client) List(limit uint) error {
+	listings, err := c.Store.GetRecentConversations(limit)
+	if err != nil {
+		return err
+	}
+	return c.writeListings(listings)
+}
+
+func (c *Client) Show(conversation string) error {
+	listing, messages, err := c.getConversation(conversation)
+	if err != nil {
+		return err
+	}
+	result := &ShowResult{
+		Conversation: ListingToResult(listing),
+		Messages:     messages,
+	}
+	if *jsonOutput {
+		return json.NewEncoder(c.Out).Encode(result)
+	}
+	writeListing(c.Out, result.Conversation)
+	for _, m := range result.Messages {
+		fmt.Fprintln(c.Out)
+		writeMessage(c.Out, m, "")
+	}
+	return nil
+}
+
+func (c *Client) Tree(conversation string) error {
+	_, messages, err := c.getConversation(conversation)
+	if err != nil {
+		return err
+	}
+	var roots []*MessageResult
+	replies := make(map[string]*MessageResult)
+	for _, m := range messages {
+		replies[m.Hash] = m
+	}
+	for _, m := range messages {
+		if parent, ok := replies[m.Previous]; ok {
+			parent.Replies = append(parent.Replies, m)
+		} else {
+			roots = append(roots, m)
+		}
+	}
+	if *jsonOutput {
+		return json.NewEncoder(c.Out).Encode(roots)
+	}
+	var write func(*MessageResult, string)
+	write = func(m *MessageResult, indent string) {
+		writeMessage(c.Out, m, indent)
+		for _, r := range m.Replies {
+			write(r, indent+"\t")
+		}
+	}
+	for _, r := range roots {
+		write(r, "")
+	}
+	return nil
+}
+
+func (c *Client) Balance(alias string) error {
+	ledger, err := c.openLedger()
+	if err != nil {
+		return err
+	}
+	balance, allowance := ledger.GetBalance(alias)
+	result := &BalanceResult{
+		Alias:     alias,
+		Balance:   balance,
+		Allowance: allowance,
+		Minted:    ledger.Minted[alias],
+		Burned:    ledger.Burned[alias],
+		Bought:    ledger.Bought[alias],
+		Sold:      ledger.Sold[alias],
+		Earned:    ledger.Earned[alias],
+		Spent:     ledger.Spent[alias],
+		Locked:    ledger.GetLocked(alias),
+	}
+	if *jsonOutput {
+		return json.NewEncoder(c.Out).Encode(result)
+	}
+	fmt.Fprintf(c.Out, "%s: %d\n", result.Alias, result.Balance)
+	fmt.Fprintf(c.Out, "\tMinted: %d\n\tBurned: %d\n\tBought: %d\n\tSold: %d\n\tEarned: %d\n\tSpent: %d\n\tLocked: %d\n\tAllowance: %d\n", result.Minted, result.Burned, result.Bought, result.Sold, result.Earned, result.Spent, result.Locked, result.Allowance)
+	return nil
+}
+
+func (c *Client) Transfer(receiver string, amount uint64, memo, idempotencyKey string) error {
+	ledger, err := c.openLedger()
+	if err != nil {
+		return err
+	}
+	receipt, err := c.Store.Transfer(ledger, c.Node.Alias, c.Node.Key, receiver, amount, memo, idempotencyKey)
+	if err != nil {
+		return err
+	}
+	if receipt.Duplicate {
+		log.Println("Already transferred with idempotency key", idempotencyKey)
+	}
+	return c.writeRecord(receipt.Hash)
+}
+
+func (c *Client) Escrow(amount uint64, duration time.Duration, conversation, memo string) error {
+	var conversationHash []byte
+	if conversation != "" {
+		h, err := base64.RawURLEncoding.DecodeString(conversation)
+		if err != nil {
+			return err
+		}
+		conversationHash = h
+	}
+	ledger, err := c.openLedger()
+	if err != nil {
+		return err
+	}
+	deadline := bcgo.Timestamp() + uint64(duration.Nanoseconds())
+	hash, err := c.Store.LockEscrow(ledger, c.Node.Alias, c.Node.Key, amount, deadline, memo, conversationHash)
+	if err != nil {
+		return err
+	}
+	return c.writeRecord(hash)
+}
+
+// Releases the escrow to the receiver, or refunds it if the receiver is this node.
+func (c *Client) Release(escrow, receiver string) error {
+	escrowHash, err := base64.RawURLEncoding.DecodeString(escrow)
+	if err != nil {
+		return err
+	}
+	ledger, err := c.openLedger()
+	if err != nil {
+		return err
+	}
+	var hash []byte
+	if receiver == c.Node.Alias {
+		hash, err = c.Store.RefundEscrow(ledger, c.Node.Alias, c.Node.Key, escrowHash)
+	} else {
+		hash, err = c.Store.ReleaseEscrow(ledger, c.Node.Alias, c.Node.Key, escrowHash, receiver)
+	}
+	if err != nil {
+		return err
+	}
+	return c.writeRecord(hash)
+}
+
+func (c *Client) Tag(message, value string) error {
+	messageHash, err := base64.RawURLEncoding.DecodeString(message)
+	if err != nil {
+		return err
+	}
+	hash, record, err := conveygo.ProtoToRecord(c.Node.Alias, c.Node.Key, bcgo.Timestamp(), &conveygo.Tag{
+		Value: value,
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.Store.AddTag(messageHash, hash, record); err != nil {
+		return err
+	}
+	return c.writeRecord(hash)
+}
+
+// Lists conversations whose topic contains the query, or whose first message has a tag matching the query.
+func (c *Client) Search(query string) error {
+	listings, err := c.Store.GetAllConversations(0, bcgo.Timestamp())
+	if err != nil {
+		return err
+	}
+	query = strings.ToLower(query)
+	var results []*conveygo.Listing
+	for _, l := range listings {
+		if strings.Contains(strings.ToLower(l.Topic), query) {
+			results = append(results, l)
+			continue
+		}
+		if _, err := c.openMessageChannel(base64.RawURLEncoding.EncodeToString(l.Hash)); err != nil {
+			return err
+		}
+		var first []byte
+		if err := c.Store.GetMessage(l.Hash, nil, func(hash []byte, timestamp uint64, author string, cost uint64, message *conveygo.Message) error {
+			if len(message.Previous) == 0 {
+				first = hash
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if first == nil {
+			continue
+		}
+		if err := c.Store.GetTags(first, func(hash []byte, timestamp uint64, author string, tag *conveygo.Tag) error {
+			if strings.EqualFold(tag.Value, query) {
+				results = append(results, l)
+				return bcgo.StopIterationError{}
+			}
+			return nil
+		}); err != nil {
+			switch err.(type) {
+			case bcgo.StopIterationError:
+				// Do nothing
+				break
+			default:
+				return err
+			}
+		}
+	}
+	return c.writeListings(results)
+}
+
+// Opens every chain affecting balances and returns an up to date ledger.
+func (c *Client) openLedger() (*conveygo.Ledger, error) {
+	for _, opener := range []func() *bcgo.Channel{
+		conveygo.OpenHourChannel,
+		conveygo.OpenDayChannel,
+		conveygo.OpenWeekChannel,
+		conveygo.OpenYearChannel,
+		conveygo.OpenDecadeChannel,
+		conveygo.OpenCenturyChannel,
+	} {
+		channel := opener()
+		c.Node.GetOrOpenChannel(channel.Name, func() *bcgo.Channel {
+			return channel
+		})
+	}
+	conversations, err := c.Node.GetChannel(conveygo.CONVEY_CONVERSATION)
+	if err != nil {
+		return nil, err
+	}
+	if err := bcgo.Iterate(conversations.Name, conversations.Head, nil, c.Node.Cache, c.Node.Network, func(h []byte, b *bcgo.Block) error {
+		for _, entry := range b.Entry {
+			if _, err := c.openMessageChannel(base64.RawURLEncoding.EncodeToString(entry.RecordHash)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	ledger := conveygo.NewLedger(c.Node)
+	if err := ledger.UpdateAll(); err != nil {
+		return nil, err
+	}
+	return ledger, nil
+}
+
+// Ensures the message channel of the given conversation is open, and returns the decoded conversation hash.
+func (c *Client) openMessageChannel(conversation string) ([]byte, error) {
+	conversationHash, err := base64.RawURLEncoding.DecodeString(conversation)
+	if err != nil {
+		return nil, err
+	}
+	c.Node.GetOrOpenChannel(conveygo.CONVEY_PREFIX_MESSAGE+conversation, func() *bcgo.Channel {
+		return conveygo.OpenMessageChannel(conversation)
+	})
+	return conversationHash, nil
+}
+
+// Returns the conversation listing, and its messages in chronological order.
+func (c *Client) getConversation(conversation string) (*conveygo.Listing, []*MessageResult, error) {
+	conversationHash, err := c.openMessageChannel(conversation)
+	if err != nil {
+		return nil, nil, err
+	}
+	listing, err := c.Store.GetConversation(conversationHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	var messages []*MessageResult
+	if err := c.Store.GetMessage(conversationHash, nil, func(hash []byte, timestamp uint64, author string, cost uint64, message *conveygo.Message) error {
+		m := &MessageResult{
+			Hash:      base64.RawURLEncoding.EncodeToString(hash),
+			Timestamp: timestamp,
+			Author:    author,
+			Cost:      cost,
+			Type:      message.Type.String(),
+			Content:   string(message.Content),
+		}
+		if len(message.Previous) > 0 {
+			m.Previous = base64.RawURLEncoding.EncodeToString(message.Previous)
+		}
+		if err := c.Store.GetTags(hash, func(h []byte, t uint64, a string, tag *conveygo.Tag) error {
+			m.Tags = append(m.Tags, tag.Value)
+			return nil
+		}); err != nil {
+			return err
+		}
+		messages = append(messages, m)
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].Timestamp < messages[j].Timestamp
+	})
+	return listing, messages, nil
+}
+
+func ListingToResult(listing *conveygo.Listing) *ConversationResult {
+	return &ConversationResult{
+		Hash:      base64.RawURLEncoding.EncodeToString(listing.Hash),
+		Timestamp: listing.Timestamp,
+		Author:    listing.Author,
+		Topic:     listing.Topic,
+		Cost:      listing.Cost,
+	}
+}
+
+func (c *Client) writeListings(listings []*conveygo.Listing) error {
+	results := make([]*ConversationResult, len(listings))
+	for i, l := range listings {
+		results[i] = ListingToResult(l)
+	}
+	if *jsonOutput {
+		return json.NewEncoder(c.Out).Encode(results)
+	}
+	for _, r := range results {
+		writeListing(c.Out, r)
+	}
+	return nil
+}
+
+func (c *Client) writeRecord(hash []byte) error {
+	result := &RecordResult{
+		Hash: base64.RawURLEncoding.EncodeToString(hash),
+	}
+	if *jsonOutput {
+		return json.NewEncoder(c.Out).Encode(result)
+	}
+	fmt.Fprintln(c.Out, result.Hash)
+	return nil
+}
+
+func writeListing(out io.Writer, r *ConversationResult) {
+	fmt.Fprintf(out, "%s %s %s %d\n\t%s\n", r.Hash, bcgo.TimestampToString(r.Timestamp), r.Author, r.Cost, r.Topic)
+}
+
+func writeMessage(out io.Writer, m *MessageResult, indent string) {
+	fmt.Fprintf(out, "%s%s %s %s %d\n", indent, m.Hash, bcgo.TimestampToString(m.Timestamp), m.Author, m.Cost)
+	if len(m.Tags) > 0 {
+		fmt.Fprintf(out, "%s[%s]\n", indent, strings.Join(m.Tags, ", "))
+	}
+	for _, line := range strings.Split(m.Content, "\n") {
+		fmt.Fprintf(out, "%s%s\n", indent, line)
+	}
+}
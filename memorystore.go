@@ -18,7 +18,7 @@ package conveygo
 
 import (
 	"bytes"
-	"crypto/rsa"
+	"crypto"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -29,70 +29,108 @@ import (
 )
 
 type MemoryStore struct {
-	Passwords     map[string][]byte
-	Keys          map[string]*rsa.PrivateKey
+	KeyStore      KeyStore
 	Timestamps    map[string]uint64
 	Conversations map[string]*bcgo.Record
 	Mappings      map[string][]string
 	Messages      map[string]*bcgo.Record
+	Tags          map[string][]*bcgo.BlockEntry
+	Registrations map[string]*financego.Registration
+	Charges       map[string][]*financego.Charge
+	Subscriptions map[string]*financego.Subscription
+	Processor     PaymentProcessor
 }
 
 func NewMemoryStore() *MemoryStore {
 	return &MemoryStore{
-		Passwords:     make(map[string][]byte),
-		Keys:          make(map[string]*rsa.PrivateKey),
+		KeyStore:      NewMemoryKeyStore(),
 		Timestamps:    make(map[string]uint64),
 		Conversations: make(map[string]*bcgo.Record),
 		Mappings:      make(map[string][]string),
 		Messages:      make(map[string]*bcgo.Record),
+		Tags:          make(map[string][]*bcgo.BlockEntry),
+		Registrations: make(map[string]*financego.Registration),
+		Charges:       make(map[string][]*financego.Charge),
+		Subscriptions: make(map[string]*financego.Subscription),
+		Processor:     NewFakeProcessor(),
 	}
 }
 
-func (s *MemoryStore) AddKey(alias string, password []byte, key *rsa.PrivateKey) error {
-	if _, ok := s.Passwords[alias]; ok {
-		return errors.New(fmt.Sprintf(ERROR_KEY_ALREADY_EXISTS, alias))
-	}
-	s.Passwords[alias] = password
-	s.Keys[alias] = key
-	return nil
+func (s *MemoryStore) AddKey(alias string, password []byte, key crypto.Signer) error {
+	return s.KeyStore.AddKey(alias, password, key)
 }
 
-func (s *MemoryStore) GetKey(alias string, password []byte) (*rsa.PrivateKey, error) {
-	pwd, ok := s.Passwords[alias]
-	if ok && bytes.Equal(pwd, password) {
-		return s.Keys[alias], nil
-	}
-	return nil, errors.New(ERROR_ACCESS_DENIED)
+func (s *MemoryStore) GetKey(alias string, password []byte) (crypto.Signer, error) {
+	return s.KeyStore.GetKey(alias, password)
 }
 
 func (s *MemoryStore) HasKey(alias string) bool {
-	_, ok := s.Passwords[alias]
-	return ok
+	return s.KeyStore.HasKey(alias)
 }
 
-func (s *MemoryStore) RegisterAlias(alias string, password []byte, key *rsa.PrivateKey) error {
-	// TODO
-	return nil
+func (s *MemoryStore) ChangePassword(alias string, oldPassword, newPassword []byte) error {
+	return s.KeyStore.ChangePassword(alias, oldPassword, newPassword)
 }
 
-func (s *MemoryStore) RegisterCustomer(alias string, key *rsa.PrivateKey, customerId string) error {
+func (s *MemoryStore) RotateKey(alias string, password []byte, key crypto.Signer) error {
+	if _, err := s.GetKey(alias, password); err != nil {
+		return err
+	}
+	return s.KeyStore.SetKey(alias, password, key)
+}
+
+func (s *MemoryStore) RegisterAlias(alias string, password []byte, key crypto.Signer) error {
 	// TODO
 	return nil
 }
 
+func (s *MemoryStore) RegisterCustomer(alias string, key crypto.Signer, email, payment string) (*financego.Registration, error) {
+	if _, ok := s.Registrations[alias]; ok {
+		return nil, errors.New(fmt.Sprintf(ERROR_ALREADY_REGISTERED, alias))
+	}
+	registration, err := s.Processor.NewCustomer("", alias, email, payment, alias)
+	if err != nil {
+		return nil, err
+	}
+	s.Registrations[alias] = registration
+	return registration, nil
+}
+
 func (s *MemoryStore) GetRegistration(alias string) (*financego.Registration, error) {
-	// TODO
-	return nil, nil
+	return s.Registrations[alias], nil
 }
 
-func (s *MemoryStore) SubscribeCustomer(alias string, key *rsa.PrivateKey, customer, payment, product, plan string) error {
-	// TODO
-	return nil
+func (s *MemoryStore) ChargeCustomer(alias string, key crypto.Signer, product, plan, country, currency string, amount int64, description string) (*financego.Charge, error) {
+	registration, ok := s.Registrations[alias]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf(ERROR_NO_SUCH_REGISTRATION, alias))
+	}
+	charge, err := s.Processor.NewCharge(registration, product, plan, country, currency, amount, description)
+	if err != nil {
+		return nil, err
+	}
+	s.Charges[alias] = append(s.Charges[alias], charge)
+	return charge, nil
+}
+
+func (s *MemoryStore) SubscribeCustomer(alias string, key crypto.Signer, product, plan string) (*financego.Subscription, error) {
+	if _, ok := s.Subscriptions[alias]; ok {
+		return nil, errors.New(fmt.Sprintf(ERROR_ALREADY_SUBSCRIBED, alias))
+	}
+	registration, ok := s.Registrations[alias]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf(ERROR_NO_SUCH_REGISTRATION, alias))
+	}
+	subscription, err := s.Processor.NewSubscription(registration, product, plan)
+	if err != nil {
+		return nil, err
+	}
+	s.Subscriptions[alias] = subscription
+	return subscription, nil
 }
 
 func (s *MemoryStore) GetSubscription(alias string) (*financego.Subscription, error) {
-	// TODO
-	return nil, nil
+	return s.Subscriptions[alias], nil
 }
 
 func (s *MemoryStore) NewConversation(conversationHash []byte, conversationRecord *bcgo.Record, messageHash []byte, messageRecord *bcgo.Record) error {
@@ -142,7 +180,7 @@ func (s *MemoryStore) GetAllConversations(from, to uint64) ([]*Listing, error) {
 			listings = append(listings, &Listing{
 				Hash:      conversationHash,
 				Timestamp: s.Timestamps[conversationHashString],
-				Author:    "",
+				Author:    value.Creator,
 				Topic:     c.Topic,
 				Cost:      Cost(value),
 			})
@@ -165,7 +203,7 @@ func (s *MemoryStore) GetRecentConversations(limit uint) ([]*Listing, error) {
 		listings = append(listings, &Listing{
 			Hash:      conversationHash,
 			Timestamp: s.Timestamps[conversationHashString],
-			Author:    "",
+			Author:    value.Creator,
 			Topic:     c.Topic,
 			Cost:      Cost(value),
 		})
@@ -208,7 +246,7 @@ func (s *MemoryStore) GetMessage(conversationHash, messageHash []byte, callback
 			if err := proto.Unmarshal(record.Payload, message); err != nil {
 				return err
 			}
-			if err := callback(hash, 0, "", Cost(record), message); err != nil {
+			if err := callback(hash, record.Timestamp, record.Creator, Cost(record), message); err != nil {
 				return err
 			}
 		}
@@ -220,3 +258,25 @@ func (s *MemoryStore) GetYield(conversationHash []byte) (uint64, uint64, error)
 	// TODO
 	return 0, 0, nil
 }
+
+func (s *MemoryStore) AddTag(messageHash, tagHash []byte, tagRecord *bcgo.Record) error {
+	messageKey := base64.RawURLEncoding.EncodeToString(messageHash)
+	s.Tags[messageKey] = append(s.Tags[messageKey], &bcgo.BlockEntry{
+		RecordHash: tagHash,
+		Record:     tagRecord,
+	})
+	return nil
+}
+
+func (s *MemoryStore) GetTags(messageHash []byte, callback func([]byte, uint64, string, *Tag) error) error {
+	for _, entry := range s.Tags[base64.RawURLEncoding.EncodeToString(messageHash)] {
+		t := &Tag{}
+		if err := proto.Unmarshal(entry.Record.Payload, t); err != nil {
+			return err
+		}
+		if err := callback(entry.RecordHash, entry.Record.Timestamp, entry.Record.Creator, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
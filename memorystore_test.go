@@ -17,6 +17,7 @@
 package conveygo_test
 
 import (
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/rsa"
 	"github.com/AletheiaWareLLC/conveygo"
@@ -28,7 +29,11 @@ func TestMemoryStore(t *testing.T) {
 	email := "alice@example.com"
 	payment := "payment1234"
 	password := []byte("password1234")
-	key, err := rsa.GenerateKey(rand.Reader, 4096)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Error("Could not generate key:", err)
+	}
+	_, newKey, err := ed25519.GenerateKey(rand.Reader)
 	if err != nil {
 		t.Error("Could not generate key:", err)
 	}
@@ -56,6 +61,38 @@ func TestMemoryStore(t *testing.T) {
 			testUserStore_HasKey_NotExists(t, conveygo.NewMemoryStore(), alias, password, key)
 		})
 	})
+	t.Run("ChangePassword", func(t *testing.T) {
+		t.Run("Exists", func(t *testing.T) {
+			testUserStore_ChangePassword_Exists(t, conveygo.NewMemoryStore(), alias, password, key)
+		})
+		t.Run("NotExists", func(t *testing.T) {
+			testUserStore_ChangePassword_NotExists(t, conveygo.NewMemoryStore(), alias, password, key)
+		})
+	})
+	t.Run("RotateKey", func(t *testing.T) {
+		t.Run("Exists", func(t *testing.T) {
+			testUserStore_RotateKey_Exists(t, conveygo.NewMemoryStore(), alias, password, key, newKey)
+		})
+		t.Run("NotExists", func(t *testing.T) {
+			testUserStore_RotateKey_NotExists(t, conveygo.NewMemoryStore(), alias, password, key, newKey)
+		})
+	})
+	t.Run("ExportKey", func(t *testing.T) {
+		t.Run("Exists", func(t *testing.T) {
+			testUserStore_ExportKey_Exists(t, conveygo.NewMemoryStore(), alias, password, key)
+		})
+		t.Run("NotExists", func(t *testing.T) {
+			testUserStore_ExportKey_NotExists(t, conveygo.NewMemoryStore(), alias, password, key)
+		})
+	})
+	t.Run("ImportKey", func(t *testing.T) {
+		t.Run("Exists", func(t *testing.T) {
+			testUserStore_ImportKey_Exists(t, conveygo.NewMemoryStore(), alias, password, key, newKey)
+		})
+		t.Run("NotExists", func(t *testing.T) {
+			testUserStore_ImportKey_NotExists(t, conveygo.NewMemoryStore(), alias, password, key, newKey)
+		})
+	})
 	t.Run("RegisterAlias", func(t *testing.T) {
 		t.Run("Exists", func(t *testing.T) {
 			testUserStore_RegisterAlias_Exists(t, conveygo.NewMemoryStore(), alias, email, payment, key)
@@ -80,6 +117,14 @@ func TestMemoryStore(t *testing.T) {
 			testUserStore_GetRegistration_NotExists(t, conveygo.NewMemoryStore(), alias, email, payment, key)
 		})
 	})
+	t.Run("ChargeCustomer", func(t *testing.T) {
+		t.Run("Exists", func(t *testing.T) {
+			testUserStore_ChargeCustomer_Exists(t, conveygo.NewMemoryStore(), alias, email, payment, key)
+		})
+		t.Run("NotExists", func(t *testing.T) {
+			testUserStore_ChargeCustomer_NotExists(t, conveygo.NewMemoryStore(), alias, email, payment, key)
+		})
+	})
 	t.Run("SubscribeCustomer", func(t *testing.T) {
 		t.Run("Exists", func(t *testing.T) {
 			testUserStore_SubscribeCustomer_Exists(t, conveygo.NewMemoryStore(), alias, email, payment, key)
@@ -165,4 +210,15 @@ func TestMemoryStore(t *testing.T) {
 			testMessageStore_GetYield_NotExists(t, conveygo.NewMemoryStore())
 		})
 	})
+	t.Run("AddTag", func(t *testing.T) {
+		testTagStore_AddTag(t, conveygo.NewMemoryStore(), alias, key)
+	})
+	t.Run("GetTags", func(t *testing.T) {
+		t.Run("Exists", func(t *testing.T) {
+			testTagStore_GetTags_Exists(t, conveygo.NewMemoryStore(), alias, key)
+		})
+		t.Run("NotExists", func(t *testing.T) {
+			testTagStore_GetTags_NotExists(t, conveygo.NewMemoryStore())
+		})
+	})
 }
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"github.com/AletheiaWareLLC/aliasgo"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/miner"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+var (
+	host = flag.String("host", "", "Convey host, empty for the default hosts")
+	pvcs = flag.String("pvcs", "hour,day,week,year,decade,century", "comma separated periodic validation chains to mine")
+)
+
+func main() {
+	flag.Parse()
+
+	selected, err := miner.SelectPVCs(strings.Split(*pvcs, ","))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rootDir, err := bcgo.GetRootDirectory()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	node, err := OpenNode(rootDir, selected)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ledger := conveygo.NewLedger(node)
+	if err := ledger.UpdateAll(); err != nil {
+		log.Println(err)
+	}
+
+	m := miner.NewMiner(node, ledger, &bcgo.PrintingMiningListener{Output: os.Stdout}, selected)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		m.Stop()
+	}()
+
+	log.Println("Mining", *pvcs, "as", node.Alias)
+	m.Start(func(minted uint64) {
+		balance, _ := ledger.GetBalance(node.Alias)
+		log.Println("Minted", minted, "tokens, balance", balance)
+	})
+}
+
+// Opens the node with the Convey channels and the message chain of each conversation, so each PVC block links their heads, and the selected PVCs.
+func OpenNode(rootDir string, pvcs []*miner.PVC) (*bcgo.Node, error) {
+	cacheDir, err := bcgo.GetCacheDirectory(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	cache, err := bcgo.NewFileCache(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	peers, err := bcgo.GetPeers(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	if *host == "" {
+		peers = append(peers, conveygo.GetConveyHosts()...)
+	} else {
+		peers = append(peers, *host)
+	}
+	log.Println("Peers:", peers)
+
+	network := bcgo.NewTCPNetwork(peers...)
+
+	node, err := bcgo.GetNode(rootDir, cache, network)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := []*bcgo.Channel{
+		aliasgo.OpenAliasChannel(),
+		conveygo.OpenConversationChannel(),
+		conveygo.OpenTransactionChannel(),
+		conveygo.OpenEscrowChannel(),
+		conveygo.OpenEscrowReleaseChannel(),
+	}
+	for _, p := range pvcs {
+		channels = append(channels, p.Channel)
+	}
+	for _, channel := range channels {
+		if err := channel.Refresh(cache, network); err != nil {
+			log.Println(err)
+		}
+		node.AddChannel(channel)
+	}
+	if err := miner.OpenMessageChannels(node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
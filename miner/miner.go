@@ -0,0 +1,214 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package miner
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DEFAULT_RETRY = time.Minute
+
+	ERROR_UNKNOWN_PVC = "Unknown periodic validation chain: %s"
+)
+
+// PVC is a Periodic Validation Chain, mined once per period at the given threshold.
+type PVC struct {
+	Channel   *bcgo.Channel
+	Period    time.Duration
+	Threshold uint64
+}
+
+// Returns the Convey PVCs, shortest period first.
+func GetPVCs() []*PVC {
+	return []*PVC{
+		{conveygo.OpenHourChannel(), bcgo.PERIOD_HOURLY, bcgo.THRESHOLD_PERIOD_HOUR},
+		{conveygo.OpenDayChannel(), bcgo.PERIOD_DAILY, bcgo.THRESHOLD_PERIOD_DAY},
+		{conveygo.OpenWeekChannel(), bcgo.PERIOD_WEEKLY, bcgo.THRESHOLD_PERIOD_WEEK},
+		{conveygo.OpenYearChannel(), bcgo.PERIOD_YEARLY, bcgo.THRESHOLD_PERIOD_YEAR},
+		{conveygo.OpenDecadeChannel(), bcgo.PERIOD_DECENNIALLY, bcgo.THRESHOLD_PERIOD_DECADE},
+		{conveygo.OpenCenturyChannel(), bcgo.PERIOD_CENTENNIALLY, bcgo.THRESHOLD_PERIOD_CENTURY},
+	}
+}
+
+// Returns the PVCs with the given names, such as "Convey-Hour", or with the short names, such as "hour".
+func SelectPVCs(names []string) ([]*PVC, error) {
+	pvcs := GetPVCs()
+	var selected []*PVC
+	for _, name := range names {
+		var found bool
+		for _, p := range pvcs {
+			if p.Channel.Name == name || strings.EqualFold(conveygo.CONVEY_PREFIX+name, p.Channel.Name) {
+				selected = append(selected, p)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.New(fmt.Sprintf(ERROR_UNKNOWN_PVC, name))
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		return selected[i].Period < selected[j].Period
+	})
+	return selected, nil
+}
+
+// Returns the timestamp of the most recent period boundary at or before the given timestamp, with periods aligned to the Unix epoch.
+func Boundary(timestamp uint64, period time.Duration) uint64 {
+	p := uint64(period.Nanoseconds())
+	return timestamp - timestamp%p
+}
+
+// Opens the message chain of each conversation in the node's Convey-Conversation chain, so PVC blocks link their heads too.
+func OpenMessageChannels(node *bcgo.Node) error {
+	conversations, err := node.GetChannel(conveygo.CONVEY_CONVERSATION)
+	if err != nil {
+		return err
+	}
+	return bcgo.Iterate(conversations.Name, conversations.Head, nil, node.Cache, node.Network, func(h []byte, b *bcgo.Block) error {
+		for _, entry := range b.Entry {
+			id := base64.RawURLEncoding.EncodeToString(entry.RecordHash)
+			node.GetOrOpenChannel(conveygo.CONVEY_PREFIX_MESSAGE+id, func() *bcgo.Channel {
+				return conveygo.OpenMessageChannel(id)
+			})
+		}
+		return nil
+	})
+}
+
+// Miner mines a block into each PVC at each period boundary, linking the current heads of the node's other channels.
+// A PVC that fails to mine is retried after the Retry interval.
+type Miner struct {
+	Node     *bcgo.Node
+	Ledger   *conveygo.Ledger
+	Listener bcgo.MiningListener
+	PVCs     []*PVC
+	Retry    time.Duration
+	stop     chan bool
+}
+
+func NewMiner(node *bcgo.Node, ledger *conveygo.Ledger, listener bcgo.MiningListener, pvcs []*PVC) *Miner {
+	return &Miner{
+		Node:     node,
+		Ledger:   ledger,
+		Listener: listener,
+		PVCs:     pvcs,
+		Retry:    DEFAULT_RETRY,
+		stop:     make(chan bool),
+	}
+}
+
+// Returns the boundary at which the PVC should next be mined, and whether it is due at the given time.
+func (m *Miner) Due(pvc *PVC, now uint64) (uint64, bool) {
+	boundary := Boundary(now, pvc.Period)
+	if pvc.Channel.Head == nil || pvc.Channel.Timestamp < boundary {
+		return boundary, true
+	}
+	return boundary + uint64(pvc.Period.Nanoseconds()), false
+}
+
+// Mines each PVC that is due at the given time, shortest period first so longer periods link the new heads, and returns the tokens minted.
+func (m *Miner) MineDue(now uint64) (uint64, error) {
+	var minted uint64
+	for _, pvc := range m.PVCs {
+		boundary, due := m.Due(pvc, now)
+		if !due {
+			continue
+		}
+		amount, err := m.Mine(pvc, boundary)
+		if err != nil {
+			return minted, err
+		}
+		minted += amount
+	}
+	return minted, nil
+}
+
+// Mines a block into the PVC with the given timestamp, pushes it to peers, and returns the tokens it minted according to the ledger.
+func (m *Miner) Mine(pvc *PVC, timestamp uint64) (uint64, error) {
+	if err := bcgo.NewValidator(pvc.Channel, pvc.Period).Update(m.Node, pvc.Threshold, m.Listener, timestamp); err != nil {
+		return 0, err
+	}
+	if m.Node.Network != nil {
+		if err := pvc.Channel.Push(m.Node.Cache, m.Node.Network); err != nil {
+			log.Println(err)
+		}
+	}
+	if m.Ledger == nil {
+		return 0, nil
+	}
+	before := m.Ledger.Minted[m.Node.Alias]
+	if err := m.Ledger.Update(pvc.Channel.Name, pvc.Channel.Head); err != nil {
+		return 0, err
+	}
+	return m.Ledger.Minted[m.Node.Alias] - before, nil
+}
+
+// Returns the time until the next PVC is due.
+func (m *Miner) Next(now uint64) time.Duration {
+	var next uint64
+	for _, pvc := range m.PVCs {
+		boundary, due := m.Due(pvc, now)
+		if due {
+			return 0
+		}
+		if next == 0 || boundary < next {
+			next = boundary
+		}
+	}
+	return time.Duration(next - now)
+}
+
+// Mines each PVC as it becomes due until stopped, calling the callback with the tokens minted.
+// Errors are logged, and mining is retried after the Retry interval.
+func (m *Miner) Start(callback func(minted uint64)) {
+	if len(m.PVCs) == 0 {
+		return
+	}
+	for {
+		minted, err := m.MineDue(bcgo.Timestamp())
+		if minted > 0 && callback != nil {
+			callback(minted)
+		}
+		wait := m.Next(bcgo.Timestamp())
+		if err != nil {
+			log.Println(err)
+			// The failed PVC is still due, so wait before trying again
+			if wait < m.Retry {
+				wait = m.Retry
+			}
+		}
+		select {
+		case <-m.stop:
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (m *Miner) Stop() {
+	close(m.stop)
+}
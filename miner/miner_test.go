@@ -0,0 +1,205 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package miner_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/miner"
+	"github.com/AletheiaWareLLC/cryptogo"
+	"github.com/AletheiaWareLLC/testinggo"
+	"github.com/golang/protobuf/proto"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBoundary(t *testing.T) {
+	hour := uint64(time.Hour.Nanoseconds())
+	for name, test := range map[string]struct {
+		timestamp, expected uint64
+	}{
+		"Start":  {3 * hour, 3 * hour},
+		"Middle": {3*hour + 1, 3 * hour},
+		"End":    {4*hour - 1, 3 * hour},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if actual := miner.Boundary(test.timestamp, time.Hour); actual != test.expected {
+				t.Errorf("Wrong boundary; expected '%d', got '%d'", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSelectPVCs(t *testing.T) {
+	pvcs, err := miner.SelectPVCs([]string{"week", conveygo.CONVEY_HOUR})
+	testinggo.AssertNoError(t, err)
+	if len(pvcs) != 2 || pvcs[0].Channel.Name != conveygo.CONVEY_HOUR || pvcs[1].Channel.Name != conveygo.CONVEY_WEEK {
+		t.Errorf("Wrong PVCs; expected hour then week")
+	}
+	_, err = miner.SelectPVCs([]string{"fortnight"})
+	testinggo.AssertError(t, "Unknown periodic validation chain: fortnight", err)
+}
+
+func TestMiner(t *testing.T) {
+	alias := "Alice"
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	testinggo.AssertNoError(t, err)
+	node := &bcgo.Node{
+		Alias:    alias,
+		Key:      key,
+		Cache:    bcgo.NewMemoryCache(10),
+		Channels: make(map[string]*bcgo.Channel),
+	}
+
+	// Add a block to another channel for the PVC to link
+	conversations := bcgo.OpenPoWChannel(conveygo.CONVEY_CONVERSATION, bcgo.THRESHOLD_Z)
+	node.AddChannel(conversations)
+	_, record, err := bcgo.CreateRecord(bcgo.Timestamp(), alias, key, nil, nil, []byte{})
+	testinggo.AssertNoError(t, err)
+	_, err = bcgo.WriteRecord(conversations.Name, node.Cache, record)
+	testinggo.AssertNoError(t, err)
+	_, _, err = node.Mine(conversations, bcgo.THRESHOLD_Z, nil)
+	testinggo.AssertNoError(t, err)
+
+	// A low threshold stands in for the Convey-Hour threshold
+	hour := &miner.PVC{
+		Channel:   bcgo.OpenPoWChannel(conveygo.CONVEY_HOUR, bcgo.THRESHOLD_Z),
+		Period:    time.Hour,
+		Threshold: bcgo.THRESHOLD_Z,
+	}
+	node.AddChannel(hour.Channel)
+	m := miner.NewMiner(node, conveygo.NewLedger(node), nil, []*miner.PVC{hour})
+
+	now := bcgo.Timestamp()
+	t.Run("Due", func(t *testing.T) {
+		boundary, due := m.Due(hour, now)
+		if !due || boundary != miner.Boundary(now, time.Hour) {
+			t.Errorf("Expected empty PVC to be due at the current boundary")
+		}
+		if next := m.Next(now); next != 0 {
+			t.Errorf("Wrong next; expected '0', got '%s'", next)
+		}
+	})
+	t.Run("MineDue", func(t *testing.T) {
+		minted, err := m.MineDue(now)
+		testinggo.AssertNoError(t, err)
+		if minted != conveygo.HOURLY_PVC_REWARD {
+			t.Errorf("Wrong minted; expected '%d', got '%d'", conveygo.HOURLY_PVC_REWARD, minted)
+		}
+		if hour.Channel.Timestamp != miner.Boundary(now, time.Hour) {
+			t.Errorf("Wrong block timestamp; expected '%d', got '%d'", miner.Boundary(now, time.Hour), hour.Channel.Timestamp)
+		}
+	})
+	t.Run("NotDue", func(t *testing.T) {
+		minted, err := m.MineDue(now)
+		testinggo.AssertNoError(t, err)
+		if minted != 0 {
+			t.Errorf("Wrong minted; expected '0', got '%d'", minted)
+		}
+		expected := time.Duration(miner.Boundary(now, time.Hour) + uint64(time.Hour.Nanoseconds()) - now)
+		if next := m.Next(now); next != expected {
+			t.Errorf("Wrong next; expected '%s', got '%s'", expected, next)
+		}
+	})
+	t.Run("NextPeriod", func(t *testing.T) {
+		minted, err := m.MineDue(now + uint64(time.Hour.Nanoseconds()))
+		testinggo.AssertNoError(t, err)
+		if minted != conveygo.HOURLY_PVC_REWARD {
+			t.Errorf("Wrong minted; expected '%d', got '%d'", conveygo.HOURLY_PVC_REWARD, minted)
+		}
+		// The conversation block was mined before this boundary
+		block, err := bcgo.GetBlock(hour.Channel.Name, node.Cache, nil, hour.Channel.Head)
+		testinggo.AssertNoError(t, err)
+		var linked bool
+		for _, entry := range block.Entry {
+			reference := &bcgo.Reference{}
+			testinggo.AssertNoError(t, proto.Unmarshal(entry.Record.Payload, reference))
+			if reference.ChannelName == conversations.Name && string(reference.BlockHash) == string(conversations.Head) {
+				linked = true
+			}
+		}
+		if !linked {
+			t.Error("Expected next PVC block to link the conversation channel head")
+		}
+	})
+	t.Run("OpenMessageChannels", func(t *testing.T) {
+		testinggo.AssertNoError(t, miner.OpenMessageChannels(node))
+		hash, err := cryptogo.HashProtobuf(record)
+		testinggo.AssertNoError(t, err)
+		name := conveygo.CONVEY_PREFIX_MESSAGE + base64.RawURLEncoding.EncodeToString(hash)
+		if _, err := node.GetChannel(name); err != nil {
+			t.Errorf("Expected message channel '%s' to be open", name)
+		}
+	})
+}
+
+// Rejects every block, counting the attempts.
+type rejectingValidator struct {
+	attempts int32
+}
+
+func (v *rejectingValidator) Validate(channel *bcgo.Channel, cache bcgo.Cache, network bcgo.Network, hash []byte, block *bcgo.Block) error {
+	atomic.AddInt32(&v.attempts, 1)
+	return errors.New("Rejected")
+}
+
+func TestStart(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	testinggo.AssertNoError(t, err)
+	node := &bcgo.Node{
+		Alias:    "Alice",
+		Key:      key,
+		Cache:    bcgo.NewMemoryCache(10),
+		Channels: make(map[string]*bcgo.Channel),
+	}
+	validator := &rejectingValidator{}
+	hour := &miner.PVC{
+		Channel:   bcgo.OpenPoWChannel(conveygo.CONVEY_HOUR, bcgo.THRESHOLD_Z),
+		Period:    time.Hour,
+		Threshold: bcgo.THRESHOLD_Z,
+	}
+	hour.Channel.AddValidator(validator)
+	node.AddChannel(hour.Channel)
+	m := miner.NewMiner(node, nil, nil, []*miner.PVC{hour})
+	m.Retry = time.Millisecond
+
+	// Failures are retried rather than ending mining
+	done := make(chan bool)
+	go func() {
+		m.Start(nil)
+		close(done)
+	}()
+	deadline := time.Now().Add(10 * time.Second)
+	for atomic.LoadInt32(&validator.attempts) < 3 {
+		select {
+		case <-done:
+			t.Fatal("Expected mining to continue after an error")
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Wrong attempts; expected at least '3', got '%d'", atomic.LoadInt32(&validator.attempts))
+		}
+		time.Sleep(time.Millisecond)
+	}
+	m.Stop()
+	<-done
+}
@@ -0,0 +1,258 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notifications
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/events"
+	"log"
+	"sort"
+	"sync"
+)
+
+const (
+	ERROR_NO_SUCH_NOTIFICATION = "No such notification: %s"
+	ERROR_NO_SUCH_MESSAGE      = "No such message: %s"
+)
+
+// Notification tells an alias that a reply was made to one of their messages, or further down a thread they are part of.
+type Notification struct {
+	// Hash of the reply, which together with the Alias identifies the notification.
+	ID           string
+	Alias        string
+	Conversation string
+	// Hash of the alias's message that was replied to, directly or further down the thread.
+	Message string
+	// Alias that wrote the reply.
+	Replier   string
+	Timestamp uint64
+	// Number of replies between the alias's message and the reply; 1 for a direct reply.
+	Depth uint
+	// Tokens the alias earned from the reply.
+	Earned uint64
+	Read   bool
+}
+
+// Inbox holds the notifications of each alias, newest first.
+type Inbox struct {
+	Notifications map[string][]*Notification
+	lock          sync.Mutex
+}
+
+func NewInbox() *Inbox {
+	return &Inbox{
+		Notifications: make(map[string][]*Notification),
+	}
+}
+
+// Adds the notification unless the alias already has one with the same ID, returning true if it was added.
+func (i *Inbox) Add(n *Notification) bool {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	ns := i.Notifications[n.Alias]
+	for _, o := range ns {
+		if o.ID == n.ID {
+			return false
+		}
+	}
+	ns = append(ns, n)
+	sort.SliceStable(ns, func(a, b int) bool {
+		return ns[a].Timestamp > ns[b].Timestamp
+	})
+	i.Notifications[n.Alias] = ns
+	return true
+}
+
+// Returns copies of the alias's notifications, newest first, optionally only those that are unread.
+func (i *Inbox) Get(alias string, unread bool) []*Notification {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	var results []*Notification
+	for _, n := range i.Notifications[alias] {
+		if unread && n.Read {
+			continue
+		}
+		c := *n
+		results = append(results, &c)
+	}
+	return results
+}
+
+func (i *Inbox) Unread(alias string) int {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	var count int
+	for _, n := range i.Notifications[alias] {
+		if !n.Read {
+			count++
+		}
+	}
+	return count
+}
+
+func (i *Inbox) MarkRead(alias, id string) error {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	for _, n := range i.Notifications[alias] {
+		if n.ID == id {
+			n.Read = true
+			return nil
+		}
+	}
+	return errors.New(fmt.Sprintf(ERROR_NO_SUCH_NOTIFICATION, id))
+}
+
+func (i *Inbox) MarkAllRead(alias string) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	for _, n := range i.Notifications[alias] {
+		n.Read = true
+	}
+}
+
+// Sink delivers notifications outside of the inbox.
+type Sink interface {
+	Deliver(*Notification) error
+}
+
+// Notifier turns reply events into notifications for every author up the thread that earns from the reply.
+type Notifier struct {
+	Messages conveygo.MessageStore
+	Inbox    *Inbox
+	Sinks    []Sink
+}
+
+func NewNotifier(messages conveygo.MessageStore, inbox *Inbox, sinks ...Sink) *Notifier {
+	return &Notifier{
+		Messages: messages,
+		Inbox:    inbox,
+		Sinks:    sinks,
+	}
+}
+
+// Handles events from the subscription until it is closed.
+func (n *Notifier) Run(s *events.Subscription) {
+	for e := range s.Events {
+		if err := n.Notify(e); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// Subscribes to replies from the dispatcher and handles them in the background.
+func (n *Notifier) Listen(dispatcher *events.Dispatcher) *events.Subscription {
+	s := dispatcher.Subscribe(&events.Filter{
+		Types: []string{events.EVENT_NEW_REPLY},
+	}, events.DEFAULT_BUFFER)
+	go n.Run(s)
+	return s
+}
+
+type threadNode struct {
+	Author    string
+	Previous  string
+	Timestamp uint64
+	Cost      uint64
+}
+
+// Adds a notification to the inbox of each author earning from the reply in the event, and delivers new notifications to the sinks.
+// Other events are ignored.
+func (n *Notifier) Notify(e *events.Event) error {
+	if e.Type != events.EVENT_NEW_REPLY {
+		return nil
+	}
+	notifications, err := n.Notifications(e.Conversation, e.Message)
+	if err != nil {
+		return err
+	}
+	for _, notification := range notifications {
+		if !n.Inbox.Add(notification) {
+			continue // Already notified
+		}
+		for _, sink := range n.Sinks {
+			if err := sink.Deliver(notification); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+	return nil
+}
+
+// Returns a notification for each author earning from the given reply, following the ledger's rule that half of the remaining cost goes to the author of each message up the thread.
+// Authors are not notified of their own replies, and an author appearing more than once up the thread gets one notification at the nearest depth.
+func (n *Notifier) Notifications(conversation, reply string) ([]*Notification, error) {
+	conversationHash, err := base64.RawURLEncoding.DecodeString(conversation)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make(map[string]*threadNode)
+	if err := n.Messages.GetMessage(conversationHash, nil, func(hash []byte, timestamp uint64, author string, cost uint64, message *conveygo.Message) error {
+		node := &threadNode{
+			Author:    author,
+			Timestamp: timestamp,
+			Cost:      cost,
+		}
+		if len(message.Previous) > 0 {
+			node.Previous = base64.RawURLEncoding.EncodeToString(message.Previous)
+		}
+		nodes[base64.RawURLEncoding.EncodeToString(hash)] = node
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	r, ok := nodes[reply]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf(ERROR_NO_SUCH_MESSAGE, reply))
+	}
+
+	var results []*Notification
+	notified := make(map[string]*Notification)
+	cost := r.Cost
+	var depth uint
+	for prev := r.Previous; prev != ""; {
+		previous, ok := nodes[prev]
+		if !ok {
+			break
+		}
+		depth++
+		half := cost / 2
+		cost -= half
+		if previous.Author != r.Author {
+			if existing, ok := notified[previous.Author]; ok {
+				existing.Earned += half
+			} else {
+				notification := &Notification{
+					ID:           reply,
+					Alias:        previous.Author,
+					Conversation: conversation,
+					Message:      prev,
+					Replier:      r.Author,
+					Timestamp:    r.Timestamp,
+					Depth:        depth,
+					Earned:       half,
+				}
+				notified[previous.Author] = notification
+				results = append(results, notification)
+			}
+		}
+		prev = previous.Previous
+	}
+	return results, nil
+}
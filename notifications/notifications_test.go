@@ -0,0 +1,247 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notifications_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/events"
+	"github.com/AletheiaWareLLC/conveygo/notifications"
+	"github.com/AletheiaWareLLC/testinggo"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Creates a conversation started by Alice, replied to by Bob, replied to in turn by Carol.
+// Returns the store, the conversation hash, and the hashes of the three messages.
+func makeThread(t *testing.T) (*conveygo.MemoryStore, string, []string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	testinggo.AssertNoError(t, err)
+	store := conveygo.NewMemoryStore()
+	timestamp := bcgo.Timestamp()
+	conversationHash, conversationRecord, err := conveygo.ProtoToRecord("Alice", key, timestamp, &conveygo.Conversation{
+		Topic: "Test",
+	})
+	testinggo.AssertNoError(t, err)
+	messageHash, messageRecord, err := conveygo.ProtoToRecord("Alice", key, timestamp, &conveygo.Message{
+		Content: []byte("Hello"),
+		Type:    conveygo.MediaType_TEXT_PLAIN,
+	})
+	testinggo.AssertNoError(t, err)
+	testinggo.AssertNoError(t, store.NewConversation(conversationHash, conversationRecord, messageHash, messageRecord))
+	hashes := []string{base64.RawURLEncoding.EncodeToString(messageHash)}
+	previous := messageHash
+	for _, alias := range []string{"Bob", "Carol"} {
+		replyHash, replyRecord, err := conveygo.ProtoToRecord(alias, key, bcgo.Timestamp(), &conveygo.Message{
+			Previous: previous,
+			Content:  bytes.Repeat([]byte("x"), 400),
+			Type:     conveygo.MediaType_TEXT_PLAIN,
+		})
+		testinggo.AssertNoError(t, err)
+		testinggo.AssertNoError(t, store.AddMessage(conversationHash, replyHash, replyRecord))
+		hashes = append(hashes, base64.RawURLEncoding.EncodeToString(replyHash))
+		previous = replyHash
+	}
+	return store, base64.RawURLEncoding.EncodeToString(conversationHash), hashes
+}
+
+type recordingSink struct {
+	Delivered []*notifications.Notification
+}
+
+func (s *recordingSink) Deliver(n *notifications.Notification) error {
+	s.Delivered = append(s.Delivered, n)
+	return nil
+}
+
+func TestNotifier(t *testing.T) {
+	store, conversation, hashes := makeThread(t)
+	inbox := notifications.NewInbox()
+	sink := &recordingSink{}
+	notifier := notifications.NewNotifier(store, inbox, sink)
+
+	reply := &events.Event{
+		Type:         events.EVENT_NEW_REPLY,
+		Conversation: conversation,
+		Message:      hashes[2],
+		Previous:     hashes[1],
+		Author:       "Carol",
+	}
+	testinggo.AssertNoError(t, notifier.Notify(reply))
+
+	t.Run("DirectReply", func(t *testing.T) {
+		ns := inbox.Get("Bob", true)
+		if len(ns) != 1 {
+			t.Fatalf("Wrong number of notifications; expected '1', got '%d'", len(ns))
+		}
+		if ns[0].Depth != 1 || ns[0].Replier != "Carol" || ns[0].Message != hashes[1] {
+			t.Errorf("Wrong notification; expected direct reply from 'Carol', got '%v'", ns[0])
+		}
+	})
+	t.Run("ThreadReply", func(t *testing.T) {
+		ns := inbox.Get("Alice", true)
+		if len(ns) != 1 {
+			t.Fatalf("Wrong number of notifications; expected '1', got '%d'", len(ns))
+		}
+		if ns[0].Depth != 2 || ns[0].Message != hashes[0] {
+			t.Errorf("Wrong notification; expected reply 2 deep, got '%v'", ns[0])
+		}
+		// Alice earns half of what remains after Bob's half
+		bob := inbox.Get("Bob", false)[0]
+		if ns[0].Earned == 0 || ns[0].Earned > bob.Earned {
+			t.Errorf("Wrong earnings; expected Alice '%d' to be less than Bob '%d'", ns[0].Earned, bob.Earned)
+		}
+	})
+	t.Run("NoSelfNotification", func(t *testing.T) {
+		if n := inbox.Unread("Carol"); n != 0 {
+			t.Errorf("Wrong unread; expected '0', got '%d'", n)
+		}
+	})
+	t.Run("Duplicate", func(t *testing.T) {
+		testinggo.AssertNoError(t, notifier.Notify(reply))
+		if len(sink.Delivered) != 2 {
+			t.Errorf("Wrong number of deliveries; expected '2', got '%d'", len(sink.Delivered))
+		}
+	})
+	t.Run("OtherEvent", func(t *testing.T) {
+		testinggo.AssertNoError(t, notifier.Notify(&events.Event{
+			Type: events.EVENT_NEW_TAG,
+		}))
+	})
+	t.Run("NoSuchMessage", func(t *testing.T) {
+		testinggo.AssertError(t, "No such message: unknown", notifier.Notify(&events.Event{
+			Type:         events.EVENT_NEW_REPLY,
+			Conversation: conversation,
+			Message:      "unknown",
+		}))
+	})
+}
+
+func TestInbox(t *testing.T) {
+	inbox := notifications.NewInbox()
+	inbox.Add(&notifications.Notification{ID: "1", Alias: "Alice", Timestamp: 1})
+	inbox.Add(&notifications.Notification{ID: "2", Alias: "Alice", Timestamp: 2})
+	if inbox.Add(&notifications.Notification{ID: "2", Alias: "Alice", Timestamp: 2}) {
+		t.Error("Expected duplicate to be rejected")
+	}
+	ns := inbox.Get("Alice", false)
+	if len(ns) != 2 || ns[0].ID != "2" {
+		t.Fatalf("Wrong notifications; expected newest first, got '%v'", ns)
+	}
+	testinggo.AssertNoError(t, inbox.MarkRead("Alice", "2"))
+	if n := inbox.Unread("Alice"); n != 1 {
+		t.Errorf("Wrong unread; expected '1', got '%d'", n)
+	}
+	testinggo.AssertError(t, "No such notification: 3", inbox.MarkRead("Alice", "3"))
+	inbox.MarkAllRead("Alice")
+	if ns := inbox.Get("Alice", true); len(ns) != 0 {
+		t.Errorf("Wrong unread notifications; expected none, got '%d'", len(ns))
+	}
+}
+
+func TestSMTPSink(t *testing.T) {
+	server, err := notifications.NewLocalSMTPServer("localhost:0")
+	testinggo.AssertNoError(t, err)
+	defer server.Close()
+	sink := &notifications.SMTPSink{
+		Address: server.Address(),
+		From:    "convey@example.com",
+		Addresses: func(alias string) string {
+			if alias == "Alice" {
+				return "alice@example.com"
+			}
+			return ""
+		},
+	}
+	testinggo.AssertNoError(t, sink.Deliver(&notifications.Notification{
+		Alias:   "Alice",
+		Replier: "Bob",
+		Depth:   1,
+		Earned:  2,
+	}))
+	testinggo.AssertError(t, "No email address for alias: Bob", sink.Deliver(&notifications.Notification{
+		Alias: "Bob",
+	}))
+	emails := server.Received()
+	if len(emails) != 1 {
+		t.Fatalf("Wrong number of emails; expected '1', got '%d'", len(emails))
+	}
+	if emails[0].From != "convey@example.com" || len(emails[0].To) != 1 || emails[0].To[0] != "alice@example.com" {
+		t.Errorf("Wrong envelope; got '%s' to '%v'", emails[0].From, emails[0].To)
+	}
+	if !bytes.Contains(emails[0].Data, []byte("Subject: Convey: Bob replied in a conversation")) {
+		t.Errorf("Wrong email; got '%s'", emails[0].Data)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	received := make(chan *notifications.Notification, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := &notifications.Notification{}
+		if err := json.NewDecoder(r.Body).Decode(n); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		received <- n
+	}))
+	defer ts.Close()
+	sink := &notifications.WebhookSink{
+		URL: ts.URL,
+	}
+	testinggo.AssertNoError(t, sink.Deliver(&notifications.Notification{
+		ID:    "1",
+		Alias: "Alice",
+	}))
+	select {
+	case n := <-received:
+		if n.ID != "1" || n.Alias != "Alice" {
+			t.Errorf("Wrong notification; got '%v'", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for webhook")
+	}
+}
+
+func TestEventSink(t *testing.T) {
+	dispatcher := events.NewDispatcher(&bcgo.Node{
+		Channels: make(map[string]*bcgo.Channel),
+	}, nil)
+	s := dispatcher.Subscribe(&events.Filter{
+		Types: []string{events.EVENT_NOTIFICATION},
+	}, 1)
+	sink := &notifications.EventSink{
+		Dispatcher: dispatcher,
+	}
+	testinggo.AssertNoError(t, sink.Deliver(&notifications.Notification{
+		ID:      "1",
+		Alias:   "Alice",
+		Replier: "Bob",
+		Earned:  2,
+	}))
+	e := <-s.Events
+	if e.Alias != "Alice" || e.Author != "Bob" || e.Message != "1" || e.Change != 2 {
+		t.Errorf("Wrong event; got '%v'", e)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/conveygo/events"
+	"net/http"
+	"net/smtp"
+)
+
+const (
+	ERROR_NO_EMAIL_ADDRESS = "No email address for alias: %s"
+	ERROR_WEBHOOK_RESPONSE = "Webhook %s responded: %s"
+	NOTIFICATION_SUBJECT   = "Convey: %s replied in a conversation"
+	NOTIFICATION_TEXT      = "%s replied %s message %s in conversation %s, earning you %d tokens.\r\n"
+	NOTIFICATION_DIRECT    = "to your"
+	NOTIFICATION_INDIRECT  = "%d replies below your"
+	CONTENT_TYPE_JSON      = "application/json"
+)
+
+// SMTPSink emails notifications to the address of each alias.
+type SMTPSink struct {
+	// Host and port of the SMTP server.
+	Address string
+	Auth    smtp.Auth
+	From    string
+	// Returns the email address of the alias, or an empty string if they have none.
+	Addresses func(alias string) string
+}
+
+func (s *SMTPSink) Deliver(n *Notification) error {
+	to := s.Addresses(n.Alias)
+	if to == "" {
+		return errors.New(fmt.Sprintf(ERROR_NO_EMAIL_ADDRESS, n.Alias))
+	}
+	return smtp.SendMail(s.Address, s.Auth, s.From, []string{to}, FormatEmail(s.From, to, n))
+}
+
+// Returns the notification formatted as an email message.
+func FormatEmail(from, to string, n *Notification) []byte {
+	relation := NOTIFICATION_DIRECT
+	if n.Depth > 1 {
+		relation = fmt.Sprintf(NOTIFICATION_INDIRECT, n.Depth-1)
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: "+NOTIFICATION_SUBJECT+"\r\n", n.Replier)
+	fmt.Fprintf(&b, "\r\n")
+	fmt.Fprintf(&b, NOTIFICATION_TEXT, n.Replier, relation, n.Message, n.Conversation, n.Earned)
+	return b.Bytes()
+}
+
+// WebhookSink posts each notification as JSON to a URL.
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s *WebhookSink) Deliver(n *Notification) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Post(s.URL, CONTENT_TYPE_JSON, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return errors.New(fmt.Sprintf(ERROR_WEBHOOK_RESPONSE, s.URL, response.Status))
+	}
+	return nil
+}
+
+// EventSink publishes notifications to the event stream, with the notified alias as the event's Alias and the replier as its Author.
+type EventSink struct {
+	Dispatcher *events.Dispatcher
+}
+
+func (s *EventSink) Deliver(n *Notification) error {
+	s.Dispatcher.Publish(&events.Event{
+		Type:         events.EVENT_NOTIFICATION,
+		Conversation: n.Conversation,
+		Message:      n.ID,
+		Previous:     n.Message,
+		Author:       n.Replier,
+		Timestamp:    n.Timestamp,
+		Alias:        n.Alias,
+		Change:       int64(n.Earned),
+	})
+	return nil
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notifications
+
+import (
+	"bytes"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+type Email struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// LocalSMTPServer is a minimal SMTP server which accepts every email and keeps it in memory, standing in for a mail server during development and testing.
+type LocalSMTPServer struct {
+	Listener net.Listener
+	Emails   []*Email
+	lock     sync.Mutex
+}
+
+// Starts a server listening on the given address, such as "localhost:0" for any free port.
+func NewLocalSMTPServer(address string) (*LocalSMTPServer, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	s := &LocalSMTPServer{
+		Listener: listener,
+	}
+	go s.serve()
+	return s, nil
+}
+
+// Returns the address the server is listening on.
+func (s *LocalSMTPServer) Address() string {
+	return s.Listener.Addr().String()
+}
+
+func (s *LocalSMTPServer) Close() error {
+	return s.Listener.Close()
+}
+
+// Returns the emails received so far.
+func (s *LocalSMTPServer) Received() []*Email {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]*Email{}, s.Emails...)
+}
+
+func (s *LocalSMTPServer) serve() {
+	for {
+		conn, err := s.Listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *LocalSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	c := textproto.NewConn(conn)
+	c.PrintfLine("220 localhost Convey SMTP")
+	email := &Email{}
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "HELO"), strings.HasPrefix(command, "EHLO"):
+			c.PrintfLine("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			email.From = parsePath(line[len("MAIL FROM:"):])
+			c.PrintfLine("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			email.To = append(email.To, parsePath(line[len("RCPT TO:"):]))
+			c.PrintfLine("250 OK")
+		case command == "DATA":
+			c.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := c.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			email.Data = bytes.ReplaceAll(data, []byte("\n"), []byte("\r\n"))
+			s.lock.Lock()
+			s.Emails = append(s.Emails, email)
+			s.lock.Unlock()
+			email = &Email{}
+			c.PrintfLine("250 OK")
+		case command == "RSET":
+			email = &Email{}
+			c.PrintfLine("250 OK")
+		case command == "NOOP":
+			c.PrintfLine("250 OK")
+		case command == "QUIT":
+			c.PrintfLine("221 Bye")
+			return
+		default:
+			c.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+// Returns the address from a path such as "<alice@example.com> BODY=8BITMIME".
+func parsePath(path string) string {
+	path = strings.TrimSpace(path)
+	if i := strings.Index(path, ">"); i >= 0 {
+		path = path[:i]
+	}
+	return strings.TrimPrefix(path, "<")
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	PASSWORD_SALT_SIZE    = 16
+	PASSWORD_HASH_SIZE    = 32
+	PASSWORD_HASH_TIME    = 1
+	PASSWORD_HASH_MEMORY  = 64 * 1024 // KiB
+	PASSWORD_HASH_THREADS = 4
+)
+
+// PasswordHash is a salted Argon2id hash of a password, with the parameters used to derive it.
+type PasswordHash struct {
+	Salt    []byte
+	Hash    []byte
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+}
+
+// Hashes the password with a new random salt.
+func NewPasswordHash(password []byte) (*PasswordHash, error) {
+	salt := make([]byte, PASSWORD_SALT_SIZE)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return &PasswordHash{
+		Salt:    salt,
+		Hash:    argon2.IDKey(password, salt, PASSWORD_HASH_TIME, PASSWORD_HASH_MEMORY, PASSWORD_HASH_THREADS, PASSWORD_HASH_SIZE),
+		Time:    PASSWORD_HASH_TIME,
+		Memory:  PASSWORD_HASH_MEMORY,
+		Threads: PASSWORD_HASH_THREADS,
+	}, nil
+}
+
+// Returns true if the password hashes to the same value, compared in constant time.
+func (h *PasswordHash) Matches(password []byte) bool {
+	hash := argon2.IDKey(password, h.Salt, h.Time, h.Memory, h.Threads, uint32(len(h.Hash)))
+	return subtle.ConstantTimeCompare(hash, h.Hash) == 1
+}
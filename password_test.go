@@ -0,0 +1,44 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo_test
+
+import (
+	"bytes"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/testinggo"
+	"testing"
+)
+
+func TestPasswordHash(t *testing.T) {
+	password := []byte("password1234")
+	a, err := conveygo.NewPasswordHash(password)
+	testinggo.AssertNoError(t, err)
+	b, err := conveygo.NewPasswordHash(password)
+	testinggo.AssertNoError(t, err)
+	if !a.Matches(password) || !b.Matches(password) {
+		t.Error("Expected password to match")
+	}
+	if a.Matches([]byte("password5678")) {
+		t.Error("Expected wrong password not to match")
+	}
+	if bytes.Equal(a.Salt, b.Salt) || bytes.Equal(a.Hash, b.Hash) {
+		t.Error("Expected each hash to have its own salt")
+	}
+	if bytes.Contains(a.Hash, password) {
+		t.Error("Expected hash not to contain password")
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package graphics
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/pdfgo"
+	"github.com/AletheiaWareLLC/pdfgo/font"
+	pdfgraphics "github.com/AletheiaWareLLC/pdfgo/graphics"
+)
+
+type CoverBox struct {
+	pdfgraphics.Rectangle
+	Host    string
+	From    uint64
+	To      uint64
+	Fonts   map[string]font.Font
+	Palette *Palette
+	Logo    *LogoBox
+	Layout  pdfgraphics.Layout
+}
+
+func (b *CoverBox) SetBounds(bounds *pdfgraphics.Rectangle) error {
+	b.Left = bounds.Left
+	b.Top = bounds.Top
+	b.Right = bounds.Right
+	b.Bottom = bounds.Bottom
+
+	// Logo fills the top half
+	middle := bounds.Bottom + (bounds.GetHeight() / 2)
+	b.Logo = &LogoBox{
+		Colour: b.Palette.Primary,
+	}
+	if err := b.Logo.SetBounds(&pdfgraphics.Rectangle{
+		Left:   bounds.Left,
+		Top:    bounds.Top,
+		Right:  bounds.Right,
+		Bottom: middle,
+	}); err != nil {
+		return err
+	}
+
+	// Title, period, and host fill the bottom half
+	b.Layout = &pdfgraphics.ListLayout{
+		Direction: pdfgraphics.TopBottom,
+		Padding:   ENTRY_PADDING * 2,
+	}
+	b.Layout.Add(&pdfgraphics.TextBox{
+		Text:       []rune("Convey Digest"),
+		FontId:     "F1",
+		Font:       b.Fonts["F1"],
+		FontSize:   48,
+		FontColour: b.Palette.Primary,
+		Align:      pdfgraphics.Center,
+	})
+	b.Layout.Add(&pdfgraphics.TextBox{
+		Text:       []rune(PeriodToString(b.From, b.To)),
+		FontId:     "F2",
+		Font:       b.Fonts["F2"],
+		FontSize:   18,
+		FontColour: b.Palette.Secondary,
+		Align:      pdfgraphics.Center,
+	})
+	b.Layout.Add(&pdfgraphics.TextBox{
+		Text:       []rune(b.Host),
+		FontId:     "F3",
+		Font:       b.Fonts["F3"],
+		FontSize:   14,
+		FontColour: b.Palette.Text,
+		Align:      pdfgraphics.Center,
+	})
+	return b.Layout.SetBounds(&pdfgraphics.Rectangle{
+		Left:   bounds.Left,
+		Top:    middle - ENTRY_PADDING,
+		Right:  bounds.Right,
+		Bottom: bounds.Bottom,
+	})
+}
+
+func (b *CoverBox) Write(p *pdfgo.PDF, buffer *bytes.Buffer) error {
+	if err := b.Logo.Write(p, buffer); err != nil {
+		return err
+	}
+	for _, box := range b.Layout.(*pdfgraphics.ListLayout).Boxes {
+		buffer.WriteString("\n")
+		if err := box.Write(p, buffer); err != nil {
+			return err
+		}
+	}
+	buffer.WriteString("\n")
+	return nil
+}
+
+func PeriodToString(from, to uint64) string {
+	if from == 0 {
+		return fmt.Sprintf("Until %s", bcgo.TimestampToString(to))
+	}
+	return fmt.Sprintf("%s to %s", bcgo.TimestampToString(from), bcgo.TimestampToString(to))
+}
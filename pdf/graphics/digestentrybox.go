@@ -24,17 +24,28 @@ import (
 	"github.com/AletheiaWareLLC/pdfgo"
 	"github.com/AletheiaWareLLC/pdfgo/font"
 	pdfgraphics "github.com/AletheiaWareLLC/pdfgo/graphics"
+	"math"
 )
 
-const ENTRY_PADDING = 10
+const (
+	ENTRY_PADDING = 10
+	QR_CODE_SIZE  = 56 // Largest QR Code size in points, narrow entries use a third of their width
+)
 
 type DigestEntryBox struct {
 	pdfgraphics.Rectangle
-	Host   string
-	Level  int
-	Entry  *conveygo.DigestEntry
-	Fonts  map[string]font.Font
-	Layout pdfgraphics.Layout
+	Host          string
+	Entry         *conveygo.DigestEntry
+	Fonts         map[string]font.Font
+	Palette       *Palette
+	FontSizes     *FontSizes
+	Layout        pdfgraphics.Layout
+	Text          []rune // Content to layout, defaults to the Entry Message Content
+	Content       *ParagraphBox
+	ContinuedFrom int // Page on which the Entry began, or 0 if it begins in this box
+	ContinuedOn   int // Page on which the Entry continues, or 0 if it ends in this box
+	ShowQRCode    bool
+	QRCode        *QRCodeBox
 }
 
 func (b *DigestEntryBox) SetBounds(bounds *pdfgraphics.Rectangle) error {
@@ -46,54 +57,188 @@ func (b *DigestEntryBox) SetBounds(bounds *pdfgraphics.Rectangle) error {
 	t := b.Entry.Message.GetType()
 	switch t {
 	case conveygo.MediaType_TEXT_PLAIN:
+		if b.Text == nil {
+			b.Text = []rune(string(b.Entry.Message.Content))
+		}
 		b.Layout = &pdfgraphics.ListLayout{
 			Direction: pdfgraphics.TopBottom,
 			Padding:   ENTRY_PADDING,
 		}
-		b.Layout.Add(&pdfgraphics.TextBox{
-			Text:       []rune(b.Entry.Topic),
-			FontId:     "F1",
-			Font:       b.Fonts["F1"],
-			FontSize:   32 - (2 * float64(b.Level)),
-			FontColour: DARK_SKY_BLUE,
-			Align:      pdfgraphics.Center,
-		})
-		b.Layout.Add(&pdfgraphics.TextBox{
-			Text:       []rune(fmt.Sprintf("%s %s %d", b.Entry.Timestamp, b.Entry.Author, b.Entry.Yield)),
-			FontId:     "F2",
-			Font:       b.Fonts["F2"],
-			FontSize:   12 - float64(b.Level),
-			FontColour: LIGHT_SKY_BLUE,
-			Align:      pdfgraphics.Center,
-		})
-		b.Layout.Add(&pdfgraphics.TextBox{
-			Text:       []rune(string(b.Entry.Message.Content)),
+		if b.ContinuedFrom > 0 {
+			b.Layout.Add(&pdfgraphics.TextBox{
+				Text:       []rune(fmt.Sprintf("%s (continued from page %d)", b.Entry.Topic, b.ContinuedFrom)),
+				FontId:     "F1",
+				Font:       b.Fonts["F1"],
+				FontSize:   b.FontSizes.Meta,
+				FontColour: b.Palette.Primary,
+				Align:      pdfgraphics.Left,
+			})
+		} else {
+			b.Layout.Add(&pdfgraphics.TextBox{
+				Text:       []rune(b.Entry.Topic),
+				FontId:     "F1",
+				Font:       b.Fonts["F1"],
+				FontSize:   b.FontSizes.Topic,
+				FontColour: b.Palette.Primary,
+				Align:      pdfgraphics.Center,
+			})
+			b.Layout.Add(&pdfgraphics.TextBox{
+				Text:       []rune(fmt.Sprintf("%s %s %d", b.Entry.Timestamp, b.Entry.Author, b.Entry.Yield)),
+				FontId:     "F2",
+				Font:       b.Fonts["F2"],
+				FontSize:   b.FontSizes.Meta,
+				FontColour: b.Palette.Secondary,
+				Align:      pdfgraphics.Center,
+			})
+		}
+		b.Content = &ParagraphBox{
+			Text:       b.Text,
 			FontId:     "F3",
 			Font:       b.Fonts["F3"],
-			FontSize:   16 - float64(b.Level),
-			FontColour: BLACK,
+			FontSize:   b.FontSizes.Content,
+			FontColour: b.Palette.Text,
 			Align:      pdfgraphics.JustifiedLeft,
-		})
+		}
+		b.Layout.Add(b.Content)
 	default:
 		return errors.New(fmt.Sprintf(conveygo.ERROR_UNRECOGNIZED_MEDIA_TYPE, t))
 	}
-	b.Layout.SetBounds(&pdfgraphics.Rectangle{
+	b.QRCode = nil
+	if b.ShowQRCode && b.Entry.Hash != "" {
+		qr, err := NewQRCodeBox(b.getURL(), b.Palette.Text)
+		if err != nil {
+			return err
+		}
+		b.QRCode = qr
+	}
+	inner := &pdfgraphics.Rectangle{
 		Left:   bounds.Left + ENTRY_PADDING,
 		Top:    bounds.Top - ENTRY_PADDING,
 		Right:  bounds.Right - ENTRY_PADDING,
 		Bottom: bounds.Bottom + ENTRY_PADDING,
-	})
+	}
+	if footer := b.getFooterHeight(); footer > 0 {
+		// Reserve space at the bottom for the QR Code
+		inner.Bottom += footer + ENTRY_PADDING
+	}
+	if err := b.Layout.SetBounds(inner); err != nil {
+		return err
+	}
+	if b.Content.Overflow != nil {
+		// Reserve space at the bottom for the continuation marker
+		if err := b.Content.SetBounds(&pdfgraphics.Rectangle{
+			Left:   inner.Left,
+			Top:    inner.Top - b.getHeaderHeight(),
+			Right:  inner.Right,
+			Bottom: bounds.Bottom + ENTRY_PADDING + b.getFooterHeight() + ENTRY_PADDING,
+		}); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+func (b *DigestEntryBox) getURL() string {
+	return fmt.Sprintf("https://%s/conversation?hash=%s", b.Host, b.Entry.Hash)
+}
+
+// Returns the size of the QR Code, or 0 if the entry has no QR Code.
+func (b *DigestEntryBox) getQRCodeSize() float64 {
+	if b.QRCode == nil {
+		return 0
+	}
+	return math.Min(QR_CODE_SIZE, (b.GetWidth()-2*ENTRY_PADDING)/3)
+}
+
+// Returns the height of the boxes below the content, excluding padding.
+func (b *DigestEntryBox) getFooterHeight() float64 {
+	height := b.getQRCodeSize()
+	if b.Content.Overflow != nil {
+		height = math.Max(height, b.FontSizes.Meta)
+	}
+	return height
+}
+
+// Returns the height of the boxes above the content, including padding.
+func (b *DigestEntryBox) getHeaderHeight() float64 {
+	var height float64
+	for _, box := range b.Layout.(*pdfgraphics.ListLayout).Boxes {
+		if box == b.Content {
+			break
+		}
+		height += box.GetHeight() + ENTRY_PADDING
+	}
+	return height
+}
+
+// Returns the height actually occupied by the entry, which may be less than the bounds.
+func (b *DigestEntryBox) GetContentHeight() float64 {
+	height := ENTRY_PADDING + b.getHeaderHeight() + b.Content.GetHeight()
+	if footer := b.getFooterHeight(); footer > 0 {
+		height += ENTRY_PADDING + footer
+	}
+	return height + ENTRY_PADDING
+}
+
 func (b *DigestEntryBox) Write(p *pdfgo.PDF, buffer *bytes.Buffer) error {
 	if b.Entry.Hash != "" {
 		// Hyperlink
-		p.AddAnnotation(pdfgo.NewHyperlink(b.Left, b.Bottom, b.Right, b.Top, fmt.Sprintf("https://%s/conversation?hash=%s", b.Host, b.Entry.Hash)))
+		p.AddAnnotation(pdfgo.NewHyperlink(b.Left, b.Bottom, b.Right, b.Top, b.getURL()))
 	}
 
 	// Border
 	// buffer.WriteString(fmt.Sprintf("%s %s %s %s re S\n", FloatToString(b.Left), FloatToString(b.Bottom), FloatToString(b.GetWidth()), FloatToString(b.GetHeight())))
 
-	return b.Layout.Write(p, buffer)
+	if err := b.Layout.Write(p, buffer); err != nil {
+		return err
+	}
+
+	qrSize := b.getQRCodeSize()
+	if b.QRCode != nil {
+		// QR Code in the bottom right corner, positioned once the final bounds are known
+		if err := b.QRCode.SetBounds(&pdfgraphics.Rectangle{
+			Left:   b.Right - ENTRY_PADDING - qrSize,
+			Top:    b.Bottom + ENTRY_PADDING + qrSize,
+			Right:  b.Right - ENTRY_PADDING,
+			Bottom: b.Bottom + ENTRY_PADDING,
+		}); err != nil {
+			return err
+		}
+		buffer.WriteString("\n")
+		if err := b.QRCode.Write(p, buffer); err != nil {
+			return err
+		}
+	}
+
+	if b.Content.Overflow != nil && b.ContinuedOn > 0 {
+		// Continuation Marker
+		marker := &pdfgraphics.TextBox{
+			Text:       []rune(fmt.Sprintf("Continued on page %d", b.ContinuedOn)),
+			FontId:     "F2",
+			Font:       b.Fonts["F2"],
+			FontSize:   b.FontSizes.Meta,
+			FontColour: b.Palette.Secondary,
+			Align:      pdfgraphics.Right,
+		}
+		size := b.FontSizes.Meta
+		right := b.Right - ENTRY_PADDING
+		if qrSize > 0 {
+			// Left of the QR Code
+			right -= qrSize + ENTRY_PADDING
+		}
+		if err := marker.SetBounds(&pdfgraphics.Rectangle{
+			Left:   b.Left + ENTRY_PADDING,
+			Top:    b.Bottom + ENTRY_PADDING + size,
+			Right:  right,
+			Bottom: b.Bottom + ENTRY_PADDING,
+		}); err != nil {
+			return err
+		}
+		buffer.WriteString("\n")
+		if err := marker.Write(p, buffer); err != nil {
+			return err
+		}
+		buffer.WriteString("\n")
+	}
+	return nil
 }
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package graphics
+
+import (
+	"bytes"
+	"github.com/AletheiaWareLLC/pdfgo"
+	pdfgraphics "github.com/AletheiaWareLLC/pdfgo/graphics"
+)
+
+// GridLayout places boxes in equally sized cells, filling each row from left to right.
+type GridLayout struct {
+	pdfgraphics.Rectangle
+	Columns int
+	Rows    int
+	Boxes   []pdfgraphics.Box
+}
+
+func (l *GridLayout) Add(box pdfgraphics.Box) {
+	l.Boxes = append(l.Boxes, box)
+}
+
+func (l *GridLayout) SetBounds(bounds *pdfgraphics.Rectangle) error {
+	l.Left = bounds.Left
+	l.Top = bounds.Top
+	l.Right = bounds.Right
+	l.Bottom = bounds.Bottom
+	width := bounds.GetWidth() / float64(l.Columns)
+	height := bounds.GetHeight() / float64(l.Rows)
+	for i, b := range l.Boxes {
+		column := i % l.Columns
+		row := i / l.Columns
+		left := bounds.Left + float64(column)*width
+		top := bounds.Top - float64(row)*height
+		if err := b.SetBounds(&pdfgraphics.Rectangle{
+			Left:   left,
+			Top:    top,
+			Right:  left + width,
+			Bottom: top - height,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *GridLayout) Write(p *pdfgo.PDF, buffer *bytes.Buffer) error {
+	for _, b := range l.Boxes {
+		if err := b.Write(p, buffer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
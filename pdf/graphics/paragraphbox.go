@@ -0,0 +1,183 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package graphics
+
+import (
+	"bytes"
+	"github.com/AletheiaWareLLC/pdfgo"
+	"github.com/AletheiaWareLLC/pdfgo/font"
+	pdfgraphics "github.com/AletheiaWareLLC/pdfgo/graphics"
+	"unicode"
+)
+
+type WrappedLine struct {
+	Text []rune
+	Next int // Index of the first rune after this line
+}
+
+// Splits text into lines no wider than width, breaking at whitespace where possible.
+func WrapText(f font.Font, fontSize float64, text []rune, width float64) []*WrappedLine {
+	var lines []*WrappedLine
+	start := 0
+	wrappoint := -1
+	end := 0
+	for end < len(text) {
+		c := text[end]
+		if c == '\r' {
+			// Carriage Return is not rendered
+			end++
+			continue
+		}
+		if c == '\n' {
+			lines = append(lines, &WrappedLine{
+				Text: trimCarriageReturns(text[start:end]),
+				Next: end + 1,
+			})
+			end++
+			start = end
+			wrappoint = -1
+			continue
+		}
+		if unicode.IsSpace(c) {
+			wrappoint = end
+		}
+		if f.MeasureText(text[start:end+1], fontSize) > width {
+			if wrappoint > start {
+				// Break at wrap point
+				lines = append(lines, &WrappedLine{
+					Text: text[start:wrappoint],
+					Next: wrappoint + 1,
+				})
+				end = wrappoint + 1
+			} else {
+				// Hard break, ensuring at least one rune per line
+				if end == start {
+					end++
+				}
+				lines = append(lines, &WrappedLine{
+					Text: text[start:end],
+					Next: end,
+				})
+			}
+			start = end
+			wrappoint = -1
+			continue
+		}
+		end++
+	}
+	if end > start {
+		lines = append(lines, &WrappedLine{
+			Text: trimCarriageReturns(text[start:end]),
+			Next: end,
+		})
+	}
+	return lines
+}
+
+func trimCarriageReturns(text []rune) []rune {
+	for len(text) > 0 && text[len(text)-1] == '\r' {
+		text = text[:len(text)-1]
+	}
+	return text
+}
+
+// ParagraphBox lays out as much text as fits within its bounds and keeps the remainder as Overflow.
+type ParagraphBox struct {
+	Text       []rune
+	FontId     string
+	Font       font.Font
+	FontSize   float64
+	FontColour []float64
+	Align      pdfgraphics.Alignment
+	TextBox    *pdfgraphics.TextBox
+	Overflow   []rune
+}
+
+func (b *ParagraphBox) GetWidth() float64 {
+	if b.TextBox == nil {
+		return 0
+	}
+	return b.TextBox.GetWidth()
+}
+
+func (b *ParagraphBox) GetHeight() float64 {
+	if b.TextBox == nil {
+		return 0
+	}
+	return b.TextBox.GetHeight()
+}
+
+func (b *ParagraphBox) GetLineCount() int {
+	if b.TextBox == nil {
+		return 0
+	}
+	return len(b.TextBox.Lines)
+}
+
+func (b *ParagraphBox) SetBounds(bounds *pdfgraphics.Rectangle) error {
+	b.TextBox = &pdfgraphics.TextBox{
+		Text:       b.Text,
+		FontId:     b.FontId,
+		Font:       b.Font,
+		FontSize:   b.FontSize,
+		FontColour: b.FontColour,
+		Align:      b.Align,
+		OriginX:    bounds.Left,
+		OriginY:    bounds.Top - b.FontSize,
+		Width:      bounds.GetWidth(),
+	}
+	b.Overflow = nil
+	lines := WrapText(b.Font, b.FontSize, b.Text, bounds.GetWidth())
+	count := int(bounds.GetHeight() / b.FontSize)
+	if count < 0 {
+		count = 0
+	}
+	if count < len(lines) {
+		next := 0
+		if count > 0 {
+			next = lines[count-1].Next
+		}
+		b.Overflow = trimLeadingSpace(b.Text[next:])
+		lines = lines[:count]
+	}
+	for _, l := range lines {
+		b.TextBox.AddLine(l.Text)
+	}
+	return nil
+}
+
+func trimLeadingSpace(text []rune) []rune {
+	for len(text) > 0 && unicode.IsSpace(text[0]) {
+		text = text[1:]
+	}
+	if len(text) == 0 {
+		return nil
+	}
+	return text
+}
+
+func (b *ParagraphBox) Write(p *pdfgo.PDF, buffer *bytes.Buffer) error {
+	if b.GetLineCount() == 0 {
+		return nil
+	}
+	buffer.WriteString("\n")
+	if err := b.TextBox.Write(p, buffer); err != nil {
+		return err
+	}
+	buffer.WriteString("\n")
+	return nil
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package graphics_test
+
+import (
+	"github.com/AletheiaWareLLC/conveygo/pdf/graphics"
+	"github.com/AletheiaWareLLC/pdfgo"
+	pdfgraphics "github.com/AletheiaWareLLC/pdfgo/graphics"
+	"github.com/AletheiaWareLLC/testinggo"
+	"testing"
+)
+
+// Measures every rune as one point wide per point of font size.
+type fixedFont struct{}
+
+func (f *fixedFont) GetReference() *pdfgo.ObjectReference {
+	return nil
+}
+
+func (f *fixedFont) MeasureText(text []rune, fontSize float64) float64 {
+	return float64(len(text)) * fontSize
+}
+
+func TestWrapText(t *testing.T) {
+	for name, test := range map[string]struct {
+		text  string
+		width float64
+		lines []string
+	}{
+		"Empty":               {"", 10, nil},
+		"Fits":                {"Hello", 10, []string{"Hello"}},
+		"ExactWidth":          {"HelloWorld", 10, []string{"HelloWorld"}},
+		"BreakAtSpace":        {"Hello World Foo", 11, []string{"Hello World", "Foo"}},
+		"BreakAtLastSpace":    {"aa bb cc dd", 8, []string{"aa bb cc", "dd"}},
+		"LongWord":            {"abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		"LongWordAfterSpace":  {"ab cdefghij", 4, []string{"ab", "cdef", "ghij"}},
+		"NarrowerThanRune":    {"abc", 0.5, []string{"a", "b", "c"}},
+		"Newline":             {"Hello\nWorld", 10, []string{"Hello", "World"}},
+		"CarriageReturn":      {"Hello\r\nWorld\r", 10, []string{"Hello", "World"}},
+		"BlankLine":           {"Hello\n\nWorld", 10, []string{"Hello", "", "World"}},
+		"TrailingNewline":     {"Hello\n", 10, []string{"Hello"}},
+		"OnlyNewlines":        {"\n\n", 10, []string{"", ""}},
+		"NewlineAfterWrap":    {"Hello World\nFoo", 5, []string{"Hello", "World", "Foo"}},
+		"NewlineResetsBreaks": {"a b\ncdefgh", 4, []string{"a b", "cdef", "gh"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			lines := graphics.WrapText(&fixedFont{}, 1, []rune(test.text), test.width)
+			if len(lines) != len(test.lines) {
+				t.Fatalf("Wrong number of lines; expected '%d' %q, got '%d'", len(test.lines), test.lines, len(lines))
+			}
+			for i, l := range lines {
+				if string(l.Text) != test.lines[i] {
+					t.Errorf("Wrong line %d; expected '%s', got '%s'", i, test.lines[i], string(l.Text))
+				}
+			}
+		})
+	}
+	t.Run("Next", func(t *testing.T) {
+		text := []rune("Hello World\nFoo")
+		lines := graphics.WrapText(&fixedFont{}, 1, text, 5)
+		// Each line resumes after the space or newline it was broken at
+		for i, expected := range []int{6, 12, 15} {
+			if lines[i].Next != expected {
+				t.Errorf("Wrong next of line %d; expected '%d', got '%d'", i, expected, lines[i].Next)
+			}
+		}
+	})
+}
+
+func TestParagraphBox(t *testing.T) {
+	newBox := func(text string) *graphics.ParagraphBox {
+		return &graphics.ParagraphBox{
+			Text:     []rune(text),
+			FontId:   "F1",
+			Font:     &fixedFont{},
+			FontSize: 1,
+		}
+	}
+	bounds := func(width, height float64) *pdfgraphics.Rectangle {
+		return &pdfgraphics.Rectangle{
+			Left:   0,
+			Top:    height,
+			Right:  width,
+			Bottom: 0,
+		}
+	}
+	t.Run("Fits", func(t *testing.T) {
+		box := newBox("Hello World")
+		testinggo.AssertNoError(t, box.SetBounds(bounds(5, 2)))
+		if box.GetLineCount() != 2 || box.Overflow != nil {
+			t.Errorf("Wrong layout; expected '2' lines without overflow, got '%d' lines and '%s'", box.GetLineCount(), string(box.Overflow))
+		}
+	})
+	t.Run("Overflow", func(t *testing.T) {
+		box := newBox("Hello World Foo Bar")
+		testinggo.AssertNoError(t, box.SetBounds(bounds(5, 2)))
+		if box.GetLineCount() != 2 {
+			t.Errorf("Wrong number of lines; expected '2', got '%d'", box.GetLineCount())
+		}
+		// The overflow starts at the next word, without the leading space
+		if string(box.Overflow) != "Foo Bar" {
+			t.Errorf("Wrong overflow; expected 'Foo Bar', got '%s'", string(box.Overflow))
+		}
+	})
+	t.Run("OverflowAfterNewline", func(t *testing.T) {
+		box := newBox("Hello\n\n  World")
+		testinggo.AssertNoError(t, box.SetBounds(bounds(5, 1)))
+		if string(box.Overflow) != "World" {
+			t.Errorf("Wrong overflow; expected 'World', got '%s'", string(box.Overflow))
+		}
+	})
+	t.Run("NoRoom", func(t *testing.T) {
+		box := newBox("Hello")
+		testinggo.AssertNoError(t, box.SetBounds(bounds(5, 0.5)))
+		if box.GetLineCount() != 0 || string(box.Overflow) != "Hello" {
+			t.Errorf("Wrong layout; expected all text to overflow, got '%d' lines and '%s'", box.GetLineCount(), string(box.Overflow))
+		}
+	})
+	t.Run("Empty", func(t *testing.T) {
+		box := newBox("")
+		testinggo.AssertNoError(t, box.SetBounds(bounds(5, 2)))
+		if box.GetLineCount() != 0 || box.Overflow != nil {
+			t.Errorf("Wrong layout; expected no lines nor overflow, got '%d' lines and '%s'", box.GetLineCount(), string(box.Overflow))
+		}
+	})
+	t.Run("Resize", func(t *testing.T) {
+		// Laying out again in larger bounds clears the overflow
+		box := newBox("Hello World")
+		testinggo.AssertNoError(t, box.SetBounds(bounds(5, 1)))
+		testinggo.AssertNoError(t, box.SetBounds(bounds(11, 1)))
+		if box.GetLineCount() != 1 || box.Overflow != nil {
+			t.Errorf("Wrong layout; expected '1' line without overflow, got '%d' lines and '%s'", box.GetLineCount(), string(box.Overflow))
+		}
+	})
+}
@@ -0,0 +1,527 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package graphics
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	QR_MIN_VERSION = 1
+	QR_MAX_VERSION = 40
+
+	ERROR_QR_DATA_TOO_LONG = "Data too long for QR Code: %d bytes"
+)
+
+type QRErrorCorrection int
+
+const (
+	QRErrorCorrectionLow QRErrorCorrection = iota
+	QRErrorCorrectionMedium
+	QRErrorCorrectionQuartile
+	QRErrorCorrectionHigh
+)
+
+// Format bits identifying each error correction level.
+var qrFormatBits = []int{1, 0, 3, 2}
+
+// Error correction codewords per block, indexed by level then version.
+var qrECCCodewordsPerBlock = [][]int{
+	{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+// Error correction blocks, indexed by level then version.
+var qrECCBlocks = [][]int{
+	{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+// QRCode holds the modules of an encoded QR Code, true modules are dark.
+type QRCode struct {
+	Version    int
+	Size       int
+	Level      QRErrorCorrection
+	Mask       int
+	Modules    [][]bool
+	isFunction [][]bool
+}
+
+// Encodes the given data in byte mode using the smallest version that fits, and the mask with the lowest penalty.
+func EncodeQRCode(data []byte, level QRErrorCorrection) (*QRCode, error) {
+	for version := QR_MIN_VERSION; version <= QR_MAX_VERSION; version++ {
+		if qrCountBits(version, len(data)) <= qrNumDataCodewords(version, level)*8 {
+			return encodeQRCode(data, level, version, -1), nil
+		}
+	}
+	return nil, errors.New(fmt.Sprintf(ERROR_QR_DATA_TOO_LONG, len(data)))
+}
+
+// Returns the number of bits needed to encode length bytes in byte mode.
+func qrCountBits(version, length int) int {
+	count := 8
+	if version > 9 {
+		count = 16
+	}
+	return 4 + count + length*8
+}
+
+func encodeQRCode(data []byte, level QRErrorCorrection, version, mask int) *QRCode {
+	capacity := qrNumDataCodewords(version, level) * 8
+
+	// Byte Mode Segment
+	var bits qrBitBuffer
+	bits.append(0x4, 4)
+	if version > 9 {
+		bits.append(len(data), 16)
+	} else {
+		bits.append(len(data), 8)
+	}
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	// Terminator and padding to a byte boundary
+	terminator := capacity - len(bits)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	bits.append(0, (8-len(bits)%8)%8)
+
+	// Alternating pad bytes fill the remaining capacity
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	codewords := make([]byte, len(bits)/8)
+	for i, b := range bits {
+		if b {
+			codewords[i>>3] |= 1 << uint(7-(i&7))
+		}
+	}
+
+	size := version*4 + 17
+	q := &QRCode{
+		Version:    version,
+		Size:       size,
+		Level:      level,
+		Modules:    make([][]bool, size),
+		isFunction: make([][]bool, size),
+	}
+	for i := 0; i < size; i++ {
+		q.Modules[i] = make([]bool, size)
+		q.isFunction[i] = make([]bool, size)
+	}
+	q.drawFunctionPatterns()
+	q.drawCodewords(q.addErrorCorrection(codewords))
+
+	if mask < 0 {
+		// Choose the mask with the lowest penalty
+		lowest := -1
+		for m := 0; m < 8; m++ {
+			q.applyMask(m)
+			q.drawFormatBits(m)
+			penalty := q.penalty()
+			if lowest < 0 || penalty < lowest {
+				mask = m
+				lowest = penalty
+			}
+			q.applyMask(m) // Masks are their own inverse
+		}
+	}
+	q.Mask = mask
+	q.applyMask(mask)
+	q.drawFormatBits(mask)
+	q.isFunction = nil
+	return q
+}
+
+func (q *QRCode) set(x, y int, dark bool) {
+	q.Modules[y][x] = dark
+	q.isFunction[y][x] = true
+}
+
+func (q *QRCode) drawFunctionPatterns() {
+	// Timing Patterns
+	for i := 0; i < q.Size; i++ {
+		q.set(6, i, i%2 == 0)
+		q.set(i, 6, i%2 == 0)
+	}
+
+	// Finder Patterns
+	q.drawFinderPattern(3, 3)
+	q.drawFinderPattern(q.Size-4, 3)
+	q.drawFinderPattern(3, q.Size-4)
+
+	// Alignment Patterns, except where they would overlap the finder patterns
+	positions := q.alignmentPositions()
+	n := len(positions)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if (i == 0 && j == 0) || (i == 0 && j == n-1) || (i == n-1 && j == 0) {
+				continue
+			}
+			q.drawAlignmentPattern(positions[i], positions[j])
+		}
+	}
+
+	// Reserve format bits, they are drawn once the mask is known
+	q.drawFormatBits(0)
+	q.drawVersionBits()
+}
+
+func (q *QRCode) drawFinderPattern(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx := x + dx
+			yy := y + dy
+			if xx >= 0 && xx < q.Size && yy >= 0 && yy < q.Size {
+				distance := qrMax(qrAbs(dx), qrAbs(dy))
+				q.set(xx, yy, distance != 2 && distance != 4)
+			}
+		}
+	}
+}
+
+func (q *QRCode) drawAlignmentPattern(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			q.set(x+dx, y+dy, qrMax(qrAbs(dx), qrAbs(dy)) != 1)
+		}
+	}
+}
+
+func (q *QRCode) alignmentPositions() []int {
+	if q.Version == 1 {
+		return nil
+	}
+	count := q.Version/7 + 2
+	step := 26
+	if q.Version != 32 {
+		step = (q.Version*4 + count*2 + 1) / (count*2 - 2) * 2
+	}
+	positions := make([]int, count)
+	positions[0] = 6
+	for i, pos := count-1, q.Size-7; i > 0; i, pos = i-1, pos-step {
+		positions[i] = pos
+	}
+	return positions
+}
+
+func (q *QRCode) drawFormatBits(mask int) {
+	data := qrFormatBits[q.Level]<<3 | mask
+	remainder := data
+	for i := 0; i < 10; i++ {
+		remainder = (remainder << 1) ^ ((remainder >> 9) * 0x537)
+	}
+	bits := (data<<10 | remainder) ^ 0x5412
+
+	// First copy around the top left finder
+	for i := 0; i <= 5; i++ {
+		q.set(8, i, qrBit(bits, i))
+	}
+	q.set(8, 7, qrBit(bits, 6))
+	q.set(8, 8, qrBit(bits, 7))
+	q.set(7, 8, qrBit(bits, 8))
+	for i := 9; i < 15; i++ {
+		q.set(14-i, 8, qrBit(bits, i))
+	}
+
+	// Second copy split between the top right and bottom left finders
+	for i := 0; i < 8; i++ {
+		q.set(q.Size-1-i, 8, qrBit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		q.set(8, q.Size-15+i, qrBit(bits, i))
+	}
+	q.set(8, q.Size-8, true) // Always dark
+}
+
+func (q *QRCode) drawVersionBits() {
+	if q.Version < 7 {
+		return
+	}
+	remainder := q.Version
+	for i := 0; i < 12; i++ {
+		remainder = (remainder << 1) ^ ((remainder >> 11) * 0x1F25)
+	}
+	bits := q.Version<<12 | remainder
+	for i := 0; i < 18; i++ {
+		bit := qrBit(bits, i)
+		a := q.Size - 11 + i%3
+		b := i / 3
+		q.set(a, b, bit)
+		q.set(b, a, bit)
+	}
+}
+
+// Splits the data into blocks, appends the Reed-Solomon codewords of each, and interleaves the results.
+func (q *QRCode) addErrorCorrection(data []byte) []byte {
+	blocks := qrECCBlocks[q.Level][q.Version]
+	eccLength := qrECCCodewordsPerBlock[q.Level][q.Version]
+	raw := qrNumRawDataModules(q.Version) / 8
+	shortBlocks := blocks - raw%blocks
+	shortLength := raw / blocks
+
+	divisor := qrReedSolomonDivisor(eccLength)
+	var bs [][]byte
+	for i, k := 0, 0; i < blocks; i++ {
+		length := shortLength - eccLength
+		if i >= shortBlocks {
+			length++
+		}
+		block := make([]byte, 0, shortLength+1)
+		block = append(block, data[k:k+length]...)
+		k += length
+		ecc := qrReedSolomonRemainder(block, divisor)
+		if i < shortBlocks {
+			block = append(block, 0) // Placeholder, skipped when interleaving
+		}
+		bs = append(bs, append(block, ecc...))
+	}
+
+	var result []byte
+	for i := 0; i < len(bs[0]); i++ {
+		for j, b := range bs {
+			if i != shortLength-eccLength || j >= shortBlocks {
+				result = append(result, b[i])
+			}
+		}
+	}
+	return result
+}
+
+// Places the codewords in a zigzag through the modules not used by function patterns.
+func (q *QRCode) drawCodewords(data []byte) {
+	i := 0
+	for right := q.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // Skip the vertical timing pattern
+		}
+		for vertical := 0; vertical < q.Size; vertical++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				upward := (right+1)&2 == 0
+				y := vertical
+				if upward {
+					y = q.Size - 1 - vertical
+				}
+				if !q.isFunction[y][x] && i < len(data)*8 {
+					q.Modules[y][x] = qrBit(int(data[i>>3]), 7-(i&7))
+					i++
+				}
+			}
+		}
+	}
+}
+
+func (q *QRCode) applyMask(mask int) {
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !q.isFunction[y][x] {
+				q.Modules[y][x] = !q.Modules[y][x]
+			}
+		}
+	}
+}
+
+// Scores the modules against the four penalty rules, lower is easier to scan.
+func (q *QRCode) penalty() int {
+	var result int
+	get := func(x, y int, vertical bool) bool {
+		if vertical {
+			return q.Modules[x][y]
+		}
+		return q.Modules[y][x]
+	}
+	for _, vertical := range []bool{false, true} {
+		for y := 0; y < q.Size; y++ {
+			// Runs of five or more modules of the same colour
+			run := 0
+			for x := 0; x < q.Size; x++ {
+				if x > 0 && get(x, y, vertical) == get(x-1, y, vertical) {
+					run++
+					if run == 5 {
+						result += 3
+					} else if run > 5 {
+						result++
+					}
+				} else {
+					run = 1
+				}
+			}
+			// Patterns resembling a finder, dark-light-dark-dark-dark-light-dark with four light modules either side
+			for x := 0; x+10 < q.Size; x++ {
+				pattern := true
+				for i, dark := range []bool{true, false, true, true, true, false, true} {
+					if get(x+i, y, vertical) != dark {
+						pattern = false
+						break
+					}
+				}
+				if !pattern {
+					continue
+				}
+				before := true
+				after := true
+				for i := 1; i <= 4; i++ {
+					if x-i >= 0 && get(x-i, y, vertical) {
+						before = false
+					}
+					if x+6+i < q.Size && get(x+6+i, y, vertical) {
+						after = false
+					}
+				}
+				if before {
+					result += 40
+				}
+				if after {
+					result += 40
+				}
+			}
+		}
+	}
+	// Blocks of 2x2 modules of the same colour
+	for y := 0; y < q.Size-1; y++ {
+		for x := 0; x < q.Size-1; x++ {
+			c := q.Modules[y][x]
+			if c == q.Modules[y][x+1] && c == q.Modules[y+1][x] && c == q.Modules[y+1][x+1] {
+				result += 3
+			}
+		}
+	}
+	// Imbalance of dark and light modules
+	var dark int
+	for _, row := range q.Modules {
+		for _, m := range row {
+			if m {
+				dark++
+			}
+		}
+	}
+	total := q.Size * q.Size
+	k := (qrAbs(dark*20-total*10)+total-1)/total - 1
+	result += k * 10
+	return result
+}
+
+// Returns the number of modules available for data and error correction, excluding function patterns.
+func qrNumRawDataModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		count := version/7 + 2
+		result -= (25*count-10)*count - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+	return result
+}
+
+func qrNumDataCodewords(version int, level QRErrorCorrection) int {
+	return qrNumRawDataModules(version)/8 - qrECCCodewordsPerBlock[level][version]*qrECCBlocks[level][version]
+}
+
+func qrReedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := 0; j < degree; j++ {
+			result[j] = qrMultiply(result[j], root)
+			if j+1 < degree {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = qrMultiply(root, 0x02)
+	}
+	return result
+}
+
+func qrReedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, d := range divisor {
+			result[i] ^= qrMultiply(d, factor)
+		}
+	}
+	return result
+}
+
+// Multiplies two elements of GF(2^8) modulo the polynomial 0x11D.
+func qrMultiply(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>uint(i))&1) * int(x)
+	}
+	return byte(z)
+}
+
+type qrBitBuffer []bool
+
+func (b *qrBitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*b = append(*b, (value>>uint(i))&1 != 0)
+	}
+}
+
+func qrBit(x, i int) bool {
+	return (x>>uint(i))&1 != 0
+}
+
+func qrAbs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func qrMax(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
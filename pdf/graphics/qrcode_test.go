@@ -0,0 +1,78 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package graphics_test
+
+import (
+	"fmt"
+	"github.com/AletheiaWareLLC/conveygo/pdf/graphics"
+	"github.com/AletheiaWareLLC/testinggo"
+	"strings"
+	"testing"
+)
+
+func TestEncodeQRCode(t *testing.T) {
+	t.Run("Version", func(t *testing.T) {
+		for _, test := range []struct {
+			length  int
+			version int
+		}{
+			{1, 1},
+			{14, 1},
+			{15, 2},
+			{138, 8},
+			{2331, 40},
+		} {
+			t.Run(fmt.Sprint(test.length), func(t *testing.T) {
+				code, err := graphics.EncodeQRCode([]byte(strings.Repeat("a", test.length)), graphics.QRErrorCorrectionMedium)
+				testinggo.AssertNoError(t, err)
+				if code.Version != test.version {
+					t.Errorf("Wrong version; expected '%d', got '%d'", test.version, code.Version)
+				}
+				if size := test.version*4 + 17; code.Size != size || len(code.Modules) != size {
+					t.Errorf("Wrong size; expected '%d', got '%d'", size, code.Size)
+				}
+			})
+		}
+	})
+	t.Run("FinderPatterns", func(t *testing.T) {
+		code, err := graphics.EncodeQRCode([]byte("https://convey.aletheiaware.com/conversation?hash=abc"), graphics.QRErrorCorrectionMedium)
+		testinggo.AssertNoError(t, err)
+		expected := []string{
+			"#######.",
+			"#.....#.",
+			"#.###.#.",
+			"#.###.#.",
+			"#.###.#.",
+			"#.....#.",
+			"#######.",
+			"........",
+		}
+		for y, row := range expected {
+			for x, c := range row {
+				dark := c == '#'
+				// Top Left, Top Right, and Bottom Left
+				if code.Modules[y][x] != dark || code.Modules[y][code.Size-1-x] != dark || code.Modules[code.Size-1-y][x] != dark {
+					t.Fatalf("Wrong finder pattern module at %d,%d", x, y)
+				}
+			}
+		}
+	})
+	t.Run("TooLong", func(t *testing.T) {
+		_, err := graphics.EncodeQRCode([]byte(strings.Repeat("a", 2332)), graphics.QRErrorCorrectionMedium)
+		testinggo.AssertError(t, "Data too long for QR Code: 2332 bytes", err)
+	})
+}
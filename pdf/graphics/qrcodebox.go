@@ -0,0 +1,89 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package graphics
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/AletheiaWareLLC/pdfgo"
+	pdfgraphics "github.com/AletheiaWareLLC/pdfgo/graphics"
+	"math"
+)
+
+const (
+	QR_QUIET_ZONE = 4 // Light modules required on each side of the symbol
+)
+
+// QRCodeBox draws a QR Code as filled rectangles, surrounded by its quiet zone, scaled to the largest square that fits within its bounds.
+type QRCodeBox struct {
+	pdfgraphics.Rectangle
+	Code   *QRCode
+	Colour []float64
+}
+
+func NewQRCodeBox(text string, colour []float64) (*QRCodeBox, error) {
+	code, err := EncodeQRCode([]byte(text), QRErrorCorrectionMedium)
+	if err != nil {
+		return nil, err
+	}
+	return &QRCodeBox{
+		Code:   code,
+		Colour: colour,
+	}, nil
+}
+
+func (b *QRCodeBox) SetBounds(bounds *pdfgraphics.Rectangle) error {
+	b.Left = bounds.Left
+	b.Top = bounds.Top
+	b.Right = bounds.Right
+	b.Bottom = bounds.Bottom
+	return nil
+}
+
+func (b *QRCodeBox) Write(p *pdfgo.PDF, buffer *bytes.Buffer) error {
+	s := pdfgraphics.FloatToString
+
+	size := math.Min(b.GetWidth(), b.GetHeight())
+	module := size / float64(b.Code.Size+2*QR_QUIET_ZONE)
+	// Center
+	left := b.Left + (b.GetWidth()-size)/2
+	top := b.Top - (b.GetHeight()-size)/2
+
+	buffer.WriteString("q\n")
+	// Quiet Zone
+	buffer.WriteString(fmt.Sprintf("1 1 1 rg\n%s %s %s %s re\nf\n", s(left), s(top-size), s(size), s(size)))
+	left += QR_QUIET_ZONE * module
+	top -= QR_QUIET_ZONE * module
+
+	buffer.WriteString(fmt.Sprintf("%s %s %s rg\n", s(b.Colour[0]), s(b.Colour[1]), s(b.Colour[2])))
+	for y, row := range b.Code.Modules {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			// Merge horizontal runs of dark modules into a single rectangle
+			start := x
+			for x+1 < len(row) && row[x+1] {
+				x++
+			}
+			width := float64(x+1-start) * module
+			buffer.WriteString(fmt.Sprintf("%s %s %s %s re\n", s(left+float64(start)*module), s(top-float64(y+1)*module), s(width), s(module)))
+		}
+	}
+	buffer.WriteString("f\nQ\n")
+	return nil
+}
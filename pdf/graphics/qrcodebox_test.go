@@ -0,0 +1,49 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package graphics_test
+
+import (
+	"bytes"
+	"github.com/AletheiaWareLLC/conveygo/pdf/graphics"
+	pdfgraphics "github.com/AletheiaWareLLC/pdfgo/graphics"
+	"github.com/AletheiaWareLLC/testinggo"
+	"strings"
+	"testing"
+)
+
+func TestQRCodeBox(t *testing.T) {
+	box, err := graphics.NewQRCodeBox("a", graphics.BLACK)
+	testinggo.AssertNoError(t, err)
+	// Version 1 is 21 modules wide, plus the quiet zone on each side makes 29
+	testinggo.AssertNoError(t, box.SetBounds(&pdfgraphics.Rectangle{
+		Left:   0,
+		Top:    29,
+		Right:  29,
+		Bottom: 0,
+	}))
+	var buffer bytes.Buffer
+	testinggo.AssertNoError(t, box.Write(nil, &buffer))
+	lines := strings.Split(buffer.String(), "\n")
+	// The quiet zone is filled light across the whole symbol
+	if lines[1] != "1 1 1 rg" || lines[2] != "0 0 29 29 re" {
+		t.Errorf("Wrong quiet zone; got '%s' '%s'", lines[1], lines[2])
+	}
+	// The top row of the top left finder pattern starts inside the quiet zone
+	if !strings.Contains(buffer.String(), "\n4 24 7 1 re\n") {
+		t.Errorf("Expected finder pattern at '4 24 7 1', got '%s'", buffer.String())
+	}
+}
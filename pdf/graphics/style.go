@@ -0,0 +1,37 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package graphics
+
+type Palette struct {
+	Primary   []float64 // Titles and Topics
+	Secondary []float64 // Metadata and Markers
+	Text      []float64 // Content
+}
+
+type FontSizes struct {
+	Topic   float64
+	Meta    float64
+	Content float64
+}
+
+func DefaultPalette() *Palette {
+	return &Palette{
+		Primary:   DARK_SKY_BLUE,
+		Secondary: LIGHT_SKY_BLUE,
+		Text:      BLACK,
+	}
+}
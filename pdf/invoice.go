@@ -0,0 +1,144 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pdf
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/pdf/graphics"
+	"github.com/AletheiaWareLLC/pdfgo"
+	"github.com/AletheiaWareLLC/pdfgo/font"
+	pdfgraphics "github.com/AletheiaWareLLC/pdfgo/graphics"
+	"strings"
+)
+
+const (
+	ERROR_INVOICE_TOO_LARGE = "Invoice too large for page: %s"
+)
+
+// Adds a page to the PDF listing the invoice details and the usage it bills.
+func AddInvoice(p *pdfgo.PDF, theme *Theme, invoice *conveygo.ItemisedInvoice, fonts map[string]font.Font) error {
+	if err := theme.Validate(); err != nil {
+		return err
+	}
+
+	// Resources
+	fs := p.NewDictionaryObject()
+	for id, font := range fonts {
+		fs.AddNameObjectEntry(id, font.GetReference())
+	}
+	resources := p.NewDictionaryObject()
+	resources.AddNameObjectEntry("Font", pdfgo.NewObjectReference(fs))
+
+	pageWidth, pageHeight, err := theme.GetPageSize()
+	if err != nil {
+		return err
+	}
+
+	// Contents
+	bounds := &pdfgraphics.Rectangle{
+		Left:   theme.Margins.Left,
+		Right:  pageWidth - theme.Margins.Right,
+		Top:    pageHeight - theme.Margins.Top,
+		Bottom: theme.Margins.Bottom,
+	}
+
+	page, err := newInvoicePage(theme, fonts, bounds, invoice)
+	if err != nil {
+		return err
+	}
+	return writePage(p, pageWidth, pageHeight, resources, page, nil)
+}
+
+// Formats the amount, given in the smallest unit of the currency, with two decimal places.
+func formatAmount(amount int64, currency string) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d %s", sign, amount/100, amount%100, strings.ToUpper(currency))
+}
+
+// Lists the merchant, customer, plan and period of the invoice, followed by each item of usage and the amount due.
+func newInvoicePage(theme *Theme, fonts map[string]font.Font, bounds *pdfgraphics.Rectangle, invoice *conveygo.ItemisedInvoice) (pageContent, error) {
+	sizes := theme.GetFontSizes(len(theme.Levels))
+	i := invoice.Invoice
+	u := invoice.Usage
+
+	var details strings.Builder
+	details.WriteString(fmt.Sprintf("Number: %s\n", i.Number))
+	details.WriteString(fmt.Sprintf("Merchant: %s\n", i.MerchantAlias))
+	details.WriteString(fmt.Sprintf("Customer: %s\n", i.CustomerAlias))
+	details.WriteString(fmt.Sprintf("Plan: %s %s\n", i.ProductId, i.PlanId))
+	details.WriteString(fmt.Sprintf("Period: %s - %s\n", bcgo.TimestampToString(u.Start), bcgo.TimestampToString(u.End)))
+	if len(invoice.Hash) > 0 {
+		details.WriteString(fmt.Sprintf("%s Record: %s\n", conveygo.CONVEY_INVOICE, base64.RawURLEncoding.EncodeToString(invoice.Hash)))
+	}
+
+	var items strings.Builder
+	if len(u.Item) == 0 {
+		items.WriteString("No usage\n")
+	}
+	for _, item := range u.Item {
+		items.WriteString(fmt.Sprintf("%s: %d records, %d tokens, %s\n", item.Description, item.Quantity, item.Tokens, formatAmount(item.Amount, i.Currency)))
+	}
+	items.WriteString(fmt.Sprintf("\nAmount Due: %s\n", formatAmount(i.AmountDue, i.Currency)))
+	items.WriteString(fmt.Sprintf("Amount Paid: %s\n", formatAmount(i.AmountPaid, i.Currency)))
+	items.WriteString(fmt.Sprintf("Amount Remaining: %s\n", formatAmount(i.AmountRemaining, i.Currency)))
+
+	meta := &graphics.ParagraphBox{
+		Text:       []rune(details.String()),
+		FontId:     "F2",
+		Font:       fonts["F2"],
+		FontSize:   sizes.Meta,
+		FontColour: theme.Palette.Text,
+		Align:      pdfgraphics.Left,
+	}
+	content := &graphics.ParagraphBox{
+		Text:       []rune(items.String()),
+		FontId:     "F3",
+		Font:       fonts["F3"],
+		FontSize:   sizes.Content,
+		FontColour: theme.Palette.Text,
+		Align:      pdfgraphics.Left,
+	}
+	layout := &pdfgraphics.ListLayout{
+		Direction: pdfgraphics.TopBottom,
+		Padding:   graphics.ENTRY_PADDING,
+	}
+	layout.Add(&pdfgraphics.TextBox{
+		Text:       []rune("Invoice"),
+		FontId:     "F1",
+		Font:       fonts["F1"],
+		FontSize:   sizes.Topic,
+		FontColour: theme.Palette.Primary,
+		Align:      pdfgraphics.Center,
+	})
+	layout.Add(meta)
+	layout.Add(content)
+	if err := layout.SetBounds(bounds); err != nil {
+		return nil, err
+	}
+	if meta.Overflow != nil || content.Overflow != nil {
+		return nil, errors.New(fmt.Sprintf(ERROR_INVOICE_TOO_LARGE, i.Number))
+	}
+	return layout, nil
+}
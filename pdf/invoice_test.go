@@ -0,0 +1,79 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pdf_test
+
+import (
+	"bytes"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/pdf"
+	"github.com/AletheiaWareLLC/financego"
+	"github.com/AletheiaWareLLC/pdfgo"
+	"github.com/AletheiaWareLLC/pdfgo/font"
+	"github.com/AletheiaWareLLC/testinggo"
+	"testing"
+)
+
+// Measures every rune as half the font size wide.
+type fixedFont struct {
+	reference *pdfgo.ObjectReference
+}
+
+func (f *fixedFont) GetReference() *pdfgo.ObjectReference {
+	return f.reference
+}
+
+func (f *fixedFont) MeasureText(text []rune, fontSize float64) float64 {
+	return float64(len(text)) * fontSize / 2
+}
+
+func TestAddInvoice(t *testing.T) {
+	p := pdfgo.NewPDF()
+	fonts := make(map[string]font.Font)
+	for _, id := range []string{"F1", "F2", "F3"} {
+		fonts[id] = &fixedFont{
+			reference: pdfgo.NewObjectReference(p.NewDictionaryObject()),
+		}
+	}
+	testinggo.AssertNoError(t, pdf.AddInvoice(p, pdf.DefaultTheme(), &conveygo.ItemisedInvoice{
+		Hash: []byte("Invoice123"),
+		Invoice: &financego.Invoice{
+			MerchantAlias:   "Merchant",
+			CustomerAlias:   "Alice",
+			Currency:        "usd",
+			Number:          "Alice-202008",
+			AmountDue:       1234,
+			AmountRemaining: 1234,
+		},
+		Usage: &conveygo.InvoiceUsage{
+			Item: []*conveygo.InvoiceItem{
+				{
+					Description: conveygo.INVOICE_ITEM_MESSAGES,
+					Quantity:    3,
+					Tokens:      123,
+					Amount:      1234,
+				},
+			},
+		},
+	}, fonts))
+	var buffer bytes.Buffer
+	testinggo.AssertNoError(t, p.Write(&buffer))
+	for _, s := range []string{"Alice-202008", "12.34 USD"} {
+		if !bytes.Contains(buffer.Bytes(), []byte(s)) {
+			t.Errorf("Expected invoice to contain '%s'", s)
+		}
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/AletheiaWareLLC/conveygo/pdf"
 	"github.com/AletheiaWareLLC/pdfgo"
 	"github.com/AletheiaWareLLC/pdfgo/font"
+	"io/ioutil"
 	"log"
 	"os"
 	"path"
@@ -34,6 +35,9 @@ var (
 	host          = flag.String("host", "test-convey.aletheiaware.com", "Convey host")
 	fontfamily    = flag.String("fontfamily", "Times", "ttf font family")
 	fontdirectory = flag.String("fontdirectory", "/usr/share/fonts/", "ttf font directory")
+	period        = flag.Duration("period", 0, "digest period ending now, zero for all time")
+	themefile     = flag.String("theme", "", "JSON theme file, empty for the default A4 theme")
+	verify        = flag.String("verify", "", "digest PDF whose embedded provenance and signature to verify against the local cache, the rendered pages are not checked")
 )
 
 func main() {
@@ -41,18 +45,42 @@ func main() {
 
 	flag.Parse()
 
+	if *verify != "" {
+		if err := VerifyDigest(*verify); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	to := bcgo.Timestamp()
+	var from uint64
+	if *period > 0 {
+		from = to - uint64(period.Nanoseconds())
+	}
+
 	var entries []*conveygo.DigestEntry
+	var provenance *conveygo.DigestProvenance
+	var signature *bcgo.Record
 	if *mock {
 		entries = GetMockDigestEntries()
 	} else {
-		entries, err = GetDigestEntries(*host)
+		node, err := OpenNode(*host)
+		if err != nil {
+			log.Fatal(err)
+		}
+		entries, provenance, err = GetDigestEntries(node, from, to)
+		if err != nil {
+			log.Fatal(err)
+		}
+		// Sign entries so readers can check the digest came from this node
+		_, signature, err = conveygo.DigestToRecord(node.Alias, node.Key, bcgo.Timestamp(), entries)
 		if err != nil {
 			log.Fatal(err)
 		}
 	}
 
-	if len(entries) < conveygo.DIGEST_LIMIT {
-		log.Fatal("Too few entries for digest")
+	if len(entries) == 0 {
+		log.Fatal("No entries for digest")
 	}
 
 	writer := os.Stdout
@@ -67,6 +95,14 @@ func main() {
 		writer = file
 	}
 
+	theme := pdf.DefaultTheme()
+	if *themefile != "" {
+		theme, err = pdf.LoadTheme(*themefile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	p := pdfgo.NewPDF()
 
 	var fonts map[string]font.Font
@@ -100,11 +136,17 @@ func main() {
 		log.Fatal(err)
 	}
 
-	err = pdf.AddEntries(p, *host, entries, fonts)
+	err = pdf.AddEntries(p, theme, *host, from, to, entries, provenance, fonts)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if signature != nil {
+		if err := pdf.AddSignature(p, signature); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	err = p.Write(writer)
 	if err != nil {
 		log.Fatal(err)
@@ -135,20 +177,28 @@ func LoadTTFFont(p *pdfgo.PDF, ids map[string]string) (map[string]font.Font, err
 	return fonts, nil
 }
 
-func GetDigestEntries(host string) ([]*conveygo.DigestEntry, error) {
+func OpenCache() (bcgo.Cache, string, error) {
 	rootDir, err := bcgo.GetRootDirectory()
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	log.Println("Root Directory:", rootDir)
 
 	cacheDir, err := bcgo.GetCacheDirectory(rootDir)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	log.Println("Cache Directory:", cacheDir)
 
 	cache, err := bcgo.NewFileCache(cacheDir)
+	if err != nil {
+		return nil, "", err
+	}
+	return cache, rootDir, nil
+}
+
+func OpenNode(host string) (*bcgo.Node, error) {
+	cache, rootDir, err := OpenCache()
 	if err != nil {
 		return nil, err
 	}
@@ -162,10 +212,12 @@ func GetDigestEntries(host string) ([]*conveygo.DigestEntry, error) {
 
 	network := bcgo.NewTCPNetwork(peers...)
 
-	node, err := bcgo.GetNode(rootDir, cache, network)
-	if err != nil {
-		return nil, err
-	}
+	return bcgo.GetNode(rootDir, cache, network)
+}
+
+func GetDigestEntries(node *bcgo.Node, from, to uint64) ([]*conveygo.DigestEntry, *conveygo.DigestProvenance, error) {
+	cache := node.Cache
+	network := node.Network
 
 	conversations := conveygo.OpenConversationChannel()
 
@@ -188,7 +240,7 @@ func GetDigestEntries(host string) ([]*conveygo.DigestEntry, error) {
 		}
 		return nil
 	}); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	messages := &conveygo.BCStore{
@@ -196,7 +248,42 @@ func GetDigestEntries(host string) ([]*conveygo.DigestEntry, error) {
 		Listener: &bcgo.PrintingMiningListener{Output: os.Stdout},
 	}
 
-	return conveygo.GetDigestEntries(messages, 0, bcgo.Timestamp())
+	entries, err := conveygo.GetDigestEntries(messages, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Generation time is taken after the yields are calculated so the verifier counts the same messages
+	provenance, err := conveygo.NewDigestProvenance(cache, network, conversations.Head, bcgo.Timestamp(), entries)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return entries, provenance, nil
+}
+
+// Verifies the provenance and signature embedded in the digest PDF, but not its rendered pages.
+func VerifyDigest(file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	cache, _, err := OpenCache()
+	if err != nil {
+		return err
+	}
+
+	provenance, signature, err := pdf.VerifyDigest(data, cache, nil)
+	if err != nil {
+		return err
+	}
+	for _, e := range provenance.Entries {
+		log.Println("Verified:", e.Topic, e.Author, e.Yield)
+	}
+	log.Println("Verified", len(provenance.Entries), "entries against", conveygo.CONVEY_CONVERSATION, provenance.Head)
+	log.Println("Signed by:", signature.Creator)
+	return nil
 }
 
 func GetMockDigestEntries() []*conveygo.DigestEntry {
@@ -241,6 +328,16 @@ func GetMockDigestEntries() []*conveygo.DigestEntry {
 				Type:    conveygo.MediaType_TEXT_PLAIN,
 			},
 		},
+		&conveygo.DigestEntry{
+			Topic:     "Fifth Article",
+			Timestamp: bcgo.TimestampToString(bcgo.Timestamp()),
+			Author:    "Emma",
+			Yield:     8,
+			Message: &conveygo.Message{
+				Content: []byte(LOREM_IPSUM),
+				Type:    conveygo.MediaType_TEXT_PLAIN,
+			},
+		},
 	}
 }
 
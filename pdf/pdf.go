@@ -18,14 +18,46 @@ package pdf
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"github.com/AletheiaWareLLC/conveygo"
 	"github.com/AletheiaWareLLC/conveygo/pdf/graphics"
 	"github.com/AletheiaWareLLC/pdfgo"
 	"github.com/AletheiaWareLLC/pdfgo/font"
 	pdfgraphics "github.com/AletheiaWareLLC/pdfgo/graphics"
+	"math"
+	"strings"
 )
 
-func AddEntries(p *pdfgo.PDF, host string, entries []*conveygo.DigestEntry, fonts map[string]font.Font) error {
+const (
+	FOOTER_FONT_SIZE = 8
+	GRID_COLUMNS     = 2
+	GRID_ROWS        = 2
+	PAGE_COVER       = 1
+	PAGE_FRONT       = 2
+
+	ERROR_ENTRY_TOO_LARGE = "Entry too large for page: %s"
+)
+
+// A portion of an entry's content yet to be laid out on a continuation page.
+type continuation struct {
+	Entry    *conveygo.DigestEntry
+	Level    int
+	Text     []rune
+	From     int
+	Previous *graphics.DigestEntryBox
+}
+
+type pageContent interface {
+	Write(p *pdfgo.PDF, buffer *bytes.Buffer) error
+}
+
+// Adds the digest pages to the PDF, ending with a provenance page when the provenance is not nil.
+func AddEntries(p *pdfgo.PDF, theme *Theme, host string, from, to uint64, entries []*conveygo.DigestEntry, provenance *conveygo.DigestProvenance, fonts map[string]font.Font) error {
+	if err := theme.Validate(); err != nil {
+		return err
+	}
+
 	// Resources
 	fs := p.NewDictionaryObject()
 	for id, font := range fonts {
@@ -34,33 +66,134 @@ func AddEntries(p *pdfgo.PDF, host string, entries []*conveygo.DigestEntry, font
 	resources := p.NewDictionaryObject()
 	resources.AddNameObjectEntry("Font", pdfgo.NewObjectReference(fs))
 
-	pageWidth := 595.28
-	pageHeight := 841.89
+	pageWidth, pageHeight, err := theme.GetPageSize()
+	if err != nil {
+		return err
+	}
 
 	// Contents
-	sizes := []float64{806, 496, 310, 186, 124, 62, 62, 0}
-	contentWidth := sizes[1]
-	contentHeight := sizes[0]
-	marginX := (pageWidth - contentWidth) / 2
-	marginY := (pageHeight - contentHeight) / 2
 	bounds := &pdfgraphics.Rectangle{
-		Left:   marginX,
-		Right:  marginX + contentWidth,
-		Top:    marginY + contentHeight,
-		Bottom: marginY,
+		Left:   theme.Margins.Left,
+		Right:  pageWidth - theme.Margins.Right,
+		Top:    pageHeight - theme.Margins.Top,
+		Bottom: theme.Margins.Bottom,
 	}
 
-	layout := &pdfgraphics.FibonacciLayout{
-		Sizes: sizes[1:],
-	}
-	for i := 0; i < conveygo.DIGEST_LIMIT; i++ {
-		layout.Add(&graphics.DigestEntryBox{
-			Host:  host,
-			Level: i + 1,
-			Entry: entries[i],
-			Fonts: fonts,
+	// Cover
+	cover := &graphics.CoverBox{
+		Host:    host,
+		From:    from,
+		To:      to,
+		Fonts:   fonts,
+		Palette: &theme.Palette,
+	}
+	if err := cover.SetBounds(bounds); err != nil {
+		return err
+	}
+	pages := []pageContent{cover}
+
+	// Front Page
+	var front pageContent
+	var boxes []*graphics.DigestEntryBox
+	switch {
+	case strings.EqualFold(theme.Layout, LAYOUT_FIBONACCI):
+		front, boxes, err = layoutFibonacci(theme, host, fonts, bounds, entries)
+	case strings.EqualFold(theme.Layout, LAYOUT_GRID):
+		front, boxes, err = layoutGrid(theme, host, fonts, bounds, entries)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Overflowing content continues after the front page, followed by any remaining entries
+	var pending []*continuation
+	if front != nil {
+		pages = append(pages, front)
+		for _, box := range boxes {
+			if box.Content.Overflow != nil {
+				pending = append(pending, &continuation{
+					Entry:    box.Entry,
+					Level:    len(theme.Levels),
+					Text:     box.Content.Overflow,
+					From:     PAGE_FRONT,
+					Previous: box,
+				})
+			}
+		}
+	}
+	for i, entry := range entries[len(boxes):] {
+		pending = append(pending, &continuation{
+			Entry: entry,
+			Level: len(boxes) + i + 1,
 		})
 	}
+
+	continuations, err := layoutContinuations(theme, host, fonts, bounds, len(pages)+1, pending)
+	if err != nil {
+		return err
+	}
+	for _, c := range continuations {
+		pages = append(pages, c)
+	}
+
+	if provenance != nil {
+		page, err := newProvenancePage(theme, fonts, bounds, provenance)
+		if err != nil {
+			return err
+		}
+		pages = append(pages, page)
+		if err := AddProvenance(p, provenance); err != nil {
+			return err
+		}
+	}
+
+	for i, page := range pages {
+		var footer pageContent
+		if i+1 != PAGE_COVER {
+			footer, err = newFooter(theme, fonts, bounds, i+1, len(pages))
+			if err != nil {
+				return err
+			}
+		}
+		if err := writePage(p, pageWidth, pageHeight, resources, page, footer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newEntryBox(theme *Theme, host string, fonts map[string]font.Font, level int, entry *conveygo.DigestEntry) *graphics.DigestEntryBox {
+	return &graphics.DigestEntryBox{
+		Host:       host,
+		Entry:      entry,
+		Fonts:      fonts,
+		Palette:    &theme.Palette,
+		FontSizes:  theme.GetFontSizes(level),
+		ShowQRCode: theme.QRCodes,
+	}
+}
+
+// Lays out the highest-yielding entries in a Fibonacci spiral, with the title and logo in the smallest squares.
+func layoutFibonacci(theme *Theme, host string, fonts map[string]font.Font, bounds *pdfgraphics.Rectangle, entries []*conveygo.DigestEntry) (pageContent, []*graphics.DigestEntryBox, error) {
+	// Largest square unit that fits 8 units wide by 13 units high
+	unit := math.Min(bounds.GetWidth()/8, bounds.GetHeight()/13)
+	sizes := []float64{8 * unit, 5 * unit, 3 * unit, 2 * unit, unit, unit, 0}
+	marginX := (bounds.GetWidth() - sizes[0]) / 2
+	marginY := (bounds.GetHeight() - 13*unit) / 2
+
+	layout := &pdfgraphics.FibonacciLayout{
+		Sizes: sizes,
+	}
+	count := len(entries)
+	if count > conveygo.DIGEST_LIMIT {
+		count = conveygo.DIGEST_LIMIT
+	}
+	var boxes []*graphics.DigestEntryBox
+	for i := 0; i < count; i++ {
+		box := newEntryBox(theme, host, fonts, i+1, entries[i])
+		layout.Add(box)
+		boxes = append(boxes, box)
+	}
 	layout.Add(&pdfgraphics.GravityLayout{
 		Gravity: pdfgraphics.Middle,
 		Box: &pdfgraphics.TextBox{
@@ -68,24 +201,146 @@ func AddEntries(p *pdfgo.PDF, host string, entries []*conveygo.DigestEntry, font
 			FontId:     "F1",
 			Font:       fonts["F1"],
 			FontSize:   18,
-			FontColour: graphics.DARK_SKY_BLUE,
+			FontColour: theme.Palette.Primary,
 			Align:      pdfgraphics.Center,
 		},
 	})
 	layout.Add(&graphics.LogoBox{
-		Colour: graphics.DARK_SKY_BLUE,
+		Colour: theme.Palette.Primary,
 	})
 
+	if err := layout.SetBounds(&pdfgraphics.Rectangle{
+		Left:   bounds.Left + marginX,
+		Right:  bounds.Right - marginX,
+		Top:    bounds.Top - marginY,
+		Bottom: bounds.Bottom + marginY,
+	}); err != nil {
+		return nil, nil, err
+	}
+	return layout, boxes, nil
+}
+
+// Lays out the highest-yielding entries in equally sized cells.
+func layoutGrid(theme *Theme, host string, fonts map[string]font.Font, bounds *pdfgraphics.Rectangle, entries []*conveygo.DigestEntry) (pageContent, []*graphics.DigestEntryBox, error) {
+	layout := &graphics.GridLayout{
+		Columns: GRID_COLUMNS,
+		Rows:    GRID_ROWS,
+	}
+	count := len(entries)
+	if count > GRID_COLUMNS*GRID_ROWS {
+		count = GRID_COLUMNS * GRID_ROWS
+	}
+	var boxes []*graphics.DigestEntryBox
+	for i := 0; i < count; i++ {
+		box := newEntryBox(theme, host, fonts, i+1, entries[i])
+		layout.Add(box)
+		boxes = append(boxes, box)
+	}
 	if err := layout.SetBounds(bounds); err != nil {
-		return err
+		return nil, nil, err
+	}
+	return layout, boxes, nil
+}
+
+// Flows the pending content in a single column across as many pages as necessary, starting at the given page number.
+func layoutContinuations(theme *Theme, host string, fonts map[string]font.Font, bounds *pdfgraphics.Rectangle, first int, pending []*continuation) ([]*pdfgraphics.ListLayout, error) {
+	var pages []*pdfgraphics.ListLayout
+	var page *pdfgraphics.ListLayout
+	var top float64
+	for len(pending) > 0 {
+		if page == nil {
+			page = &pdfgraphics.ListLayout{
+				Direction: pdfgraphics.TopBottom,
+			}
+			pages = append(pages, page)
+			top = bounds.Top
+		}
+		number := first + len(pages) - 1
+		c := pending[0]
+		box := newEntryBox(theme, host, fonts, c.Level, c.Entry)
+		box.Text = c.Text
+		box.ContinuedFrom = c.From
+		if err := box.SetBounds(&pdfgraphics.Rectangle{
+			Left:   bounds.Left,
+			Top:    top,
+			Right:  bounds.Right,
+			Bottom: bounds.Bottom,
+		}); err != nil {
+			return nil, err
+		}
+		if box.Content.Overflow != nil && box.Content.GetLineCount() == 0 {
+			if len(page.Boxes) == 0 {
+				return nil, errors.New(fmt.Sprintf(ERROR_ENTRY_TOO_LARGE, c.Entry.Topic))
+			}
+			// Not enough room, move to next page
+			page = nil
+			continue
+		}
+		if c.Previous != nil {
+			c.Previous.ContinuedOn = number
+		}
+		box.Bottom = top - box.GetContentHeight()
+		page.Add(box)
+		if box.Content.Overflow != nil {
+			pending[0] = &continuation{
+				Entry:    c.Entry,
+				Level:    c.Level,
+				Text:     box.Content.Overflow,
+				From:     number,
+				Previous: box,
+			}
+			page = nil
+		} else {
+			pending = pending[1:]
+			top = box.Bottom
+			if top <= bounds.Bottom {
+				page = nil
+			}
+		}
 	}
+	return pages, nil
+}
+
+func newFooter(theme *Theme, fonts map[string]font.Font, bounds *pdfgraphics.Rectangle, page, total int) (pdfgraphics.Box, error) {
+	footer := &pdfgraphics.GravityLayout{
+		Gravity: pdfgraphics.Middle,
+		Box: &pdfgraphics.TextBox{
+			Text:       []rune(fmt.Sprintf("Page %d of %d", page, total)),
+			FontId:     "F2",
+			Font:       fonts["F2"],
+			FontSize:   FOOTER_FONT_SIZE,
+			FontColour: theme.Palette.Secondary,
+			Align:      pdfgraphics.Center,
+		},
+	}
+	if err := footer.SetBounds(&pdfgraphics.Rectangle{
+		Left:   bounds.Left,
+		Top:    bounds.Bottom,
+		Right:  bounds.Right,
+		Bottom: bounds.Bottom - theme.Margins.Bottom,
+	}); err != nil {
+		return nil, err
+	}
+	return footer, nil
+}
+
+func writePage(p *pdfgo.PDF, width, height float64, resources *pdfgo.DictionaryObject, content, footer pageContent) error {
+	// Each page holds its own annotations
+	p.Annotations = &pdfgo.ArrayObject{}
 
 	var buffer bytes.Buffer
-	if err := layout.Write(p, &buffer); err != nil {
+	if err := content.Write(p, &buffer); err != nil {
 		return err
 	}
+	if footer != nil {
+		buffer.WriteString("\n")
+		if err := footer.Write(p, &buffer); err != nil {
+			return err
+		}
+		buffer.WriteString("\n")
+	}
 	contents := p.NewStreamObject()
 	contents.Data = buffer.Bytes()
-	p.AddPage(pageWidth, pageHeight, pdfgo.NewObjectReference(resources), pdfgo.NewObjectReference(contents))
+	p.AddPage(width, height, pdfgo.NewObjectReference(resources), pdfgo.NewObjectReference(contents))
 	return nil
 }
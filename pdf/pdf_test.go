@@ -0,0 +1,142 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pdf_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/pdf"
+	"github.com/AletheiaWareLLC/pdfgo"
+	"github.com/AletheiaWareLLC/pdfgo/font"
+	"github.com/AletheiaWareLLC/testinggo"
+	"strings"
+	"testing"
+)
+
+func makeEntries(count int, content string) []*conveygo.DigestEntry {
+	var entries []*conveygo.DigestEntry
+	for i := 1; i <= count; i++ {
+		entries = append(entries, &conveygo.DigestEntry{
+			Topic:     fmt.Sprintf("Topic%d", i),
+			Timestamp: "2020-08-01",
+			Author:    "Alice",
+			Message: &conveygo.Message{
+				Content: []byte(content),
+				Type:    conveygo.MediaType_TEXT_PLAIN,
+			},
+		})
+	}
+	return entries
+}
+
+// Lays out the entries and returns the number of pages and the written PDF.
+func addEntries(t *testing.T, layout string, entries []*conveygo.DigestEntry) (int, []byte) {
+	t.Helper()
+	p := pdfgo.NewPDF()
+	fonts := make(map[string]font.Font)
+	for _, id := range []string{"F1", "F2", "F3"} {
+		fonts[id] = &fixedFont{
+			reference: pdfgo.NewObjectReference(p.NewDictionaryObject()),
+		}
+	}
+	theme := pdf.DefaultTheme()
+	theme.Layout = layout
+	testinggo.AssertNoError(t, pdf.AddEntries(p, theme, "example.com", 0, 0, entries, nil, fonts))
+	var buffer bytes.Buffer
+	testinggo.AssertNoError(t, p.Write(&buffer))
+	return int(p.PageCount.Number), buffer.Bytes()
+}
+
+func assertContains(t *testing.T, data []byte, expected ...string) {
+	t.Helper()
+	for _, e := range expected {
+		if !bytes.Contains(data, []byte(e)) {
+			t.Errorf("Expected PDF to contain '%s'", e)
+		}
+	}
+}
+
+func assertNotContains(t *testing.T, data []byte, unexpected ...string) {
+	t.Helper()
+	for _, u := range unexpected {
+		if bytes.Contains(data, []byte(u)) {
+			t.Errorf("Expected PDF not to contain '%s'", u)
+		}
+	}
+}
+
+func TestAddEntries(t *testing.T) {
+	t.Run("Partial", func(t *testing.T) {
+		// Fewer entries than the digest limit fill the first squares or cells of the front page, leaving the rest empty
+		for _, layout := range []string{pdf.LAYOUT_FIBONACCI, pdf.LAYOUT_GRID} {
+			t.Run(layout, func(t *testing.T) {
+				pages, data := addEntries(t, layout, makeEntries(conveygo.DIGEST_LIMIT-2, "Hello World"))
+				if pages != 2 {
+					t.Errorf("Wrong number of pages; expected '2', got '%d'", pages)
+				}
+				assertContains(t, data, "(Topic1) Tj", "(Topic2) Tj", "(Hello World) Tj", "(Page 2 of 2) Tj")
+				assertNotContains(t, data, "(Topic3) Tj", "continued", "Continued")
+			})
+		}
+	})
+	t.Run("Full", func(t *testing.T) {
+		pages, data := addEntries(t, pdf.LAYOUT_FIBONACCI, makeEntries(conveygo.DIGEST_LIMIT, "Hello World"))
+		if pages != 2 {
+			t.Errorf("Wrong number of pages; expected '2', got '%d'", pages)
+		}
+		for i := 1; i <= conveygo.DIGEST_LIMIT; i++ {
+			assertContains(t, data, fmt.Sprintf("(Topic%d) Tj", i))
+		}
+	})
+	t.Run("Overflow", func(t *testing.T) {
+		// Content overflowing its square continues on the page after the front page
+		pages, data := addEntries(t, pdf.LAYOUT_FIBONACCI, makeEntries(1, strings.Repeat("Lorem ipsum dolor sit amet. ", 200)))
+		if pages != 3 {
+			t.Errorf("Wrong number of pages; expected '3', got '%d'", pages)
+		}
+		assertContains(t, data, "(Continued on page 3) Tj", "(Topic1 \\(continued from page 2\\)) Tj", "(Page 3 of 3) Tj")
+	})
+	t.Run("OverflowAcrossPages", func(t *testing.T) {
+		// Content overflowing a continuation page continues on the next
+		pages, data := addEntries(t, pdf.LAYOUT_FIBONACCI, makeEntries(1, strings.Repeat("Lorem ipsum dolor sit amet. ", 2000)))
+		if pages < 4 {
+			t.Fatalf("Wrong number of pages; expected at least '4', got '%d'", pages)
+		}
+		for page := 3; page <= pages; page++ {
+			assertContains(t, data, fmt.Sprintf("(Continued on page %d) Tj", page), fmt.Sprintf("(Topic1 \\(continued from page %d\\)) Tj", page-1))
+		}
+		assertNotContains(t, data, fmt.Sprintf("(Continued on page %d) Tj", pages+1))
+	})
+	t.Run("RemainingEntries", func(t *testing.T) {
+		// Entries beyond the cells of the grid are listed after the front page
+		pages, data := addEntries(t, pdf.LAYOUT_GRID, makeEntries(6, "Hello World"))
+		if pages != 3 {
+			t.Errorf("Wrong number of pages; expected '3', got '%d'", pages)
+		}
+		assertContains(t, data, "(Topic5) Tj", "(Topic6) Tj", "(Page 3 of 3) Tj")
+		assertNotContains(t, data, "continued", "Continued")
+	})
+	t.Run("SingleColumn", func(t *testing.T) {
+		// Without a front page every entry is listed after the cover
+		pages, data := addEntries(t, pdf.LAYOUT_SINGLE_COLUMN, makeEntries(2, "Hello World"))
+		if pages != 2 {
+			t.Errorf("Wrong number of pages; expected '2', got '%d'", pages)
+		}
+		assertContains(t, data, "(Topic1) Tj", "(Topic2) Tj", "(Page 2 of 2) Tj")
+	})
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pdf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/aliasgo"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/pdf/graphics"
+	"github.com/AletheiaWareLLC/pdfgo"
+	"github.com/AletheiaWareLLC/pdfgo/font"
+	pdfgraphics "github.com/AletheiaWareLLC/pdfgo/graphics"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	PROVENANCE_KEY = "ConveyProvenance" // Catalog entry referencing the JSON encoded provenance stream
+
+	ERROR_NO_PROVENANCE        = "No provenance in digest"
+	ERROR_PROVENANCE_TOO_LARGE = "Provenance too large for page"
+	ERROR_SIGNATURE_MISMATCH   = "Signed entries don't match provenance"
+	ERROR_STREAM_INCOMPLETE    = "Stream incomplete: %d of %d bytes"
+)
+
+// Embeds the provenance as a JSON stream referenced from the document catalog.
+func AddProvenance(p *pdfgo.PDF, provenance *conveygo.DigestProvenance) error {
+	data, err := json.Marshal(provenance)
+	if err != nil {
+		return err
+	}
+	stream := p.NewStreamObject()
+	stream.Data = data
+	p.Catalog.AddNameObjectEntry(PROVENANCE_KEY, pdfgo.NewObjectReference(stream))
+	return nil
+}
+
+// Returns the data of the stream referenced by the given catalog entry, or nil if there is no such entry.
+// Only PDFs written by pdfgo are supported; the catalog and stream are found by matching text, so PDFs with compressed streams or object streams,
+// such as those re-saved by another application, are reported as having no such entry.
+func readCatalogStream(data []byte, key string) ([]byte, error) {
+	match := regexp.MustCompile(`/` + key + ` (\d+) 0 R`).FindSubmatch(data)
+	if match == nil {
+		return nil, nil
+	}
+	indices := regexp.MustCompile(`(?m)^` + string(match[1]) + ` 0 obj <</Length (\d+)[^>]*>>\nstream\n`).FindSubmatchIndex(data)
+	if indices == nil {
+		return nil, nil
+	}
+	length, err := strconv.Atoi(string(data[indices[2]:indices[3]]))
+	if err != nil {
+		return nil, err
+	}
+	start := indices[1]
+	if start+length > len(data) {
+		return nil, errors.New(fmt.Sprintf(ERROR_STREAM_INCOMPLETE, len(data)-start, length))
+	}
+	return data[start : start+length], nil
+}
+
+// Extracts the embedded provenance from a digest PDF, which must be uncompressed as written by pdfgo.
+func ReadProvenance(data []byte) (*conveygo.DigestProvenance, error) {
+	stream, err := readCatalogStream(data, PROVENANCE_KEY)
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		return nil, errors.New(ERROR_NO_PROVENANCE)
+	}
+	provenance := &conveygo.DigestProvenance{}
+	if err := json.Unmarshal(stream, provenance); err != nil {
+		return nil, err
+	}
+	return provenance, nil
+}
+
+// Confirms the entries listed in the digest's provenance against the chain in the given cache,
+// and that they match the entries signed by the key registered to the signer's alias.
+// Only the embedded provenance and signature are verified; the rendered pages are not compared with the signed entries,
+// so a PDF whose page content has been edited while its metadata was left intact still verifies.
+func VerifyDigest(data []byte, cache bcgo.Cache, network bcgo.Network) (*conveygo.DigestProvenance, *bcgo.Record, error) {
+	provenance, err := ReadProvenance(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := conveygo.VerifyDigestProvenance(cache, network, provenance); err != nil {
+		return nil, nil, err
+	}
+
+	record, err := ReadSignature(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	aliases := aliasgo.OpenAliasChannel()
+	if err := aliases.LoadHead(cache, network); err != nil {
+		return nil, nil, err
+	}
+	rotations := conveygo.OpenKeyRotationChannel()
+	if err := rotations.LoadHead(cache, network); err != nil {
+		// Without rotations the record is checked against the key registered in the Alias Chain
+		rotations = nil
+	}
+	entries, err := conveygo.VerifyDigestRecord(aliases, rotations, cache, network, record)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(entries) != len(provenance.Entries) {
+		return nil, nil, errors.New(ERROR_SIGNATURE_MISMATCH)
+	}
+	for i, e := range entries {
+		p := provenance.Entries[i]
+		if e.Hash != p.RecordHash || e.Topic != p.Topic || e.Author != p.Author || e.Yield != p.Yield {
+			return nil, nil, errors.New(ERROR_SIGNATURE_MISMATCH)
+		}
+	}
+	return provenance, record, nil
+}
+
+// Lists the chain head, generation time, and the record and block hashes of each entry.
+func newProvenancePage(theme *Theme, fonts map[string]font.Font, bounds *pdfgraphics.Rectangle, provenance *conveygo.DigestProvenance) (pageContent, error) {
+	sizes := theme.GetFontSizes(len(theme.Levels))
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("Generated: %s\n", bcgo.TimestampToString(provenance.Timestamp)))
+	text.WriteString(fmt.Sprintf("%s Head: %s\n", conveygo.CONVEY_CONVERSATION, provenance.Head))
+	for _, e := range provenance.Entries {
+		text.WriteString(fmt.Sprintf("\n%s\n", e.Topic))
+		text.WriteString(fmt.Sprintf("Author: %s Yield: %d\n", e.Author, e.Yield))
+		text.WriteString(fmt.Sprintf("Record: %s\n", e.RecordHash))
+		text.WriteString(fmt.Sprintf("Block: %s\n", e.BlockHash))
+	}
+
+	content := &graphics.ParagraphBox{
+		Text:       []rune(text.String()),
+		FontId:     "F2",
+		Font:       fonts["F2"],
+		FontSize:   sizes.Meta,
+		FontColour: theme.Palette.Text,
+		Align:      pdfgraphics.Left,
+	}
+	layout := &pdfgraphics.ListLayout{
+		Direction: pdfgraphics.TopBottom,
+		Padding:   graphics.ENTRY_PADDING,
+	}
+	layout.Add(&pdfgraphics.TextBox{
+		Text:       []rune("Provenance"),
+		FontId:     "F1",
+		Font:       fonts["F1"],
+		FontSize:   sizes.Topic,
+		FontColour: theme.Palette.Primary,
+		Align:      pdfgraphics.Center,
+	})
+	layout.Add(content)
+	if err := layout.SetBounds(bounds); err != nil {
+		return nil, err
+	}
+	if content.Overflow != nil {
+		return nil, errors.New(ERROR_PROVENANCE_TOO_LARGE)
+	}
+	return layout, nil
+}
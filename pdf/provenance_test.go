@@ -0,0 +1,62 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pdf_test
+
+import (
+	"bytes"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/pdf"
+	"github.com/AletheiaWareLLC/pdfgo"
+	"github.com/AletheiaWareLLC/testinggo"
+	"testing"
+)
+
+func TestReadProvenance(t *testing.T) {
+	t.Run("Exists", func(t *testing.T) {
+		p := pdfgo.NewPDF()
+		testinggo.AssertNoError(t, pdf.AddProvenance(p, &conveygo.DigestProvenance{
+			Head:      "Head123",
+			Timestamp: 1234,
+			Entries: []*conveygo.EntryProvenance{
+				{
+					RecordHash: "Record123",
+					BlockHash:  "Block123",
+					Topic:      "Test (endstream)",
+					Author:     "Alice",
+					Yield:      -5,
+				},
+			},
+		}))
+		var buffer bytes.Buffer
+		testinggo.AssertNoError(t, p.Write(&buffer))
+		provenance, err := pdf.ReadProvenance(buffer.Bytes())
+		testinggo.AssertNoError(t, err)
+		if provenance.Head != "Head123" || provenance.Timestamp != 1234 {
+			t.Errorf("Wrong provenance; expected 'Head123 1234', got '%s %d'", provenance.Head, provenance.Timestamp)
+		}
+		if len(provenance.Entries) != 1 || provenance.Entries[0].Topic != "Test (endstream)" || provenance.Entries[0].Yield != -5 {
+			t.Errorf("Wrong entries; got '%v'", provenance.Entries)
+		}
+	})
+	t.Run("NotExists", func(t *testing.T) {
+		p := pdfgo.NewPDF()
+		var buffer bytes.Buffer
+		testinggo.AssertNoError(t, p.Write(&buffer))
+		_, err := pdf.ReadProvenance(buffer.Bytes())
+		testinggo.AssertError(t, pdf.ERROR_NO_PROVENANCE, err)
+	})
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pdf
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/pdfgo"
+	"github.com/golang/protobuf/proto"
+	"io"
+)
+
+const (
+	METADATA_KEY  = "Metadata"                                // Catalog entry referencing the XMP metadata stream
+	XMP_NAMESPACE = "http://aletheiaware.com/convey/xmp/1.0/" // Namespace of the Convey properties in the XMP metadata
+
+	ERROR_NO_SIGNATURE = "No signature in digest"
+)
+
+// Packet holding the base64 encoded signature as the convey:Signature property.
+const xmpPacket = "<?xpacket begin=\"\ufeff\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n" +
+	"<x:xmpmeta xmlns:x=\"adobe:ns:meta/\">\n" +
+	"<rdf:RDF xmlns:rdf=\"http://www.w3.org/1999/02/22-rdf-syntax-ns#\">\n" +
+	"<rdf:Description rdf:about=\"\" xmlns:convey=\"%s\">\n" +
+	"<convey:Signature>%s</convey:Signature>\n" +
+	"</rdf:Description>\n" +
+	"</rdf:RDF>\n" +
+	"</x:xmpmeta>\n" +
+	"<?xpacket end=\"r\"?>"
+
+// metadataStream is a stream of XMP metadata, which pdfgo.StreamObject cannot declare.
+type metadataStream struct {
+	pdfgo.Metadata
+	Data []byte
+}
+
+func (o *metadataStream) Write(out io.Writer) (int, error) {
+	var count int
+	n, err := pdfgo.WriteF(out, "<</Length %d /Type /Metadata /Subtype /XML>>\nstream\n", len(o.Data))
+	if err != nil {
+		return 0, err
+	}
+	count += n
+	n, err = out.Write(o.Data)
+	if err != nil {
+		return 0, err
+	}
+	count += n
+	n, err = pdfgo.WriteS(out, "\nendstream")
+	if err != nil {
+		return 0, err
+	}
+	count += n
+	return count, nil
+}
+
+type xmpMeta struct {
+	Descriptions []struct {
+		Signature string `xml:"http://aletheiaware.com/convey/xmp/1.0/ Signature"`
+	} `xml:"RDF>Description"`
+}
+
+// Embeds the signed digest record, protobuf and base64 encoded, in the XMP metadata of the document.
+func AddSignature(p *pdfgo.PDF, record *bcgo.Record) error {
+	data, err := proto.Marshal(record)
+	if err != nil {
+		return err
+	}
+	stream := &metadataStream{
+		Data: []byte(fmt.Sprintf(xmpPacket, XMP_NAMESPACE, base64.StdEncoding.EncodeToString(data))),
+	}
+	p.Objects = append(p.Objects, stream)
+	stream.SetName(len(p.Objects))
+	p.Catalog.AddNameObjectEntry(METADATA_KEY, pdfgo.NewObjectReference(stream))
+	return nil
+}
+
+// Extracts the signed digest record from the XMP metadata of a digest PDF, which must be uncompressed as written by pdfgo.
+func ReadSignature(data []byte) (*bcgo.Record, error) {
+	stream, err := readCatalogStream(data, METADATA_KEY)
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		return nil, errors.New(ERROR_NO_SIGNATURE)
+	}
+	meta := &xmpMeta{}
+	if err := xml.Unmarshal(stream, meta); err != nil {
+		return nil, err
+	}
+	for _, d := range meta.Descriptions {
+		if d.Signature == "" {
+			continue
+		}
+		signature, err := base64.StdEncoding.DecodeString(d.Signature)
+		if err != nil {
+			return nil, err
+		}
+		record := &bcgo.Record{}
+		if err := proto.Unmarshal(signature, record); err != nil {
+			return nil, err
+		}
+		return record, nil
+	}
+	return nil, errors.New(ERROR_NO_SIGNATURE)
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pdf_test
+
+import (
+	"bytes"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo/pdf"
+	"github.com/AletheiaWareLLC/pdfgo"
+	"github.com/AletheiaWareLLC/testinggo"
+	"testing"
+)
+
+func TestReadSignature(t *testing.T) {
+	t.Run("Exists", func(t *testing.T) {
+		p := pdfgo.NewPDF()
+		testinggo.AssertNoError(t, pdf.AddSignature(p, &bcgo.Record{
+			Timestamp: 1234,
+			Creator:   "Alice",
+			Payload:   []byte("Digest"),
+			Signature: []byte("Signature"),
+		}))
+		var buffer bytes.Buffer
+		testinggo.AssertNoError(t, p.Write(&buffer))
+		// The signature is held in the document's XMP metadata
+		for _, s := range []string{"/Metadata ", "/Type /Metadata /Subtype /XML", "<convey:Signature>"} {
+			if !bytes.Contains(buffer.Bytes(), []byte(s)) {
+				t.Errorf("Expected PDF to contain '%s'", s)
+			}
+		}
+		record, err := pdf.ReadSignature(buffer.Bytes())
+		testinggo.AssertNoError(t, err)
+		if record.Timestamp != 1234 || record.Creator != "Alice" || string(record.Payload) != "Digest" || string(record.Signature) != "Signature" {
+			t.Errorf("Wrong record; got '%v'", record)
+		}
+	})
+	t.Run("NotExists", func(t *testing.T) {
+		p := pdfgo.NewPDF()
+		var buffer bytes.Buffer
+		testinggo.AssertNoError(t, p.Write(&buffer))
+		_, err := pdf.ReadSignature(buffer.Bytes())
+		testinggo.AssertError(t, pdf.ERROR_NO_SIGNATURE, err)
+	})
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pdf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/conveygo/pdf/graphics"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	PAGE_A4     = "A4"
+	PAGE_A5     = "A5"
+	PAGE_LETTER = "Letter"
+	PAGE_CUSTOM = "Custom"
+
+	LAYOUT_FIBONACCI     = "Fibonacci"
+	LAYOUT_GRID          = "Grid"
+	LAYOUT_SINGLE_COLUMN = "SingleColumn"
+
+	ERROR_INVALID_PAGE_SIZE      = "Invalid Page Size: %gx%g"
+	ERROR_INVALID_FONT_SIZES     = "Invalid Font Sizes at Level %d: %gx%gx%g"
+	ERROR_INVALID_MARGINS        = "Invalid Margins: content area is %.2fx%.2f"
+	ERROR_MISSING_FONT_SIZES     = "Missing Font Sizes at Level %d"
+	ERROR_NO_FONT_SIZES          = "No Font Sizes"
+	ERROR_UNRECOGNIZED_LAYOUT    = "Unrecognized Layout: %s"
+	ERROR_UNRECOGNIZED_PAGE_SIZE = "Unrecognized Page Size: %s"
+)
+
+type Margins struct {
+	Top    float64
+	Right  float64
+	Bottom float64
+	Left   float64
+}
+
+type Theme struct {
+	Page    string  // One of A4, A5, Letter, or Custom
+	Width   float64 // Page width in points, only used for Custom pages
+	Height  float64 // Page height in points, only used for Custom pages
+	Margins Margins
+	Palette graphics.Palette
+	Levels  []*graphics.FontSizes // Font sizes of the highest-yielding entry first
+	Layout  string                // One of Fibonacci, Grid, or SingleColumn
+	QRCodes bool                  // Draw QR Codes linking each entry to its conversation
+}
+
+// Returns the theme of the original A4 print edition.
+func DefaultTheme() *Theme {
+	return &Theme{
+		Page: PAGE_A4,
+		Margins: Margins{
+			Top:    17.945,
+			Right:  49.64,
+			Bottom: 17.945,
+			Left:   49.64,
+		},
+		Palette: *graphics.DefaultPalette(),
+		Levels: []*graphics.FontSizes{
+			{Topic: 30, Meta: 11, Content: 15},
+			{Topic: 28, Meta: 10, Content: 14},
+			{Topic: 26, Meta: 9, Content: 13},
+			{Topic: 24, Meta: 8, Content: 12},
+		},
+		Layout:  LAYOUT_FIBONACCI,
+		QRCodes: true,
+	}
+}
+
+// Reads a JSON theme, any fields not given keep their default value.
+func ReadTheme(reader io.Reader) (*Theme, error) {
+	theme := DefaultTheme()
+	if err := json.NewDecoder(reader).Decode(theme); err != nil {
+		return nil, err
+	}
+	if err := theme.Validate(); err != nil {
+		return nil, err
+	}
+	return theme, nil
+}
+
+func LoadTheme(file string) (*Theme, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadTheme(f)
+}
+
+func (t *Theme) Validate() error {
+	width, height, err := t.GetPageSize()
+	if err != nil {
+		return err
+	}
+	w := width - t.Margins.Left - t.Margins.Right
+	h := height - t.Margins.Top - t.Margins.Bottom
+	if w <= 0 || h <= 0 {
+		return errors.New(fmt.Sprintf(ERROR_INVALID_MARGINS, w, h))
+	}
+	if len(t.Levels) == 0 {
+		return errors.New(ERROR_NO_FONT_SIZES)
+	}
+	for i, l := range t.Levels {
+		level := i + 1
+		if l == nil {
+			return errors.New(fmt.Sprintf(ERROR_MISSING_FONT_SIZES, level))
+		}
+		if l.Topic <= 0 || l.Meta <= 0 || l.Content <= 0 {
+			return errors.New(fmt.Sprintf(ERROR_INVALID_FONT_SIZES, level, l.Topic, l.Meta, l.Content))
+		}
+	}
+	switch {
+	case strings.EqualFold(t.Layout, LAYOUT_FIBONACCI),
+		strings.EqualFold(t.Layout, LAYOUT_GRID),
+		strings.EqualFold(t.Layout, LAYOUT_SINGLE_COLUMN):
+		return nil
+	default:
+		return errors.New(fmt.Sprintf(ERROR_UNRECOGNIZED_LAYOUT, t.Layout))
+	}
+}
+
+// Returns the width and height of the page in points.
+func (t *Theme) GetPageSize() (float64, float64, error) {
+	switch {
+	case strings.EqualFold(t.Page, PAGE_A4):
+		return 595.28, 841.89, nil
+	case strings.EqualFold(t.Page, PAGE_A5):
+		return 419.53, 595.28, nil
+	case strings.EqualFold(t.Page, PAGE_LETTER):
+		return 612, 792, nil
+	case strings.EqualFold(t.Page, PAGE_CUSTOM):
+		if t.Width <= 0 || t.Height <= 0 {
+			return 0, 0, errors.New(fmt.Sprintf(ERROR_INVALID_PAGE_SIZE, t.Width, t.Height))
+		}
+		return t.Width, t.Height, nil
+	default:
+		return 0, 0, errors.New(fmt.Sprintf(ERROR_UNRECOGNIZED_PAGE_SIZE, t.Page))
+	}
+}
+
+// Returns the font sizes for the given level, starting at 1, levels beyond those in the theme use the last.
+func (t *Theme) GetFontSizes(level int) *graphics.FontSizes {
+	index := level - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(t.Levels) {
+		index = len(t.Levels) - 1
+	}
+	return t.Levels[index]
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pdf_test
+
+import (
+	"github.com/AletheiaWareLLC/conveygo/pdf"
+	"github.com/AletheiaWareLLC/testinggo"
+	"strings"
+	"testing"
+)
+
+func TestReadTheme(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		theme, err := pdf.ReadTheme(strings.NewReader(`{}`))
+		testinggo.AssertNoError(t, err)
+		width, height, err := theme.GetPageSize()
+		testinggo.AssertNoError(t, err)
+		if width != 595.28 || height != 841.89 {
+			t.Errorf("Wrong page size; expected '595.28x841.89', got '%gx%g'", width, height)
+		}
+		if theme.Layout != pdf.LAYOUT_FIBONACCI {
+			t.Errorf("Wrong layout; expected '%s', got '%s'", pdf.LAYOUT_FIBONACCI, theme.Layout)
+		}
+	})
+	t.Run("Custom", func(t *testing.T) {
+		theme, err := pdf.ReadTheme(strings.NewReader(`{"Page":"Custom","Width":300,"Height":400,"Layout":"SingleColumn","Levels":[{"Topic":20,"Meta":8,"Content":10}]}`))
+		testinggo.AssertNoError(t, err)
+		width, height, err := theme.GetPageSize()
+		testinggo.AssertNoError(t, err)
+		if width != 300 || height != 400 {
+			t.Errorf("Wrong page size; expected '300x400', got '%gx%g'", width, height)
+		}
+		// Levels beyond those given use the last
+		if sizes := theme.GetFontSizes(3); sizes.Content != 10 {
+			t.Errorf("Wrong content font size; expected '%g', got '%g'", 10.0, sizes.Content)
+		}
+	})
+	t.Run("Custom_NoSize", func(t *testing.T) {
+		_, err := pdf.ReadTheme(strings.NewReader(`{"Page":"Custom"}`))
+		testinggo.AssertError(t, "Invalid Page Size: 0x0", err)
+	})
+	t.Run("UnrecognizedPageSize", func(t *testing.T) {
+		_, err := pdf.ReadTheme(strings.NewReader(`{"Page":"B4"}`))
+		testinggo.AssertError(t, "Unrecognized Page Size: B4", err)
+	})
+	t.Run("UnrecognizedLayout", func(t *testing.T) {
+		_, err := pdf.ReadTheme(strings.NewReader(`{"Layout":"Spiral"}`))
+		testinggo.AssertError(t, "Unrecognized Layout: Spiral", err)
+	})
+	t.Run("MissingFontSizes", func(t *testing.T) {
+		_, err := pdf.ReadTheme(strings.NewReader(`{"Levels":[{"Topic":20,"Meta":8,"Content":10},null]}`))
+		testinggo.AssertError(t, "Missing Font Sizes at Level 2", err)
+	})
+	t.Run("InvalidFontSizes", func(t *testing.T) {
+		_, err := pdf.ReadTheme(strings.NewReader(`{"Levels":[{"Topic":20,"Meta":0,"Content":-10}]}`))
+		testinggo.AssertError(t, "Invalid Font Sizes at Level 1: 20x0x-10", err)
+	})
+	t.Run("InvalidMargins", func(t *testing.T) {
+		_, err := pdf.ReadTheme(strings.NewReader(`{"Page":"A5","Margins":{"Left":300,"Right":300}}`))
+		testinggo.AssertError(t, "Invalid Margins: content area is -180.47x559.39", err)
+	})
+}
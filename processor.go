@@ -0,0 +1,79 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo
+
+import (
+	"github.com/AletheiaWareLLC/financego"
+	"github.com/stripe/stripe-go"
+	"github.com/stripe/stripe-go/refund"
+	"os"
+)
+
+const (
+	ERROR_NO_PAYMENT_PROCESSOR  = "No payment processor"
+	ERROR_NO_SUCH_CHARGE        = "No such charge: %s"
+	ERROR_NO_SUCH_CUSTOMER      = "No such customer: %s"
+	ERROR_NO_SUCH_REGISTRATION  = "No such registration: %s"
+	ERROR_PAYMENT_DECLINED      = "Payment declined: %s"
+	ERROR_REFUND_EXCEEDS_CHARGE = "Refund exceeds charge: %d > %d"
+)
+
+// PaymentProcessor creates customers, charges, subscriptions and refunds with a payment service, returning the records to write to the Convey chains.
+type PaymentProcessor interface {
+	// Creates a customer with the payment method, and returns their registration.
+	NewCustomer(merchant, alias, email, payment, description string) (*financego.Registration, error)
+	// Charges the registered customer the amount, in the smallest unit of the currency.
+	NewCharge(registration *financego.Registration, product, plan, country, currency string, amount int64, description string) (*financego.Charge, error)
+	// Subscribes the registered customer to the product plan.
+	NewSubscription(registration *financego.Registration, product, plan string) (*financego.Subscription, error)
+	// Refunds the amount of the charge, or all of it if the amount is zero, and returns the refund ID.
+	Refund(charge *financego.Charge, amount int64) (string, error)
+}
+
+// StripeProcessor processes payments with Stripe, using the secret key in the STRIPE_SECRET_KEY environment variable.
+type StripeProcessor struct {
+}
+
+func (p *StripeProcessor) NewCustomer(merchant, alias, email, payment, description string) (*financego.Registration, error) {
+	_, registration, err := financego.NewRegistration(merchant, alias, email, payment, description)
+	return registration, err
+}
+
+func (p *StripeProcessor) NewCharge(registration *financego.Registration, product, plan, country, currency string, amount int64, description string) (*financego.Charge, error) {
+	_, charge, err := financego.NewCustomerCharge(registration, product, plan, country, currency, amount, description)
+	return charge, err
+}
+
+func (p *StripeProcessor) NewSubscription(registration *financego.Registration, product, plan string) (*financego.Subscription, error) {
+	_, subscription, err := financego.NewSubscription(registration.MerchantAlias, registration.CustomerAlias, registration.CustomerId, registration.PaymentId, product, plan)
+	return subscription, err
+}
+
+func (p *StripeProcessor) Refund(charge *financego.Charge, amount int64) (string, error) {
+	stripe.Key = os.Getenv("STRIPE_SECRET_KEY")
+	params := &stripe.RefundParams{
+		Charge: stripe.String(charge.ChargeId),
+	}
+	if amount > 0 {
+		params.Amount = stripe.Int64(amount)
+	}
+	r, err := refund.New(params)
+	if err != nil {
+		return "", err
+	}
+	return r.ID, nil
+}
@@ -0,0 +1,193 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/cryptogo"
+)
+
+const (
+	ERROR_BLOCK_HASH_INCORRECT  = "Block hash incorrect: %s"
+	ERROR_BLOCK_NOT_IN_CHAIN    = "Block not in chain: %s"
+	ERROR_RECORD_HASH_INCORRECT = "Record hash incorrect: %s"
+	ERROR_RECORD_NOT_IN_BLOCK   = "Record not in block: %s"
+	ERROR_RECORD_NOT_IN_CHAIN   = "Record not in chain: %s"
+	ERROR_AUTHOR_MISMATCH       = "Author mismatch for %s: expected '%s', got '%s'"
+	ERROR_TOPIC_MISMATCH        = "Topic mismatch for %s: expected '%s', got '%s'"
+	ERROR_YIELD_MISMATCH        = "Yield mismatch for %s: expected '%d', got '%d'"
+)
+
+// DigestProvenance identifies where on the chain each entry of a digest came from.
+type DigestProvenance struct {
+	Head      string // Head of the Convey-Conversation chain when the digest was generated
+	Timestamp uint64 // Time the digest was generated, later messages don't count towards the yield
+	Entries   []*EntryProvenance
+}
+
+type EntryProvenance struct {
+	RecordHash string
+	BlockHash  string
+	Topic      string
+	Author     string
+	Yield      int64
+}
+
+// Returns the provenance of the given entries by locating the block holding each conversation in the chain ending at head.
+func NewDigestProvenance(cache bcgo.Cache, network bcgo.Network, head []byte, timestamp uint64, entries []*DigestEntry) (*DigestProvenance, error) {
+	blocks := make(map[string]string)
+	for _, e := range entries {
+		blocks[e.Hash] = ""
+	}
+	remaining := len(blocks)
+	if err := bcgo.Iterate(CONVEY_CONVERSATION, head, nil, cache, network, func(h []byte, b *bcgo.Block) error {
+		for _, entry := range b.Entry {
+			key := base64.RawURLEncoding.EncodeToString(entry.RecordHash)
+			if hash, ok := blocks[key]; ok && hash == "" {
+				blocks[key] = base64.RawURLEncoding.EncodeToString(h)
+				remaining--
+			}
+		}
+		if remaining == 0 {
+			return bcgo.StopIterationError{}
+		}
+		return nil
+	}); err != nil {
+		switch err.(type) {
+		case bcgo.StopIterationError:
+			// Do nothing
+			break
+		default:
+			return nil, err
+		}
+	}
+	provenance := &DigestProvenance{
+		Head:      base64.RawURLEncoding.EncodeToString(head),
+		Timestamp: timestamp,
+	}
+	for _, e := range entries {
+		block := blocks[e.Hash]
+		if block == "" {
+			return nil, errors.New(fmt.Sprintf(ERROR_RECORD_NOT_IN_CHAIN, e.Hash))
+		}
+		provenance.Entries = append(provenance.Entries, &EntryProvenance{
+			RecordHash: e.Hash,
+			BlockHash:  block,
+			Topic:      e.Topic,
+			Author:     e.Author,
+			Yield:      e.Yield,
+		})
+	}
+	return provenance, nil
+}
+
+// Confirms each entry's block is in the chain ending at the provenance head, and that the entry's topic, author, and yield match the chain.
+func VerifyDigestProvenance(cache bcgo.Cache, network bcgo.Network, provenance *DigestProvenance) error {
+	head, err := base64.RawURLEncoding.DecodeString(provenance.Head)
+	if err != nil {
+		return err
+	}
+
+	// Collect the hashes of blocks in the chain, checking each block matches its hash
+	chain := make(map[string]bool)
+	if err := bcgo.Iterate(CONVEY_CONVERSATION, head, nil, cache, network, func(h []byte, b *bcgo.Block) error {
+		hash, err := cryptogo.HashProtobuf(b)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(h, hash) {
+			return errors.New(fmt.Sprintf(ERROR_BLOCK_HASH_INCORRECT, base64.RawURLEncoding.EncodeToString(h)))
+		}
+		chain[base64.RawURLEncoding.EncodeToString(h)] = true
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	node := &bcgo.Node{
+		Cache:    cache,
+		Network:  network,
+		Channels: make(map[string]*bcgo.Channel),
+	}
+	messages := &BCStore{
+		Node: node,
+	}
+
+	for _, e := range provenance.Entries {
+		if !chain[e.BlockHash] {
+			return errors.New(fmt.Sprintf(ERROR_BLOCK_NOT_IN_CHAIN, e.BlockHash))
+		}
+		blockHash, err := base64.RawURLEncoding.DecodeString(e.BlockHash)
+		if err != nil {
+			return err
+		}
+		block, err := bcgo.GetBlock(CONVEY_CONVERSATION, cache, network, blockHash)
+		if err != nil {
+			return err
+		}
+		recordHash, err := base64.RawURLEncoding.DecodeString(e.RecordHash)
+		if err != nil {
+			return err
+		}
+		var listing *Listing
+		for _, entry := range block.Entry {
+			if bytes.Equal(recordHash, entry.RecordHash) {
+				hash, err := cryptogo.HashProtobuf(entry.Record)
+				if err != nil {
+					return err
+				}
+				if !bytes.Equal(recordHash, hash) {
+					return errors.New(fmt.Sprintf(ERROR_RECORD_HASH_INCORRECT, e.RecordHash))
+				}
+				listing, err = ConversationEntryToListing(entry)
+				if err != nil {
+					return err
+				}
+				break
+			}
+		}
+		if listing == nil {
+			return errors.New(fmt.Sprintf(ERROR_RECORD_NOT_IN_BLOCK, e.RecordHash))
+		}
+		if listing.Topic != e.Topic {
+			return errors.New(fmt.Sprintf(ERROR_TOPIC_MISMATCH, e.RecordHash, listing.Topic, e.Topic))
+		}
+		if listing.Author != e.Author {
+			return errors.New(fmt.Sprintf(ERROR_AUTHOR_MISMATCH, e.RecordHash, listing.Author, e.Author))
+		}
+
+		// Recalculate yield from the messages created before the digest
+		channel := bcgo.OpenPoWChannel(CONVEY_PREFIX_MESSAGE+e.RecordHash, bcgo.THRESHOLD_G)
+		if err := channel.LoadHead(cache, network); err != nil {
+			return err
+		}
+		node.AddChannel(channel)
+		cost, reward, err := messages.GetYieldUntil(recordHash, provenance.Timestamp)
+		if err != nil {
+			return err
+		}
+		yield := int64(reward) - int64(listing.Cost+cost)
+		if yield != e.Yield {
+			return errors.New(fmt.Sprintf(ERROR_YIELD_MISMATCH, e.RecordHash, yield, e.Yield))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/testinggo"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func makeProvenance(t *testing.T, store *conveygo.BCStore, alias string, key crypto.Signer) *conveygo.DigestProvenance {
+	t.Helper()
+	registerAlias(t, store.Node, alias, key)
+	testConversationStore_NewConversation(t, store, alias, key)
+	entries, err := conveygo.GetDigestEntries(store, 0, bcgo.Timestamp())
+	testinggo.AssertNoError(t, err)
+	conversations, err := store.Node.GetChannel(conveygo.CONVEY_CONVERSATION)
+	testinggo.AssertNoError(t, err)
+	provenance, err := conveygo.NewDigestProvenance(store.Node.Cache, nil, conversations.Head, bcgo.Timestamp(), entries)
+	testinggo.AssertNoError(t, err)
+	return provenance
+}
+
+func TestDigestProvenance(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	testinggo.AssertNoError(t, err)
+	defer os.RemoveAll(dir)
+	alias := "Alice"
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Error("Could not generate key:", err)
+	}
+	t.Run("Valid", func(t *testing.T) {
+		store := makeBCStore(t, alias, nil, dir)
+		provenance := makeProvenance(t, store, alias, key)
+		if len(provenance.Entries) != 1 {
+			t.Fatalf("Wrong number of entries; expected '1', got '%d'", len(provenance.Entries))
+		}
+		if provenance.Entries[0].Topic != "Test123" {
+			t.Errorf("Wrong topic; expected 'Test123', got '%s'", provenance.Entries[0].Topic)
+		}
+		testinggo.AssertNoError(t, conveygo.VerifyDigestProvenance(store.Node.Cache, nil, provenance))
+	})
+	t.Run("WrongTopic", func(t *testing.T) {
+		store := makeBCStore(t, alias, nil, dir)
+		provenance := makeProvenance(t, store, alias, key)
+		provenance.Entries[0].Topic = "Fake"
+		testinggo.AssertError(t, "Topic mismatch for "+provenance.Entries[0].RecordHash+": expected 'Test123', got 'Fake'", conveygo.VerifyDigestProvenance(store.Node.Cache, nil, provenance))
+	})
+	t.Run("WrongYield", func(t *testing.T) {
+		store := makeBCStore(t, alias, nil, dir)
+		provenance := makeProvenance(t, store, alias, key)
+		expected := provenance.Entries[0].Yield
+		provenance.Entries[0].Yield = 1000
+		testinggo.AssertError(t, "Yield mismatch for "+provenance.Entries[0].RecordHash+": expected '"+fmt.Sprint(expected)+"', got '1000'", conveygo.VerifyDigestProvenance(store.Node.Cache, nil, provenance))
+	})
+	t.Run("BlockNotInChain", func(t *testing.T) {
+		store := makeBCStore(t, alias, nil, dir)
+		provenance := makeProvenance(t, store, alias, key)
+		provenance.Entries[0].BlockHash = "DoesNotExist"
+		testinggo.AssertError(t, "Block not in chain: DoesNotExist", conveygo.VerifyDigestProvenance(store.Node.Cache, nil, provenance))
+	})
+}
@@ -0,0 +1,219 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/financego"
+	"github.com/golang/protobuf/proto"
+	"log"
+	"math"
+	"sort"
+)
+
+const (
+	ERROR_CHARGE_NOT_REFUNDED = "Charge not refunded after transfer failed: %s: %s"
+	ERROR_INVALID_PRICE       = "Invalid price: %d"
+)
+
+// Purchase confirms tokens were bought with fiat currency.
+type Purchase struct {
+	Charge     *financego.Charge
+	ChargeHash []byte
+	Receipt    *Receipt
+}
+
+// Charges the registered customer the price of each token with the payment processor, records the charge in the Convey-Charge Chain,
+// and transfers the tokens from the merchant to the customer in a transaction referencing the charge.
+// If the transfer fails the charge is refunded, and the refund recorded in the Convey-Refund Chain.
+func (s *BCStore) PurchaseTokens(ledger *Ledger, alias string, key crypto.Signer, tokens uint64, country, currency string, price int64) (*Purchase, error) {
+	if tokens == 0 {
+		return nil, errors.New(fmt.Sprintf(ERROR_INVALID_AMOUNT, tokens))
+	}
+	if price <= 0 || uint64(price) > math.MaxInt64/tokens {
+		return nil, errors.New(fmt.Sprintf(ERROR_INVALID_PRICE, price))
+	}
+	merchant := s.Node.Alias
+	if alias == merchant {
+		return nil, errors.New(fmt.Sprintf(ERROR_SELF_TRANSFER, alias))
+	}
+
+	// Check merchant can sell the tokens before charging the customer
+	if err := ledger.UpdateAll(); err != nil {
+		return nil, err
+	}
+	if balance, _ := ledger.GetBalance(merchant); balance < int64(tokens) {
+		return nil, errors.New(fmt.Sprintf(ERROR_INSUFFICIENT_BALANCE, balance, tokens))
+	}
+
+	charge, chargeHash, err := s.chargeCustomer(alias, key, "", "", country, currency, int64(tokens)*price, fmt.Sprintf("%d Convey Tokens", tokens))
+	if err != nil {
+		return nil, err
+	}
+
+	// The charge ID prevents a retry issuing the tokens twice
+	receipt, err := s.transfer(ledger, merchant, s.Node.Key, alias, tokens, charge.Description, charge.ChargeId, []*bcgo.Reference{
+		{
+			ChannelName: CONVEY_CHARGE,
+			RecordHash:  chargeHash,
+		},
+	})
+	if err != nil {
+		if e := s.refundCharge(alias, key, charge, chargeHash); e != nil {
+			return nil, errors.New(fmt.Sprintf(ERROR_CHARGE_NOT_REFUNDED, err, e))
+		}
+		return nil, err
+	}
+	return &Purchase{
+		Charge:     charge,
+		ChargeHash: chargeHash,
+		Receipt:    receipt,
+	}, nil
+}
+
+// Refunds the charge in full with the payment processor, and records the refund in the Convey-Refund Chain, readable by the customer and the merchant.
+func (s *BCStore) refundCharge(alias string, key crypto.Signer, charge *financego.Charge, chargeHash []byte) error {
+	publicKey, err := EncryptionKey(key)
+	if err != nil {
+		return err
+	}
+
+	// Refund Charge with Payment Processor
+	id, err := s.Processor.Refund(charge, 0)
+	if err != nil {
+		return err
+	}
+	refund := &Refund{
+		Charge:   chargeHash,
+		RefundId: id,
+		Amount:   charge.Amount,
+	}
+	log.Println("Refund", refund)
+
+	// Write Refund to Refund Channel
+	_, err = s.writeCustomerRecord(s.Node.GetOrOpenChannel(CONVEY_REFUND, OpenRefundChannel), alias, publicKey, refund)
+	return err
+}
+
+// Reconciliation compares the charges a merchant received against the tokens sold for them.
+type Reconciliation struct {
+	Merchant string
+	Charges  int
+	// Currency -> Amount charged
+	Received map[string]int64
+	// Tokens sold in transactions referencing the charges
+	Sold uint64
+	// Hashes of charges without a transaction selling the tokens
+	Unfulfilled []string
+	// Hashes of charges with more than one transaction selling the tokens
+	Duplicated []string
+	// Hashes of charges referenced by a transaction which were not received, or were refunded
+	Unmatched []string
+	// Hashes of refunded charges, which are not counted as received
+	Refunded []string
+}
+
+// Returns true if every charge received has exactly one transaction selling the tokens, and every such transaction references a charge received.
+func (r *Reconciliation) IsBalanced() bool {
+	return len(r.Unfulfilled) == 0 && len(r.Duplicated) == 0 && len(r.Unmatched) == 0
+}
+
+// Reconciles the charges the merchant received in the Convey-Charge Chain, which only the merchant and customer can read, against the tokens sold for them in the Convey-Transaction Chain.
+// Charges refunded in the Convey-Refund Chain are skipped.
+func (l *Ledger) Reconcile(merchant string, key *rsa.PrivateKey) (*Reconciliation, error) {
+	if err := l.UpdateAll(); err != nil {
+		return nil, err
+	}
+	r := &Reconciliation{
+		Merchant: merchant,
+		Received: make(map[string]int64),
+	}
+	refunded := make(map[string]bool)
+	if refunds, err := l.Node.GetChannel(CONVEY_REFUND); err == nil {
+		if err := bcgo.Read(refunds.Name, refunds.Head, nil, l.Node.Cache, l.Node.Network, merchant, key, nil, func(entry *bcgo.BlockEntry, key, data []byte) error {
+			// Unmarshal as Refund
+			refund := &Refund{}
+			if err := proto.Unmarshal(data, refund); err != nil {
+				return err
+			}
+			refunded[base64.RawURLEncoding.EncodeToString(refund.Charge)] = true
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	received := make(map[string]bool)
+	if charges, err := l.Node.GetChannel(CONVEY_CHARGE); err == nil {
+		if err := bcgo.Read(charges.Name, charges.Head, nil, l.Node.Cache, l.Node.Network, merchant, key, nil, func(entry *bcgo.BlockEntry, key, data []byte) error {
+			// Unmarshal as Charge
+			c := &financego.Charge{}
+			if err := proto.Unmarshal(data, c); err != nil {
+				return err
+			}
+			if c.MerchantAlias != merchant {
+				return nil
+			}
+			hash := base64.RawURLEncoding.EncodeToString(entry.RecordHash)
+			if refunded[hash] {
+				r.Refunded = append(r.Refunded, hash)
+				return nil
+			}
+			received[hash] = true
+			r.Charges++
+			r.Received[c.Currency] += c.Amount
+			fulfilled := 0
+			for _, t := range l.Purchases[hash] {
+				if t.Sender == merchant {
+					r.Sold += t.Amount
+					fulfilled++
+				}
+			}
+			switch fulfilled {
+			case 0:
+				r.Unfulfilled = append(r.Unfulfilled, hash)
+			case 1:
+				// Do nothing
+			default:
+				r.Duplicated = append(r.Duplicated, hash)
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for hash, ts := range l.Purchases {
+		if received[hash] {
+			continue
+		}
+		for _, t := range ts {
+			if t.Sender == merchant {
+				r.Unmatched = append(r.Unmatched, hash)
+				break
+			}
+		}
+	}
+	sort.Strings(r.Unfulfilled)
+	sort.Strings(r.Duplicated)
+	sort.Strings(r.Unmatched)
+	sort.Strings(r.Refunded)
+	return r, nil
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/testinggo"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+// rejectingValidator fails validation of every block, after calling Hook if it is set.
+type rejectingValidator struct {
+	Hook func()
+}
+
+func (v *rejectingValidator) Validate(channel *bcgo.Channel, cache bcgo.Cache, network bcgo.Network, hash []byte, block *bcgo.Block) error {
+	if v.Hook != nil {
+		v.Hook()
+	}
+	return errors.New("Rejected")
+}
+
+func TestPurchaseTokens(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	testinggo.AssertNoError(t, err)
+	defer os.RemoveAll(dir)
+	merchant := "Merchant"
+	merchantKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	testinggo.AssertNoError(t, err)
+	customer := "Alice"
+	customerKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	testinggo.AssertNoError(t, err)
+
+	setup := func(t *testing.T) (*conveygo.BCStore, *conveygo.FakeProcessor, *conveygo.Ledger) {
+		t.Helper()
+		s := makeBCStore(t, merchant, merchantKey, dir)
+		registerAlias(t, s.Node, merchant, merchantKey)
+		processor := conveygo.NewFakeProcessor()
+		s.Processor = processor
+		_, err := s.RegisterCustomer(customer, customerKey, "alice@example.com", "payment1234")
+		testinggo.AssertNoError(t, err)
+		ledger := conveygo.NewLedger(s.Node)
+		ledger.RecordMinted(merchant, 1000)
+		return s, processor, ledger
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		s, _, ledger := setup(t)
+		purchase, err := s.PurchaseTokens(ledger, customer, customerKey, 300, "US", "usd", 2)
+		testinggo.AssertNoError(t, err)
+		if purchase.Charge.Amount != 600 || purchase.Receipt.Transaction.Amount != 300 || purchase.Receipt.Transaction.IdempotencyKey != purchase.Charge.ChargeId {
+			t.Errorf("Wrong purchase; got '%v' '%v'", purchase.Charge, purchase.Receipt.Transaction)
+		}
+		if b, _ := ledger.GetBalance(customer); b != 300 {
+			t.Errorf("Wrong balance; expected '300', got '%d'", b)
+		}
+		r, err := ledger.Reconcile(merchant, merchantKey)
+		testinggo.AssertNoError(t, err)
+		if !r.IsBalanced() || r.Charges != 1 || r.Received["usd"] != 600 || r.Sold != 300 {
+			t.Errorf("Wrong reconciliation; got '%+v'", r)
+		}
+	})
+	t.Run("InsufficientBalance", func(t *testing.T) {
+		s, processor, ledger := setup(t)
+		_, err := s.PurchaseTokens(ledger, customer, customerKey, 3000, "US", "usd", 2)
+		testinggo.AssertError(t, "Insufficient balance: 1000 < 3000", err)
+		if len(processor.Charges) != 0 {
+			t.Errorf("Expected customer not to be charged")
+		}
+	})
+	t.Run("Declined", func(t *testing.T) {
+		s, processor, ledger := setup(t)
+		processor.Decline("payment1234")
+		_, err := s.PurchaseTokens(ledger, customer, customerKey, 300, "US", "usd", 2)
+		testinggo.AssertError(t, "Payment declined: payment1234", err)
+		if b, _ := ledger.GetBalance(customer); b != 0 {
+			t.Errorf("Wrong balance; expected '0', got '%d'", b)
+		}
+	})
+	t.Run("Unfulfilled", func(t *testing.T) {
+		s, _, ledger := setup(t)
+		_, err := s.PurchaseTokens(ledger, customer, customerKey, 300, "US", "usd", 2)
+		testinggo.AssertNoError(t, err)
+		// A charge without a transaction, such as when the merchant crashes after charging
+		_, err = s.ChargeCustomer(customer, customerKey, "", "", "US", "usd", 200, "")
+		testinggo.AssertNoError(t, err)
+		r, err := ledger.Reconcile(merchant, merchantKey)
+		testinggo.AssertNoError(t, err)
+		if r.IsBalanced() || r.Charges != 2 || r.Received["usd"] != 800 || r.Sold != 300 || len(r.Unfulfilled) != 1 {
+			t.Errorf("Wrong reconciliation; got '%+v'", r)
+		}
+		if _, err := base64.RawURLEncoding.DecodeString(r.Unfulfilled[0]); err != nil {
+			t.Errorf("Expected unfulfilled charge hash, got '%s'", r.Unfulfilled[0])
+		}
+	})
+	t.Run("InvalidPrice", func(t *testing.T) {
+		s, processor, ledger := setup(t)
+		_, err := s.PurchaseTokens(ledger, customer, customerKey, 300, "US", "usd", 0)
+		testinggo.AssertError(t, fmt.Sprintf(conveygo.ERROR_INVALID_PRICE, 0), err)
+		if len(processor.Charges) != 0 {
+			t.Errorf("Expected customer not to be charged")
+		}
+	})
+	t.Run("TransferFailed", func(t *testing.T) {
+		s, processor, ledger := setup(t)
+		transactions := s.Node.GetOrOpenChannel(conveygo.CONVEY_TRANSACTION, conveygo.OpenTransactionChannel)
+		transactions.AddValidator(&rejectingValidator{})
+		_, err := s.PurchaseTokens(ledger, customer, customerKey, 300, "US", "usd", 2)
+		testinggo.AssertError(t, "Chain invalid: Rejected", err)
+		if len(processor.Charges) != 1 {
+			t.Fatalf("Expected customer to be charged once, got '%d'", len(processor.Charges))
+		}
+		for id, c := range processor.Charges {
+			if processor.Refunded[id] != c.Amount {
+				t.Errorf("Wrong refund; expected '%d', got '%d'", c.Amount, processor.Refunded[id])
+			}
+		}
+		if b, _ := ledger.GetBalance(customer); b != 0 {
+			t.Errorf("Wrong balance; expected '0', got '%d'", b)
+		}
+		r, err := ledger.Reconcile(merchant, merchantKey)
+		testinggo.AssertNoError(t, err)
+		if !r.IsBalanced() || r.Charges != 0 || r.Received["usd"] != 0 || len(r.Refunded) != 1 {
+			t.Errorf("Wrong reconciliation; got '%+v'", r)
+		}
+	})
+	t.Run("RefundFailed", func(t *testing.T) {
+		s, processor, ledger := setup(t)
+		transactions := s.Node.GetOrOpenChannel(conveygo.CONVEY_TRANSACTION, conveygo.OpenTransactionChannel)
+		transactions.AddValidator(&rejectingValidator{
+			Hook: func() {
+				// The processor loses the charge, so it cannot be refunded
+				for id := range processor.Charges {
+					delete(processor.Charges, id)
+				}
+			},
+		})
+		_, err := s.PurchaseTokens(ledger, customer, customerKey, 300, "US", "usd", 2)
+		if err == nil || !strings.HasPrefix(err.Error(), "Charge not refunded after transfer failed: Chain invalid: Rejected: No such charge: ") {
+			t.Errorf("Expected refund error, got '%v'", err)
+		}
+		r, err := ledger.Reconcile(merchant, merchantKey)
+		testinggo.AssertNoError(t, err)
+		if r.IsBalanced() || len(r.Unfulfilled) != 1 || len(r.Refunded) != 0 {
+			t.Errorf("Wrong reconciliation; got '%+v'", r)
+		}
+	})
+	t.Run("Duplicated", func(t *testing.T) {
+		s, _, ledger := setup(t)
+		purchase, err := s.PurchaseTokens(ledger, customer, customerKey, 300, "US", "usd", 2)
+		testinggo.AssertNoError(t, err)
+		// A second transaction selling tokens for the same charge
+		hash, record, err := conveygo.ProtoToRecordWithReferences(merchant, merchantKey, bcgo.Timestamp(), []*bcgo.Reference{
+			{
+				ChannelName: conveygo.CONVEY_CHARGE,
+				RecordHash:  purchase.ChargeHash,
+			},
+		}, &conveygo.Transaction{
+			Sender:   merchant,
+			Receiver: customer,
+			Amount:   300,
+		})
+		testinggo.AssertNoError(t, err)
+		transactions := s.Node.GetOrOpenChannel(conveygo.CONVEY_TRANSACTION, conveygo.OpenTransactionChannel)
+		testinggo.AssertNoError(t, s.MineBlockEntry(transactions, &bcgo.BlockEntry{
+			RecordHash: hash,
+			Record:     record,
+		}))
+		r, err := ledger.Reconcile(merchant, merchantKey)
+		testinggo.AssertNoError(t, err)
+		if r.IsBalanced() || r.Charges != 1 || r.Sold != 600 || len(r.Duplicated) != 1 || r.Duplicated[0] != base64.RawURLEncoding.EncodeToString(purchase.ChargeHash) {
+			t.Errorf("Wrong reconciliation; got '%+v'", r)
+		}
+	})
+}
@@ -0,0 +1,272 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/AletheiaWareLLC/aliasgo"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/cryptogo"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sync"
+)
+
+const (
+	ERROR_MISSING_RECORD        = "Missing record: %s"
+	ERROR_NO_SUCH_MESSAGE       = "No such message: %s"
+	ERROR_RECORD_HASH_INCORRECT = "Record hash incorrect: %s"
+	ERROR_RECORD_SIGNATURE      = "Record signature incorrect: %s"
+)
+
+// Server implements the Convey service over a MessageStore and Ledger.
+// Records are created and signed by the client; the server checks each record hash and signature before storing it.
+type Server struct {
+	Messages conveygo.MessageStore
+	Ledger   *conveygo.Ledger
+	lock     sync.Mutex
+}
+
+func NewServer(messages conveygo.MessageStore, ledger *conveygo.Ledger) *Server {
+	return &Server{
+		Messages: messages,
+		Ledger:   ledger,
+	}
+}
+
+func (s *Server) GetConversation(ctx context.Context, request *ConversationRequest) (*conveygo.Listing, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.getConversation(request.ConversationHash)
+}
+
+func (s *Server) getConversation(hash []byte) (*conveygo.Listing, error) {
+	listing, err := s.Messages.GetConversation(hash)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return listing, nil
+}
+
+func (s *Server) ListConversations(request *ListConversationsRequest, stream Convey_ListConversationsServer) error {
+	var listings []*conveygo.Listing
+	var err error
+	s.lock.Lock()
+	if request.From == 0 && request.To == 0 {
+		listings, err = s.Messages.GetRecentConversations(uint(request.Limit))
+	} else {
+		to := request.To
+		if to == 0 {
+			to = bcgo.Timestamp()
+		}
+		listings, err = s.Messages.GetAllConversations(request.From, to)
+	}
+	s.lock.Unlock()
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	for _, l := range listings {
+		if err := stream.Send(l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) NewConversation(ctx context.Context, request *NewConversationRequest) (*conveygo.Listing, error) {
+	if err := checkRecord("conversation", request.ConversationHash, request.ConversationRecord); err != nil {
+		return nil, err
+	}
+	if err := checkRecord("message", request.MessageHash, request.MessageRecord); err != nil {
+		return nil, err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.checkSignature(request.ConversationHash, request.ConversationRecord); err != nil {
+		return nil, err
+	}
+	if err := s.checkSignature(request.MessageHash, request.MessageRecord); err != nil {
+		return nil, err
+	}
+	if err := s.Messages.NewConversation(request.ConversationHash, request.ConversationRecord, request.MessageHash, request.MessageRecord); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return s.getConversation(request.ConversationHash)
+}
+
+func (s *Server) AddMessage(ctx context.Context, request *AddMessageRequest) (*MessageEntry, error) {
+	if err := checkRecord("message", request.MessageHash, request.MessageRecord); err != nil {
+		return nil, err
+	}
+	message := &conveygo.Message{}
+	if err := proto.Unmarshal(request.MessageRecord.Payload, message); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.checkSignature(request.MessageHash, request.MessageRecord); err != nil {
+		return nil, err
+	}
+	if previous := message.Previous; len(previous) > 0 {
+		// The message being replied to must be in the same conversation
+		found := false
+		if err := s.Messages.GetMessage(request.ConversationHash, previous, func([]byte, uint64, string, uint64, *conveygo.Message) error {
+			found = true
+			return nil
+		}); err != nil {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		if !found {
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf(ERROR_NO_SUCH_MESSAGE, base64.RawURLEncoding.EncodeToString(previous)))
+		}
+	}
+	if err := s.Messages.AddMessage(request.ConversationHash, request.MessageHash, request.MessageRecord); err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &MessageEntry{
+		Hash:      request.MessageHash,
+		Timestamp: request.MessageRecord.Timestamp,
+		Author:    request.MessageRecord.Creator,
+		Cost:      conveygo.Cost(request.MessageRecord),
+		Message:   message,
+	}, nil
+}
+
+func (s *Server) GetMessages(request *MessagesRequest, stream Convey_GetMessagesServer) error {
+	// Messages are collected under the lock and sent after, so a slow client does not hold up others
+	var entries []*MessageEntry
+	s.lock.Lock()
+	err := s.Messages.GetMessage(request.ConversationHash, request.MessageHash, func(hash []byte, timestamp uint64, author string, cost uint64, message *conveygo.Message) error {
+		entries = append(entries, &MessageEntry{
+			Hash:      hash,
+			Timestamp: timestamp,
+			Author:    author,
+			Cost:      cost,
+			Message:   message,
+		})
+		return nil
+	})
+	s.lock.Unlock()
+	if err != nil {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	for _, e := range entries {
+		if err := stream.Send(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) GetYield(ctx context.Context, request *ConversationRequest) (*YieldResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	listing, err := s.Messages.GetConversation(request.ConversationHash)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	cost, reward, err := s.Messages.GetYield(request.ConversationHash)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	cost += listing.Cost
+	return &YieldResponse{
+		Cost:   cost,
+		Reward: reward,
+		Yield:  int64(reward) - int64(cost),
+	}, nil
+}
+
+func (s *Server) GetBalance(ctx context.Context, request *BalanceRequest) (*BalanceResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.Ledger.UpdateAll(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	alias := request.Alias
+	balance, _ := s.Ledger.GetBalance(alias)
+	return &BalanceResponse{
+		Alias:   alias,
+		Balance: balance,
+		Minted:  s.Ledger.Minted[alias],
+		Burned:  s.Ledger.Burned[alias],
+		Bought:  s.Ledger.Bought[alias],
+		Sold:    s.Ledger.Sold[alias],
+		Earned:  s.Ledger.Earned[alias],
+		Spent:   s.Ledger.Spent[alias],
+	}, nil
+}
+
+func (s *Server) GetTransactions(request *TransactionsRequest, stream Convey_GetTransactionsServer) error {
+	node := s.Ledger.Node
+	transactions, err := node.GetChannel(conveygo.CONVEY_TRANSACTION)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	if err := bcgo.Iterate(transactions.Name, transactions.Head, nil, node.Cache, node.Network, func(h []byte, b *bcgo.Block) error {
+		for _, entry := range b.Entry {
+			t := &conveygo.Transaction{}
+			if err := proto.Unmarshal(entry.Record.Payload, t); err != nil {
+				return err
+			}
+			if request.Alias == "" || t.Sender == request.Alias || t.Receiver == request.Alias {
+				if err := stream.Send(&TransactionEntry{
+					Hash:        entry.RecordHash,
+					Timestamp:   entry.Record.Timestamp,
+					Transaction: t,
+				}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// Checks the record is present and matches its hash.
+func checkRecord(name string, hash []byte, record *bcgo.Record) error {
+	if record == nil {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf(ERROR_MISSING_RECORD, name))
+	}
+	expected, err := cryptogo.HashProtobuf(record)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if !bytes.Equal(hash, expected) {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf(ERROR_RECORD_HASH_INCORRECT, base64.RawURLEncoding.EncodeToString(hash)))
+	}
+	return nil
+}
+
+// Checks the record was signed by the key that was active for its creator's alias at its timestamp.
+func (s *Server) checkSignature(hash []byte, record *bcgo.Record) error {
+	node := s.Ledger.Node
+	aliases := node.GetOrOpenChannel(aliasgo.ALIAS, aliasgo.OpenAliasChannel)
+	rotations := node.GetOrOpenChannel(conveygo.CONVEY_KEY_ROTATION, conveygo.OpenKeyRotationChannel)
+	if err := conveygo.VerifyRecordAt(aliases, rotations, node.Cache, node.Network, record); err != nil {
+		return status.Error(codes.PermissionDenied, fmt.Sprintf(ERROR_RECORD_SIGNATURE, base64.RawURLEncoding.EncodeToString(hash)))
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"github.com/AletheiaWareLLC/aliasgo"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/rpc"
+	"github.com/AletheiaWareLLC/testinggo"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"io"
+	"net"
+	"testing"
+)
+
+// Starts the server on an in-process listener and returns a client connected to it.
+func makeClient(t *testing.T, server *rpc.Server) (rpc.ConveyClient, func()) {
+	t.Helper()
+	listener := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	rpc.RegisterConveyServer(s, server)
+	go s.Serve(listener)
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+		return listener.Dial()
+	}), grpc.WithInsecure())
+	testinggo.AssertNoError(t, err)
+	return rpc.NewConveyClient(conn), func() {
+		conn.Close()
+		s.Stop()
+	}
+}
+
+func assertCode(t *testing.T, expected codes.Code, err error) {
+	t.Helper()
+	if actual := status.Code(err); actual != expected {
+		t.Errorf("Wrong code; expected '%s', got '%s' (%v)", expected, actual, err)
+	}
+}
+
+func TestServer(t *testing.T) {
+	alias := "Alice"
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	testinggo.AssertNoError(t, err)
+	node := &bcgo.Node{
+		Alias:    alias,
+		Cache:    bcgo.NewMemoryCache(10),
+		Channels: make(map[string]*bcgo.Channel),
+	}
+	transactions := bcgo.OpenPoWChannel(conveygo.CONVEY_TRANSACTION, bcgo.THRESHOLD_Z)
+	node.AddChannel(transactions)
+	// Register the alias so the server can verify record signatures
+	aliasRecord, err := conveygo.CreateSignedAliasRecord(alias, key)
+	testinggo.AssertNoError(t, err)
+	_, err = bcgo.WriteRecord(aliasgo.ALIAS, node.Cache, aliasRecord)
+	testinggo.AssertNoError(t, err)
+	aliases := node.GetOrOpenChannel(aliasgo.ALIAS, aliasgo.OpenAliasChannel)
+	_, _, err = node.Mine(aliases, aliasgo.ALIAS_THRESHOLD, nil)
+	testinggo.AssertNoError(t, err)
+	store := conveygo.NewMemoryStore()
+	client, closer := makeClient(t, rpc.NewServer(store, conveygo.NewLedger(node)))
+	defer closer()
+	ctx := context.Background()
+
+	timestamp := bcgo.Timestamp()
+	conversationHash, conversationRecord, err := conveygo.ProtoToRecord(alias, key, timestamp, &conveygo.Conversation{
+		Topic: "Test",
+	})
+	testinggo.AssertNoError(t, err)
+	messageHash, messageRecord, err := conveygo.ProtoToRecord(alias, key, timestamp, &conveygo.Message{
+		Content: []byte("Hello"),
+		Type:    conveygo.MediaType_TEXT_PLAIN,
+	})
+	testinggo.AssertNoError(t, err)
+
+	t.Run("NewConversation", func(t *testing.T) {
+		t.Run("HashIncorrect", func(t *testing.T) {
+			_, err := client.NewConversation(ctx, &rpc.NewConversationRequest{
+				ConversationHash:   messageHash,
+				ConversationRecord: conversationRecord,
+				MessageHash:        messageHash,
+				MessageRecord:      messageRecord,
+			})
+			assertCode(t, codes.InvalidArgument, err)
+		})
+		t.Run("SignatureIncorrect", func(t *testing.T) {
+			// Signed by a key that is not registered to the alias
+			other, err := rsa.GenerateKey(rand.Reader, 2048)
+			testinggo.AssertNoError(t, err)
+			forgedHash, forgedRecord, err := conveygo.ProtoToRecord(alias, other, timestamp, &conveygo.Conversation{
+				Topic: "Forged",
+			})
+			testinggo.AssertNoError(t, err)
+			_, err = client.NewConversation(ctx, &rpc.NewConversationRequest{
+				ConversationHash:   forgedHash,
+				ConversationRecord: forgedRecord,
+				MessageHash:        messageHash,
+				MessageRecord:      messageRecord,
+			})
+			assertCode(t, codes.PermissionDenied, err)
+		})
+		t.Run("Valid", func(t *testing.T) {
+			listing, err := client.NewConversation(ctx, &rpc.NewConversationRequest{
+				ConversationHash:   conversationHash,
+				ConversationRecord: conversationRecord,
+				MessageHash:        messageHash,
+				MessageRecord:      messageRecord,
+			})
+			testinggo.AssertNoError(t, err)
+			if listing.Topic != "Test" || listing.Author != alias {
+				t.Errorf("Wrong listing; expected 'Test' by '%s', got '%s' by '%s'", alias, listing.Topic, listing.Author)
+			}
+		})
+	})
+	t.Run("GetConversation", func(t *testing.T) {
+		t.Run("Exists", func(t *testing.T) {
+			listing, err := client.GetConversation(ctx, &rpc.ConversationRequest{
+				ConversationHash: conversationHash,
+			})
+			testinggo.AssertNoError(t, err)
+			if !bytes.Equal(listing.Hash, conversationHash) {
+				t.Errorf("Wrong hash; expected '%x', got '%x'", conversationHash, listing.Hash)
+			}
+		})
+		t.Run("NotExists", func(t *testing.T) {
+			_, err := client.GetConversation(ctx, &rpc.ConversationRequest{
+				ConversationHash: messageHash,
+			})
+			assertCode(t, codes.NotFound, err)
+		})
+	})
+	t.Run("ListConversations", func(t *testing.T) {
+		stream, err := client.ListConversations(ctx, &rpc.ListConversationsRequest{
+			Limit: 10,
+		})
+		testinggo.AssertNoError(t, err)
+		var count int
+		for {
+			_, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			testinggo.AssertNoError(t, err)
+			count++
+		}
+		if count != 1 {
+			t.Errorf("Wrong number of conversations; expected '1', got '%d'", count)
+		}
+	})
+	t.Run("AddMessage", func(t *testing.T) {
+		t.Run("NoSuchPrevious", func(t *testing.T) {
+			// The conversation's hash is not a message in it
+			replyHash, replyRecord, err := conveygo.ProtoToRecord(alias, key, bcgo.Timestamp(), &conveygo.Message{
+				Previous: conversationHash,
+				Content:  []byte("Reply"),
+				Type:     conveygo.MediaType_TEXT_PLAIN,
+			})
+			testinggo.AssertNoError(t, err)
+			_, err = client.AddMessage(ctx, &rpc.AddMessageRequest{
+				ConversationHash: conversationHash,
+				MessageHash:      replyHash,
+				MessageRecord:    replyRecord,
+			})
+			assertCode(t, codes.InvalidArgument, err)
+		})
+		replyHash, replyRecord, err := conveygo.ProtoToRecord(alias, key, bcgo.Timestamp(), &conveygo.Message{
+			Previous: messageHash,
+			Content:  []byte("Reply"),
+			Type:     conveygo.MediaType_TEXT_PLAIN,
+		})
+		testinggo.AssertNoError(t, err)
+		entry, err := client.AddMessage(ctx, &rpc.AddMessageRequest{
+			ConversationHash: conversationHash,
+			MessageHash:      replyHash,
+			MessageRecord:    replyRecord,
+		})
+		testinggo.AssertNoError(t, err)
+		if !bytes.Equal(entry.Message.Previous, messageHash) {
+			t.Errorf("Wrong previous; expected '%x', got '%x'", messageHash, entry.Message.Previous)
+		}
+	})
+	t.Run("GetMessages", func(t *testing.T) {
+		stream, err := client.GetMessages(ctx, &rpc.MessagesRequest{
+			ConversationHash: conversationHash,
+		})
+		testinggo.AssertNoError(t, err)
+		var contents []string
+		for {
+			entry, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			testinggo.AssertNoError(t, err)
+			contents = append(contents, string(entry.Message.Content))
+		}
+		if len(contents) != 2 {
+			t.Errorf("Wrong number of messages; expected '2', got '%d'", len(contents))
+		}
+	})
+	t.Run("GetYield", func(t *testing.T) {
+		yield, err := client.GetYield(ctx, &rpc.ConversationRequest{
+			ConversationHash: conversationHash,
+		})
+		testinggo.AssertNoError(t, err)
+		if yield.Yield != int64(yield.Reward)-int64(yield.Cost) {
+			t.Errorf("Wrong yield; expected '%d', got '%d'", int64(yield.Reward)-int64(yield.Cost), yield.Yield)
+		}
+	})
+	t.Run("Transactions", func(t *testing.T) {
+		data, err := proto.Marshal(&conveygo.Transaction{
+			Sender:   alias,
+			Receiver: "Bob",
+			Amount:   100,
+		})
+		testinggo.AssertNoError(t, err)
+		record, err := conveygo.CreateRecord(bcgo.Timestamp(), alias, key, nil, data)
+		testinggo.AssertNoError(t, err)
+		_, err = bcgo.WriteRecord(transactions.Name, node.Cache, record)
+		testinggo.AssertNoError(t, err)
+		_, _, err = node.Mine(transactions, bcgo.THRESHOLD_Z, nil)
+		testinggo.AssertNoError(t, err)
+
+		balance, err := client.GetBalance(ctx, &rpc.BalanceRequest{
+			Alias: "Bob",
+		})
+		testinggo.AssertNoError(t, err)
+		if balance.Balance != 100 || balance.Bought != 100 {
+			t.Errorf("Wrong balance; expected '100', got '%d'", balance.Balance)
+		}
+
+		stream, err := client.GetTransactions(ctx, &rpc.TransactionsRequest{
+			Alias: "Bob",
+		})
+		testinggo.AssertNoError(t, err)
+		entry, err := stream.Recv()
+		testinggo.AssertNoError(t, err)
+		if entry.Transaction.Amount != 100 || entry.Transaction.Sender != alias {
+			t.Errorf("Wrong transaction; expected '100' from '%s', got '%d' from '%s'", alias, entry.Transaction.Amount, entry.Transaction.Sender)
+		}
+		_, err = stream.Recv()
+		if err != io.EOF {
+			t.Errorf("Expected end of stream, got '%v'", err)
+		}
+	})
+}
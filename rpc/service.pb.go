@@ -0,0 +1,1139 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: rpc/service.proto
+
+package rpc
+
+import (
+	context "context"
+	fmt "fmt"
+	bcgo "github.com/AletheiaWareLLC/bcgo"
+	conveygo "github.com/AletheiaWareLLC/conveygo"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type ConversationRequest struct {
+	ConversationHash     []byte   `protobuf:"bytes,1,opt,name=conversation_hash,json=conversationHash,proto3" json:"conversation_hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ConversationRequest) Reset()         { *m = ConversationRequest{} }
+func (m *ConversationRequest) String() string { return proto.CompactTextString(m) }
+func (*ConversationRequest) ProtoMessage()    {}
+func (*ConversationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ec64d44e618a02a6, []int{0}
+}
+
+func (m *ConversationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConversationRequest.Unmarshal(m, b)
+}
+func (m *ConversationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConversationRequest.Marshal(b, m, deterministic)
+}
+func (m *ConversationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConversationRequest.Merge(m, src)
+}
+func (m *ConversationRequest) XXX_Size() int {
+	return xxx_messageInfo_ConversationRequest.Size(m)
+}
+func (m *ConversationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConversationRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConversationRequest proto.InternalMessageInfo
+
+func (m *ConversationRequest) GetConversationHash() []byte {
+	if m != nil {
+		return m.ConversationHash
+	}
+	return nil
+}
+
+type ListConversationsRequest struct {
+	// Maximum number of recent conversations, used when from and to are zero.
+	Limit                uint32   `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	From                 uint64   `protobuf:"varint,2,opt,name=from,proto3" json:"from,omitempty"`
+	To                   uint64   `protobuf:"varint,3,opt,name=to,proto3" json:"to,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListConversationsRequest) Reset()         { *m = ListConversationsRequest{} }
+func (m *ListConversationsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListConversationsRequest) ProtoMessage()    {}
+func (*ListConversationsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ec64d44e618a02a6, []int{1}
+}
+
+func (m *ListConversationsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListConversationsRequest.Unmarshal(m, b)
+}
+func (m *ListConversationsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListConversationsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListConversationsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListConversationsRequest.Merge(m, src)
+}
+func (m *ListConversationsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListConversationsRequest.Size(m)
+}
+func (m *ListConversationsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListConversationsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListConversationsRequest proto.InternalMessageInfo
+
+func (m *ListConversationsRequest) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *ListConversationsRequest) GetFrom() uint64 {
+	if m != nil {
+		return m.From
+	}
+	return 0
+}
+
+func (m *ListConversationsRequest) GetTo() uint64 {
+	if m != nil {
+		return m.To
+	}
+	return 0
+}
+
+type NewConversationRequest struct {
+	ConversationHash     []byte       `protobuf:"bytes,1,opt,name=conversation_hash,json=conversationHash,proto3" json:"conversation_hash,omitempty"`
+	ConversationRecord   *bcgo.Record `protobuf:"bytes,2,opt,name=conversation_record,json=conversationRecord,proto3" json:"conversation_record,omitempty"`
+	MessageHash          []byte       `protobuf:"bytes,3,opt,name=message_hash,json=messageHash,proto3" json:"message_hash,omitempty"`
+	MessageRecord        *bcgo.Record `protobuf:"bytes,4,opt,name=message_record,json=messageRecord,proto3" json:"message_record,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *NewConversationRequest) Reset()         { *m = NewConversationRequest{} }
+func (m *NewConversationRequest) String() string { return proto.CompactTextString(m) }
+func (*NewConversationRequest) ProtoMessage()    {}
+func (*NewConversationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ec64d44e618a02a6, []int{2}
+}
+
+func (m *NewConversationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NewConversationRequest.Unmarshal(m, b)
+}
+func (m *NewConversationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NewConversationRequest.Marshal(b, m, deterministic)
+}
+func (m *NewConversationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NewConversationRequest.Merge(m, src)
+}
+func (m *NewConversationRequest) XXX_Size() int {
+	return xxx_messageInfo_NewConversationRequest.Size(m)
+}
+func (m *NewConversationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_NewConversationRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NewConversationRequest proto.InternalMessageInfo
+
+func (m *NewConversationRequest) GetConversationHash() []byte {
+	if m != nil {
+		return m.ConversationHash
+	}
+	return nil
+}
+
+func (m *NewConversationRequest) GetConversationRecord() *bcgo.Record {
+	if m != nil {
+		return m.ConversationRecord
+	}
+	return nil
+}
+
+func (m *NewConversationRequest) GetMessageHash() []byte {
+	if m != nil {
+		return m.MessageHash
+	}
+	return nil
+}
+
+func (m *NewConversationRequest) GetMessageRecord() *bcgo.Record {
+	if m != nil {
+		return m.MessageRecord
+	}
+	return nil
+}
+
+type AddMessageRequest struct {
+	ConversationHash     []byte       `protobuf:"bytes,1,opt,name=conversation_hash,json=conversationHash,proto3" json:"conversation_hash,omitempty"`
+	MessageHash          []byte       `protobuf:"bytes,2,opt,name=message_hash,json=messageHash,proto3" json:"message_hash,omitempty"`
+	MessageRecord        *bcgo.Record `protobuf:"bytes,3,opt,name=message_record,json=messageRecord,proto3" json:"message_record,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *AddMessageRequest) Reset()         { *m = AddMessageRequest{} }
+func (m *AddMessageRequest) String() string { return proto.CompactTextString(m) }
+func (*AddMessageRequest) ProtoMessage()    {}
+func (*AddMessageRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ec64d44e618a02a6, []int{3}
+}
+
+func (m *AddMessageRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddMessageRequest.Unmarshal(m, b)
+}
+func (m *AddMessageRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddMessageRequest.Marshal(b, m, deterministic)
+}
+func (m *AddMessageRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddMessageRequest.Merge(m, src)
+}
+func (m *AddMessageRequest) XXX_Size() int {
+	return xxx_messageInfo_AddMessageRequest.Size(m)
+}
+func (m *AddMessageRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddMessageRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddMessageRequest proto.InternalMessageInfo
+
+func (m *AddMessageRequest) GetConversationHash() []byte {
+	if m != nil {
+		return m.ConversationHash
+	}
+	return nil
+}
+
+func (m *AddMessageRequest) GetMessageHash() []byte {
+	if m != nil {
+		return m.MessageHash
+	}
+	return nil
+}
+
+func (m *AddMessageRequest) GetMessageRecord() *bcgo.Record {
+	if m != nil {
+		return m.MessageRecord
+	}
+	return nil
+}
+
+type MessagesRequest struct {
+	ConversationHash []byte `protobuf:"bytes,1,opt,name=conversation_hash,json=conversationHash,proto3" json:"conversation_hash,omitempty"`
+	// Optional hash of a single message.
+	MessageHash          []byte   `protobuf:"bytes,2,opt,name=message_hash,json=messageHash,proto3" json:"message_hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MessagesRequest) Reset()         { *m = MessagesRequest{} }
+func (m *MessagesRequest) String() string { return proto.CompactTextString(m) }
+func (*MessagesRequest) ProtoMessage()    {}
+func (*MessagesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ec64d44e618a02a6, []int{4}
+}
+
+func (m *MessagesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MessagesRequest.Unmarshal(m, b)
+}
+func (m *MessagesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MessagesRequest.Marshal(b, m, deterministic)
+}
+func (m *MessagesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MessagesRequest.Merge(m, src)
+}
+func (m *MessagesRequest) XXX_Size() int {
+	return xxx_messageInfo_MessagesRequest.Size(m)
+}
+func (m *MessagesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MessagesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MessagesRequest proto.InternalMessageInfo
+
+func (m *MessagesRequest) GetConversationHash() []byte {
+	if m != nil {
+		return m.ConversationHash
+	}
+	return nil
+}
+
+func (m *MessagesRequest) GetMessageHash() []byte {
+	if m != nil {
+		return m.MessageHash
+	}
+	return nil
+}
+
+type MessageEntry struct {
+	Hash                 []byte            `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Timestamp            uint64            `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Author               string            `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	Cost                 uint64            `protobuf:"varint,4,opt,name=cost,proto3" json:"cost,omitempty"`
+	Message              *conveygo.Message `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *MessageEntry) Reset()         { *m = MessageEntry{} }
+func (m *MessageEntry) String() string { return proto.CompactTextString(m) }
+func (*MessageEntry) ProtoMessage()    {}
+func (*MessageEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ec64d44e618a02a6, []int{5}
+}
+
+func (m *MessageEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MessageEntry.Unmarshal(m, b)
+}
+func (m *MessageEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MessageEntry.Marshal(b, m, deterministic)
+}
+func (m *MessageEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MessageEntry.Merge(m, src)
+}
+func (m *MessageEntry) XXX_Size() int {
+	return xxx_messageInfo_MessageEntry.Size(m)
+}
+func (m *MessageEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_MessageEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MessageEntry proto.InternalMessageInfo
+
+func (m *MessageEntry) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *MessageEntry) GetTimestamp() uint64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *MessageEntry) GetAuthor() string {
+	if m != nil {
+		return m.Author
+	}
+	return ""
+}
+
+func (m *MessageEntry) GetCost() uint64 {
+	if m != nil {
+		return m.Cost
+	}
+	return 0
+}
+
+func (m *MessageEntry) GetMessage() *conveygo.Message {
+	if m != nil {
+		return m.Message
+	}
+	return nil
+}
+
+type YieldResponse struct {
+	Cost                 uint64   `protobuf:"varint,1,opt,name=cost,proto3" json:"cost,omitempty"`
+	Reward               uint64   `protobuf:"varint,2,opt,name=reward,proto3" json:"reward,omitempty"`
+	Yield                int64    `protobuf:"zigzag64,3,opt,name=yield,proto3" json:"yield,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *YieldResponse) Reset()         { *m = YieldResponse{} }
+func (m *YieldResponse) String() string { return proto.CompactTextString(m) }
+func (*YieldResponse) ProtoMessage()    {}
+func (*YieldResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ec64d44e618a02a6, []int{6}
+}
+
+func (m *YieldResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_YieldResponse.Unmarshal(m, b)
+}
+func (m *YieldResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_YieldResponse.Marshal(b, m, deterministic)
+}
+func (m *YieldResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_YieldResponse.Merge(m, src)
+}
+func (m *YieldResponse) XXX_Size() int {
+	return xxx_messageInfo_YieldResponse.Size(m)
+}
+func (m *YieldResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_YieldResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_YieldResponse proto.InternalMessageInfo
+
+func (m *YieldResponse) GetCost() uint64 {
+	if m != nil {
+		return m.Cost
+	}
+	return 0
+}
+
+func (m *YieldResponse) GetReward() uint64 {
+	if m != nil {
+		return m.Reward
+	}
+	return 0
+}
+
+func (m *YieldResponse) GetYield() int64 {
+	if m != nil {
+		return m.Yield
+	}
+	return 0
+}
+
+type BalanceRequest struct {
+	Alias                string   `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BalanceRequest) Reset()         { *m = BalanceRequest{} }
+func (m *BalanceRequest) String() string { return proto.CompactTextString(m) }
+func (*BalanceRequest) ProtoMessage()    {}
+func (*BalanceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ec64d44e618a02a6, []int{7}
+}
+
+func (m *BalanceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BalanceRequest.Unmarshal(m, b)
+}
+func (m *BalanceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BalanceRequest.Marshal(b, m, deterministic)
+}
+func (m *BalanceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BalanceRequest.Merge(m, src)
+}
+func (m *BalanceRequest) XXX_Size() int {
+	return xxx_messageInfo_BalanceRequest.Size(m)
+}
+func (m *BalanceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BalanceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BalanceRequest proto.InternalMessageInfo
+
+func (m *BalanceRequest) GetAlias() string {
+	if m != nil {
+		return m.Alias
+	}
+	return ""
+}
+
+type BalanceResponse struct {
+	Alias                string   `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	Balance              int64    `protobuf:"zigzag64,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	Minted               uint64   `protobuf:"varint,3,opt,name=minted,proto3" json:"minted,omitempty"`
+	Burned               uint64   `protobuf:"varint,4,opt,name=burned,proto3" json:"burned,omitempty"`
+	Bought               uint64   `protobuf:"varint,5,opt,name=bought,proto3" json:"bought,omitempty"`
+	Sold                 uint64   `protobuf:"varint,6,opt,name=sold,proto3" json:"sold,omitempty"`
+	Earned               uint64   `protobuf:"varint,7,opt,name=earned,proto3" json:"earned,omitempty"`
+	Spent                uint64   `protobuf:"varint,8,opt,name=spent,proto3" json:"spent,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BalanceResponse) Reset()         { *m = BalanceResponse{} }
+func (m *BalanceResponse) String() string { return proto.CompactTextString(m) }
+func (*BalanceResponse) ProtoMessage()    {}
+func (*BalanceResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ec64d44e618a02a6, []int{8}
+}
+
+func (m *BalanceResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BalanceResponse.Unmarshal(m, b)
+}
+func (m *BalanceResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BalanceResponse.Marshal(b, m, deterministic)
+}
+func (m *BalanceResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BalanceResponse.Merge(m, src)
+}
+func (m *BalanceResponse) XXX_Size() int {
+	return xxx_messageInfo_BalanceResponse.Size(m)
+}
+func (m *BalanceResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BalanceResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BalanceResponse proto.InternalMessageInfo
+
+func (m *BalanceResponse) GetAlias() string {
+	if m != nil {
+		return m.Alias
+	}
+	return ""
+}
+
+func (m *BalanceResponse) GetBalance() int64 {
+	if m != nil {
+		return m.Balance
+	}
+	return 0
+}
+
+func (m *BalanceResponse) GetMinted() uint64 {
+	if m != nil {
+		return m.Minted
+	}
+	return 0
+}
+
+func (m *BalanceResponse) GetBurned() uint64 {
+	if m != nil {
+		return m.Burned
+	}
+	return 0
+}
+
+func (m *BalanceResponse) GetBought() uint64 {
+	if m != nil {
+		return m.Bought
+	}
+	return 0
+}
+
+func (m *BalanceResponse) GetSold() uint64 {
+	if m != nil {
+		return m.Sold
+	}
+	return 0
+}
+
+func (m *BalanceResponse) GetEarned() uint64 {
+	if m != nil {
+		return m.Earned
+	}
+	return 0
+}
+
+func (m *BalanceResponse) GetSpent() uint64 {
+	if m != nil {
+		return m.Spent
+	}
+	return 0
+}
+
+type TransactionsRequest struct {
+	Alias                string   `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TransactionsRequest) Reset()         { *m = TransactionsRequest{} }
+func (m *TransactionsRequest) String() string { return proto.CompactTextString(m) }
+func (*TransactionsRequest) ProtoMessage()    {}
+func (*TransactionsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ec64d44e618a02a6, []int{9}
+}
+
+func (m *TransactionsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TransactionsRequest.Unmarshal(m, b)
+}
+func (m *TransactionsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TransactionsRequest.Marshal(b, m, deterministic)
+}
+func (m *TransactionsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransactionsRequest.Merge(m, src)
+}
+func (m *TransactionsRequest) XXX_Size() int {
+	return xxx_messageInfo_TransactionsRequest.Size(m)
+}
+func (m *TransactionsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransactionsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransactionsRequest proto.InternalMessageInfo
+
+func (m *TransactionsRequest) GetAlias() string {
+	if m != nil {
+		return m.Alias
+	}
+	return ""
+}
+
+type TransactionEntry struct {
+	Hash                 []byte                `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Timestamp            uint64                `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Transaction          *conveygo.Transaction `protobuf:"bytes,3,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *TransactionEntry) Reset()         { *m = TransactionEntry{} }
+func (m *TransactionEntry) String() string { return proto.CompactTextString(m) }
+func (*TransactionEntry) ProtoMessage()    {}
+func (*TransactionEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ec64d44e618a02a6, []int{10}
+}
+
+func (m *TransactionEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TransactionEntry.Unmarshal(m, b)
+}
+func (m *TransactionEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TransactionEntry.Marshal(b, m, deterministic)
+}
+func (m *TransactionEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransactionEntry.Merge(m, src)
+}
+func (m *TransactionEntry) XXX_Size() int {
+	return xxx_messageInfo_TransactionEntry.Size(m)
+}
+func (m *TransactionEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransactionEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransactionEntry proto.InternalMessageInfo
+
+func (m *TransactionEntry) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *TransactionEntry) GetTimestamp() uint64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *TransactionEntry) GetTransaction() *conveygo.Transaction {
+	if m != nil {
+		return m.Transaction
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*ConversationRequest)(nil), "convey.rpc.ConversationRequest")
+	proto.RegisterType((*ListConversationsRequest)(nil), "convey.rpc.ListConversationsRequest")
+	proto.RegisterType((*NewConversationRequest)(nil), "convey.rpc.NewConversationRequest")
+	proto.RegisterType((*AddMessageRequest)(nil), "convey.rpc.AddMessageRequest")
+	proto.RegisterType((*MessagesRequest)(nil), "convey.rpc.MessagesRequest")
+	proto.RegisterType((*MessageEntry)(nil), "convey.rpc.MessageEntry")
+	proto.RegisterType((*YieldResponse)(nil), "convey.rpc.YieldResponse")
+	proto.RegisterType((*BalanceRequest)(nil), "convey.rpc.BalanceRequest")
+	proto.RegisterType((*BalanceResponse)(nil), "convey.rpc.BalanceResponse")
+	proto.RegisterType((*TransactionsRequest)(nil), "convey.rpc.TransactionsRequest")
+	proto.RegisterType((*TransactionEntry)(nil), "convey.rpc.TransactionEntry")
+}
+
+func init() {
+	proto.RegisterFile("rpc/service.proto", fileDescriptor_ec64d44e618a02a6)
+}
+
+var fileDescriptor_ec64d44e618a02a6 = []byte{
+	// 709 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x55, 0xd1, 0x4e, 0xdb, 0x30,
+	0x14, 0x25, 0xa5, 0x14, 0xb8, 0x2d, 0x94, 0xba, 0x13, 0xca, 0x02, 0xd3, 0x58, 0x34, 0x6d, 0x20,
+	0xa4, 0x74, 0x63, 0xda, 0xd3, 0x9e, 0x00, 0xa1, 0xf2, 0xc0, 0x90, 0x66, 0x21, 0x4d, 0xdb, 0xcb,
+	0xe4, 0x3a, 0x5e, 0x13, 0xa9, 0x89, 0x33, 0xdb, 0x05, 0x55, 0xfb, 0x8f, 0xed, 0xa3, 0xf6, 0x03,
+	0xfb, 0x81, 0xfd, 0xc7, 0x14, 0xc7, 0x69, 0x5d, 0x08, 0x02, 0x31, 0xed, 0xcd, 0xe7, 0xf8, 0xe4,
+	0xdc, 0x7b, 0xdd, 0x7b, 0x6f, 0xa1, 0x23, 0x32, 0xda, 0x93, 0x4c, 0x5c, 0xc6, 0x94, 0x05, 0x99,
+	0xe0, 0x8a, 0x23, 0xa0, 0x3c, 0xbd, 0x64, 0x93, 0x40, 0x64, 0xd4, 0x5b, 0x19, 0xd0, 0x82, 0xf5,
+	0x5a, 0x86, 0xd5, 0xc8, 0x3f, 0x82, 0xee, 0x71, 0x8e, 0x85, 0x24, 0x2a, 0xe6, 0x29, 0x66, 0xdf,
+	0xc6, 0x4c, 0x2a, 0xb4, 0x0f, 0x1d, 0x6a, 0xd1, 0x5f, 0x22, 0x22, 0x23, 0xd7, 0xd9, 0x71, 0x76,
+	0x5b, 0x78, 0xc3, 0xbe, 0x38, 0x25, 0x32, 0xf2, 0x2f, 0xc0, 0x3d, 0x8b, 0xa5, 0xb2, 0x7d, 0x64,
+	0x69, 0xf4, 0x08, 0x96, 0x46, 0x71, 0x12, 0x2b, 0xfd, 0xf1, 0x1a, 0x2e, 0x00, 0x42, 0x50, 0xff,
+	0x2a, 0x78, 0xe2, 0xd6, 0x76, 0x9c, 0xdd, 0x3a, 0xd6, 0x67, 0xb4, 0x0e, 0x35, 0xc5, 0xdd, 0x45,
+	0xcd, 0xd4, 0x14, 0xf7, 0x7f, 0x3b, 0xb0, 0x79, 0xce, 0xae, 0xfe, 0x35, 0x3b, 0xf4, 0x0e, 0xba,
+	0x73, 0x62, 0xc1, 0x28, 0x17, 0xa1, 0x0e, 0xdd, 0x3c, 0x80, 0x60, 0x40, 0x03, 0xac, 0x19, 0x8c,
+	0xe8, 0x5c, 0xa8, 0x9c, 0x43, 0xcf, 0xa0, 0x95, 0x30, 0x29, 0xc9, 0x90, 0x15, 0x41, 0x16, 0x75,
+	0x90, 0xa6, 0xe1, 0xb4, 0xff, 0x6b, 0x58, 0x2f, 0x25, 0xc6, 0xba, 0x7e, 0xc3, 0x7a, 0xcd, 0x28,
+	0x0a, 0xe8, 0xff, 0x70, 0xa0, 0x73, 0x18, 0x86, 0xef, 0x4b, 0xf2, 0x01, 0x55, 0x5d, 0x4f, 0xac,
+	0x76, 0x9f, 0xc4, 0x16, 0xef, 0x4a, 0x8c, 0x40, 0xdb, 0x24, 0x25, 0xff, 0x53, 0x56, 0xfe, 0x4f,
+	0x07, 0x5a, 0x26, 0xc6, 0x49, 0xaa, 0xc4, 0x24, 0xef, 0x05, 0xcb, 0x53, 0x9f, 0xd1, 0x36, 0xac,
+	0xaa, 0x38, 0x61, 0x52, 0x91, 0x24, 0x33, 0x4d, 0x32, 0x23, 0xd0, 0x26, 0x34, 0xc8, 0x58, 0x45,
+	0x5c, 0xe8, 0x82, 0x56, 0xb1, 0x41, 0xb9, 0x13, 0xe5, 0x52, 0xe9, 0xf7, 0xaf, 0x63, 0x7d, 0x46,
+	0x7b, 0xb0, 0x6c, 0xa2, 0xbb, 0x4b, 0xba, 0xfa, 0x76, 0x60, 0xfa, 0xbf, 0x7c, 0xfd, 0xf2, 0xde,
+	0xff, 0x00, 0x6b, 0x9f, 0x62, 0x36, 0x0a, 0x31, 0x93, 0x19, 0x4f, 0x25, 0x9b, 0xfa, 0x39, 0x96,
+	0xdf, 0x26, 0x34, 0x04, 0xbb, 0x22, 0xa6, 0x81, 0xea, 0xd8, 0xa0, 0xbc, 0xcf, 0x27, 0xf9, 0xc7,
+	0x3a, 0x25, 0x84, 0x0b, 0xe0, 0xbf, 0x80, 0xf5, 0x23, 0x32, 0x22, 0x29, 0x65, 0xd6, 0x3c, 0x90,
+	0x51, 0x4c, 0xa4, 0x36, 0x5d, 0xc5, 0x05, 0xf0, 0x7f, 0x39, 0xd0, 0x9e, 0x0a, 0x4d, 0xf4, 0x4a,
+	0x25, 0x72, 0x61, 0x79, 0x50, 0x08, 0x75, 0x02, 0x08, 0x97, 0x30, 0xcf, 0x2c, 0x89, 0x53, 0xc5,
+	0x42, 0x33, 0x43, 0x06, 0xe5, 0xfc, 0x60, 0x2c, 0x52, 0x16, 0x9a, 0x77, 0x31, 0x48, 0xf3, 0x7c,
+	0x3c, 0x8c, 0x94, 0xbb, 0x64, 0x78, 0x8d, 0xf2, 0xaa, 0x25, 0x1f, 0x85, 0x6e, 0xa3, 0xa8, 0x3a,
+	0x3f, 0xe7, 0x5a, 0x46, 0xb4, 0xc7, 0x72, 0xa1, 0x2d, 0x50, 0x9e, 0xa3, 0xcc, 0x58, 0xaa, 0xdc,
+	0x15, 0x4d, 0x17, 0xc0, 0xdf, 0x87, 0xee, 0x85, 0x20, 0xa9, 0x24, 0xf4, 0xfa, 0x2a, 0xa8, 0x28,
+	0xfd, 0x3b, 0x6c, 0x58, 0xe2, 0x87, 0xb6, 0xc4, 0x5b, 0x68, 0xaa, 0x99, 0x8b, 0x69, 0xf4, 0x6e,
+	0xf9, 0x53, 0x5b, 0x01, 0xb0, 0xad, 0x3b, 0xf8, 0x53, 0x87, 0x86, 0x5e, 0x30, 0x13, 0x74, 0x02,
+	0xed, 0x3e, 0x9b, 0xdb, 0x61, 0xe8, 0x69, 0x30, 0x5b, 0xa0, 0x41, 0xc5, 0x1e, 0xf2, 0xa6, 0xbd,
+	0x94, 0xaf, 0xbf, 0x38, 0x1d, 0xfa, 0x0b, 0xe8, 0x1c, 0x3a, 0x37, 0x76, 0x21, 0x7a, 0x6e, 0x1b,
+	0xdd, 0xb6, 0x2a, 0x2b, 0xdc, 0x5e, 0x39, 0xe8, 0x14, 0xda, 0xd7, 0x96, 0x20, 0xf2, 0x6d, 0xb7,
+	0xea, 0x0d, 0x59, 0x95, 0x59, 0x1f, 0x60, 0xb6, 0x73, 0xd0, 0x13, 0xdb, 0xe4, 0xc6, 0x2e, 0xf2,
+	0x5c, 0xfb, 0xda, 0x1e, 0x57, 0x7f, 0x01, 0x9d, 0x42, 0xb3, 0xcf, 0x94, 0x21, 0x25, 0xda, 0xaa,
+	0x90, 0xca, 0x7b, 0xf8, 0xe8, 0xe2, 0x56, 0xfa, 0x4c, 0xe9, 0xa1, 0xbb, 0xfb, 0xb1, 0x1f, 0xdb,
+	0x82, 0xb9, 0x41, 0x2d, 0x8a, 0xeb, 0x33, 0x65, 0x46, 0x08, 0x79, 0xb6, 0x74, 0x7e, 0x00, 0xbd,
+	0xad, 0xca, 0xbb, 0xa9, 0xd1, 0x85, 0x6e, 0x03, 0xbb, 0x7d, 0xe7, 0x33, 0xab, 0x68, 0x6c, 0x6f,
+	0xfb, 0x16, 0xc1, 0xb4, 0xd0, 0xa3, 0xbd, 0xcf, 0x2f, 0x87, 0xb1, 0x8a, 0xc6, 0x83, 0x80, 0xf2,
+	0xa4, 0x77, 0x38, 0x62, 0x2a, 0x62, 0x31, 0xf9, 0x48, 0x04, 0x3b, 0x3b, 0x3b, 0xee, 0x15, 0x5f,
+	0x0f, 0x79, 0x4f, 0x64, 0x74, 0xd0, 0xd0, 0xff, 0xcb, 0x6f, 0xfe, 0x0e, 0x00, 0xb0, 0xdd, 0xa1,
+	0xd1, 0xd0, 0x07, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// ConveyClient is the client API for Convey service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ConveyClient interface {
+	// Returns the listing of a conversation.
+	GetConversation(ctx context.Context, in *ConversationRequest, opts ...grpc.CallOption) (*conveygo.Listing, error)
+	// Streams recent conversations, or those in a time period.
+	ListConversations(ctx context.Context, in *ListConversationsRequest, opts ...grpc.CallOption) (Convey_ListConversationsClient, error)
+	// Starts a conversation from records signed by the client.
+	NewConversation(ctx context.Context, in *NewConversationRequest, opts ...grpc.CallOption) (*conveygo.Listing, error)
+	// Adds a message signed by the client to a conversation.
+	AddMessage(ctx context.Context, in *AddMessageRequest, opts ...grpc.CallOption) (*MessageEntry, error)
+	// Streams the messages in a conversation.
+	GetMessages(ctx context.Context, in *MessagesRequest, opts ...grpc.CallOption) (Convey_GetMessagesClient, error)
+	// Returns the cost and reward of a conversation.
+	GetYield(ctx context.Context, in *ConversationRequest, opts ...grpc.CallOption) (*YieldResponse, error)
+	// Returns the balance of an alias.
+	GetBalance(ctx context.Context, in *BalanceRequest, opts ...grpc.CallOption) (*BalanceResponse, error)
+	// Streams the transactions sent or received by an alias, or all transactions if the alias is empty.
+	GetTransactions(ctx context.Context, in *TransactionsRequest, opts ...grpc.CallOption) (Convey_GetTransactionsClient, error)
+}
+
+type conveyClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewConveyClient(cc grpc.ClientConnInterface) ConveyClient {
+	return &conveyClient{cc}
+}
+
+func (c *conveyClient) GetConversation(ctx context.Context, in *ConversationRequest, opts ...grpc.CallOption) (*conveygo.Listing, error) {
+	out := new(conveygo.Listing)
+	err := c.cc.Invoke(ctx, "/convey.rpc.Convey/GetConversation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *conveyClient) ListConversations(ctx context.Context, in *ListConversationsRequest, opts ...grpc.CallOption) (Convey_ListConversationsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Convey_serviceDesc.Streams[0], "/convey.rpc.Convey/ListConversations", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &conveyListConversationsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Convey_ListConversationsClient interface {
+	Recv() (*conveygo.Listing, error)
+	grpc.ClientStream
+}
+
+type conveyListConversationsClient struct {
+	grpc.ClientStream
+}
+
+func (x *conveyListConversationsClient) Recv() (*conveygo.Listing, error) {
+	m := new(conveygo.Listing)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *conveyClient) NewConversation(ctx context.Context, in *NewConversationRequest, opts ...grpc.CallOption) (*conveygo.Listing, error) {
+	out := new(conveygo.Listing)
+	err := c.cc.Invoke(ctx, "/convey.rpc.Convey/NewConversation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *conveyClient) AddMessage(ctx context.Context, in *AddMessageRequest, opts ...grpc.CallOption) (*MessageEntry, error) {
+	out := new(MessageEntry)
+	err := c.cc.Invoke(ctx, "/convey.rpc.Convey/AddMessage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *conveyClient) GetMessages(ctx context.Context, in *MessagesRequest, opts ...grpc.CallOption) (Convey_GetMessagesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Convey_serviceDesc.Streams[1], "/convey.rpc.Convey/GetMessages", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &conveyGetMessagesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Convey_GetMessagesClient interface {
+	Recv() (*MessageEntry, error)
+	grpc.ClientStream
+}
+
+type conveyGetMessagesClient struct {
+	grpc.ClientStream
+}
+
+func (x *conveyGetMessagesClient) Recv() (*MessageEntry, error) {
+	m := new(MessageEntry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *conveyClient) GetYield(ctx context.Context, in *ConversationRequest, opts ...grpc.CallOption) (*YieldResponse, error) {
+	out := new(YieldResponse)
+	err := c.cc.Invoke(ctx, "/convey.rpc.Convey/GetYield", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *conveyClient) GetBalance(ctx context.Context, in *BalanceRequest, opts ...grpc.CallOption) (*BalanceResponse, error) {
+	out := new(BalanceResponse)
+	err := c.cc.Invoke(ctx, "/convey.rpc.Convey/GetBalance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *conveyClient) GetTransactions(ctx context.Context, in *TransactionsRequest, opts ...grpc.CallOption) (Convey_GetTransactionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Convey_serviceDesc.Streams[2], "/convey.rpc.Convey/GetTransactions", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &conveyGetTransactionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Convey_GetTransactionsClient interface {
+	Recv() (*TransactionEntry, error)
+	grpc.ClientStream
+}
+
+type conveyGetTransactionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *conveyGetTransactionsClient) Recv() (*TransactionEntry, error) {
+	m := new(TransactionEntry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ConveyServer is the server API for Convey service.
+type ConveyServer interface {
+	// Returns the listing of a conversation.
+	GetConversation(context.Context, *ConversationRequest) (*conveygo.Listing, error)
+	// Streams recent conversations, or those in a time period.
+	ListConversations(*ListConversationsRequest, Convey_ListConversationsServer) error
+	// Starts a conversation from records signed by the client.
+	NewConversation(context.Context, *NewConversationRequest) (*conveygo.Listing, error)
+	// Adds a message signed by the client to a conversation.
+	AddMessage(context.Context, *AddMessageRequest) (*MessageEntry, error)
+	// Streams the messages in a conversation.
+	GetMessages(*MessagesRequest, Convey_GetMessagesServer) error
+	// Returns the cost and reward of a conversation.
+	GetYield(context.Context, *ConversationRequest) (*YieldResponse, error)
+	// Returns the balance of an alias.
+	GetBalance(context.Context, *BalanceRequest) (*BalanceResponse, error)
+	// Streams the transactions sent or received by an alias, or all transactions if the alias is empty.
+	GetTransactions(*TransactionsRequest, Convey_GetTransactionsServer) error
+}
+
+// UnimplementedConveyServer can be embedded to have forward compatible implementations.
+type UnimplementedConveyServer struct {
+}
+
+func (*UnimplementedConveyServer) GetConversation(ctx context.Context, req *ConversationRequest) (*conveygo.Listing, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConversation not implemented")
+}
+func (*UnimplementedConveyServer) ListConversations(req *ListConversationsRequest, srv Convey_ListConversationsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListConversations not implemented")
+}
+func (*UnimplementedConveyServer) NewConversation(ctx context.Context, req *NewConversationRequest) (*conveygo.Listing, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NewConversation not implemented")
+}
+func (*UnimplementedConveyServer) AddMessage(ctx context.Context, req *AddMessageRequest) (*MessageEntry, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddMessage not implemented")
+}
+func (*UnimplementedConveyServer) GetMessages(req *MessagesRequest, srv Convey_GetMessagesServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetMessages not implemented")
+}
+func (*UnimplementedConveyServer) GetYield(ctx context.Context, req *ConversationRequest) (*YieldResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetYield not implemented")
+}
+func (*UnimplementedConveyServer) GetBalance(ctx context.Context, req *BalanceRequest) (*BalanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
+}
+func (*UnimplementedConveyServer) GetTransactions(req *TransactionsRequest, srv Convey_GetTransactionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetTransactions not implemented")
+}
+
+func RegisterConveyServer(s *grpc.Server, srv ConveyServer) {
+	s.RegisterService(&_Convey_serviceDesc, srv)
+}
+
+func _Convey_GetConversation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConveyServer).GetConversation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/convey.rpc.Convey/GetConversation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConveyServer).GetConversation(ctx, req.(*ConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Convey_ListConversations_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListConversationsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ConveyServer).ListConversations(m, &conveyListConversationsServer{stream})
+}
+
+type Convey_ListConversationsServer interface {
+	Send(*conveygo.Listing) error
+	grpc.ServerStream
+}
+
+type conveyListConversationsServer struct {
+	grpc.ServerStream
+}
+
+func (x *conveyListConversationsServer) Send(m *conveygo.Listing) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Convey_NewConversation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NewConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConveyServer).NewConversation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/convey.rpc.Convey/NewConversation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConveyServer).NewConversation(ctx, req.(*NewConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Convey_AddMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConveyServer).AddMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/convey.rpc.Convey/AddMessage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConveyServer).AddMessage(ctx, req.(*AddMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Convey_GetMessages_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(MessagesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ConveyServer).GetMessages(m, &conveyGetMessagesServer{stream})
+}
+
+type Convey_GetMessagesServer interface {
+	Send(*MessageEntry) error
+	grpc.ServerStream
+}
+
+type conveyGetMessagesServer struct {
+	grpc.ServerStream
+}
+
+func (x *conveyGetMessagesServer) Send(m *MessageEntry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Convey_GetYield_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConveyServer).GetYield(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/convey.rpc.Convey/GetYield",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConveyServer).GetYield(ctx, req.(*ConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Convey_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConveyServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/convey.rpc.Convey/GetBalance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConveyServer).GetBalance(ctx, req.(*BalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Convey_GetTransactions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TransactionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ConveyServer).GetTransactions(m, &conveyGetTransactionsServer{stream})
+}
+
+type Convey_GetTransactionsServer interface {
+	Send(*TransactionEntry) error
+	grpc.ServerStream
+}
+
+type conveyGetTransactionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *conveyGetTransactionsServer) Send(m *TransactionEntry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Convey_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "convey.rpc.Convey",
+	HandlerType: (*ConveyServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetConversation",
+			Handler:    _Convey_GetConversation_Handler,
+		},
+		{
+			MethodName: "NewConversation",
+			Handler:    _Convey_NewConversation_Handler,
+		},
+		{
+			MethodName: "AddMessage",
+			Handler:    _Convey_AddMessage_Handler,
+		},
+		{
+			MethodName: "GetYield",
+			Handler:    _Convey_GetYield_Handler,
+		},
+		{
+			MethodName: "GetBalance",
+			Handler:    _Convey_GetBalance_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListConversations",
+			Handler:       _Convey_ListConversations_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetMessages",
+			Handler:       _Convey_GetMessages_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetTransactions",
+			Handler:       _Convey_GetTransactions_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "rpc/service.proto",
+}
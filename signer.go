@@ -0,0 +1,225 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/aliasgo"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/cryptogo"
+	"github.com/golang/protobuf/proto"
+	"os"
+	"path"
+)
+
+const (
+	ERROR_UNSUPPORTED_KEY_TYPE      = "Unsupported key type: %T"
+	ERROR_KEY_CANNOT_DECRYPT        = "Key cannot receive encrypted records: %T"
+	ERROR_KEY_CANNOT_SIGN_RECORDS   = "Key cannot sign records, only RSA keys are supported: %T"
+	ERROR_ECDSA_CURVE               = "Unsupported ECDSA curve: %s"
+	ERROR_PRIVATE_KEY_NOT_ENCRYPTED = "Private key file not encrypted: %s"
+)
+
+// Checks the key is of a type that can be held in a keystore; RSA, ECDSA on the P-256 curve, or Ed25519.
+func checkKeyType(key crypto.PublicKey) error {
+	switch k := key.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+		return nil
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return errors.New(fmt.Sprintf(ERROR_ECDSA_CURVE, k.Curve.Params().Name))
+		}
+		return nil
+	default:
+		return errors.New(fmt.Sprintf(ERROR_UNSUPPORTED_KEY_TYPE, key))
+	}
+}
+
+// Returns the algorithm used to sign records with the given key; RSA keys use RSASSA-PSS, as bcgo does.
+// cryptogo has no signature algorithms for ECDSA or Ed25519, so those keys cannot sign records.
+func SignatureAlgorithmForKey(key crypto.PublicKey) (cryptogo.SignatureAlgorithm, error) {
+	if _, ok := key.(*rsa.PublicKey); !ok {
+		return cryptogo.SignatureAlgorithm_UNKNOWN_SIGNATURE, errors.New(fmt.Sprintf(ERROR_KEY_CANNOT_SIGN_RECORDS, key))
+	}
+	return cryptogo.SignatureAlgorithm_SHA512WITHRSA_PSS, nil
+}
+
+// Signs the SHA-512 hash with the signer, and returns the signature and the algorithm used.
+func CreateSignature(signer crypto.Signer, hash []byte) ([]byte, cryptogo.SignatureAlgorithm, error) {
+	algorithm, err := SignatureAlgorithmForKey(signer.Public())
+	if err != nil {
+		return nil, algorithm, err
+	}
+	signature, err := signer.Sign(rand.Reader, hash, &rsa.PSSOptions{
+		SaltLength: rsa.PSSSaltLengthAuto,
+		Hash:       crypto.SHA512,
+	})
+	if err != nil {
+		return nil, algorithm, err
+	}
+	return signature, algorithm, nil
+}
+
+// Verifies the signature of the SHA-512 hash with the public key.
+func VerifySignature(key crypto.PublicKey, hash, signature []byte, algorithm cryptogo.SignatureAlgorithm) error {
+	k, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return errors.New(fmt.Sprintf(ERROR_KEY_CANNOT_SIGN_RECORDS, key))
+	}
+	return cryptogo.VerifySignature(k, hash, signature, algorithm)
+}
+
+// Creates a public record of the payload signed by the creator, which must have an RSA key.
+// RSA private keys are signed by bcgo, other RSA signers, such as those held by an agent, are signed in the same form.
+func CreateRecord(timestamp uint64, creatorAlias string, creatorKey crypto.Signer, references []*bcgo.Reference, payload []byte) (*bcgo.Record, error) {
+	if key, ok := creatorKey.(*rsa.PrivateKey); ok {
+		_, record, err := bcgo.CreateRecord(timestamp, creatorAlias, key, nil, references, payload)
+		return record, err
+	}
+	if size := uint64(len(payload)); size > bcgo.MAX_PAYLOAD_SIZE_BYTES {
+		return nil, errors.New("Payload too large: " + bcgo.BinarySizeToString(size) + " max: " + bcgo.BinarySizeToString(bcgo.MAX_PAYLOAD_SIZE_BYTES))
+	}
+	signature, algorithm, err := CreateSignature(creatorKey, cryptogo.Hash(payload))
+	if err != nil {
+		return nil, err
+	}
+	return &bcgo.Record{
+		Timestamp:           timestamp,
+		Creator:             creatorAlias,
+		Payload:             payload,
+		EncryptionAlgorithm: cryptogo.EncryptionAlgorithm_UNKNOWN_ENCRYPTION,
+		Signature:           signature,
+		SignatureAlgorithm:  algorithm,
+		Reference:           references,
+	}, nil
+}
+
+// Returns the RSA public key of the signer, which records can be encrypted for.
+func EncryptionKey(signer crypto.Signer) (*rsa.PublicKey, error) {
+	key, ok := signer.Public().(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf(ERROR_KEY_CANNOT_DECRYPT, signer.Public()))
+	}
+	return key, nil
+}
+
+// Parses a public key of any supported type.
+func ParsePublicKey(publicKey []byte, format cryptogo.PublicKeyFormat) (crypto.PublicKey, error) {
+	switch format {
+	case cryptogo.PublicKeyFormat_PKIX, cryptogo.PublicKeyFormat_X509:
+		key, err := x509.ParsePKIXPublicKey(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkKeyType(key); err != nil {
+			return nil, err
+		}
+		return key, nil
+	default:
+		return cryptogo.ParseRSAPublicKey(publicKey, format)
+	}
+}
+
+// Returns the public key registered to the alias in the Alias Chain, of any supported type.
+func GetPublicKey(aliases *bcgo.Channel, cache bcgo.Cache, network bcgo.Network, alias string) (crypto.PublicKey, error) {
+	_, a, err := aliasgo.GetRecord(aliases, cache, network, alias)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePublicKey(a.PublicKey, a.PublicFormat)
+}
+
+// Creates an alias record for the signer's public key, signed by the signer.
+func CreateSignedAliasRecord(alias string, signer crypto.Signer) (*bcgo.Record, error) {
+	if key, ok := signer.(*rsa.PrivateKey); ok {
+		return aliasgo.CreateSignedAliasRecord(alias, key)
+	}
+	if err := aliasgo.ValidateAlias(alias); err != nil {
+		return nil, err
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	// aliasgo only takes RSA private keys, so the record for other RSA signers is created here in the same form
+	data, err := proto.Marshal(&aliasgo.Alias{
+		Alias:        alias,
+		PublicKey:    publicKey,
+		PublicFormat: cryptogo.PublicKeyFormat_PKIX,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return CreateRecord(bcgo.Timestamp(), alias, signer, nil, data)
+}
+
+// Parses a PKCS #8 private key of any supported type.
+func ParsePrivateKey(privateKey []byte) (crypto.Signer, error) {
+	key, err := x509.ParsePKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf(ERROR_UNSUPPORTED_KEY_TYPE, key))
+	}
+	if err := checkKeyType(signer.Public()); err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
+
+// Writes the private key to the directory, encrypted with the password, in the same form as cryptogo.WriteRSAPrivateKey.
+func WritePrivateKey(key crypto.Signer, directory, name string, password []byte) error {
+	data, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+	block, err := x509.EncryptPEMBlock(rand.Reader, "ENCRYPTED PRIVATE KEY", data, password, x509.PEMCipherAES128)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(directory, os.ModePerm); err != nil {
+		return err
+	}
+	return cryptogo.WritePEM(block, path.Join(directory, name+".go.private"))
+}
+
+// Reads the private key from the directory, decrypted with the password; keys written by cryptogo.WriteRSAPrivateKey are also read.
+func ReadPrivateKey(directory, name string, password []byte) (crypto.Signer, error) {
+	filename := path.Join(directory, name+".go.private")
+	block, err := cryptogo.ReadPEM(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !x509.IsEncryptedPEMBlock(block) {
+		return nil, errors.New(fmt.Sprintf(ERROR_PRIVATE_KEY_NOT_ENCRYPTED, filename))
+	}
+	data, err := x509.DecryptPEMBlock(block, password)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePrivateKey(data)
+}
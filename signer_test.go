@@ -0,0 +1,159 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"github.com/AletheiaWareLLC/aliasgo"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/cryptogo"
+	"github.com/AletheiaWareLLC/testinggo"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func assertKeyEqual(t *testing.T, expected, actual crypto.Signer) {
+	t.Helper()
+	e, err := x509.MarshalPKCS8PrivateKey(expected)
+	testinggo.AssertNoError(t, err)
+	a, err := x509.MarshalPKCS8PrivateKey(actual)
+	testinggo.AssertNoError(t, err)
+	if !bytes.Equal(e, a) {
+		t.Errorf("Wrong private key; expected '%T', got '%T'", expected, actual)
+	}
+}
+
+func assertPublicKeyEqual(t *testing.T, expected, actual crypto.PublicKey) {
+	t.Helper()
+	e, err := x509.MarshalPKIXPublicKey(expected)
+	testinggo.AssertNoError(t, err)
+	a, err := x509.MarshalPKIXPublicKey(actual)
+	testinggo.AssertNoError(t, err)
+	if !bytes.Equal(e, a) {
+		t.Errorf("Wrong public key; expected '%T', got '%T'", expected, actual)
+	}
+}
+
+func TestSigner(t *testing.T) {
+	alias := "Alice"
+	password := []byte("password1234")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	testinggo.AssertNoError(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	testinggo.AssertNoError(t, err)
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	testinggo.AssertNoError(t, err)
+	t.Run("RSA", func(t *testing.T) {
+		t.Run("Record", func(t *testing.T) {
+			record, err := conveygo.CreateRecord(bcgo.Timestamp(), alias, rsaKey, nil, []byte("FooBar"))
+			testinggo.AssertNoError(t, err)
+			testinggo.AssertNoError(t, conveygo.VerifyRecord(rsaKey.Public(), record))
+			record.Payload = []byte("Tampered")
+			testinggo.AssertError(t, rsa.ErrVerification.Error(), conveygo.VerifyRecord(rsaKey.Public(), record))
+		})
+		t.Run("Alias", func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "keystore")
+			testinggo.AssertNoError(t, err)
+			defer os.RemoveAll(dir)
+			store := makeBCStore(t, alias, nil, dir)
+			testinggo.AssertNoError(t, store.RegisterAlias(alias, password, rsaKey))
+			aliases, err := store.Node.GetChannel(aliasgo.ALIAS)
+			testinggo.AssertNoError(t, err)
+			actual, err := conveygo.GetPublicKey(aliases, store.Node.Cache, nil, alias)
+			testinggo.AssertNoError(t, err)
+			assertPublicKeyEqual(t, rsaKey.Public(), actual)
+		})
+	})
+	// cryptogo has no signature algorithms for other key types, so they cannot sign records
+	for name, key := range map[string]crypto.Signer{
+		"ECDSA":   ecdsaKey,
+		"Ed25519": ed25519Key,
+	} {
+		key := key
+		t.Run(name, func(t *testing.T) {
+			t.Run("Record", func(t *testing.T) {
+				_, err := conveygo.CreateRecord(bcgo.Timestamp(), alias, key, nil, []byte("FooBar"))
+				testinggo.AssertError(t, fmt.Sprintf(conveygo.ERROR_KEY_CANNOT_SIGN_RECORDS, key.Public()), err)
+			})
+			t.Run("Alias", func(t *testing.T) {
+				dir, err := ioutil.TempDir("", "keystore")
+				testinggo.AssertNoError(t, err)
+				defer os.RemoveAll(dir)
+				store := makeBCStore(t, alias, nil, dir)
+				testinggo.AssertError(t, fmt.Sprintf(conveygo.ERROR_KEY_CANNOT_SIGN_RECORDS, key.Public()), store.RegisterAlias(alias, password, key))
+			})
+			t.Run("File", func(t *testing.T) {
+				dir, err := ioutil.TempDir("", "keystore")
+				testinggo.AssertNoError(t, err)
+				defer os.RemoveAll(dir)
+				testinggo.AssertNoError(t, conveygo.WritePrivateKey(key, dir, alias, password))
+				_, err = conveygo.ReadPrivateKey(dir, alias, []byte("newpassword5678"))
+				if err == nil {
+					t.Error("Expected error reading with wrong password")
+				}
+				actual, err := conveygo.ReadPrivateKey(dir, alias, password)
+				testinggo.AssertNoError(t, err)
+				assertKeyEqual(t, key, actual)
+			})
+		})
+	}
+	t.Run("RSAFile", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "keystore")
+		testinggo.AssertNoError(t, err)
+		defer os.RemoveAll(dir)
+		testinggo.AssertNoError(t, conveygo.WritePrivateKey(rsaKey, dir, alias, password))
+		actual, err := conveygo.ReadPrivateKey(dir, alias, password)
+		testinggo.AssertNoError(t, err)
+		assertKeyEqual(t, rsaKey, actual)
+	})
+	t.Run("ExistingRSAFile", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "keystore")
+		testinggo.AssertNoError(t, err)
+		defer os.RemoveAll(dir)
+		testinggo.AssertNoError(t, cryptogo.WriteRSAPrivateKey(rsaKey, dir, alias, password))
+		actual, err := conveygo.ReadPrivateKey(dir, alias, password)
+		testinggo.AssertNoError(t, err)
+		assertKeyEqual(t, rsaKey, actual)
+	})
+	t.Run("UnsupportedCurve", func(t *testing.T) {
+		key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		testinggo.AssertNoError(t, err)
+		dir, err := ioutil.TempDir("", "keystore")
+		testinggo.AssertNoError(t, err)
+		defer os.RemoveAll(dir)
+		testinggo.AssertNoError(t, conveygo.WritePrivateKey(key, dir, alias, password))
+		_, err = conveygo.ReadPrivateKey(dir, alias, password)
+		testinggo.AssertError(t, fmt.Sprintf(conveygo.ERROR_ECDSA_CURVE, "P-384"), err)
+	})
+	t.Run("EncryptionKey", func(t *testing.T) {
+		key, err := conveygo.EncryptionKey(rsaKey)
+		testinggo.AssertNoError(t, err)
+		assertPublicKeyEqual(t, rsaKey.Public(), key)
+		_, err = conveygo.EncryptionKey(ed25519Key)
+		testinggo.AssertError(t, fmt.Sprintf(conveygo.ERROR_KEY_CANNOT_DECRYPT, ed25519Key.Public()), err)
+	})
+}
@@ -17,7 +17,7 @@
 package conveygo
 
 import (
-	"crypto/rsa"
+	"crypto"
 	"github.com/AletheiaWareLLC/bcgo"
 	"github.com/AletheiaWareLLC/financego"
 )
@@ -26,6 +26,8 @@ const (
 	ERROR_ACCESS_DENIED        = "Access denied"
 	ERROR_NO_SUCH_CONVERSATION = "No such conversation: %s"
 	ERROR_KEY_ALREADY_EXISTS   = "Key already exists: %s"
+	ERROR_ALREADY_REGISTERED   = "Already registered: %s"
+	ERROR_ALREADY_SUBSCRIBED   = "Already subscribed: %s"
 )
 
 type ConversationStore interface {
@@ -42,13 +44,35 @@ type MessageStore interface {
 	GetYield(conversationHash []byte) (uint64, uint64, error)
 }
 
+type TagStore interface {
+	AddTag(messageHash, tagHash []byte, tagRecord *bcgo.Record) error
+	GetTags(messageHash []byte, callback func([]byte, uint64, string, *Tag) error) error
+}
+
+type TransactionStore interface {
+	Transfer(ledger *Ledger, alias string, key crypto.Signer, receiver string, amount uint64, memo, idempotencyKey string) (*Receipt, error)
+	GetTransactions(alias string, callback func([]byte, uint64, *Transaction) error) error
+}
+
+type EscrowStore interface {
+	LockEscrow(ledger *Ledger, alias string, key crypto.Signer, amount, deadline uint64, memo string, conversation []byte) ([]byte, error)
+	ReleaseEscrow(ledger *Ledger, alias string, key crypto.Signer, escrowHash []byte, receiver string) ([]byte, error)
+	RefundEscrow(ledger *Ledger, alias string, key crypto.Signer, escrowHash []byte) ([]byte, error)
+	GetEscrowRelease(escrowHash []byte) (*EscrowRelease, error)
+}
+
 type UserStore interface {
-	AddKey(alias string, password []byte, key *rsa.PrivateKey) error
-	GetKey(alias string, password []byte) (*rsa.PrivateKey, error)
+	AddKey(alias string, password []byte, key crypto.Signer) error
+	GetKey(alias string, password []byte) (crypto.Signer, error)
 	HasKey(alias string) bool
-	RegisterAlias(alias string, password []byte, key *rsa.PrivateKey) error
-	RegisterCustomer(alias string, key *rsa.PrivateKey, customerId string) error
+	ChangePassword(alias string, oldPassword, newPassword []byte) error
+	RotateKey(alias string, password []byte, key crypto.Signer) error
+	ExportKey(alias string, password []byte) ([]byte, error)
+	ImportKey(data, password []byte, force bool) (string, error)
+	RegisterAlias(alias string, password []byte, key crypto.Signer) error
+	RegisterCustomer(alias string, key crypto.Signer, email, payment string) (*financego.Registration, error)
 	GetRegistration(alias string) (*financego.Registration, error)
-	// TODO(v3) SubscribeCustomer(alias string, key *rsa.PrivateKey, customer, product, plan string) error
-	// TODO(v3) GetSubscription(alias string) (*financego.Subscription, error)
+	ChargeCustomer(alias string, key crypto.Signer, product, plan, country, currency string, amount int64, description string) (*financego.Charge, error)
+	SubscribeCustomer(alias string, key crypto.Signer, product, plan string) (*financego.Subscription, error)
+	GetSubscription(alias string) (*financego.Subscription, error)
 }
@@ -17,42 +17,44 @@
 package conveygo_test
 
 import (
-	"crypto/rsa"
+	"crypto"
+	"encoding/base64"
 	"fmt"
 	"github.com/AletheiaWareLLC/bcgo"
 	"github.com/AletheiaWareLLC/conveygo"
 	"github.com/AletheiaWareLLC/testinggo"
 	"testing"
+	"time"
 )
 
-func testUserStore_AddKey_Exists(t *testing.T, s conveygo.UserStore, alias string, password []byte, key *rsa.PrivateKey) {
+func testUserStore_AddKey_Exists(t *testing.T, s conveygo.UserStore, alias string, password []byte, key crypto.Signer) {
 	t.Helper()
 	testinggo.AssertNoError(t, s.AddKey(alias, password, key))
 	testinggo.AssertError(t, fmt.Sprintf(conveygo.ERROR_KEY_ALREADY_EXISTS, alias), s.AddKey(alias, password, key))
 }
 
-func testUserStore_AddKey_NotExists(t *testing.T, s conveygo.UserStore, alias string, password []byte, key *rsa.PrivateKey) {
+func testUserStore_AddKey_NotExists(t *testing.T, s conveygo.UserStore, alias string, password []byte, key crypto.Signer) {
 	t.Helper()
 	testinggo.AssertNoError(t, s.AddKey(alias, password, key))
 }
 
-func testUserStore_GetKey_Exists(t *testing.T, s conveygo.UserStore, alias string, password []byte, key *rsa.PrivateKey) {
+func testUserStore_GetKey_Exists(t *testing.T, s conveygo.UserStore, alias string, password []byte, key crypto.Signer) {
 	t.Helper()
 	testinggo.AssertNoError(t, s.AddKey(alias, password, key))
 	_, err := s.GetKey(alias, append(password, password...))
 	testinggo.AssertError(t, conveygo.ERROR_ACCESS_DENIED, err)
 	actual, err := s.GetKey(alias, password)
 	testinggo.AssertNoError(t, err)
-	testinggo.AssertPrivateKeyEqual(t, key, actual)
+	assertKeyEqual(t, key, actual)
 }
 
-func testUserStore_GetKey_NotExists(t *testing.T, s conveygo.UserStore, alias string, password []byte, key *rsa.PrivateKey) {
+func testUserStore_GetKey_NotExists(t *testing.T, s conveygo.UserStore, alias string, password []byte, key crypto.Signer) {
 	t.Helper()
 	_, err := s.GetKey(alias, password)
 	testinggo.AssertError(t, conveygo.ERROR_ACCESS_DENIED, err)
 }
 
-func testUserStore_HasKey_Exists(t *testing.T, s conveygo.UserStore, alias string, password []byte, key *rsa.PrivateKey) {
+func testUserStore_HasKey_Exists(t *testing.T, s conveygo.UserStore, alias string, password []byte, key crypto.Signer) {
 	t.Helper()
 	testinggo.AssertNoError(t, s.AddKey(alias, password, key))
 	if !s.HasKey(alias) {
@@ -60,64 +62,217 @@ func testUserStore_HasKey_Exists(t *testing.T, s conveygo.UserStore, alias strin
 	}
 }
 
-func testUserStore_HasKey_NotExists(t *testing.T, s conveygo.UserStore, alias string, password []byte, key *rsa.PrivateKey) {
+func testUserStore_HasKey_NotExists(t *testing.T, s conveygo.UserStore, alias string, password []byte, key crypto.Signer) {
 	t.Helper()
 	if s.HasKey(alias) {
 		t.Error("HasKey should return false")
 	}
 }
 
-func testUserStore_RegisterAlias_Exists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key *rsa.PrivateKey) {
+func testUserStore_ChangePassword_Exists(t *testing.T, s conveygo.UserStore, alias string, password []byte, key crypto.Signer) {
 	t.Helper()
-	// TODO
+	newPassword := []byte("newpassword5678")
+	testinggo.AssertNoError(t, s.AddKey(alias, password, key))
+	testinggo.AssertError(t, conveygo.ERROR_ACCESS_DENIED, s.ChangePassword(alias, newPassword, newPassword))
+	testinggo.AssertNoError(t, s.ChangePassword(alias, password, newPassword))
+	_, err := s.GetKey(alias, password)
+	testinggo.AssertError(t, conveygo.ERROR_ACCESS_DENIED, err)
+	actual, err := s.GetKey(alias, newPassword)
+	testinggo.AssertNoError(t, err)
+	assertKeyEqual(t, key, actual)
 }
 
-func testUserStore_RegisterAlias_NotExists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key *rsa.PrivateKey) {
+func testUserStore_ChangePassword_NotExists(t *testing.T, s conveygo.UserStore, alias string, password []byte, key crypto.Signer) {
 	t.Helper()
-	// TODO
+	testinggo.AssertError(t, conveygo.ERROR_ACCESS_DENIED, s.ChangePassword(alias, password, []byte("newpassword5678")))
 }
 
-func testUserStore_RegisterCustomer_Exists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key *rsa.PrivateKey) {
+func testUserStore_RotateKey_Exists(t *testing.T, s conveygo.UserStore, alias string, password []byte, key, newKey crypto.Signer) {
 	t.Helper()
-	// TODO
+	testinggo.AssertNoError(t, s.AddKey(alias, password, key))
+	testinggo.AssertNoError(t, s.RegisterAlias(alias, password, key))
+	testinggo.AssertError(t, conveygo.ERROR_ACCESS_DENIED, s.RotateKey(alias, []byte("newpassword5678"), newKey))
+	testinggo.AssertNoError(t, s.RotateKey(alias, password, newKey))
+	actual, err := s.GetKey(alias, password)
+	testinggo.AssertNoError(t, err)
+	assertKeyEqual(t, newKey, actual)
 }
 
-func testUserStore_RegisterCustomer_NotExists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key *rsa.PrivateKey) {
+func testUserStore_RotateKey_NotExists(t *testing.T, s conveygo.UserStore, alias string, password []byte, key, newKey crypto.Signer) {
 	t.Helper()
-	// TODO
+	testinggo.AssertError(t, conveygo.ERROR_ACCESS_DENIED, s.RotateKey(alias, password, newKey))
 }
 
-func testUserStore_GetRegistration_Exists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key *rsa.PrivateKey) {
+func testUserStore_ExportKey_Exists(t *testing.T, s conveygo.UserStore, alias string, password []byte, key crypto.Signer) {
 	t.Helper()
-	// TODO
+	testinggo.AssertNoError(t, s.AddKey(alias, password, key))
+	_, err := s.ExportKey(alias, []byte("newpassword5678"))
+	testinggo.AssertError(t, conveygo.ERROR_ACCESS_DENIED, err)
+	data, err := s.ExportKey(alias, password)
+	testinggo.AssertNoError(t, err)
+	content, actual, err := conveygo.ReadKeyBundle(data, password)
+	testinggo.AssertNoError(t, err)
+	if content.Alias != alias {
+		t.Errorf("Wrong alias; expected '%s', got '%s'", alias, content.Alias)
+	}
+	assertKeyEqual(t, key, actual)
 }
 
-func testUserStore_GetRegistration_NotExists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key *rsa.PrivateKey) {
+func testUserStore_ExportKey_NotExists(t *testing.T, s conveygo.UserStore, alias string, password []byte, key crypto.Signer) {
 	t.Helper()
-	// TODO
+	_, err := s.ExportKey(alias, password)
+	testinggo.AssertError(t, conveygo.ERROR_ACCESS_DENIED, err)
 }
 
-func testUserStore_SubscribeCustomer_Exists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key *rsa.PrivateKey) {
+func testUserStore_ImportKey_Exists(t *testing.T, s conveygo.UserStore, alias string, password []byte, key, otherKey crypto.Signer) {
 	t.Helper()
-	// TODO
+	testinggo.AssertNoError(t, s.AddKey(alias, password, key))
+	data, err := conveygo.NewKeyBundle(alias, otherKey, nil, password)
+	testinggo.AssertNoError(t, err)
+	_, err = s.ImportKey(data, password, false)
+	testinggo.AssertError(t, fmt.Sprintf(conveygo.ERROR_KEY_ALREADY_EXISTS, alias), err)
+	actual, err := s.GetKey(alias, password)
+	testinggo.AssertNoError(t, err)
+	assertKeyEqual(t, key, actual)
+	imported, err := s.ImportKey(data, password, true)
+	testinggo.AssertNoError(t, err)
+	if imported != alias {
+		t.Errorf("Wrong alias; expected '%s', got '%s'", alias, imported)
+	}
+	actual, err = s.GetKey(alias, password)
+	testinggo.AssertNoError(t, err)
+	assertKeyEqual(t, otherKey, actual)
 }
 
-func testUserStore_SubscribeCustomer_NotExists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key *rsa.PrivateKey) {
+func testUserStore_ImportKey_NotExists(t *testing.T, s conveygo.UserStore, alias string, password []byte, key, otherKey crypto.Signer) {
 	t.Helper()
-	// TODO
+	data, err := conveygo.NewKeyBundle(alias, key, nil, password)
+	testinggo.AssertNoError(t, err)
+	_, err = s.ImportKey(data, []byte("newpassword5678"), false)
+	testinggo.AssertError(t, conveygo.ERROR_ACCESS_DENIED, err)
+	imported, err := s.ImportKey(data, password, false)
+	testinggo.AssertNoError(t, err)
+	if imported != alias {
+		t.Errorf("Wrong alias; expected '%s', got '%s'", alias, imported)
+	}
+	actual, err := s.GetKey(alias, password)
+	testinggo.AssertNoError(t, err)
+	assertKeyEqual(t, key, actual)
 }
 
-func testUserStore_GetSubscription_Exists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key *rsa.PrivateKey) {
+func testUserStore_RegisterAlias_Exists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key crypto.Signer) {
 	t.Helper()
 	// TODO
 }
 
-func testUserStore_GetSubscription_NotExists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key *rsa.PrivateKey) {
+func testUserStore_RegisterAlias_NotExists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key crypto.Signer) {
 	t.Helper()
 	// TODO
 }
 
-func testConversationStore_NewConversation(t *testing.T, s conveygo.ConversationStore, alias string, key *rsa.PrivateKey) {
+func testUserStore_RegisterCustomer_Exists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key crypto.Signer) {
+	t.Helper()
+	_, err := s.RegisterCustomer(alias, key, email, payment)
+	testinggo.AssertNoError(t, err)
+	_, err = s.RegisterCustomer(alias, key, email, payment)
+	testinggo.AssertError(t, fmt.Sprintf(conveygo.ERROR_ALREADY_REGISTERED, alias), err)
+}
+
+func testUserStore_RegisterCustomer_NotExists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key crypto.Signer) {
+	t.Helper()
+	registration, err := s.RegisterCustomer(alias, key, email, payment)
+	testinggo.AssertNoError(t, err)
+	if registration.CustomerAlias != alias || registration.CustomerId == "" || registration.PaymentId != payment {
+		t.Errorf("Wrong registration; got '%v'", registration)
+	}
+}
+
+func testUserStore_GetRegistration_Exists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key crypto.Signer) {
+	t.Helper()
+	expected, err := s.RegisterCustomer(alias, key, email, payment)
+	testinggo.AssertNoError(t, err)
+	registration, err := s.GetRegistration(alias)
+	testinggo.AssertNoError(t, err)
+	if registration == nil {
+		t.Fatal("Expected registration")
+	}
+	if registration.CustomerAlias != alias || registration.CustomerId != expected.CustomerId {
+		t.Errorf("Wrong registration; expected '%v', got '%v'", expected, registration)
+	}
+}
+
+func testUserStore_GetRegistration_NotExists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key crypto.Signer) {
+	t.Helper()
+	registration, err := s.GetRegistration(alias)
+	testinggo.AssertNoError(t, err)
+	if registration != nil {
+		t.Errorf("Expected no registration, got '%v'", registration)
+	}
+}
+
+func testUserStore_ChargeCustomer_Exists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key crypto.Signer) {
+	t.Helper()
+	registration, err := s.RegisterCustomer(alias, key, email, payment)
+	testinggo.AssertNoError(t, err)
+	charge, err := s.ChargeCustomer(alias, key, "product1234", "plan1234", "US", "usd", 500, "Test")
+	testinggo.AssertNoError(t, err)
+	if charge.CustomerAlias != alias || charge.CustomerId != registration.CustomerId || charge.ChargeId == "" || charge.Amount != 500 {
+		t.Errorf("Wrong charge; got '%v'", charge)
+	}
+}
+
+func testUserStore_ChargeCustomer_NotExists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key crypto.Signer) {
+	t.Helper()
+	_, err := s.ChargeCustomer(alias, key, "product1234", "plan1234", "US", "usd", 500, "Test")
+	testinggo.AssertError(t, fmt.Sprintf(conveygo.ERROR_NO_SUCH_REGISTRATION, alias), err)
+}
+
+func testUserStore_SubscribeCustomer_Exists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key crypto.Signer) {
+	t.Helper()
+	_, err := s.RegisterCustomer(alias, key, email, payment)
+	testinggo.AssertNoError(t, err)
+	_, err = s.SubscribeCustomer(alias, key, "product1234", "plan1234")
+	testinggo.AssertNoError(t, err)
+	_, err = s.SubscribeCustomer(alias, key, "product1234", "plan1234")
+	testinggo.AssertError(t, fmt.Sprintf(conveygo.ERROR_ALREADY_SUBSCRIBED, alias), err)
+}
+
+func testUserStore_SubscribeCustomer_NotExists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key crypto.Signer) {
+	t.Helper()
+	_, err := s.SubscribeCustomer(alias, key, "product1234", "plan1234")
+	testinggo.AssertError(t, fmt.Sprintf(conveygo.ERROR_NO_SUCH_REGISTRATION, alias), err)
+	_, err = s.RegisterCustomer(alias, key, email, payment)
+	testinggo.AssertNoError(t, err)
+	_, err = s.SubscribeCustomer(alias, key, "product1234", "plan1234")
+	testinggo.AssertNoError(t, err)
+}
+
+func testUserStore_GetSubscription_Exists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key crypto.Signer) {
+	t.Helper()
+	registration, err := s.RegisterCustomer(alias, key, email, payment)
+	testinggo.AssertNoError(t, err)
+	_, err = s.SubscribeCustomer(alias, key, "product1234", "plan1234")
+	testinggo.AssertNoError(t, err)
+	subscription, err := s.GetSubscription(alias)
+	testinggo.AssertNoError(t, err)
+	if subscription == nil {
+		t.Fatal("Expected subscription")
+	}
+	if subscription.CustomerAlias != alias || subscription.CustomerId != registration.CustomerId || subscription.PaymentId != payment || subscription.ProductId != "product1234" || subscription.PlanId != "plan1234" || subscription.SubscriptionId == "" {
+		t.Errorf("Wrong subscription; got '%v'", subscription)
+	}
+}
+
+func testUserStore_GetSubscription_NotExists(t *testing.T, s conveygo.UserStore, alias, email, payment string, key crypto.Signer) {
+	t.Helper()
+	subscription, err := s.GetSubscription(alias)
+	testinggo.AssertNoError(t, err)
+	if subscription != nil {
+		t.Errorf("Expected no subscription, got '%v'", subscription)
+	}
+}
+
+func testConversationStore_NewConversation(t *testing.T, s conveygo.ConversationStore, alias string, key crypto.Signer) {
 	t.Helper()
 	timestamp := bcgo.Timestamp()
 	conversationHash, conversationRecord, err := conveygo.ProtoToRecord(alias, key, timestamp, &conveygo.Conversation{
@@ -132,7 +287,7 @@ func testConversationStore_NewConversation(t *testing.T, s conveygo.Conversation
 	testinggo.AssertNoError(t, s.NewConversation(conversationHash, conversationRecord, messageHash, messageRecord))
 }
 
-func testConversationStore_GetConversation_Exists(t *testing.T, s conveygo.ConversationStore, alias string, key *rsa.PrivateKey) {
+func testConversationStore_GetConversation_Exists(t *testing.T, s conveygo.ConversationStore, alias string, key crypto.Signer) {
 	t.Helper()
 	expected := "Test123"
 	timestamp := bcgo.Timestamp()
@@ -171,7 +326,7 @@ func testConversationStore_GetAllConversations_Empty(t *testing.T, s conveygo.Co
 	}
 }
 
-func testConversationStore_GetAllConversations_NotEmpty(t *testing.T, s conveygo.ConversationStore, alias string, key *rsa.PrivateKey) {
+func testConversationStore_GetAllConversations_NotEmpty(t *testing.T, s conveygo.ConversationStore, alias string, key crypto.Signer) {
 	t.Helper()
 	expected := "Foo"
 	timestamp := bcgo.Timestamp()
@@ -194,7 +349,7 @@ func testConversationStore_GetAllConversations_NotEmpty(t *testing.T, s conveygo
 	}
 }
 
-func testConversationStore_GetAllConversations_From(t *testing.T, s conveygo.ConversationStore, alias string, key *rsa.PrivateKey) {
+func testConversationStore_GetAllConversations_From(t *testing.T, s conveygo.ConversationStore, alias string, key crypto.Signer) {
 	t.Helper()
 	{
 		timestamp := uint64(0)
@@ -232,7 +387,7 @@ func testConversationStore_GetAllConversations_From(t *testing.T, s conveygo.Con
 	}
 }
 
-func testConversationStore_GetAllConversations_To(t *testing.T, s conveygo.ConversationStore, alias string, key *rsa.PrivateKey) {
+func testConversationStore_GetAllConversations_To(t *testing.T, s conveygo.ConversationStore, alias string, key crypto.Signer) {
 	t.Helper()
 	expected := "Foo"
 	{
@@ -279,7 +434,7 @@ func testConversationStore_GetRecentConversations_Empty(t *testing.T, s conveygo
 	}
 }
 
-func testConversationStore_GetRecentConversations_NotEmpty(t *testing.T, s conveygo.ConversationStore, alias string, key *rsa.PrivateKey) {
+func testConversationStore_GetRecentConversations_NotEmpty(t *testing.T, s conveygo.ConversationStore, alias string, key crypto.Signer) {
 	t.Helper()
 	expected := "Foo"
 	timestamp := bcgo.Timestamp()
@@ -302,7 +457,7 @@ func testConversationStore_GetRecentConversations_NotEmpty(t *testing.T, s conve
 	}
 }
 
-func testConversationStore_GetRecentConversations_Limit(t *testing.T, s conveygo.ConversationStore, alias string, key *rsa.PrivateKey) {
+func testConversationStore_GetRecentConversations_Limit(t *testing.T, s conveygo.ConversationStore, alias string, key crypto.Signer) {
 	t.Helper()
 	expected := "Foo"
 	{
@@ -341,7 +496,7 @@ func testConversationStore_GetRecentConversations_Limit(t *testing.T, s conveygo
 	}
 }
 
-func testMessageStore_AddMessage_Exists(t *testing.T, s conveygo.MessageStore, alias string, key *rsa.PrivateKey) {
+func testMessageStore_AddMessage_Exists(t *testing.T, s conveygo.MessageStore, alias string, key crypto.Signer) {
 	t.Helper()
 	timestamp := bcgo.Timestamp()
 	conversationHash, conversationRecord, err := conveygo.ProtoToRecord(alias, key, timestamp, &conveygo.Conversation{
@@ -363,7 +518,7 @@ func testMessageStore_AddMessage_Exists(t *testing.T, s conveygo.MessageStore, a
 	testinggo.AssertNoError(t, s.AddMessage(conversationHash, replyHash, replyRecord))
 }
 
-func testMessageStore_AddMessage_NotExists(t *testing.T, s conveygo.MessageStore, alias string, key *rsa.PrivateKey) {
+func testMessageStore_AddMessage_NotExists(t *testing.T, s conveygo.MessageStore, alias string, key crypto.Signer) {
 	t.Helper()
 	replyHash, replyRecord, err := conveygo.ProtoToRecord(alias, key, bcgo.Timestamp(), &conveygo.Message{
 		Content: []byte("FooBar"),
@@ -374,7 +529,7 @@ func testMessageStore_AddMessage_NotExists(t *testing.T, s conveygo.MessageStore
 	testinggo.AssertError(t, "No such conversation: Q29udmVyc2F0aW9uRG9lc05vdEV4aXN0", err)
 }
 
-func testMessageStore_GetMessage_Exists(t *testing.T, s conveygo.MessageStore, alias string, key *rsa.PrivateKey) {
+func testMessageStore_GetMessage_Exists(t *testing.T, s conveygo.MessageStore, alias string, key crypto.Signer) {
 	t.Helper()
 	timestamp := bcgo.Timestamp()
 	conversationHash, conversationRecord, err := conveygo.ProtoToRecord(alias, key, timestamp, &conveygo.Conversation{
@@ -405,7 +560,7 @@ func testMessageStore_GetMessage_Exists(t *testing.T, s conveygo.MessageStore, a
 	}
 }
 
-func testMessageStore_GetMessage_Exists_Hash(t *testing.T, s conveygo.MessageStore, alias string, key *rsa.PrivateKey) {
+func testMessageStore_GetMessage_Exists_Hash(t *testing.T, s conveygo.MessageStore, alias string, key crypto.Signer) {
 	t.Helper()
 	timestamp := bcgo.Timestamp()
 	conversationHash, conversationRecord, err := conveygo.ProtoToRecord(alias, key, timestamp, &conveygo.Conversation{
@@ -443,7 +598,7 @@ func testMessageStore_GetMessage_Exists_Hash(t *testing.T, s conveygo.MessageSto
 	}
 }
 
-func testMessageStore_GetMessage_Exists_Reply(t *testing.T, s conveygo.MessageStore, alias string, key *rsa.PrivateKey) {
+func testMessageStore_GetMessage_Exists_Reply(t *testing.T, s conveygo.MessageStore, alias string, key crypto.Signer) {
 	t.Helper()
 	timestamp := bcgo.Timestamp()
 	conversationHash, conversationRecord, err := conveygo.ProtoToRecord(alias, key, timestamp, &conveygo.Conversation{
@@ -502,12 +657,12 @@ func testMessageStore_GetMessage_NotExists(t *testing.T, s conveygo.MessageStore
 	}
 }
 
-func testMessageStore_GetYield_Exists(t *testing.T, s conveygo.MessageStore, alias string, key *rsa.PrivateKey) {
+func testMessageStore_GetYield_Exists(t *testing.T, s conveygo.MessageStore, alias string, key crypto.Signer) {
 	t.Helper()
 	// TODO
 }
 
-func testMessageStore_GetYield_Exists_Reply(t *testing.T, s conveygo.MessageStore, alias string, key *rsa.PrivateKey) {
+func testMessageStore_GetYield_Exists_Reply(t *testing.T, s conveygo.MessageStore, alias string, key crypto.Signer) {
 	t.Helper()
 	// TODO
 }
@@ -516,3 +671,144 @@ func testMessageStore_GetYield_NotExists(t *testing.T, s conveygo.MessageStore)
 	t.Helper()
 	// TODO
 }
+
+func testTagStore_AddTag(t *testing.T, s conveygo.TagStore, alias string, key crypto.Signer) {
+	t.Helper()
+	tagHash, tagRecord, err := conveygo.ProtoToRecord(alias, key, bcgo.Timestamp(), &conveygo.Tag{
+		Value: "Test123",
+	})
+	testinggo.AssertNoError(t, err)
+	testinggo.AssertNoError(t, s.AddTag([]byte("Message123"), tagHash, tagRecord))
+}
+
+func testTagStore_GetTags_Exists(t *testing.T, s conveygo.TagStore, alias string, key crypto.Signer) {
+	t.Helper()
+	messageHash := []byte("Message123")
+	for _, v := range []string{"Foo", "Bar"} {
+		tagHash, tagRecord, err := conveygo.ProtoToRecord(alias, key, bcgo.Timestamp(), &conveygo.Tag{
+			Value: v,
+		})
+		testinggo.AssertNoError(t, err)
+		testinggo.AssertNoError(t, s.AddTag(messageHash, tagHash, tagRecord))
+	}
+	values := make(map[string]string)
+	testinggo.AssertNoError(t, s.GetTags(messageHash, func(hash []byte, timestamp uint64, author string, tag *conveygo.Tag) error {
+		values[tag.Value] = author
+		return nil
+	}))
+	if len(values) != 2 || values["Foo"] != alias || values["Bar"] != alias {
+		t.Errorf("Wrong tags; expected 'Foo' and 'Bar' by '%s', got '%v'", alias, values)
+	}
+}
+
+func testTagStore_GetTags_NotExists(t *testing.T, s conveygo.TagStore) {
+	t.Helper()
+	testinggo.AssertNoError(t, s.GetTags([]byte("Message123"), func(hash []byte, timestamp uint64, author string, tag *conveygo.Tag) error {
+		t.Errorf("Unexpected tag: %s", tag.Value)
+		return nil
+	}))
+}
+
+func testTransactionStore_Transfer(t *testing.T, s conveygo.TransactionStore, ledger *conveygo.Ledger, alias string, key crypto.Signer, receiver string) {
+	t.Helper()
+	ledger.RecordMinted(alias, 100)
+	receipt, err := s.Transfer(ledger, alias, key, receiver, 30, "Thanks", "")
+	testinggo.AssertNoError(t, err)
+	if receipt.Balance != 70 || receipt.Duplicate {
+		t.Errorf("Wrong receipt; expected balance '70', got '%d'", receipt.Balance)
+	}
+	if b, _ := ledger.GetBalance(receiver); b != 30 {
+		t.Errorf("Wrong receiver balance; expected '30', got '%d'", b)
+	}
+	var transactions []*conveygo.Transaction
+	testinggo.AssertNoError(t, s.GetTransactions(receiver, func(hash []byte, timestamp uint64, transaction *conveygo.Transaction) error {
+		transactions = append(transactions, transaction)
+		return nil
+	}))
+	if len(transactions) != 1 || transactions[0].Sender != alias || transactions[0].Amount != 30 || transactions[0].Memo != "Thanks" {
+		t.Errorf("Wrong transactions; got '%v'", transactions)
+	}
+}
+
+func testTransactionStore_Transfer_InsufficientBalance(t *testing.T, s conveygo.TransactionStore, ledger *conveygo.Ledger, alias string, key crypto.Signer, receiver string) {
+	t.Helper()
+	ledger.RecordMinted(alias, 10)
+	_, err := s.Transfer(ledger, alias, key, receiver, 30, "", "")
+	testinggo.AssertError(t, "Insufficient balance: 10 < 30", err)
+	_, err = s.Transfer(ledger, alias, key, receiver, 0, "", "")
+	testinggo.AssertError(t, "Invalid amount: 0", err)
+	_, err = s.Transfer(ledger, alias, key, alias, 5, "", "")
+	testinggo.AssertError(t, fmt.Sprintf("Cannot transfer to self: %s", alias), err)
+}
+
+func testTransactionStore_Transfer_IdempotencyKey(t *testing.T, s conveygo.TransactionStore, ledger *conveygo.Ledger, alias string, key crypto.Signer, receiver string) {
+	t.Helper()
+	ledger.RecordMinted(alias, 100)
+	first, err := s.Transfer(ledger, alias, key, receiver, 30, "", "Key123")
+	testinggo.AssertNoError(t, err)
+	second, err := s.Transfer(ledger, alias, key, receiver, 30, "", "Key123")
+	testinggo.AssertNoError(t, err)
+	if !second.Duplicate || string(second.Hash) != string(first.Hash) {
+		t.Errorf("Expected retry to return the first receipt")
+	}
+	if b, _ := ledger.GetBalance(alias); b != 70 {
+		t.Errorf("Wrong balance; expected '70', got '%d'", b)
+	}
+	third, err := s.Transfer(ledger, alias, key, receiver, 30, "", "Key456")
+	testinggo.AssertNoError(t, err)
+	if third.Duplicate || third.Balance != 40 {
+		t.Errorf("Wrong receipt; expected new transfer with balance '40', got '%d'", third.Balance)
+	}
+}
+
+func testEscrowStore_Release(t *testing.T, s conveygo.EscrowStore, ledger *conveygo.Ledger, alias string, key crypto.Signer, receiver string) {
+	t.Helper()
+	ledger.RecordMinted(alias, 100)
+	escrowHash, err := s.LockEscrow(ledger, alias, key, 40, bcgo.Timestamp()+uint64(time.Hour), "Bounty", nil)
+	testinggo.AssertNoError(t, err)
+	b, _ := ledger.GetBalance(alias)
+	if l := ledger.GetLocked(alias); b != 60 || l != 40 {
+		t.Errorf("Wrong balance; expected '60' available and '40' locked, got '%d' and '%d'", b, l)
+	}
+	_, err = s.RefundEscrow(ledger, alias, key, escrowHash)
+	testinggo.AssertError(t, fmt.Sprintf("Escrow deadline not passed: %s", base64.RawURLEncoding.EncodeToString(escrowHash)), err)
+	_, err = s.ReleaseEscrow(ledger, alias, key, escrowHash, receiver)
+	testinggo.AssertNoError(t, err)
+	b, _ = ledger.GetBalance(alias)
+	if l := ledger.GetLocked(alias); b != 60 || l != 0 {
+		t.Errorf("Wrong balance; expected '60' available and '0' locked, got '%d' and '%d'", b, l)
+	}
+	if b, _ := ledger.GetBalance(receiver); b != 40 {
+		t.Errorf("Wrong receiver balance; expected '40', got '%d'", b)
+	}
+	release, err := s.GetEscrowRelease(escrowHash)
+	testinggo.AssertNoError(t, err)
+	if release == nil || release.Receiver != receiver {
+		t.Errorf("Wrong release; expected receiver '%s', got '%v'", receiver, release)
+	}
+	_, err = s.ReleaseEscrow(ledger, alias, key, escrowHash, receiver)
+	testinggo.AssertError(t, fmt.Sprintf("Escrow already released: %s", base64.RawURLEncoding.EncodeToString(escrowHash)), err)
+}
+
+func testEscrowStore_Refund(t *testing.T, s conveygo.EscrowStore, ledger *conveygo.Ledger, alias string, key crypto.Signer, receiver string) {
+	t.Helper()
+	ledger.RecordMinted(alias, 100)
+	escrowHash, err := s.LockEscrow(ledger, alias, key, 40, bcgo.Timestamp()+uint64(100*time.Millisecond), "", nil)
+	testinggo.AssertNoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	_, err = s.RefundEscrow(ledger, alias, key, escrowHash)
+	testinggo.AssertNoError(t, err)
+	b, _ := ledger.GetBalance(alias)
+	if l := ledger.GetLocked(alias); b != 100 || l != 0 {
+		t.Errorf("Wrong balance; expected '100' available and '0' locked, got '%d' and '%d'", b, l)
+	}
+	_, err = s.ReleaseEscrow(ledger, alias, key, escrowHash, receiver)
+	testinggo.AssertError(t, fmt.Sprintf("Escrow already released: %s", base64.RawURLEncoding.EncodeToString(escrowHash)), err)
+}
+
+func testEscrowStore_LockEscrow_InsufficientBalance(t *testing.T, s conveygo.EscrowStore, ledger *conveygo.Ledger, alias string, key crypto.Signer) {
+	t.Helper()
+	ledger.RecordMinted(alias, 10)
+	_, err := s.LockEscrow(ledger, alias, key, 40, bcgo.Timestamp()+uint64(time.Hour), "", nil)
+	testinggo.AssertError(t, "Insufficient balance: 10 < 40", err)
+}
@@ -0,0 +1,166 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conveygo
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	ERROR_INSUFFICIENT_BALANCE = "Insufficient balance: %d < %d"
+	ERROR_INVALID_AMOUNT       = "Invalid amount: %d"
+	ERROR_MISSING_RECEIVER     = "Missing receiver"
+	ERROR_SELF_TRANSFER        = "Cannot transfer to self: %s"
+)
+
+// Receipt confirms a Transaction was mined into the Convey-Transaction Chain.
+type Receipt struct {
+	Hash        []byte
+	Timestamp   uint64
+	Transaction *Transaction
+	// Sender's balance after the transfer.
+	Balance int64
+	// True if the idempotency key matched an earlier transfer, which is returned instead of transferring again.
+	Duplicate bool
+}
+
+// Transfers tokens from the alias to the receiver, after checking the alias's balance in the ledger.
+// If the idempotency key is set and the alias already made a transfer with the same key, the receipt of that transfer is returned instead.
+func (s *BCStore) Transfer(ledger *Ledger, alias string, key crypto.Signer, receiver string, amount uint64, memo, idempotencyKey string) (*Receipt, error) {
+	return s.transfer(ledger, alias, key, receiver, amount, memo, idempotencyKey, nil)
+}
+
+func (s *BCStore) transfer(ledger *Ledger, alias string, key crypto.Signer, receiver string, amount uint64, memo, idempotencyKey string, references []*bcgo.Reference) (*Receipt, error) {
+	if receiver == "" {
+		return nil, errors.New(ERROR_MISSING_RECEIVER)
+	}
+	if receiver == alias {
+		return nil, errors.New(fmt.Sprintf(ERROR_SELF_TRANSFER, alias))
+	}
+	if amount == 0 {
+		return nil, errors.New(fmt.Sprintf(ERROR_INVALID_AMOUNT, amount))
+	}
+
+	transactions := s.Node.GetOrOpenChannel(CONVEY_TRANSACTION, OpenTransactionChannel)
+
+	if err := ledger.UpdateAll(); err != nil {
+		return nil, err
+	}
+
+	if idempotencyKey != "" {
+		receipt, err := s.findTransfer(transactions, alias, idempotencyKey)
+		if err != nil {
+			return nil, err
+		}
+		if receipt != nil {
+			receipt.Balance, _ = ledger.GetBalance(alias)
+			receipt.Duplicate = true
+			return receipt, nil
+		}
+	}
+
+	if balance, _ := ledger.GetBalance(alias); balance < int64(amount) {
+		return nil, errors.New(fmt.Sprintf(ERROR_INSUFFICIENT_BALANCE, balance, amount))
+	}
+
+	transaction := &Transaction{
+		Sender:         alias,
+		Receiver:       receiver,
+		Amount:         amount,
+		Memo:           memo,
+		IdempotencyKey: idempotencyKey,
+	}
+	timestamp := bcgo.Timestamp()
+	hash, record, err := ProtoToRecordWithReferences(alias, key, timestamp, references, transaction)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.MineBlockEntry(transactions, &bcgo.BlockEntry{
+		RecordHash: hash,
+		Record:     record,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := ledger.Update(transactions.Name, transactions.Head); err != nil {
+		return nil, err
+	}
+	balance, _ := ledger.GetBalance(alias)
+	return &Receipt{
+		Hash:        hash,
+		Timestamp:   timestamp,
+		Transaction: transaction,
+		Balance:     balance,
+	}, nil
+}
+
+// Calls the callback with each transaction sent or received by the alias, newest first, or every transaction if the alias is empty.
+func (s *BCStore) GetTransactions(alias string, callback func([]byte, uint64, *Transaction) error) error {
+	transactions, err := s.Node.GetChannel(CONVEY_TRANSACTION)
+	if err != nil {
+		return err
+	}
+	return bcgo.Iterate(transactions.Name, transactions.Head, nil, s.Node.Cache, s.Node.Network, func(h []byte, b *bcgo.Block) error {
+		for _, entry := range b.Entry {
+			t := &Transaction{}
+			if err := proto.Unmarshal(entry.Record.Payload, t); err != nil {
+				return err
+			}
+			if alias == "" || t.Sender == alias || t.Receiver == alias {
+				if err := callback(entry.RecordHash, entry.Record.Timestamp, t); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Returns the receipt of the sender's transfer with the given idempotency key, or nil if there is none.
+func (s *BCStore) findTransfer(transactions *bcgo.Channel, sender, idempotencyKey string) (*Receipt, error) {
+	var receipt *Receipt
+	if err := bcgo.Iterate(transactions.Name, transactions.Head, nil, s.Node.Cache, s.Node.Network, func(h []byte, b *bcgo.Block) error {
+		for _, entry := range b.Entry {
+			t := &Transaction{}
+			if err := proto.Unmarshal(entry.Record.Payload, t); err != nil {
+				return err
+			}
+			if t.Sender == sender && t.IdempotencyKey == idempotencyKey {
+				receipt = &Receipt{
+					Hash:        entry.RecordHash,
+					Timestamp:   entry.Record.Timestamp,
+					Transaction: t,
+				}
+				return bcgo.StopIterationError{}
+			}
+		}
+		return nil
+	}); err != nil {
+		switch err.(type) {
+		case bcgo.StopIterationError:
+			// Do nothing
+			break
+		default:
+			return nil, err
+		}
+	}
+	return receipt, nil
+}
@@ -0,0 +1,284 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"github.com/AletheiaWareLLC/aliasgo"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/api"
+	"github.com/AletheiaWareLLC/conveygo/events"
+	"github.com/AletheiaWareLLC/conveygo/notifications"
+	"github.com/AletheiaWareLLC/conveygo/web"
+	"github.com/AletheiaWareLLC/conveygo/webhooks"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+var (
+	address = flag.String("address", ":8080", "address to listen on")
+	host    = flag.String("host", "", "Convey host, empty for the default hosts")
+	memory  = flag.Bool("memory", false, "serve from an in-memory store instead of the chain")
+	webhook = flag.String("webhook", "", "URL to post reply notifications to, empty for none")
+	hooks   = flag.String("hooks", "", "JSON file listing webhook endpoints, empty for none")
+	dead    = flag.String("dead-letters", "", "file to append undeliverable webhooks to, empty for none")
+	timeout = flag.Duration("hook-timeout", webhooks.DEFAULT_TIMEOUT, "time to wait for a webhook endpoint to respond before retrying")
+	plans   = flag.String("plans", "", "JSON file mapping subscription plan IDs to monthly posting allowances, empty for none")
+)
+
+// Opens the message chain of a conversation before it is read or written, so conversations started by peers can be served.
+type ChannelOpeningStore struct {
+	*conveygo.BCStore
+}
+
+func (s *ChannelOpeningStore) open(conversationHash []byte) {
+	name := conveygo.CONVEY_PREFIX_MESSAGE + base64.RawURLEncoding.EncodeToString(conversationHash)
+	s.Node.GetOrOpenChannel(name, func() *bcgo.Channel {
+		return bcgo.OpenPoWChannel(name, bcgo.THRESHOLD_G)
+	})
+}
+
+func (s *ChannelOpeningStore) AddMessage(conversationHash, messageHash []byte, messageRecord *bcgo.Record) error {
+	s.open(conversationHash)
+	return s.BCStore.AddMessage(conversationHash, messageHash, messageRecord)
+}
+
+func (s *ChannelOpeningStore) GetMessage(conversationHash, messageHash []byte, callback func([]byte, uint64, string, uint64, *conveygo.Message) error) error {
+	s.open(conversationHash)
+	return s.BCStore.GetMessage(conversationHash, messageHash, callback)
+}
+
+func (s *ChannelOpeningStore) GetYield(conversationHash []byte) (uint64, uint64, error) {
+	s.open(conversationHash)
+	return s.BCStore.GetYield(conversationHash)
+}
+
+func main() {
+	flag.Parse()
+
+	rootDir, err := bcgo.GetRootDirectory()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var messages conveygo.MessageStore
+	var users conveygo.UserStore
+	var node *bcgo.Node
+	if *memory {
+		node, err = bcgo.GetNode(rootDir, bcgo.NewMemoryCache(100), nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+		store := conveygo.NewMemoryStore()
+		// Allow the node's alias to post with its keystore password
+		if err := store.AddKey(node.Alias, []byte(os.Getenv("PASSWORD")), node.Key); err != nil {
+			log.Fatal(err)
+		}
+		messages = store
+		users = store
+	} else {
+		node, err = OpenNode(rootDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		keystore, err := bcgo.GetKeyDirectory(rootDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		store := &conveygo.BCStore{
+			Node:     node,
+			Listener: &bcgo.PrintingMiningListener{Output: os.Stdout},
+			KeyStore: conveygo.NewFileKeyStore(keystore),
+		}
+		messages = &ChannelOpeningStore{store}
+		users = store
+	}
+
+	// The web and api servers, notifier and webhooks all share the store, so access to it is serialized
+	messages = conveygo.NewLockingMessageStore(messages)
+
+	allowances := make(map[string]*conveygo.PostingPlan)
+	if *plans != "" {
+		allowances, err = LoadPlans(*plans)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	newLedger := func() *conveygo.Ledger {
+		ledger := conveygo.NewLedger(node)
+		ledger.Plans = allowances
+		return ledger
+	}
+
+	// Each consumer keeps its own Ledger as they are updated independently
+	server := web.NewServer(messages, users, newLedger())
+	server.Alias = node.Alias
+	server.Key = node.Key
+
+	dispatcher := events.NewDispatcher(node, newLedger())
+	if err := WatchChannels(node, dispatcher); err != nil {
+		log.Fatal(err)
+	}
+
+	sinks := []notifications.Sink{
+		&notifications.EventSink{Dispatcher: dispatcher},
+	}
+	if *webhook != "" {
+		sinks = append(sinks, &notifications.WebhookSink{URL: *webhook})
+	}
+	notifier := notifications.NewNotifier(messages, notifications.NewInbox(), sinks...)
+	notifier.Listen(dispatcher)
+
+	if *hooks != "" {
+		if err := StartWebhooks(node, messages, dispatcher, *hooks, *dead, *timeout); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	apiServer := api.NewServer(messages, users, newLedger())
+	apiServer.Inbox = notifier.Inbox
+
+	mux := http.NewServeMux()
+	mux.Handle("/", server.Handler())
+	mux.Handle("/api/", http.StripPrefix("/api", apiServer.Handler()))
+	mux.Handle("/events", dispatcher)
+
+	log.Println("Listening on", *address)
+	log.Fatal(http.ListenAndServe(*address, mux))
+}
+
+func OpenNode(rootDir string) (*bcgo.Node, error) {
+	cacheDir, err := bcgo.GetCacheDirectory(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	cache, err := bcgo.NewFileCache(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	peers, err := bcgo.GetPeers(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	if *host == "" {
+		peers = append(peers, conveygo.GetConveyHosts()...)
+	} else {
+		peers = append(peers, *host)
+	}
+	log.Println("Peers:", peers)
+
+	network := bcgo.NewTCPNetwork(peers...)
+
+	node, err := bcgo.GetNode(rootDir, cache, network)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, opener := range []func() *bcgo.Channel{
+		aliasgo.OpenAliasChannel,
+		conveygo.OpenConversationChannel,
+		conveygo.OpenTransactionChannel,
+		conveygo.OpenEscrowChannel,
+		conveygo.OpenEscrowReleaseChannel,
+		conveygo.OpenHourChannel,
+		conveygo.OpenDayChannel,
+		conveygo.OpenWeekChannel,
+		conveygo.OpenYearChannel,
+		conveygo.OpenDecadeChannel,
+		conveygo.OpenCenturyChannel,
+	} {
+		channel := opener()
+		if err := channel.Refresh(cache, network); err != nil {
+			log.Println(err)
+		}
+		node.AddChannel(channel)
+	}
+	return node, nil
+}
+
+// Watches every open channel, and the message chain of each existing conversation.
+func WatchChannels(node *bcgo.Node, dispatcher *events.Dispatcher) error {
+	for _, channel := range node.GetChannels() {
+		dispatcher.Watch(channel)
+	}
+	conversations, err := node.GetChannel(conveygo.CONVEY_CONVERSATION)
+	if err != nil {
+		// In-memory stores have no chains to watch
+		return nil
+	}
+	return bcgo.Iterate(conversations.Name, conversations.Head, nil, node.Cache, node.Network, func(h []byte, b *bcgo.Block) error {
+		for _, entry := range b.Entry {
+			dispatcher.WatchConversation(base64.RawURLEncoding.EncodeToString(entry.RecordHash))
+		}
+		return nil
+	})
+}
+
+// Reads the posting allowance of each subscription plan, keyed by plan ID, from the JSON file.
+func LoadPlans(file string) (map[string]*conveygo.PostingPlan, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	plans := make(map[string]*conveygo.PostingPlan)
+	if err := json.Unmarshal(data, &plans); err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+// Delivers events, and the daily and weekly digests, to the endpoints listed in the hooks file.
+func StartWebhooks(node *bcgo.Node, messages conveygo.MessageStore, dispatcher *events.Dispatcher, hooks, dead string, timeout time.Duration) error {
+	data, err := ioutil.ReadFile(hooks)
+	if err != nil {
+		return err
+	}
+	var endpoints []*webhooks.Endpoint
+	if err := json.Unmarshal(data, &endpoints); err != nil {
+		return err
+	}
+	w := webhooks.NewDispatcher(messages)
+	w.Client.Timeout = timeout
+	for _, e := range endpoints {
+		w.Register(e)
+	}
+	if dead != "" {
+		file, err := os.OpenFile(dead, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		w.DeadLetterLog = file
+	}
+	for name, period := range map[string]time.Duration{
+		conveygo.CONVEY_DAY:  24 * time.Hour,
+		conveygo.CONVEY_WEEK: 7 * 24 * time.Hour,
+	} {
+		if channel, err := node.GetChannel(name); err == nil {
+			w.WatchDigests(channel, period)
+		}
+	}
+	w.Listen(dispatcher)
+	return nil
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"html/template"
+)
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"digestURL": DigestURL,
+}).Parse(`
+{{define "header"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+{{with .}}{{.}}{{end}}
+<title>Convey</title>
+<style>
+body { font-family: sans-serif; max-width: 48em; margin: 0 auto; padding: 1em; color: #212121; }
+nav a { margin-right: 1em; }
+.meta { color: #757575; font-size: small; }
+.replies { margin-left: 1.5em; border-left: 1px solid #e0e0e0; padding-left: 1em; }
+textarea { width: 100%; }
+</style>
+</head>
+<body>
+<nav><a href="/">Conversations</a><a href="/compose">Compose</a><a href="/digests">Digests</a></nav>
+{{end}}
+
+{{define "footer"}}</body>
+</html>
+{{end}}
+
+{{define "credentials"}}<input type="text" name="alias" placeholder="Alias" required>
+<input type="password" name="password" placeholder="Password" required>{{end}}
+
+{{define "conversations"}}<ul>
+{{range .}}<li><a href="/conversation?hash={{.Hash}}">{{.Topic}}</a>
+<div class="meta"><a href="/alias?alias={{.Author}}">{{.Author}}</a> {{.Timestamp}} cost {{.Cost}}</div></li>
+{{else}}<li>No conversations</li>
+{{end}}</ul>{{end}}
+
+{{define "message"}}<div class="message" id="{{.Hash}}">
+<div class="meta"><a href="/alias?alias={{.Author}}">{{.Author}}</a> {{.Timestamp}} cost {{.Cost}} reward {{.Reward}} yield {{.Yield}}</div>
+{{.Content}}
+<details><summary>Reply</summary>
+<form method="post" action="/reply">
+<input type="hidden" name="conversation" value="{{.Conversation}}">
+<input type="hidden" name="previous" value="{{.Hash}}">
+<textarea name="content" rows="4" required></textarea>
+{{template "credentials"}}
+<button type="submit">Reply</button>
+</form>
+</details>
+{{if .Replies}}<div class="replies">
+{{range .Replies}}{{template "message" .}}{{end}}
+</div>{{end}}
+</div>{{end}}
+
+{{define "list"}}{{template "header"}}
+<h1>Conversations</h1>
+{{template "conversations" .Conversations}}
+{{template "footer"}}{{end}}
+
+{{define "conversation"}}{{template "header"}}
+<h1>{{.Conversation.Topic}}</h1>
+<div class="meta"><a href="/alias?alias={{.Conversation.Author}}">{{.Conversation.Author}}</a> {{.Conversation.Timestamp}} cost {{.Conversation.Cost}}</div>
+{{range .Messages}}{{template "message" .}}{{end}}
+{{template "footer"}}{{end}}
+
+{{define "compose"}}{{template "header"}}
+<h1>Compose</h1>
+<form method="post" action="/compose">
+<p><input type="text" name="topic" placeholder="Topic" required></p>
+<p><textarea name="content" rows="12" required></textarea></p>
+<p>{{template "credentials"}}</p>
+<button type="submit">Post</button>
+</form>
+{{template "footer"}}{{end}}
+
+{{define "alias"}}{{template "header"}}
+<h1>{{.Alias}}</h1>
+<p>Balance: {{.Balance}}</p>
+{{if .Allowance}}<p>Allowance: {{.Allowance}}</p>{{end}}
+<table>
+<tr><td>Minted</td><td>{{.Minted}}</td></tr>
+<tr><td>Burned</td><td>{{.Burned}}</td></tr>
+<tr><td>Bought</td><td>{{.Bought}}</td></tr>
+<tr><td>Sold</td><td>{{.Sold}}</td></tr>
+<tr><td>Earned</td><td>{{.Earned}}</td></tr>
+<tr><td>Spent</td><td>{{.Spent}}</td></tr>
+</table>
+<h2>History</h2>
+{{template "conversations" .Conversations}}
+{{template "footer"}}{{end}}
+
+{{define "digests"}}{{template "header"}}
+<h1>Digests</h1>
+<ul>
+{{range .Periods}}<li><a href="{{digestURL .}}">{{.Label}}</a></li>
+{{end}}</ul>
+{{template "footer"}}{{end}}
+
+{{define "digest"}}{{template "header" .Signature}}
+<h1>Digest {{.Period.Label}}</h1>
+{{range .Entries}}<article>
+<h2><a href="/conversation?hash={{.Hash}}">{{.Topic}}</a></h2>
+<div class="meta"><a href="/alias?alias={{.Author}}">{{.Author}}</a> {{.Timestamp}} yield {{.Yield}}</div>
+{{.Content}}
+</article>
+{{else}}<p>No conversations in this period</p>
+{{end}}
+{{template "footer"}}{{end}}
+
+{{define "error"}}{{template "header"}}
+<h1>{{.Status}}</h1>
+<p>{{.Error}}</p>
+{{template "footer"}}{{end}}
+`))
@@ -0,0 +1,541 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"crypto"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/html"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	LIST_LIMIT      = 20
+	ARCHIVE_PERIODS = 12
+	ARCHIVE_PERIOD  = 7 * 24 * time.Hour
+
+	ERROR_MISSING_FIELD   = "Missing field: %s"
+	ERROR_NO_SUCH_MESSAGE = "No such message: %s"
+)
+
+type Conversation struct {
+	Hash      string
+	Timestamp string
+	Author    string
+	Topic     string
+	Cost      uint64
+}
+
+type Message struct {
+	Conversation string
+	Hash         string
+	Timestamp    string
+	Author       string
+	Cost         uint64
+	Reward       uint64
+	Yield        int64
+	Content      template.HTML
+	Replies      []*Message
+}
+
+type Period struct {
+	From  uint64
+	To    uint64
+	Label string
+}
+
+type DigestEntry struct {
+	Hash      string
+	Topic     string
+	Timestamp string
+	Author    string
+	Yield     int64
+	Content   template.HTML
+}
+
+// Server renders Convey pages from the stores and ledger.
+// Forms that write records take an alias and password, which unlock the alias's key in the UserStore.
+// If Alias and Key are set, digest pages carry a signature from that alias.
+// Handlers run concurrently, so a Messages store shared with other servers should be wrapped in a conveygo.LockingMessageStore.
+type Server struct {
+	Messages conveygo.MessageStore
+	Users    conveygo.UserStore
+	Ledger   *conveygo.Ledger
+	Alias    string
+	Key      crypto.Signer
+	lock     sync.Mutex
+}
+
+func NewServer(messages conveygo.MessageStore, users conveygo.UserStore, ledger *conveygo.Ledger) *Server {
+	return &Server{
+		Messages: messages,
+		Users:    users,
+		Ledger:   ledger,
+	}
+}
+
+// Returns a handler serving;
+//
+//	GET  /                        - recent conversations
+//	GET  /conversation?hash=H     - conversation with reply tree
+//	GET  /compose                 - compose form
+//	POST /compose                 - start a conversation
+//	POST /reply                   - reply to a message
+//	GET  /alias?alias=A           - balance and history of an alias
+//	GET  /digests                 - digest archive
+//	GET  /digest?from=T&to=T      - digest for a period
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.HandleIndex)
+	mux.HandleFunc("/conversation", s.HandleConversation)
+	mux.HandleFunc("/compose", s.HandleCompose)
+	mux.HandleFunc("/reply", s.HandleReply)
+	mux.HandleFunc("/alias", s.HandleAlias)
+	mux.HandleFunc("/digests", s.HandleDigests)
+	mux.HandleFunc("/digest", s.HandleDigest)
+	return mux
+}
+
+func (s *Server) HandleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	listings, err := s.Messages.GetRecentConversations(LIST_LIMIT)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writePage(w, "list", struct {
+		Conversations []*Conversation
+	}{
+		Conversations: listingsToConversations(listings),
+	})
+}
+
+func (s *Server) HandleConversation(w http.ResponseWriter, r *http.Request) {
+	hash := r.FormValue("hash")
+	conversationHash, err := base64.RawURLEncoding.DecodeString(hash)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	listing, err := s.Messages.GetConversation(conversationHash)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	messages, err := s.GetMessageTree(conversationHash)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writePage(w, "conversation", struct {
+		Conversation *Conversation
+		Messages     []*Message
+	}{
+		Conversation: listingToConversation(listing),
+		Messages:     messages,
+	})
+}
+
+func (s *Server) HandleCompose(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writePage(w, "compose", nil)
+	case http.MethodPost:
+		alias, key, err := s.authenticate(r)
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, err)
+			return
+		}
+		topic := r.FormValue("topic")
+		if topic == "" {
+			writeError(w, http.StatusBadRequest, errors.New(fmt.Sprintf(ERROR_MISSING_FIELD, "topic")))
+			return
+		}
+		content := r.FormValue("content")
+		if content == "" {
+			writeError(w, http.StatusBadRequest, errors.New(fmt.Sprintf(ERROR_MISSING_FIELD, "content")))
+			return
+		}
+		timestamp := bcgo.Timestamp()
+		conversationHash, conversationRecord, err := conveygo.ProtoToRecord(alias, key, timestamp, &conveygo.Conversation{
+			Topic: topic,
+		})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		messageHash, messageRecord, err := conveygo.ProtoToRecord(alias, key, timestamp, &conveygo.Message{
+			Content: []byte(content),
+			Type:    conveygo.MediaType_TEXT_PLAIN,
+		})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if err := s.Messages.NewConversation(conversationHash, conversationRecord, messageHash, messageRecord); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		http.Redirect(w, r, "/conversation?hash="+base64.RawURLEncoding.EncodeToString(conversationHash), http.StatusSeeOther)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) HandleReply(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	alias, key, err := s.authenticate(r)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+	hash := r.FormValue("conversation")
+	conversationHash, err := base64.RawURLEncoding.DecodeString(hash)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	previous, err := base64.RawURLEncoding.DecodeString(r.FormValue("previous"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(previous) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New(fmt.Sprintf(ERROR_MISSING_FIELD, "previous")))
+		return
+	}
+	content := r.FormValue("content")
+	if content == "" {
+		writeError(w, http.StatusBadRequest, errors.New(fmt.Sprintf(ERROR_MISSING_FIELD, "content")))
+		return
+	}
+	// The message being replied to must be in the same conversation
+	found := false
+	if err := s.Messages.GetMessage(conversationHash, previous, func([]byte, uint64, string, uint64, *conveygo.Message) error {
+		found = true
+		return nil
+	}); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if !found {
+		writeError(w, http.StatusBadRequest, errors.New(fmt.Sprintf(ERROR_NO_SUCH_MESSAGE, r.FormValue("previous"))))
+		return
+	}
+	messageHash, messageRecord, err := conveygo.ProtoToRecord(alias, key, bcgo.Timestamp(), &conveygo.Message{
+		Previous: previous,
+		Content:  []byte(content),
+		Type:     conveygo.MediaType_TEXT_PLAIN,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := s.Messages.AddMessage(conversationHash, messageHash, messageRecord); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	http.Redirect(w, r, "/conversation?hash="+hash, http.StatusSeeOther)
+}
+
+func (s *Server) HandleAlias(w http.ResponseWriter, r *http.Request) {
+	alias := r.FormValue("alias")
+	if alias == "" {
+		writeError(w, http.StatusBadRequest, errors.New(fmt.Sprintf(ERROR_MISSING_FIELD, "alias")))
+		return
+	}
+
+	s.lock.Lock()
+	if err := s.Ledger.UpdateAll(); err != nil {
+		s.lock.Unlock()
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := s.Ledger.UpdateAllowance(s.Users, alias); err != nil {
+		s.lock.Unlock()
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	balance, allowance := s.Ledger.GetBalance(alias)
+	data := struct {
+		Alias         string
+		Balance       int64
+		Allowance     uint64
+		Minted        uint64
+		Burned        uint64
+		Bought        uint64
+		Sold          uint64
+		Earned        uint64
+		Spent         uint64
+		Conversations []*Conversation
+	}{
+		Alias:     alias,
+		Balance:   balance,
+		Allowance: allowance,
+		Minted:    s.Ledger.Minted[alias],
+		Burned:    s.Ledger.Burned[alias],
+		Bought:    s.Ledger.Bought[alias],
+		Sold:      s.Ledger.Sold[alias],
+		Earned:    s.Ledger.Earned[alias],
+		Spent:     s.Ledger.Spent[alias],
+	}
+	s.lock.Unlock()
+
+	// History is the conversations started by the alias, most recent first
+	listings, err := s.Messages.GetAllConversations(0, bcgo.Timestamp())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	var authored []*conveygo.Listing
+	for _, l := range listings {
+		if l.Author == alias {
+			authored = append(authored, l)
+		}
+	}
+	sort.Slice(authored, func(i, j int) bool {
+		return authored[i].Timestamp > authored[j].Timestamp
+	})
+	data.Conversations = listingsToConversations(authored)
+	writePage(w, "alias", data)
+}
+
+func (s *Server) HandleDigests(w http.ResponseWriter, r *http.Request) {
+	writePage(w, "digests", struct {
+		Periods []*Period
+	}{
+		Periods: GetArchivePeriods(time.Now(), ARCHIVE_PERIOD, ARCHIVE_PERIODS),
+	})
+}
+
+func (s *Server) HandleDigest(w http.ResponseWriter, r *http.Request) {
+	from, err := strconv.ParseUint(r.FormValue("from"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	to, err := strconv.ParseUint(r.FormValue("to"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	entries, err := conveygo.GetDigestEntries(s.Messages, from, to)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	var signature template.HTML
+	if s.Key != nil && len(entries) > 0 {
+		_, record, err := conveygo.DigestToRecord(s.Alias, s.Key, bcgo.Timestamp(), entries)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		signature, err = html.DigestSignatureToHTML(record)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	var results []*DigestEntry
+	for _, e := range entries {
+		entry := &DigestEntry{
+			Hash:      e.Hash,
+			Topic:     e.Topic,
+			Timestamp: e.Timestamp,
+			Author:    e.Author,
+			Yield:     e.Yield,
+		}
+		if e.Message != nil {
+			entry.Content = contentToHTML(e.Message)
+		}
+		results = append(results, entry)
+	}
+	writePage(w, "digest", struct {
+		Signature template.HTML
+		Period    *Period
+		Entries   []*DigestEntry
+	}{
+		Signature: signature,
+		Period:    newPeriod(from, to),
+		Entries:   results,
+	})
+}
+
+// Returns the messages in the given conversation nested under the messages they reply to, with the yield of each.
+func (s *Server) GetMessageTree(conversationHash []byte) ([]*Message, error) {
+	var messages []*Message
+	previous := make(map[*Message]string)
+	var timestamps []uint64
+	if err := s.Messages.GetMessage(conversationHash, nil, func(hash []byte, timestamp uint64, author string, cost uint64, message *conveygo.Message) error {
+		m := &Message{
+			Conversation: base64.RawURLEncoding.EncodeToString(conversationHash),
+			Hash:         base64.RawURLEncoding.EncodeToString(hash),
+			Timestamp:    bcgo.TimestampToString(timestamp),
+			Author:       author,
+			Cost:         cost,
+			Content:      contentToHTML(message),
+		}
+		previous[m] = base64.RawURLEncoding.EncodeToString(message.Previous)
+		messages = append(messages, m)
+		timestamps = append(timestamps, timestamp)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// Chronological order, keeping store order for equal timestamps
+	order := make([]int, len(messages))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return timestamps[order[i]] < timestamps[order[j]]
+	})
+	sorted := make([]*Message, len(messages))
+	for i, o := range order {
+		sorted[i] = messages[o]
+	}
+
+	index := make(map[string]*Message)
+	for _, m := range sorted {
+		index[m.Hash] = m
+	}
+
+	// Each reply pays half its cost to the message it replies to, and half the remainder up the hierarchy, as in the Ledger
+	for _, m := range sorted {
+		cost := m.Cost
+		for parent, ok := index[previous[m]]; ok; parent, ok = index[previous[parent]] {
+			half := cost / 2
+			parent.Reward += half
+			cost -= half
+		}
+	}
+
+	var roots []*Message
+	for _, m := range sorted {
+		m.Yield = int64(m.Reward) - int64(m.Cost)
+		if parent, ok := index[previous[m]]; ok {
+			parent.Replies = append(parent.Replies, m)
+		} else {
+			roots = append(roots, m)
+		}
+	}
+	return roots, nil
+}
+
+// Returns the given number of consecutive periods of the given length, most recent first, ending at the start of the day containing now.
+func GetArchivePeriods(now time.Time, length time.Duration, count int) []*Period {
+	end := now.UTC().Truncate(24 * time.Hour)
+	var periods []*Period
+	for i := 0; i < count; i++ {
+		start := end.Add(-length)
+		periods = append(periods, newPeriod(uint64(start.UnixNano()), uint64(end.UnixNano())))
+		end = start
+	}
+	return periods
+}
+
+func newPeriod(from, to uint64) *Period {
+	return &Period{
+		From:  from,
+		To:    to,
+		Label: time.Unix(0, int64(from)).UTC().Format("2006-01-02") + " to " + time.Unix(0, int64(to)).UTC().Format("2006-01-02"),
+	}
+}
+
+func listingToConversation(listing *conveygo.Listing) *Conversation {
+	return &Conversation{
+		Hash:      base64.RawURLEncoding.EncodeToString(listing.Hash),
+		Timestamp: bcgo.TimestampToString(listing.Timestamp),
+		Author:    listing.Author,
+		Topic:     listing.Topic,
+		Cost:      listing.Cost,
+	}
+}
+
+func listingsToConversations(listings []*conveygo.Listing) []*Conversation {
+	conversations := make([]*Conversation, len(listings))
+	for i, l := range listings {
+		conversations[i] = listingToConversation(l)
+	}
+	return conversations
+}
+
+// Renders the message content, or an escaped note if the media type is not supported.
+func contentToHTML(message *conveygo.Message) template.HTML {
+	content, err := html.ContentToHTML(message)
+	if err != nil {
+		return template.HTML(`<p><em>` + template.HTMLEscapeString(err.Error()) + `</em></p>`)
+	}
+	return content
+}
+
+func (s *Server) authenticate(r *http.Request) (string, crypto.Signer, error) {
+	alias := r.FormValue("alias")
+	key, err := s.Users.GetKey(alias, []byte(r.FormValue("password")))
+	if err != nil {
+		return "", nil, err
+	}
+	return alias, key, nil
+}
+
+func writePage(w http.ResponseWriter, name string, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.ExecuteTemplate(w, name, data); err != nil {
+		log.Println(err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := templates.ExecuteTemplate(w, "error", struct {
+		Status string
+		Error  string
+	}{
+		Status: http.StatusText(status),
+		Error:  err.Error(),
+	}); err != nil {
+		log.Println(err)
+	}
+}
+
+// Returns the digest page URL for the given period.
+func DigestURL(p *Period) string {
+	values := url.Values{}
+	values.Set("from", strconv.FormatUint(p.From, 10))
+	values.Set("to", strconv.FormatUint(p.To, 10))
+	return "/digest?" + values.Encode()
+}
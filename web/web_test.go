@@ -0,0 +1,238 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/html"
+	"github.com/AletheiaWareLLC/conveygo/web"
+	"github.com/AletheiaWareLLC/testinggo"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func assertContains(t *testing.T, body, expected string) {
+	t.Helper()
+	if !strings.Contains(body, expected) {
+		t.Errorf("Expected page to contain '%s', got '%s'", expected, body)
+	}
+}
+
+func get(t *testing.T, u string, status int) string {
+	t.Helper()
+	response, err := http.Get(u)
+	testinggo.AssertNoError(t, err)
+	defer response.Body.Close()
+	if response.StatusCode != status {
+		t.Fatalf("Wrong status; expected '%d', got '%d'", status, response.StatusCode)
+	}
+	body, err := ioutil.ReadAll(response.Body)
+	testinggo.AssertNoError(t, err)
+	return string(body)
+}
+
+func post(t *testing.T, u string, values url.Values, status int) (string, string) {
+	t.Helper()
+	response, err := http.PostForm(u, values)
+	testinggo.AssertNoError(t, err)
+	defer response.Body.Close()
+	if response.StatusCode != status {
+		t.Fatalf("Wrong status; expected '%d', got '%d'", status, response.StatusCode)
+	}
+	body, err := ioutil.ReadAll(response.Body)
+	testinggo.AssertNoError(t, err)
+	return response.Request.URL.String(), string(body)
+}
+
+func TestServer(t *testing.T) {
+	alias := "Alice"
+	password := "password1234"
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	testinggo.AssertNoError(t, err)
+	store := conveygo.NewMemoryStore()
+	testinggo.AssertNoError(t, store.AddKey(alias, []byte(password), key))
+	node := &bcgo.Node{
+		Alias:    alias,
+		Cache:    bcgo.NewMemoryCache(10),
+		Channels: make(map[string]*bcgo.Channel),
+	}
+	server := web.NewServer(store, store, conveygo.NewLedger(node))
+	server.Alias = alias
+	server.Key = key
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+
+	// Compose redirects to the new conversation
+	location, body := post(t, ts.URL+"/compose", url.Values{
+		"alias":    {alias},
+		"password": {password},
+		"topic":    {"Test <Topic>"},
+		"content":  {"Hello\n\nWorld"},
+	}, http.StatusOK)
+	assertContains(t, body, "Test &lt;Topic&gt;")
+	assertContains(t, body, "<p>Hello</p><p>World</p>")
+	conversation := strings.TrimPrefix(location, ts.URL+"/conversation?hash=")
+
+	listing, err := store.GetRecentConversations(1)
+	testinggo.AssertNoError(t, err)
+	var first string
+	testinggo.AssertNoError(t, store.GetMessage(listing[0].Hash, nil, func(hash []byte, timestamp uint64, author string, cost uint64, message *conveygo.Message) error {
+		if len(message.Previous) == 0 {
+			first = base64.RawURLEncoding.EncodeToString(hash)
+		}
+		return nil
+	}))
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		post(t, ts.URL+"/compose", url.Values{
+			"alias":    {alias},
+			"password": {"wrong"},
+			"topic":    {"Test"},
+			"content":  {"Hello"},
+		}, http.StatusUnauthorized)
+	})
+	t.Run("List", func(t *testing.T) {
+		body := get(t, ts.URL+"/", http.StatusOK)
+		assertContains(t, body, `<a href="/conversation?hash=`+conversation+`">Test &lt;Topic&gt;</a>`)
+	})
+	t.Run("Reply", func(t *testing.T) {
+		_, body := post(t, ts.URL+"/reply", url.Values{
+			"alias":        {alias},
+			"password":     {password},
+			"conversation": {conversation},
+			"previous":     {first},
+			"content":      {"Reply"},
+		}, http.StatusOK)
+		assertContains(t, body, `<div class="replies">`)
+		assertContains(t, body, "<p>Reply</p>")
+	})
+	t.Run("ReplyNoSuchPrevious", func(t *testing.T) {
+		// The conversation's hash is not a message in it
+		post(t, ts.URL+"/reply", url.Values{
+			"alias":        {alias},
+			"password":     {password},
+			"conversation": {conversation},
+			"previous":     {conversation},
+			"content":      {"Reply"},
+		}, http.StatusBadRequest)
+	})
+	t.Run("Compose", func(t *testing.T) {
+		body := get(t, ts.URL+"/compose", http.StatusOK)
+		assertContains(t, body, `<form method="post" action="/compose">`)
+	})
+	t.Run("Alias", func(t *testing.T) {
+		server.Ledger.RecordMinted(alias, 3600)
+		body := get(t, ts.URL+"/alias?alias="+alias, http.StatusOK)
+		assertContains(t, body, "Balance: 3600")
+		assertContains(t, body, "Test &lt;Topic&gt;")
+	})
+	t.Run("Digests", func(t *testing.T) {
+		body := get(t, ts.URL+"/digests", http.StatusOK)
+		assertContains(t, body, "/digest?from=")
+	})
+	t.Run("Digest", func(t *testing.T) {
+		body := get(t, ts.URL+web.DigestURL(&web.Period{From: 0, To: bcgo.Timestamp()}), http.StatusOK)
+		assertContains(t, body, "Test &lt;Topic&gt;")
+		record, err := html.ReadDigestSignature([]byte(body))
+		testinggo.AssertNoError(t, err)
+		if record.Creator != alias {
+			t.Errorf("Wrong signer; expected '%s', got '%s'", alias, record.Creator)
+		}
+	})
+	t.Run("NotFound", func(t *testing.T) {
+		get(t, ts.URL+"/conversation?hash=AAAA", http.StatusNotFound)
+	})
+}
+
+func TestGetMessageTree(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	testinggo.AssertNoError(t, err)
+	store := conveygo.NewMemoryStore()
+	server := web.NewServer(store, store, nil)
+
+	timestamp := bcgo.Timestamp()
+	conversationHash, conversationRecord, err := conveygo.ProtoToRecord("Alice", key, timestamp, &conveygo.Conversation{
+		Topic: "Test",
+	})
+	testinggo.AssertNoError(t, err)
+	rootHash, rootRecord, err := conveygo.ProtoToRecord("Alice", key, timestamp, &conveygo.Message{
+		Content: []byte("Root"),
+		Type:    conveygo.MediaType_TEXT_PLAIN,
+	})
+	testinggo.AssertNoError(t, err)
+	testinggo.AssertNoError(t, store.NewConversation(conversationHash, conversationRecord, rootHash, rootRecord))
+	replyHash, replyRecord, err := conveygo.ProtoToRecord("Bob", key, timestamp, &conveygo.Message{
+		Previous: rootHash,
+		Content:  []byte(strings.Repeat("Reply", 100)),
+		Type:     conveygo.MediaType_TEXT_PLAIN,
+	})
+	testinggo.AssertNoError(t, err)
+	testinggo.AssertNoError(t, store.AddMessage(conversationHash, replyHash, replyRecord))
+	nestedHash, nestedRecord, err := conveygo.ProtoToRecord("Charlie", key, timestamp, &conveygo.Message{
+		Previous: replyHash,
+		Content:  []byte(strings.Repeat("Nested", 100)),
+		Type:     conveygo.MediaType_TEXT_PLAIN,
+	})
+	testinggo.AssertNoError(t, err)
+	testinggo.AssertNoError(t, store.AddMessage(conversationHash, nestedHash, nestedRecord))
+
+	roots, err := server.GetMessageTree(conversationHash)
+	testinggo.AssertNoError(t, err)
+	if len(roots) != 1 || len(roots[0].Replies) != 1 || len(roots[0].Replies[0].Replies) != 1 {
+		t.Fatalf("Wrong tree; expected root > reply > nested")
+	}
+	root := roots[0]
+	reply := root.Replies[0]
+	nested := reply.Replies[0]
+	replyCost := conveygo.Cost(replyRecord)
+	nestedCost := conveygo.Cost(nestedRecord)
+	// Reply pays half to the root, Nested pays half to the reply and half the remainder to the root
+	expectedReply := nestedCost / 2
+	expectedRoot := replyCost/2 + (nestedCost-nestedCost/2)/2
+	if reply.Reward != expectedReply {
+		t.Errorf("Wrong reply reward; expected '%d', got '%d'", expectedReply, reply.Reward)
+	}
+	if root.Reward != expectedRoot {
+		t.Errorf("Wrong root reward; expected '%d', got '%d'", expectedRoot, root.Reward)
+	}
+	if nested.Yield != -int64(nestedCost) {
+		t.Errorf("Wrong nested yield; expected '%d', got '%d'", -int64(nestedCost), nested.Yield)
+	}
+}
+
+func TestGetArchivePeriods(t *testing.T) {
+	now := time.Date(2020, 6, 15, 12, 0, 0, 0, time.UTC)
+	periods := web.GetArchivePeriods(now, 7*24*time.Hour, 2)
+	if len(periods) != 2 {
+		t.Fatalf("Wrong number of periods; expected '2', got '%d'", len(periods))
+	}
+	if periods[0].Label != "2020-06-08 to 2020-06-15" {
+		t.Errorf("Wrong label; expected '2020-06-08 to 2020-06-15', got '%s'", periods[0].Label)
+	}
+	if periods[1].To != periods[0].From {
+		t.Errorf("Periods not consecutive; expected '%d', got '%d'", periods[0].From, periods[1].To)
+	}
+}
@@ -0,0 +1,390 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/events"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	TYPE_NEW_DIGEST = "NewDigest"
+
+	HEADER_DELIVERY  = "X-Convey-Delivery"
+	HEADER_EVENT     = "X-Convey-Event"
+	HEADER_SIGNATURE = "X-Convey-Signature"
+
+	SIGNATURE_PREFIX = "sha256="
+
+	DEFAULT_MAX_ATTEMPTS  = 5
+	DEFAULT_BACKOFF       = time.Second
+	DEFAULT_MAX_BACKOFF   = 5 * time.Minute
+	DEFAULT_TIMEOUT       = 30 * time.Second
+	DEFAULT_HISTORY_LIMIT = 100
+
+	ERROR_NO_SUCH_ENDPOINT = "No such endpoint: %s"
+	ERROR_NO_SUCH_DELIVERY = "No such delivery: %s"
+	ERROR_RESPONSE_STATUS  = "Endpoint responded: %s"
+)
+
+// The event types delivered to endpoints that do not choose their own.
+var DEFAULT_TYPES = []string{
+	events.EVENT_NEW_CONVERSATION,
+	events.EVENT_NEW_REPLY,
+	events.EVENT_NEW_TRANSACTION,
+	TYPE_NEW_DIGEST,
+}
+
+// Endpoint is a URL registered to receive payloads, signed with its secret.
+type Endpoint struct {
+	ID     string
+	URL    string
+	Secret []byte
+	// Types of payload to deliver; empty for DEFAULT_TYPES.
+	Types []string
+}
+
+func (e *Endpoint) Accepts(t string) bool {
+	types := e.Types
+	if len(types) == 0 {
+		types = DEFAULT_TYPES
+	}
+	for _, a := range types {
+		if a == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Digest is the payload of a NewDigest delivery.
+type Digest struct {
+	From    uint64
+	To      uint64
+	Entries []*conveygo.DigestEntry
+}
+
+// Payload is the JSON body posted to endpoints.
+type Payload struct {
+	ID        string
+	Type      string
+	Timestamp uint64
+	Event     *events.Event `json:",omitempty"`
+	Digest    *Digest       `json:",omitempty"`
+}
+
+// Delivery records the attempts to post a payload to an endpoint.
+type Delivery struct {
+	ID        string
+	Endpoint  string
+	URL       string
+	Type      string
+	Payload   []byte
+	Attempts  int
+	Status    int    `json:",omitempty"`
+	Error     string `json:",omitempty"`
+	Delivered bool
+	Timestamp uint64
+}
+
+// Dispatcher signs payloads with HMAC-SHA256 and posts them to registered endpoints, retrying failures with exponential backoff.
+// Deliveries that exhaust their attempts are kept as dead letters, and written as JSON lines to the DeadLetterLog if it is set.
+// An endpoint that does not respond within the Client's timeout counts as a failed attempt.
+type Dispatcher struct {
+	// Used to compute digests, may be nil if digests are not delivered.
+	Messages      conveygo.MessageStore
+	Client        *http.Client
+	MaxAttempts   int
+	Backoff       time.Duration
+	MaxBackoff    time.Duration
+	HistoryLimit  int
+	DeadLetterLog io.Writer
+	Endpoints     map[string]*Endpoint
+	History       map[string][]*Delivery // Endpoint ID -> Deliveries, oldest first
+	DeadLetters   []*Delivery
+	lock          sync.Mutex
+	pending       sync.WaitGroup
+}
+
+func NewDispatcher(messages conveygo.MessageStore) *Dispatcher {
+	return &Dispatcher{
+		Messages:     messages,
+		Client:       &http.Client{Timeout: DEFAULT_TIMEOUT},
+		MaxAttempts:  DEFAULT_MAX_ATTEMPTS,
+		Backoff:      DEFAULT_BACKOFF,
+		MaxBackoff:   DEFAULT_MAX_BACKOFF,
+		HistoryLimit: DEFAULT_HISTORY_LIMIT,
+		Endpoints:    make(map[string]*Endpoint),
+		History:      make(map[string][]*Delivery),
+	}
+}
+
+func (d *Dispatcher) Register(endpoint *Endpoint) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.Endpoints[endpoint.ID] = endpoint
+}
+
+func (d *Dispatcher) Unregister(id string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	delete(d.Endpoints, id)
+}
+
+// Returns copies of the deliveries made to the endpoint, oldest first.
+func (d *Dispatcher) GetHistory(id string) ([]*Delivery, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if _, ok := d.Endpoints[id]; !ok {
+		return nil, errors.New(fmt.Sprintf(ERROR_NO_SUCH_ENDPOINT, id))
+	}
+	var results []*Delivery
+	for _, delivery := range d.History[id] {
+		c := *delivery
+		results = append(results, &c)
+	}
+	return results, nil
+}
+
+// Returns copies of the deliveries that exhausted their attempts.
+func (d *Dispatcher) GetDeadLetters() []*Delivery {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	var results []*Delivery
+	for _, delivery := range d.DeadLetters {
+		c := *delivery
+		results = append(results, &c)
+	}
+	return results
+}
+
+// Removes the dead letter and delivers it again, if its endpoint is still registered.
+func (d *Dispatcher) Redeliver(id string) error {
+	d.lock.Lock()
+	var delivery *Delivery
+	for i, l := range d.DeadLetters {
+		if l.ID == id {
+			delivery = l
+			d.DeadLetters = append(d.DeadLetters[:i], d.DeadLetters[i+1:]...)
+			break
+		}
+	}
+	if delivery == nil {
+		d.lock.Unlock()
+		return errors.New(fmt.Sprintf(ERROR_NO_SUCH_DELIVERY, id))
+	}
+	endpoint, ok := d.Endpoints[delivery.Endpoint]
+	if !ok {
+		d.lock.Unlock()
+		return errors.New(fmt.Sprintf(ERROR_NO_SUCH_ENDPOINT, delivery.Endpoint))
+	}
+	delivery.Attempts = 0
+	delivery.Error = ""
+	d.pending.Add(1)
+	d.lock.Unlock()
+	go d.deliver(endpoint, delivery)
+	return nil
+}
+
+// Blocks until all pending deliveries have succeeded or been dead lettered.
+func (d *Dispatcher) Wait() {
+	d.pending.Wait()
+}
+
+// Subscribes to every event from the dispatcher and delivers them in the background.
+func (d *Dispatcher) Listen(dispatcher *events.Dispatcher) *events.Subscription {
+	s := dispatcher.Subscribe(nil, events.DEFAULT_BUFFER)
+	go d.Run(s)
+	return s
+}
+
+// Delivers events from the subscription until it is closed.
+func (d *Dispatcher) Run(s *events.Subscription) {
+	for e := range s.Events {
+		if err := d.Send(&Payload{
+			Type:      e.Type,
+			Timestamp: e.Timestamp,
+			Event:     e,
+		}); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// Delivers the digest of the given period to every endpoint accepting digests.
+func (d *Dispatcher) SendDigest(from, to uint64) error {
+	entries, err := conveygo.GetDigestEntries(d.Messages, from, to)
+	if err != nil {
+		return err
+	}
+	return d.Send(&Payload{
+		Type:      TYPE_NEW_DIGEST,
+		Timestamp: to,
+		Digest: &Digest{
+			From:    from,
+			To:      to,
+			Entries: entries,
+		},
+	})
+}
+
+// Delivers the digest of the preceding period each time a block is added to the channel, such as one of the periodic validation chains.
+func (d *Dispatcher) WatchDigests(channel *bcgo.Channel, period time.Duration) {
+	channel.AddTrigger(func() {
+		to := channel.Timestamp
+		from := to - uint64(period.Nanoseconds())
+		if err := d.SendDigest(from, to); err != nil {
+			log.Println(err)
+		}
+	})
+}
+
+// Assigns the payload an ID, then posts it to each endpoint accepting its type.
+func (d *Dispatcher) Send(payload *Payload) error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	payload.ID = id
+	if payload.Timestamp == 0 {
+		payload.Timestamp = bcgo.Timestamp()
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for _, endpoint := range d.Endpoints {
+		if !endpoint.Accepts(payload.Type) {
+			continue
+		}
+		delivery := &Delivery{
+			ID:        id + "-" + endpoint.ID,
+			Endpoint:  endpoint.ID,
+			URL:       endpoint.URL,
+			Type:      payload.Type,
+			Payload:   data,
+			Timestamp: bcgo.Timestamp(),
+		}
+		history := append(d.History[endpoint.ID], delivery)
+		if d.HistoryLimit > 0 && len(history) > d.HistoryLimit {
+			history = history[len(history)-d.HistoryLimit:]
+		}
+		d.History[endpoint.ID] = history
+		d.pending.Add(1)
+		go d.deliver(endpoint, delivery)
+	}
+	return nil
+}
+
+// Attempts the delivery until it succeeds or runs out of attempts, doubling the wait between each.
+func (d *Dispatcher) deliver(endpoint *Endpoint, delivery *Delivery) {
+	defer d.pending.Done()
+	backoff := d.Backoff
+	for {
+		status, err := d.post(endpoint, delivery)
+		d.lock.Lock()
+		delivery.Attempts++
+		delivery.Status = status
+		if err == nil {
+			delivery.Delivered = true
+			delivery.Error = ""
+			d.lock.Unlock()
+			return
+		}
+		delivery.Error = err.Error()
+		if delivery.Attempts >= d.MaxAttempts {
+			d.DeadLetters = append(d.DeadLetters, delivery)
+			if d.DeadLetterLog != nil {
+				if data, err := json.Marshal(delivery); err != nil {
+					log.Println(err)
+				} else if _, err := fmt.Fprintf(d.DeadLetterLog, "%s\n", data); err != nil {
+					log.Println(err)
+				}
+			}
+			d.lock.Unlock()
+			return
+		}
+		d.lock.Unlock()
+		time.Sleep(backoff)
+		backoff *= 2
+		if d.MaxBackoff > 0 && backoff > d.MaxBackoff {
+			backoff = d.MaxBackoff
+		}
+	}
+}
+
+func (d *Dispatcher) post(endpoint *Endpoint, delivery *Delivery) (int, error) {
+	request, err := http.NewRequest(http.MethodPost, endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HEADER_DELIVERY, delivery.ID)
+	request.Header.Set(HEADER_EVENT, delivery.Type)
+	request.Header.Set(HEADER_SIGNATURE, Sign(endpoint.Secret, delivery.Payload))
+	response, err := d.Client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, errors.New(fmt.Sprintf(ERROR_RESPONSE_STATUS, response.Status))
+	}
+	return response.StatusCode, nil
+}
+
+// Returns the signature header value for the payload.
+func Sign(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return SIGNATURE_PREFIX + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Checks the signature header value matches the payload, for use by receivers.
+func Verify(secret, payload []byte, signature string) bool {
+	if !strings.HasPrefix(signature, SIGNATURE_PREFIX) {
+		return false
+	}
+	expected := Sign(secret, payload)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+func newID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(id), nil
+}
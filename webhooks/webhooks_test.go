@@ -0,0 +1,311 @@
+/*
+ * Copyright 2020 Aletheia Ware LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webhooks_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"github.com/AletheiaWareLLC/bcgo"
+	"github.com/AletheiaWareLLC/conveygo"
+	"github.com/AletheiaWareLLC/conveygo/events"
+	"github.com/AletheiaWareLLC/conveygo/webhooks"
+	"github.com/AletheiaWareLLC/testinggo"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Receiver records the payloads posted to it, failing the first Failures requests.
+type receiver struct {
+	Secret   []byte
+	Failures int
+	Payloads []*webhooks.Payload
+	Invalid  int
+	lock     sync.Mutex
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.Failures > 0 {
+		r.Failures--
+		http.Error(w, "Unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	data, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !webhooks.Verify(r.Secret, data, req.Header.Get(webhooks.HEADER_SIGNATURE)) {
+		r.Invalid++
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+	payload := &webhooks.Payload{}
+	if err := json.Unmarshal(data, payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.Payloads = append(r.Payloads, payload)
+}
+
+func makeDispatcher(t *testing.T, messages conveygo.MessageStore, r *receiver, types ...string) (*webhooks.Dispatcher, *httptest.Server) {
+	t.Helper()
+	ts := httptest.NewServer(r)
+	d := webhooks.NewDispatcher(messages)
+	d.Backoff = time.Millisecond
+	d.MaxAttempts = 3
+	d.Register(&webhooks.Endpoint{
+		ID:     "test",
+		URL:    ts.URL,
+		Secret: r.Secret,
+		Types:  types,
+	})
+	return d, ts
+}
+
+func TestSignature(t *testing.T) {
+	secret := []byte("secret")
+	payload := []byte(`{"Type":"NewReply"}`)
+	signature := webhooks.Sign(secret, payload)
+	if !strings.HasPrefix(signature, "sha256=") {
+		t.Errorf("Wrong signature format; got '%s'", signature)
+	}
+	if !webhooks.Verify(secret, payload, signature) {
+		t.Error("Expected signature to verify")
+	}
+	if webhooks.Verify([]byte("other"), payload, signature) {
+		t.Error("Expected signature with wrong secret to fail")
+	}
+	if webhooks.Verify(secret, []byte(`{"Type":"NewTag"}`), signature) {
+		t.Error("Expected signature of altered payload to fail")
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Run("Delivered", func(t *testing.T) {
+		r := &receiver{Secret: []byte("secret")}
+		d, ts := makeDispatcher(t, nil, r)
+		defer ts.Close()
+		testinggo.AssertNoError(t, d.Send(&webhooks.Payload{
+			Type: events.EVENT_NEW_REPLY,
+			Event: &events.Event{
+				Type:   events.EVENT_NEW_REPLY,
+				Author: "Alice",
+			},
+		}))
+		d.Wait()
+		if len(r.Payloads) != 1 || r.Payloads[0].Event.Author != "Alice" {
+			t.Fatalf("Wrong payloads; expected reply from 'Alice', got '%v'", r.Payloads)
+		}
+		history, err := d.GetHistory("test")
+		testinggo.AssertNoError(t, err)
+		if len(history) != 1 || !history[0].Delivered || history[0].Attempts != 1 || history[0].Status != http.StatusOK {
+			t.Errorf("Wrong history; got '%v'", history[0])
+		}
+	})
+	t.Run("Types", func(t *testing.T) {
+		r := &receiver{Secret: []byte("secret")}
+		d, ts := makeDispatcher(t, nil, r)
+		defer ts.Close()
+		testinggo.AssertNoError(t, d.Send(&webhooks.Payload{
+			Type: events.EVENT_NEW_TAG,
+		}))
+		d.Wait()
+		if len(r.Payloads) != 0 {
+			t.Errorf("Wrong payloads; expected none, got '%d'", len(r.Payloads))
+		}
+	})
+	t.Run("Retried", func(t *testing.T) {
+		r := &receiver{Secret: []byte("secret"), Failures: 2}
+		d, ts := makeDispatcher(t, nil, r)
+		defer ts.Close()
+		testinggo.AssertNoError(t, d.Send(&webhooks.Payload{
+			Type: events.EVENT_NEW_CONVERSATION,
+		}))
+		d.Wait()
+		if len(r.Payloads) != 1 {
+			t.Fatalf("Wrong payloads; expected '1', got '%d'", len(r.Payloads))
+		}
+		history, err := d.GetHistory("test")
+		testinggo.AssertNoError(t, err)
+		if history[0].Attempts != 3 || !history[0].Delivered {
+			t.Errorf("Wrong attempts; expected '3', got '%d'", history[0].Attempts)
+		}
+	})
+	t.Run("DeadLetter", func(t *testing.T) {
+		r := &receiver{Secret: []byte("secret"), Failures: 3}
+		d, ts := makeDispatcher(t, nil, r)
+		defer ts.Close()
+		var log bytes.Buffer
+		d.DeadLetterLog = &log
+		testinggo.AssertNoError(t, d.Send(&webhooks.Payload{
+			Type: events.EVENT_NEW_TRANSACTION,
+		}))
+		d.Wait()
+		letters := d.GetDeadLetters()
+		if len(letters) != 1 || letters[0].Delivered || letters[0].Status != http.StatusServiceUnavailable {
+			t.Fatalf("Wrong dead letters; got '%v'", letters)
+		}
+		logged := &webhooks.Delivery{}
+		testinggo.AssertNoError(t, json.Unmarshal(log.Bytes(), logged))
+		if logged.ID != letters[0].ID {
+			t.Errorf("Wrong logged delivery; expected '%s', got '%s'", letters[0].ID, logged.ID)
+		}
+
+		testinggo.AssertNoError(t, d.Redeliver(letters[0].ID))
+		d.Wait()
+		if len(r.Payloads) != 1 || len(d.GetDeadLetters()) != 0 {
+			t.Errorf("Expected redelivery to succeed")
+		}
+		testinggo.AssertError(t, "No such delivery: unknown", d.Redeliver("unknown"))
+	})
+	t.Run("Timeout", func(t *testing.T) {
+		// The endpoint does not respond until the test ends, so each attempt times out
+		release := make(chan struct{})
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			<-release
+		}))
+		defer ts.Close()
+		defer close(release)
+		d := webhooks.NewDispatcher(nil)
+		d.Backoff = time.Millisecond
+		d.MaxAttempts = 2
+		d.Client.Timeout = 10 * time.Millisecond
+		d.Register(&webhooks.Endpoint{
+			ID:     "test",
+			URL:    ts.URL,
+			Secret: []byte("secret"),
+		})
+		testinggo.AssertNoError(t, d.Send(&webhooks.Payload{
+			Type: events.EVENT_NEW_REPLY,
+		}))
+		d.Wait()
+		letters := d.GetDeadLetters()
+		if len(letters) != 1 || letters[0].Delivered || letters[0].Attempts != 2 || letters[0].Error == "" {
+			t.Fatalf("Wrong dead letters; expected '2' timed out attempts, got '%v'", letters)
+		}
+	})
+	t.Run("InvalidSecret", func(t *testing.T) {
+		r := &receiver{Secret: []byte("secret")}
+		d, ts := makeDispatcher(t, nil, r)
+		defer ts.Close()
+		d.MaxAttempts = 1
+		d.Register(&webhooks.Endpoint{
+			ID:     "test",
+			URL:    ts.URL,
+			Secret: []byte("wrong"),
+		})
+		testinggo.AssertNoError(t, d.Send(&webhooks.Payload{
+			Type: events.EVENT_NEW_REPLY,
+		}))
+		d.Wait()
+		if r.Invalid != 1 || len(d.GetDeadLetters()) != 1 {
+			t.Errorf("Expected invalid signature to be rejected")
+		}
+	})
+	t.Run("NoSuchEndpoint", func(t *testing.T) {
+		d := webhooks.NewDispatcher(nil)
+		_, err := d.GetHistory("unknown")
+		testinggo.AssertError(t, "No such endpoint: unknown", err)
+	})
+}
+
+func TestListen(t *testing.T) {
+	r := &receiver{Secret: []byte("secret")}
+	d, ts := makeDispatcher(t, nil, r)
+	defer ts.Close()
+	dispatcher := events.NewDispatcher(&bcgo.Node{
+		Channels: make(map[string]*bcgo.Channel),
+	}, nil)
+	s := d.Listen(dispatcher)
+	dispatcher.Publish(&events.Event{
+		Type:     events.EVENT_NEW_TRANSACTION,
+		Author:   "Alice",
+		Receiver: "Bob",
+		Amount:   10,
+	})
+	dispatcher.Unsubscribe(s)
+	// Wait for the subscription to be drained before waiting on the deliveries
+	for i := 0; i < 100; i++ {
+		r.lock.Lock()
+		n := len(r.Payloads)
+		r.lock.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	d.Wait()
+	if len(r.Payloads) != 1 || r.Payloads[0].Event.Receiver != "Bob" || r.Payloads[0].Event.Amount != 10 {
+		t.Errorf("Wrong payloads; expected transaction to 'Bob', got '%v'", r.Payloads)
+	}
+}
+
+func TestDigest(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	testinggo.AssertNoError(t, err)
+	store := conveygo.NewMemoryStore()
+	timestamp := bcgo.Timestamp()
+	conversationHash, conversationRecord, err := conveygo.ProtoToRecord("Alice", key, timestamp, &conveygo.Conversation{
+		Topic: "Test",
+	})
+	testinggo.AssertNoError(t, err)
+	messageHash, messageRecord, err := conveygo.ProtoToRecord("Alice", key, timestamp, &conveygo.Message{
+		Content: []byte("Hello"),
+		Type:    conveygo.MediaType_TEXT_PLAIN,
+	})
+	testinggo.AssertNoError(t, err)
+	testinggo.AssertNoError(t, store.NewConversation(conversationHash, conversationRecord, messageHash, messageRecord))
+
+	r := &receiver{Secret: []byte("secret")}
+	d, ts := makeDispatcher(t, store, r, webhooks.TYPE_NEW_DIGEST)
+	defer ts.Close()
+
+	// A low threshold stands in for the Convey-Day channel
+	channel := bcgo.OpenPoWChannel("Test-Day", bcgo.THRESHOLD_Z)
+	d.WatchDigests(channel, 24*time.Hour)
+	node := &bcgo.Node{
+		Alias:    "Alice",
+		Key:      key,
+		Cache:    bcgo.NewMemoryCache(10),
+		Channels: make(map[string]*bcgo.Channel),
+	}
+	node.AddChannel(channel)
+	_, record, err := bcgo.CreateRecord(bcgo.Timestamp(), "Alice", key, nil, nil, []byte{})
+	testinggo.AssertNoError(t, err)
+	_, err = bcgo.WriteRecord(channel.Name, node.Cache, record)
+	testinggo.AssertNoError(t, err)
+	_, _, err = node.Mine(channel, bcgo.THRESHOLD_Z, nil)
+	testinggo.AssertNoError(t, err)
+	d.Wait()
+
+	if len(r.Payloads) != 1 || r.Payloads[0].Type != webhooks.TYPE_NEW_DIGEST {
+		t.Fatalf("Wrong payloads; expected a digest, got '%v'", r.Payloads)
+	}
+	digest := r.Payloads[0].Digest
+	if digest.To-digest.From != uint64(24*time.Hour) || len(digest.Entries) != 1 || digest.Entries[0].Topic != "Test" {
+		t.Errorf("Wrong digest; got '%v'", digest)
+	}
+}